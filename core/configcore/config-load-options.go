@@ -2,8 +2,10 @@ package configcore
 
 // SecretsOptions определяет, какие секреты нужно загружать
 type SecretsOptions struct {
-	Admin bool
-	User  bool
+	Admin                bool
+	User                 bool
+	IntrospectionClients bool
+	AESBucketKey         bool
 }
 
 // GrpsClientsOptions определяет, какие gRPC клиенты нужно загружать
@@ -21,6 +23,7 @@ type ExposedServiceOptions struct {
 // ConfigLoadOptions определяет, какие группы конфигурации нужно загружать
 type ConfigLoadOptions struct {
 	Database             bool
+	Redis                bool
 	RabbitMQConfig       bool
 	Telegram             bool
 	Secrets              SecretsOptions
@@ -2,6 +2,7 @@ package configcore
 
 import (
 	"aidanwoods.dev/go-paseto"
+	"time"
 )
 
 // RedisConfig конфигурация Redis
@@ -10,6 +11,7 @@ type RedisConfig struct {
 	Port     int    `yaml:"port" env-required:"true"`
 	User     string `yaml:"user" env-required:"true"`
 	Password string `yaml:"password" env-required:"true"`
+	Required bool   `yaml:"required"` // не запускать сервисы без Redis; иначе состояние хранится в памяти каждого процесса
 }
 
 // DatabaseConfig конфигурация базы данных
@@ -41,20 +43,38 @@ type BucketStorageConfig struct {
 
 // TelegramConfig конфигурация Telegram
 type TelegramConfig struct {
-	BotToken  string `yaml:"bot_token"`
-	WebAppUrl string `yaml:"web_app_url"`
+	BotToken   string        `yaml:"bot_token"` // токен бота; пусто — вход через Telegram отключен
+	WebAppUrl  string        `yaml:"web_app_url"`
+	AuthMaxAge time.Duration `yaml:"auth_max_age"` // срок действия подписанных данных входа (по умолчанию 1h)
+}
+
+// JWTSigningKeyConfig ключ подписи JWT
+type JWTSigningKeyConfig struct {
+	KID        string `yaml:"kid"`         // идентификатор ключа (заголовок kid)
+	Algorithm  string `yaml:"algorithm"`   // EdDSA, ES256 или RS256
+	PrivateKey string `yaml:"private_key"` // PEM; пусто для ключей, оставленных только для проверки
+	PublicKey  string `yaml:"public_key"`  // PEM; если не указан, выводится из приватного ключа
 }
 
 // AuthJWTConfig конфигурация JWT аутентификации
 type AuthJWTConfig struct {
-	AdminSecret string `yaml:"admin_secret"`
-	UserSecret  string `yaml:"user_secret"`
+	AdminSecret string                `yaml:"admin_secret"`
+	UserSecret  string                `yaml:"user_secret"`   // устаревшая подпись HS512, используется при отсутствии ключей
+	ActiveKeyID string                `yaml:"active_key_id"` // ключ, которым подписываются новые токены
+	SigningKeys []JWTSigningKeyConfig `yaml:"signing_keys"`
+}
+
+// ClientCredentialsConfig учетные данные внутреннего клиента
+type ClientCredentialsConfig struct {
+	ClientID     string `yaml:"client_id"`
+	ClientSecret string `yaml:"client_secret"`
 }
 
 // SecretsConfig конфигурация секретов
 type SecretsConfig struct {
-	AESBucketKey string        `yaml:"aes_bucket_key"`
-	AuthJWT      AuthJWTConfig `yaml:"auth_jwt"`
+	AESBucketKey         string                    `yaml:"aes_bucket_key"`
+	AuthJWT              AuthJWTConfig             `yaml:"auth_jwt"`
+	IntrospectionClients []ClientCredentialsConfig `yaml:"introspection_clients"` // клиенты, которым разрешена интроспекция токенов
 }
 
 // ServiceConfig конфигурация сервиса
@@ -70,7 +90,15 @@ type GrpsClientsConfig struct {
 
 // CorsConfig конфигурация CORS
 type CorsConfig struct {
-	AllowedOrigins []string `yaml:"allowed_origins"`
+	AllowedOrigins []string `yaml:"allowed_origins"` // явные origin; запросы принимаются с учетными данными (cookie), поэтому "*" не допускается
+}
+
+// WebAuthnConfig проверяющая сторона (Relying Party) WebAuthn
+type WebAuthnConfig struct {
+	RPID          string        `yaml:"rp_id"`           // домен сайта без схемы и порта; пусто — вход по ключам доступа отключен
+	RPDisplayName string        `yaml:"rp_display_name"` // название сервиса в диалоге аутентификатора
+	RPOrigins     []string      `yaml:"rp_origins"`      // разрешенные origin; если не заданы, используются cors.allowed_origins
+	Timeout       time.Duration `yaml:"timeout"`         // время на регистрацию или вход (по умолчанию 5m)
 }
 
 // SwaggerConfig конфигурация Swagger
@@ -79,24 +107,125 @@ type SwaggerConfig struct {
 	Pass string `yaml:"pass" env-required:"true"`
 }
 
+// EmailVerificationConfig подтверждение адреса электронной почты
+type EmailVerificationConfig struct {
+	CodeTTL        time.Duration `yaml:"code_ttl"`        // время жизни кода (по умолчанию 15m)
+	MaxAttempts    int           `yaml:"max_attempts"`    // попыток ввода одного кода (по умолчанию 5)
+	ResendInterval time.Duration `yaml:"resend_interval"` // минимальный интервал повторной отправки (по умолчанию 1m)
+	LinkURL        string        `yaml:"link_url"`        // страница подтверждения; если задана, в письмо добавляется ссылка с кодом
+}
+
+// PasswordResetConfig восстановление доступа по email
+type PasswordResetConfig struct {
+	TokenTTL       time.Duration `yaml:"token_ttl"`       // время жизни токена сброса (по умолчанию 30m)
+	ResendInterval time.Duration `yaml:"resend_interval"` // минимальный интервал повторной отправки (по умолчанию 1m)
+	LinkURL        string        `yaml:"link_url"`        // страница сброса пароля; токен передается в параметре token
+}
+
+// MagicLinkConfig вход по одноразовой ссылке из письма
+type MagicLinkConfig struct {
+	TokenTTL       time.Duration `yaml:"token_ttl"`       // время жизни ссылки (по умолчанию 15m)
+	ResendInterval time.Duration `yaml:"resend_interval"` // минимальный интервал между письмами на один адрес (по умолчанию 1m)
+	LinkURL        string        `yaml:"link_url"`        // страница входа; токен передается в параметре token
+}
+
+// OIDCProviderConfig внешний провайдер OpenID Connect для входа
+type OIDCProviderConfig struct {
+	Name         string   `yaml:"name"`   // имя провайдера в URL (/api/auth/oidc/{name}/...)
+	Issuer       string   `yaml:"issuer"` // issuer провайдера; конфигурация загружается из /.well-known/openid-configuration
+	ClientID     string   `yaml:"client_id"`
+	ClientSecret string   `yaml:"client_secret"`
+	RedirectURL  string   `yaml:"redirect_url"` // страница клиента, принимающая code и state
+	Scopes       []string `yaml:"scopes"`       // по умолчанию openid, email, profile
+	TrustEmail   bool     `yaml:"trust_email"`  // привязывать к существующему аккаунту по подтвержденному провайдером email
+}
+
+// OIDCConfig вход через внешних провайдеров OpenID Connect
+type OIDCConfig struct {
+	Providers []OIDCProviderConfig `yaml:"providers"`
+	StateTTL  time.Duration        `yaml:"state_ttl"` // время на авторизацию у провайдера (по умолчанию 10m)
+}
+
+// MFAConfig двухфакторная аутентификация
+type MFAConfig struct {
+	TOTPIssuer  string `yaml:"totp_issuer"`  // издатель в URI otpauth, отображается в приложении-аутентификаторе
+	MaxAttempts int    `yaml:"max_attempts"` // попыток ввода второго фактора на один промежуточный токен (по умолчанию 5)
+}
+
+// OAuthServerConfig сервер авторизации OAuth 2.0 для клиентских приложений
+type OAuthServerConfig struct {
+	CodeTTL time.Duration `yaml:"code_ttl"` // время жизни кода авторизации (по умолчанию 1m)
+	// Страница входа и согласия фронтенда, которая принимает параметры запроса авторизации
+	// и вызывает /oauth/authorize; публикуется как authorization_endpoint (по умолчанию {issuer}/oauth/authorize)
+	AuthorizationPageURL string `yaml:"authorization_page_url"`
+}
+
+// LockoutConfig защита от перебора учетных данных: задержки и временные блокировки по аккаунту и IP-адресу
+type LockoutConfig struct {
+	AccountMaxFailures int           `yaml:"account_max_failures"` // неудачных попыток по аккаунту до блокировки (по умолчанию 5)
+	IPMaxFailures      int           `yaml:"ip_max_failures"`      // неудачных попыток с IP-адреса до блокировки (по умолчанию 50)
+	FailureWindow      time.Duration `yaml:"failure_window"`       // окно подсчета неудачных попыток (по умолчанию 15m)
+	BaseDelay          time.Duration `yaml:"base_delay"`           // задержка после первой неудачи, удваивается (по умолчанию 1s)
+	MaxDelay           time.Duration `yaml:"max_delay"`            // (по умолчанию 30s)
+	BaseLockout        time.Duration `yaml:"base_lockout"`         // первая блокировка, удваивается с каждой следующей (по умолчанию 5m)
+	MaxLockout         time.Duration `yaml:"max_lockout"`          // (по умолчанию 1h)
+}
+
+// StepUpConfig повторная аутентификация для чувствительных операций
+type StepUpConfig struct {
+	MaxAge time.Duration `yaml:"max_age"` // допустимое время с последней аутентификации (по умолчанию 5m)
+}
+
 // RestServiceConfig конфигурация REST сервиса
 type RestServiceConfig struct {
-	PortRest int           `yaml:"port_rest" env-required:"true"`
-	Cors     CorsConfig    `yaml:"cors"`
-	Swagger  SwaggerConfig `yaml:"swagger"`
+	PortRest          int                     `yaml:"port_rest" env-required:"true"`
+	Cors              CorsConfig              `yaml:"cors"`
+	TrustedProxies    []string                `yaml:"trusted_proxies"` // адреса и сети (CIDR) обратных прокси, которым доверяется X-Forwarded-For
+	WebAuthn          WebAuthnConfig          `yaml:"webauthn"`
+	Swagger           SwaggerConfig           `yaml:"swagger"`
+	EmailVerification EmailVerificationConfig `yaml:"email_verification"`
+	PasswordReset     PasswordResetConfig     `yaml:"password_reset"`
+	MagicLink         MagicLinkConfig         `yaml:"magic_link"`
+	OIDC              OIDCConfig              `yaml:"oidc"`
+	OAuthServer       OAuthServerConfig       `yaml:"oauth_server"`
+	MFA               MFAConfig               `yaml:"mfa"`
+	Lockout           LockoutConfig           `yaml:"lockout"`
+	StepUp            StepUpConfig            `yaml:"step_up"`
 }
 
-// PASETOConfig конфигурация PASETO
+// PASETOConfig конфигурация PASETO.
+// Ключи задаются в hex и разбираются при загрузке конфигурации.
 type PASETOConfig struct {
-	SymmetricKey  paseto.V4SymmetricKey
-	ImplicitBytes []byte
+	SymmetricKeyHex string `yaml:"symmetric_key"` // v4.local
+	SecretKeyHex    string `yaml:"secret_key"`    // v4.public, подпись
+	PublicKeyHex    string `yaml:"public_key"`    // v4.public, проверка; выводится из secret_key, если не указан
+	Implicit        string `yaml:"implicit"`      // неявное утверждение, связывающее токен с сервисом
+
+	SymmetricKey  paseto.V4SymmetricKey         `yaml:"-"`
+	SecretKey     *paseto.V4AsymmetricSecretKey `yaml:"-"`
+	PublicKey     *paseto.V4AsymmetricPublicKey `yaml:"-"`
+	ImplicitBytes []byte                        `yaml:"-"`
+}
+
+// IPBindingConfig привязка токенов к IP-адресу клиента
+type IPBindingConfig struct {
+	Mode         string `yaml:"mode"`           // strict (по умолчанию), subnet, asn или off
+	Action       string `yaml:"action"`         // действие при несовпадении: block (по умолчанию), step_up или notify
+	SubnetV4Bits int    `yaml:"subnet_v4_bits"` // размер подсети IPv4 для режима subnet (по умолчанию 24)
+	SubnetV6Bits int    `yaml:"subnet_v6_bits"` // размер подсети IPv6 для режима subnet (по умолчанию 64)
+	ASNDatabase  string `yaml:"asn_database"`   // путь к базе ASN в формате MaxMind DB для режима asn
 }
 
 // GrpcServiceConfig конфигурация gRPC сервиса
 type GrpcServiceConfig struct {
 	GrpcPort     int `yaml:"grpc_port" env-required:"true"`
 	CertFileName string
-	PASETO       PASETOConfig
+	TokenFormat  string          `yaml:"token_format"` // jwt (по умолчанию), paseto_v4_local или paseto_v4_public
+	PASETO       PASETOConfig    `yaml:"paseto"`
+	Issuer       string          `yaml:"issuer"`   // claim iss выпускаемых токенов
+	Audience     string          `yaml:"audience"` // claim aud выпускаемых токенов
+	Leeway       time.Duration   `yaml:"leeway"`   // допустимое расхождение часов при проверке токенов
+	IPBinding    IPBindingConfig `yaml:"ip_binding"`
 }
 
 // ExposedServiceConfig конфигурация всех сервисов
@@ -118,7 +247,9 @@ type SMTPMailServer struct {
 type Config struct {
 	SMTPMailServer       SMTPMailServer       `yaml:"smtp_mail_server"`
 	Database             DatabaseConfig       `yaml:"database"`
+	Redis                RedisConfig          `yaml:"redis"`
 	RabbitMQConfig       RabbitMQConfig       `yaml:"rabbitmq"`
+	Telegram             TelegramConfig       `yaml:"telegram"`
 	Secrets              SecretsConfig        `yaml:"secrets"`
 	GrpsClients          GrpsClientsConfig    `yaml:"grps_clients"`
 	ExposedServiceConfig ExposedServiceConfig `yaml:"exposed_service_config"`
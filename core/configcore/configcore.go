@@ -1,6 +1,7 @@
 package configcore
 
 import (
+	"aidanwoods.dev/go-paseto"
 	_ "embed"
 	"errors"
 	"fmt"
@@ -47,6 +48,10 @@ func LoadConfig(options *ConfigLoadOptions) (*Config, error) {
 	// Копируем только нужные поля на основе опций
 	copyConfigFields(options, &config, result)
 
+	if err := loadPASETOKeys(&result.ExposedServiceConfig.AuthService.PASETO); err != nil {
+		return nil, fmt.Errorf("paseto keys error: %v", err)
+	}
+
 	logrus.Info("✅ Config loaded successfully")
 	return result, nil
 }
@@ -58,17 +63,35 @@ func copyConfigFields(options *ConfigLoadOptions, source, target *Config) {
 		target.Database = source.Database
 	}
 
+	// Копируем Redis
+	if options.Redis {
+		target.Redis = source.Redis
+	}
+
 	// Копируем RabbitMQ
 	if options.RabbitMQConfig {
 		target.RabbitMQConfig = source.RabbitMQConfig
 	}
 
+	// Копируем Telegram
+	if options.Telegram {
+		target.Telegram = source.Telegram
+	}
+
 	// Копируем Secrets
 	if options.Secrets.Admin {
 		target.Secrets.AuthJWT.AdminSecret = source.Secrets.AuthJWT.AdminSecret
 	}
 	if options.Secrets.User {
 		target.Secrets.AuthJWT.UserSecret = source.Secrets.AuthJWT.UserSecret
+		target.Secrets.AuthJWT.ActiveKeyID = source.Secrets.AuthJWT.ActiveKeyID
+		target.Secrets.AuthJWT.SigningKeys = source.Secrets.AuthJWT.SigningKeys
+	}
+	if options.Secrets.IntrospectionClients {
+		target.Secrets.IntrospectionClients = source.Secrets.IntrospectionClients
+	}
+	if options.Secrets.AESBucketKey {
+		target.Secrets.AESBucketKey = source.Secrets.AESBucketKey
 	}
 
 	// Копируем GrpsClients
@@ -87,3 +110,38 @@ func copyConfigFields(options *ConfigLoadOptions, source, target *Config) {
 		target.ExposedServiceConfig.NotifyService = source.ExposedServiceConfig.NotifyService
 	}
 }
+
+// loadPASETOKeys разбирает ключи PASETO из hex-представления
+func loadPASETOKeys(cfg *PASETOConfig) error {
+	if cfg.SymmetricKeyHex != "" {
+		key, err := paseto.V4SymmetricKeyFromHex(cfg.SymmetricKeyHex)
+		if err != nil {
+			return fmt.Errorf("symmetric_key: %v", err)
+		}
+		cfg.SymmetricKey = key
+	}
+
+	if cfg.SecretKeyHex != "" {
+		key, err := paseto.NewV4AsymmetricSecretKeyFromHex(cfg.SecretKeyHex)
+		if err != nil {
+			return fmt.Errorf("secret_key: %v", err)
+		}
+		publicKey := key.Public()
+		cfg.SecretKey = &key
+		cfg.PublicKey = &publicKey
+	}
+
+	if cfg.PublicKeyHex != "" {
+		key, err := paseto.NewV4AsymmetricPublicKeyFromHex(cfg.PublicKeyHex)
+		if err != nil {
+			return fmt.Errorf("public_key: %v", err)
+		}
+		cfg.PublicKey = &key
+	}
+
+	if cfg.Implicit != "" {
+		cfg.ImplicitBytes = []byte(cfg.Implicit)
+	}
+
+	return nil
+}
@@ -10,10 +10,19 @@ import (
 )
 
 type ModuleDB struct {
-	Pool          *pgxpool.Pool
-	gormDB        *gorm.DB
-	Users         dbcore.UserDBI
-	Notifications *dbcore.NotificationDB
+	Pool           *pgxpool.Pool
+	gormDB         *gorm.DB
+	Users          dbcore.UserDBI
+	Notifications  *dbcore.NotificationDB
+	RefreshTokens  dbcore.RefreshTokenDBI
+	Sessions       dbcore.SessionDBI
+	RecoveryCodes  dbcore.RecoveryCodeDBI
+	WebAuthn       dbcore.WebAuthnCredentialDBI
+	Identities     dbcore.UserIdentityDBI
+	OAuthClients   dbcore.OAuthClientDBI
+	OAuthConsents  dbcore.OAuthConsentDBI
+	PersonalTokens dbcore.PersonalAccessTokenDBI
+	Impersonations dbcore.ImpersonationDBI
 }
 
 func NewModuleDB(
@@ -45,6 +54,15 @@ func NewModuleDB(
 func initDBModules(modules *ModuleDB) *ModuleDB {
 	modules.Users = dbcore.NewUserDB(modules.Pool)
 	modules.Notifications = dbcore.NewNotificationDB(modules.Pool)
+	modules.RefreshTokens = dbcore.NewRefreshTokenDB(modules.Pool)
+	modules.Sessions = dbcore.NewSessionDB(modules.Pool)
+	modules.RecoveryCodes = dbcore.NewRecoveryCodeDB(modules.Pool)
+	modules.WebAuthn = dbcore.NewWebAuthnCredentialDB(modules.Pool)
+	modules.Identities = dbcore.NewUserIdentityDB(modules.Pool)
+	modules.OAuthClients = dbcore.NewOAuthClientDB(modules.Pool)
+	modules.OAuthConsents = dbcore.NewOAuthConsentDB(modules.Pool)
+	modules.PersonalTokens = dbcore.NewPersonalAccessTokenDB(modules.Pool)
+	modules.Impersonations = dbcore.NewImpersonationDB(modules.Pool)
 	return modules
 }
 
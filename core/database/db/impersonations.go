@@ -0,0 +1,132 @@
+package dbcore
+
+import (
+	dbcoretablenames "authentication_service/core/database/table_names"
+	errm "authentication_service/core/errmodule"
+	"authentication_service/core/typescore"
+	dbutils "authentication_service/core/utilscore/db"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+type ImpersonationDB struct {
+	pool *pgxpool.Pool
+}
+
+func NewImpersonationDB(pool *pgxpool.Pool) *ImpersonationDB {
+	return &ImpersonationDB{pool: pool}
+}
+
+type ImpersonationDBI interface {
+	GetImpersonationsListDB(ctx context.Context, options ...typescore.ListDbOptions) ([]*typescore.Impersonation, uint64, *errm.Error)
+	CreateImpersonationDB(ctx context.Context, tx pgx.Tx, impersonationObj *typescore.Impersonation, returnObj ...bool) (*typescore.Impersonation, pgx.Tx, *errm.Error)
+}
+
+// GetImpersonationsListDB Получение записей журнала имперсонации
+func (u *ImpersonationDB) GetImpersonationsListDB(ctx context.Context, options ...typescore.ListDbOptions) ([]*typescore.Impersonation, uint64, *errm.Error) {
+	// logrus.Info("🩵 GetImpersonationsListDB")
+	fields := dbutils.GetStructFieldsDB(&typescore.Impersonation{}, nil)
+	// Добавляем поле total_count
+	selectFields := append(fields, "COUNT(*) OVER() AS total_count")
+
+	opts, filter, err := dbutils.GetOptionsDB[typescore.Impersonation](options...)
+	if err != nil {
+		return nil, 0, errm.NewError(
+			"error_get",
+			fmt.Errorf("get_options: failed to create SELECT %s SQL: %w", dbcoretablenames.TableNameImpersonations.ToString(), err),
+		)
+	}
+
+	query := dbutils.BuildSelectQuery(dbcoretablenames.TableNameImpersonations.ToString(), selectFields)
+	query = dbutils.SetterLimitAndOffsetQuery(query, opts.Offset, opts.Limit)
+	query = dbutils.ApplyFilters(query, filter, opts.LikeFields)
+	query = query.OrderBy("created_at DESC")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, 0, errm.NewError(
+			"error_select",
+			fmt.Errorf("sql: failed to create SELECT %s SQL: %v", dbcoretablenames.TableNameImpersonations.ToString(), err),
+		)
+	}
+
+	rows, err := u.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, 0, errm.NewError(
+			"error_select",
+			fmt.Errorf("query: failed to create SELECT %s SQL: %v", dbcoretablenames.TableNameImpersonations.ToString(), err),
+		)
+	}
+	defer rows.Close()
+
+	var impersonations []*typescore.Impersonation
+	var totalCount uint64
+	for rows.Next() {
+		impersonation := &typescore.Impersonation{}
+		if err := dbutils.ScanRowsToStructRows(rows, impersonation, &totalCount); err != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "GetImpersonationsListDB-ScanRowsToStructRows", err)
+			continue
+		}
+
+		impersonations = append(impersonations, impersonation)
+	}
+
+	return impersonations, totalCount, nil
+}
+
+// CreateImpersonationDB Сохранение записи журнала имперсонации
+func (u *ImpersonationDB) CreateImpersonationDB(ctx context.Context, tx pgx.Tx, impersonationObj *typescore.Impersonation, returnObj ...bool) (*typescore.Impersonation, pgx.Tx, *errm.Error) {
+	// logrus.Info("🩵 CreateImpersonationDB")
+	if impersonationObj == nil || impersonationObj.ID == nil {
+		return nil, nil, errm.NewError(
+			"error_insert",
+			fmt.Errorf("failed to create INSERT %s SQL: %v", dbcoretablenames.TableNameImpersonations.ToString(), errors.New("impersonationObj or id is nil")),
+		)
+	}
+
+	err := dbutils.ExecuteTx(ctx, u.pool, tx, func(tx pgx.Tx) error {
+		query := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).Insert(dbcoretablenames.TableNameImpersonations.ToString())
+
+		sqlV, args, errW := dbutils.GenerateInsertRequest(query, impersonationObj, typescore.InsertOptions{
+			IgnoreConflict: false,
+		})
+		if errW != nil {
+			return errW
+		}
+
+		if _, err := tx.Exec(ctx, *sqlV, args...); err != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "CreateImpersonationDB-Exec", err)
+			return err
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, tx, err
+	}
+
+	returnObjBool := false
+	if len(returnObj) > 0 {
+		returnObjBool = returnObj[0]
+	}
+
+	if returnObjBool {
+		impersonations, _, err := u.GetImpersonationsListDB(ctx, typescore.ListDbOptions{Filtering: &typescore.Impersonation{
+			ID: impersonationObj.ID,
+		}})
+		if err != nil {
+			return nil, tx, err
+		}
+		if len(impersonations) > 0 {
+			return impersonations[0], tx, nil
+		}
+	}
+
+	return nil, tx, nil
+}
@@ -0,0 +1,194 @@
+package dbcore
+
+import (
+	dbcoretablenames "authentication_service/core/database/table_names"
+	errm "authentication_service/core/errmodule"
+	"authentication_service/core/typescore"
+	dbutils "authentication_service/core/utilscore/db"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+type OAuthClientDB struct {
+	pool *pgxpool.Pool
+}
+
+func NewOAuthClientDB(pool *pgxpool.Pool) *OAuthClientDB {
+	return &OAuthClientDB{pool: pool}
+}
+
+type OAuthClientDBI interface {
+	GetOAuthClientsListDB(ctx context.Context, options ...typescore.ListDbOptions) ([]*typescore.OAuthClient, uint64, *errm.Error)
+	CreateOAuthClientDB(ctx context.Context, tx pgx.Tx, clientObj *typescore.OAuthClient, returnObj ...bool) (*typescore.OAuthClient, pgx.Tx, *errm.Error)
+	UpdateOAuthClientDB(ctx context.Context, tx pgx.Tx, paramsUpdate *typescore.OAuthClient, returnObj ...bool) (*typescore.OAuthClient, pgx.Tx, *errm.Error)
+}
+
+// GetOAuthClientsListDB Получение клиентов OAuth 2.0
+func (u *OAuthClientDB) GetOAuthClientsListDB(ctx context.Context, options ...typescore.ListDbOptions) ([]*typescore.OAuthClient, uint64, *errm.Error) {
+	// logrus.Info("🩵 GetOAuthClientsListDB")
+	fields := dbutils.GetStructFieldsDB(&typescore.OAuthClient{}, nil)
+	// Добавляем поле total_count
+	selectFields := append(fields, "COUNT(*) OVER() AS total_count")
+
+	opts, filter, err := dbutils.GetOptionsDB[typescore.OAuthClient](options...)
+	if err != nil {
+		return nil, 0, errm.NewError(
+			"error_get",
+			fmt.Errorf("get_options: failed to create SELECT %s SQL: %w", dbcoretablenames.TableNameOAuthClients.ToString(), err),
+		)
+	}
+
+	query := dbutils.BuildSelectQuery(dbcoretablenames.TableNameOAuthClients.ToString(), selectFields)
+	query = dbutils.SetterLimitAndOffsetQuery(query, opts.Offset, opts.Limit)
+	query = dbutils.ApplyFilters(query, filter, opts.LikeFields)
+	query = query.OrderBy("created_at DESC")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, 0, errm.NewError(
+			"error_select",
+			fmt.Errorf("sql: failed to create SELECT %s SQL: %v", dbcoretablenames.TableNameOAuthClients.ToString(), err),
+		)
+	}
+
+	rows, err := u.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, 0, errm.NewError(
+			"error_select",
+			fmt.Errorf("query: failed to create SELECT %s SQL: %v", dbcoretablenames.TableNameOAuthClients.ToString(), err),
+		)
+	}
+	defer rows.Close()
+
+	var clients []*typescore.OAuthClient
+	var totalCount uint64
+	for rows.Next() {
+		client := &typescore.OAuthClient{}
+		if err := dbutils.ScanRowsToStructRows(rows, client, &totalCount); err != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "GetOAuthClientsListDB-ScanRowsToStructRows", err)
+			continue
+		}
+
+		clients = append(clients, client)
+	}
+
+	return clients, totalCount, nil
+}
+
+// CreateOAuthClientDB Регистрация клиента OAuth 2.0
+func (u *OAuthClientDB) CreateOAuthClientDB(ctx context.Context, tx pgx.Tx, clientObj *typescore.OAuthClient, returnObj ...bool) (*typescore.OAuthClient, pgx.Tx, *errm.Error) {
+	// logrus.Info("🩵 CreateOAuthClientDB")
+	if clientObj == nil || clientObj.ID == nil {
+		return nil, nil, errm.NewError(
+			"error_insert",
+			fmt.Errorf("failed to create INSERT %s SQL: %v", dbcoretablenames.TableNameOAuthClients.ToString(), errors.New("clientObj or id is nil")),
+		)
+	}
+
+	err := dbutils.ExecuteTx(ctx, u.pool, tx, func(tx pgx.Tx) error {
+		query := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).Insert(dbcoretablenames.TableNameOAuthClients.ToString())
+
+		sqlV, args, errW := dbutils.GenerateInsertRequest(query, clientObj, typescore.InsertOptions{
+			IgnoreConflict: false,
+		})
+		if errW != nil {
+			return errW
+		}
+
+		if _, err := tx.Exec(ctx, *sqlV, args...); err != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "CreateOAuthClientDB-Exec", err)
+			return err
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, tx, err
+	}
+
+	returnObjBool := false
+	if len(returnObj) > 0 {
+		returnObjBool = returnObj[0]
+	}
+
+	if returnObjBool {
+		clients, _, err := u.GetOAuthClientsListDB(ctx, typescore.ListDbOptions{Filtering: &typescore.OAuthClient{
+			ID: clientObj.ID,
+		}})
+		if err != nil {
+			return nil, tx, err
+		}
+		if len(clients) > 0 {
+			return clients[0], tx, nil
+		}
+	}
+
+	return nil, tx, nil
+}
+
+// UpdateOAuthClientDB Обновление клиента OAuth 2.0
+func (u *OAuthClientDB) UpdateOAuthClientDB(ctx context.Context, tx pgx.Tx, paramsUpdate *typescore.OAuthClient, returnObj ...bool) (*typescore.OAuthClient, pgx.Tx, *errm.Error) {
+	// logrus.Info("🩵 UpdateOAuthClientDB")
+	if paramsUpdate == nil || paramsUpdate.ID == nil {
+		logrus.Errorf("❌ UpdateOAuthClientDB error: %s", errors.New("id is nil"))
+		return nil, nil, errm.NewError(
+			"error_update",
+			fmt.Errorf("failed to create UPDATE %s SQL: %v", dbcoretablenames.TableNameOAuthClients.ToString(), errors.New("id is nil")),
+		)
+	}
+
+	err := dbutils.ExecuteTx(ctx, u.pool, tx, func(tx pgx.Tx) error {
+		query := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).Update(dbcoretablenames.TableNameOAuthClients.ToString())
+
+		// Используем функцию для добавления ненулевых полей в запрос
+		query = dbutils.AddNonNullFieldsToQueryUpdate(query, *paramsUpdate)
+
+		// Добавляем условие WHERE
+		query = query.Where(squirrel.Eq{"id": paramsUpdate.ID})
+
+		sql, args, err := query.ToSql()
+		if err != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "UpdateOAuthClientDB-ToSql", err)
+			return err
+		}
+
+		_, err = tx.Exec(ctx, sql, args...)
+		if err != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "UpdateOAuthClientDB-Exec", err)
+			return err
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, tx, err
+	}
+
+	returnObjBool := false
+	if len(returnObj) > 0 {
+		returnObjBool = returnObj[0]
+	}
+
+	if returnObjBool {
+		options := typescore.ListDbOptions{Filtering: &typescore.OAuthClient{
+			ID: paramsUpdate.ID,
+		}}
+		getInfoUp, _, errW := u.GetOAuthClientsListDB(ctx, options)
+		if errW != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "UpdateOAuthClientDB-GetOAuthClientsListDB", errW)
+			return nil, nil, errW
+		}
+		if len(getInfoUp) > 0 {
+			return getInfoUp[0], tx, nil
+		}
+	}
+
+	return nil, tx, nil
+}
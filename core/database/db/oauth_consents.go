@@ -0,0 +1,194 @@
+package dbcore
+
+import (
+	dbcoretablenames "authentication_service/core/database/table_names"
+	errm "authentication_service/core/errmodule"
+	"authentication_service/core/typescore"
+	dbutils "authentication_service/core/utilscore/db"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+type OAuthConsentDB struct {
+	pool *pgxpool.Pool
+}
+
+func NewOAuthConsentDB(pool *pgxpool.Pool) *OAuthConsentDB {
+	return &OAuthConsentDB{pool: pool}
+}
+
+type OAuthConsentDBI interface {
+	GetOAuthConsentsListDB(ctx context.Context, options ...typescore.ListDbOptions) ([]*typescore.OAuthConsent, uint64, *errm.Error)
+	CreateOAuthConsentDB(ctx context.Context, tx pgx.Tx, consentObj *typescore.OAuthConsent, returnObj ...bool) (*typescore.OAuthConsent, pgx.Tx, *errm.Error)
+	UpdateOAuthConsentDB(ctx context.Context, tx pgx.Tx, paramsUpdate *typescore.OAuthConsent, returnObj ...bool) (*typescore.OAuthConsent, pgx.Tx, *errm.Error)
+}
+
+// GetOAuthConsentsListDB Получение согласий пользователей клиентам OAuth 2.0
+func (u *OAuthConsentDB) GetOAuthConsentsListDB(ctx context.Context, options ...typescore.ListDbOptions) ([]*typescore.OAuthConsent, uint64, *errm.Error) {
+	// logrus.Info("🩵 GetOAuthConsentsListDB")
+	fields := dbutils.GetStructFieldsDB(&typescore.OAuthConsent{}, nil)
+	// Добавляем поле total_count
+	selectFields := append(fields, "COUNT(*) OVER() AS total_count")
+
+	opts, filter, err := dbutils.GetOptionsDB[typescore.OAuthConsent](options...)
+	if err != nil {
+		return nil, 0, errm.NewError(
+			"error_get",
+			fmt.Errorf("get_options: failed to create SELECT %s SQL: %w", dbcoretablenames.TableNameOAuthConsents.ToString(), err),
+		)
+	}
+
+	query := dbutils.BuildSelectQuery(dbcoretablenames.TableNameOAuthConsents.ToString(), selectFields)
+	query = dbutils.SetterLimitAndOffsetQuery(query, opts.Offset, opts.Limit)
+	query = dbutils.ApplyFilters(query, filter, opts.LikeFields)
+	query = query.OrderBy("created_at DESC")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, 0, errm.NewError(
+			"error_select",
+			fmt.Errorf("sql: failed to create SELECT %s SQL: %v", dbcoretablenames.TableNameOAuthConsents.ToString(), err),
+		)
+	}
+
+	rows, err := u.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, 0, errm.NewError(
+			"error_select",
+			fmt.Errorf("query: failed to create SELECT %s SQL: %v", dbcoretablenames.TableNameOAuthConsents.ToString(), err),
+		)
+	}
+	defer rows.Close()
+
+	var consents []*typescore.OAuthConsent
+	var totalCount uint64
+	for rows.Next() {
+		consent := &typescore.OAuthConsent{}
+		if err := dbutils.ScanRowsToStructRows(rows, consent, &totalCount); err != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "GetOAuthConsentsListDB-ScanRowsToStructRows", err)
+			continue
+		}
+
+		consents = append(consents, consent)
+	}
+
+	return consents, totalCount, nil
+}
+
+// CreateOAuthConsentDB Сохранение согласия пользователя
+func (u *OAuthConsentDB) CreateOAuthConsentDB(ctx context.Context, tx pgx.Tx, consentObj *typescore.OAuthConsent, returnObj ...bool) (*typescore.OAuthConsent, pgx.Tx, *errm.Error) {
+	// logrus.Info("🩵 CreateOAuthConsentDB")
+	if consentObj == nil || consentObj.ID == nil {
+		return nil, nil, errm.NewError(
+			"error_insert",
+			fmt.Errorf("failed to create INSERT %s SQL: %v", dbcoretablenames.TableNameOAuthConsents.ToString(), errors.New("consentObj or id is nil")),
+		)
+	}
+
+	err := dbutils.ExecuteTx(ctx, u.pool, tx, func(tx pgx.Tx) error {
+		query := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).Insert(dbcoretablenames.TableNameOAuthConsents.ToString())
+
+		sqlV, args, errW := dbutils.GenerateInsertRequest(query, consentObj, typescore.InsertOptions{
+			IgnoreConflict: false,
+		})
+		if errW != nil {
+			return errW
+		}
+
+		if _, err := tx.Exec(ctx, *sqlV, args...); err != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "CreateOAuthConsentDB-Exec", err)
+			return err
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, tx, err
+	}
+
+	returnObjBool := false
+	if len(returnObj) > 0 {
+		returnObjBool = returnObj[0]
+	}
+
+	if returnObjBool {
+		consents, _, err := u.GetOAuthConsentsListDB(ctx, typescore.ListDbOptions{Filtering: &typescore.OAuthConsent{
+			ID: consentObj.ID,
+		}})
+		if err != nil {
+			return nil, tx, err
+		}
+		if len(consents) > 0 {
+			return consents[0], tx, nil
+		}
+	}
+
+	return nil, tx, nil
+}
+
+// UpdateOAuthConsentDB Обновление согласия пользователя (области доступа)
+func (u *OAuthConsentDB) UpdateOAuthConsentDB(ctx context.Context, tx pgx.Tx, paramsUpdate *typescore.OAuthConsent, returnObj ...bool) (*typescore.OAuthConsent, pgx.Tx, *errm.Error) {
+	// logrus.Info("🩵 UpdateOAuthConsentDB")
+	if paramsUpdate == nil || paramsUpdate.ID == nil {
+		logrus.Errorf("❌ UpdateOAuthConsentDB error: %s", errors.New("id is nil"))
+		return nil, nil, errm.NewError(
+			"error_update",
+			fmt.Errorf("failed to create UPDATE %s SQL: %v", dbcoretablenames.TableNameOAuthConsents.ToString(), errors.New("id is nil")),
+		)
+	}
+
+	err := dbutils.ExecuteTx(ctx, u.pool, tx, func(tx pgx.Tx) error {
+		query := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).Update(dbcoretablenames.TableNameOAuthConsents.ToString())
+
+		// Используем функцию для добавления ненулевых полей в запрос
+		query = dbutils.AddNonNullFieldsToQueryUpdate(query, *paramsUpdate)
+
+		// Добавляем условие WHERE
+		query = query.Where(squirrel.Eq{"id": paramsUpdate.ID})
+
+		sql, args, err := query.ToSql()
+		if err != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "UpdateOAuthConsentDB-ToSql", err)
+			return err
+		}
+
+		_, err = tx.Exec(ctx, sql, args...)
+		if err != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "UpdateOAuthConsentDB-Exec", err)
+			return err
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, tx, err
+	}
+
+	returnObjBool := false
+	if len(returnObj) > 0 {
+		returnObjBool = returnObj[0]
+	}
+
+	if returnObjBool {
+		options := typescore.ListDbOptions{Filtering: &typescore.OAuthConsent{
+			ID: paramsUpdate.ID,
+		}}
+		getInfoUp, _, errW := u.GetOAuthConsentsListDB(ctx, options)
+		if errW != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "UpdateOAuthConsentDB-GetOAuthConsentsListDB", errW)
+			return nil, nil, errW
+		}
+		if len(getInfoUp) > 0 {
+			return getInfoUp[0], tx, nil
+		}
+	}
+
+	return nil, tx, nil
+}
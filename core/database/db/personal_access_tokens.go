@@ -0,0 +1,244 @@
+package dbcore
+
+import (
+	dbcoretablenames "authentication_service/core/database/table_names"
+	errm "authentication_service/core/errmodule"
+	"authentication_service/core/typescore"
+	dbutils "authentication_service/core/utilscore/db"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+type PersonalAccessTokenDB struct {
+	pool *pgxpool.Pool
+}
+
+func NewPersonalAccessTokenDB(pool *pgxpool.Pool) *PersonalAccessTokenDB {
+	return &PersonalAccessTokenDB{pool: pool}
+}
+
+type PersonalAccessTokenDBI interface {
+	GetPersonalAccessTokensListDB(ctx context.Context, options ...typescore.ListDbOptions) ([]*typescore.PersonalAccessToken, uint64, *errm.Error)
+	CreatePersonalAccessTokenDB(ctx context.Context, tx pgx.Tx, tokenObj *typescore.PersonalAccessToken, returnObj ...bool) (*typescore.PersonalAccessToken, pgx.Tx, *errm.Error)
+	UpdatePersonalAccessTokenDB(ctx context.Context, tx pgx.Tx, paramsUpdate *typescore.PersonalAccessToken, returnObj ...bool) (*typescore.PersonalAccessToken, pgx.Tx, *errm.Error)
+	DeletePersonalAccessTokenDB(ctx context.Context, userID, id string) (bool, *errm.Error)
+	RevokeUserPersonalTokensDB(ctx context.Context, tx pgx.Tx, userID string) *errm.Error
+}
+
+// GetPersonalAccessTokensListDB Получение персональных токенов доступа
+func (u *PersonalAccessTokenDB) GetPersonalAccessTokensListDB(ctx context.Context, options ...typescore.ListDbOptions) ([]*typescore.PersonalAccessToken, uint64, *errm.Error) {
+	// logrus.Info("🩵 GetPersonalAccessTokensListDB")
+	fields := dbutils.GetStructFieldsDB(&typescore.PersonalAccessToken{}, nil)
+	// Добавляем поле total_count
+	selectFields := append(fields, "COUNT(*) OVER() AS total_count")
+
+	opts, filter, err := dbutils.GetOptionsDB[typescore.PersonalAccessToken](options...)
+	if err != nil {
+		return nil, 0, errm.NewError(
+			"error_get",
+			fmt.Errorf("get_options: failed to create SELECT %s SQL: %w", dbcoretablenames.TableNamePersonalTokens.ToString(), err),
+		)
+	}
+
+	query := dbutils.BuildSelectQuery(dbcoretablenames.TableNamePersonalTokens.ToString(), selectFields)
+	query = dbutils.SetterLimitAndOffsetQuery(query, opts.Offset, opts.Limit)
+	query = dbutils.ApplyFilters(query, filter, opts.LikeFields)
+	query = query.OrderBy("created_at DESC")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, 0, errm.NewError(
+			"error_select",
+			fmt.Errorf("sql: failed to create SELECT %s SQL: %v", dbcoretablenames.TableNamePersonalTokens.ToString(), err),
+		)
+	}
+
+	rows, err := u.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, 0, errm.NewError(
+			"error_select",
+			fmt.Errorf("query: failed to create SELECT %s SQL: %v", dbcoretablenames.TableNamePersonalTokens.ToString(), err),
+		)
+	}
+	defer rows.Close()
+
+	var tokens []*typescore.PersonalAccessToken
+	var totalCount uint64
+	for rows.Next() {
+		token := &typescore.PersonalAccessToken{}
+		if err := dbutils.ScanRowsToStructRows(rows, token, &totalCount); err != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "GetPersonalAccessTokensListDB-ScanRowsToStructRows", err)
+			continue
+		}
+
+		tokens = append(tokens, token)
+	}
+
+	return tokens, totalCount, nil
+}
+
+// CreatePersonalAccessTokenDB Сохранение персонального токена доступа
+func (u *PersonalAccessTokenDB) CreatePersonalAccessTokenDB(ctx context.Context, tx pgx.Tx, tokenObj *typescore.PersonalAccessToken, returnObj ...bool) (*typescore.PersonalAccessToken, pgx.Tx, *errm.Error) {
+	// logrus.Info("🩵 CreatePersonalAccessTokenDB")
+	if tokenObj == nil || tokenObj.ID == nil {
+		return nil, nil, errm.NewError(
+			"error_insert",
+			fmt.Errorf("failed to create INSERT %s SQL: %v", dbcoretablenames.TableNamePersonalTokens.ToString(), errors.New("tokenObj or id is nil")),
+		)
+	}
+
+	err := dbutils.ExecuteTx(ctx, u.pool, tx, func(tx pgx.Tx) error {
+		query := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).Insert(dbcoretablenames.TableNamePersonalTokens.ToString())
+
+		sqlV, args, errW := dbutils.GenerateInsertRequest(query, tokenObj, typescore.InsertOptions{
+			IgnoreConflict: false,
+		})
+		if errW != nil {
+			return errW
+		}
+
+		if _, err := tx.Exec(ctx, *sqlV, args...); err != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "CreatePersonalAccessTokenDB-Exec", err)
+			return err
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, tx, err
+	}
+
+	returnObjBool := false
+	if len(returnObj) > 0 {
+		returnObjBool = returnObj[0]
+	}
+
+	if returnObjBool {
+		tokens, _, err := u.GetPersonalAccessTokensListDB(ctx, typescore.ListDbOptions{Filtering: &typescore.PersonalAccessToken{
+			ID: tokenObj.ID,
+		}})
+		if err != nil {
+			return nil, tx, err
+		}
+		if len(tokens) > 0 {
+			return tokens[0], tx, nil
+		}
+	}
+
+	return nil, tx, nil
+}
+
+// UpdatePersonalAccessTokenDB Обновление персонального токена доступа (время и IP-адрес использования)
+func (u *PersonalAccessTokenDB) UpdatePersonalAccessTokenDB(ctx context.Context, tx pgx.Tx, paramsUpdate *typescore.PersonalAccessToken, returnObj ...bool) (*typescore.PersonalAccessToken, pgx.Tx, *errm.Error) {
+	// logrus.Info("🩵 UpdatePersonalAccessTokenDB")
+	if paramsUpdate == nil || paramsUpdate.ID == nil {
+		logrus.Errorf("❌ UpdatePersonalAccessTokenDB error: %s", errors.New("id is nil"))
+		return nil, nil, errm.NewError(
+			"error_update",
+			fmt.Errorf("failed to create UPDATE %s SQL: %v", dbcoretablenames.TableNamePersonalTokens.ToString(), errors.New("id is nil")),
+		)
+	}
+
+	err := dbutils.ExecuteTx(ctx, u.pool, tx, func(tx pgx.Tx) error {
+		query := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).Update(dbcoretablenames.TableNamePersonalTokens.ToString())
+
+		// Используем функцию для добавления ненулевых полей в запрос
+		query = dbutils.AddNonNullFieldsToQueryUpdate(query, *paramsUpdate)
+
+		// Добавляем условие WHERE
+		query = query.Where(squirrel.Eq{"id": paramsUpdate.ID})
+
+		sql, args, err := query.ToSql()
+		if err != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "UpdatePersonalAccessTokenDB-ToSql", err)
+			return err
+		}
+
+		_, err = tx.Exec(ctx, sql, args...)
+		if err != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "UpdatePersonalAccessTokenDB-Exec", err)
+			return err
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, tx, err
+	}
+
+	returnObjBool := false
+	if len(returnObj) > 0 {
+		returnObjBool = returnObj[0]
+	}
+
+	if returnObjBool {
+		options := typescore.ListDbOptions{Filtering: &typescore.PersonalAccessToken{
+			ID: paramsUpdate.ID,
+		}}
+		getInfoUp, _, errW := u.GetPersonalAccessTokensListDB(ctx, options)
+		if errW != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "UpdatePersonalAccessTokenDB-GetPersonalAccessTokensListDB", errW)
+			return nil, nil, errW
+		}
+		if len(getInfoUp) > 0 {
+			return getInfoUp[0], tx, nil
+		}
+	}
+
+	return nil, tx, nil
+}
+
+// DeletePersonalAccessTokenDB Отзыв (удаление) персонального токена доступа пользователя.
+// Возвращает false, если токен не найден или принадлежит другому пользователю.
+func (u *PersonalAccessTokenDB) DeletePersonalAccessTokenDB(ctx context.Context, userID, id string) (bool, *errm.Error) {
+	// logrus.Info("🩵 DeletePersonalAccessTokenDB")
+	sql, args, err := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).
+		Delete(dbcoretablenames.TableNamePersonalTokens.ToString()).
+		Where(squirrel.Eq{"id": id}).
+		Where(squirrel.Eq{"user_id": userID}).
+		ToSql()
+	if err != nil {
+		return false, errm.NewError(
+			"error_delete",
+			fmt.Errorf("failed to create DELETE %s SQL: %v", dbcoretablenames.TableNamePersonalTokens.ToString(), err),
+		)
+	}
+
+	tag, err := u.pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return false, errm.NewError(
+			"error_delete",
+			fmt.Errorf("failed to execute DELETE %s SQL: %v", dbcoretablenames.TableNamePersonalTokens.ToString(), err),
+		)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// RevokeUserPersonalTokensDB Отзыв (удаление) всех персональных токенов доступа пользователя
+func (u *PersonalAccessTokenDB) RevokeUserPersonalTokensDB(ctx context.Context, tx pgx.Tx, userID string) *errm.Error {
+	// logrus.Info("🩵 RevokeUserPersonalTokensDB")
+	return dbutils.ExecuteTx(ctx, u.pool, tx, func(tx pgx.Tx) error {
+		sql, args, err := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).
+			Delete(dbcoretablenames.TableNamePersonalTokens.ToString()).
+			Where(squirrel.Eq{"user_id": userID}).
+			ToSql()
+		if err != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "RevokeUserPersonalTokensDB-ToSql", err)
+			return err
+		}
+
+		if _, err = tx.Exec(ctx, sql, args...); err != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "RevokeUserPersonalTokensDB-Exec", err)
+			return err
+		}
+		return nil
+	})
+}
@@ -0,0 +1,74 @@
+package dbcore
+
+import (
+	tablesmigration "authentication_service/core/database/db_migration/tables"
+	"authentication_service/core/securecore"
+	"authentication_service/core/typescore"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestRevokeUserPersonalTokensDB(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	ctx := context.Background()
+
+	gormDB, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("gorm.Open: %v", err)
+	}
+	if err := tablesmigration.PersonalAccessTokenTableMigrate(gormDB); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		t.Fatalf("pgxpool.New: %v", err)
+	}
+	defer pool.Close()
+	db := NewPersonalAccessTokenDB(pool)
+
+	create := func(userID string) {
+		id, _ := securecore.GenerateUUID()
+		raw, _ := securecore.GenerateUUID()
+		hash := securecore.HashToken(raw)
+		name, scopes := "test", ""
+		if _, _, errObj := db.CreatePersonalAccessTokenDB(ctx, nil, &typescore.PersonalAccessToken{
+			ID: &id, UserID: &userID, Name: &name, TokenHash: &hash, Scopes: &scopes,
+		}); errObj != nil {
+			t.Fatalf("CreatePersonalAccessTokenDB: %v", errObj.Error)
+		}
+	}
+	count := func(userID string) int {
+		tokens, _, errObj := db.GetPersonalAccessTokensListDB(ctx, typescore.ListDbOptions{
+			Filtering: &typescore.PersonalAccessToken{UserID: &userID},
+		})
+		if errObj != nil {
+			t.Fatalf("GetPersonalAccessTokensListDB: %v", errObj.Error)
+		}
+		return len(tokens)
+	}
+
+	userID, _ := securecore.GenerateUUID()
+	otherUserID, _ := securecore.GenerateUUID()
+	create(userID)
+	create(userID)
+	create(otherUserID)
+	defer db.RevokeUserPersonalTokensDB(ctx, nil, otherUserID)
+
+	if errObj := db.RevokeUserPersonalTokensDB(ctx, nil, userID); errObj != nil {
+		t.Fatalf("RevokeUserPersonalTokensDB: %v", errObj.Error)
+	}
+	if got := count(userID); got != 0 {
+		t.Fatalf("user has %d personal tokens after revoke, want 0", got)
+	}
+	if got := count(otherUserID); got != 1 {
+		t.Fatalf("other user has %d personal tokens, want 1", got)
+	}
+}
@@ -0,0 +1,151 @@
+package dbcore
+
+import (
+	dbcoretablenames "authentication_service/core/database/table_names"
+	errm "authentication_service/core/errmodule"
+	"authentication_service/core/typescore"
+	dbutils "authentication_service/core/utilscore/db"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+type RecoveryCodeDB struct {
+	pool *pgxpool.Pool
+}
+
+func NewRecoveryCodeDB(pool *pgxpool.Pool) *RecoveryCodeDB {
+	return &RecoveryCodeDB{pool: pool}
+}
+
+type RecoveryCodeDBI interface {
+	GetRecoveryCodesListDB(ctx context.Context, options ...typescore.ListDbOptions) ([]*typescore.RecoveryCode, uint64, *errm.Error)
+	ReplaceUserRecoveryCodesDB(ctx context.Context, tx pgx.Tx, userID string, codeHashes []string) *errm.Error
+	UseRecoveryCodeDB(ctx context.Context, userID, codeHash string) (bool, *errm.Error)
+}
+
+// GetRecoveryCodesListDB Получение кодов восстановления
+func (u *RecoveryCodeDB) GetRecoveryCodesListDB(ctx context.Context, options ...typescore.ListDbOptions) ([]*typescore.RecoveryCode, uint64, *errm.Error) {
+	// logrus.Info("🩵 GetRecoveryCodesListDB")
+	fields := dbutils.GetStructFieldsDB(&typescore.RecoveryCode{}, nil)
+	// Добавляем поле total_count
+	selectFields := append(fields, "COUNT(*) OVER() AS total_count")
+
+	opts, filter, err := dbutils.GetOptionsDB[typescore.RecoveryCode](options...)
+	if err != nil {
+		return nil, 0, errm.NewError(
+			"error_get",
+			fmt.Errorf("get_options: failed to create SELECT %s SQL: %w", dbcoretablenames.TableNameRecoveryCodes.ToString(), err),
+		)
+	}
+
+	query := dbutils.BuildSelectQuery(dbcoretablenames.TableNameRecoveryCodes.ToString(), selectFields)
+	query = dbutils.SetterLimitAndOffsetQuery(query, opts.Offset, opts.Limit)
+	query = dbutils.ApplyFilters(query, filter, opts.LikeFields)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, 0, errm.NewError(
+			"error_select",
+			fmt.Errorf("sql: failed to create SELECT %s SQL: %v", dbcoretablenames.TableNameRecoveryCodes.ToString(), err),
+		)
+	}
+
+	rows, err := u.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, 0, errm.NewError(
+			"error_select",
+			fmt.Errorf("query: failed to create SELECT %s SQL: %v", dbcoretablenames.TableNameRecoveryCodes.ToString(), err),
+		)
+	}
+	defer rows.Close()
+
+	var codes []*typescore.RecoveryCode
+	var totalCount uint64
+	for rows.Next() {
+		code := &typescore.RecoveryCode{}
+		if err := dbutils.ScanRowsToStructRows(rows, code, &totalCount); err != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "GetRecoveryCodesListDB-ScanRowsToStructRows", err)
+			continue
+		}
+
+		codes = append(codes, code)
+	}
+
+	return codes, totalCount, nil
+}
+
+// ReplaceUserRecoveryCodesDB Заменяет все коды восстановления пользователя новым набором
+func (u *RecoveryCodeDB) ReplaceUserRecoveryCodesDB(ctx context.Context, tx pgx.Tx, userID string, codeHashes []string) *errm.Error {
+	// logrus.Info("🩵 ReplaceUserRecoveryCodesDB")
+	return dbutils.ExecuteTx(ctx, u.pool, tx, func(tx pgx.Tx) error {
+		sql, args, err := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).
+			Delete(dbcoretablenames.TableNameRecoveryCodes.ToString()).
+			Where(squirrel.Eq{"user_id": userID}).
+			ToSql()
+		if err != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "ReplaceUserRecoveryCodesDB-DeleteToSql", err)
+			return err
+		}
+		if _, err = tx.Exec(ctx, sql, args...); err != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "ReplaceUserRecoveryCodesDB-DeleteExec", err)
+			return err
+		}
+
+		if len(codeHashes) == 0 {
+			return nil
+		}
+
+		query := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).
+			Insert(dbcoretablenames.TableNameRecoveryCodes.ToString()).
+			Columns("id", "user_id", "code_hash")
+		for _, codeHash := range codeHashes {
+			query = query.Values(squirrel.Expr("gen_random_uuid()"), userID, codeHash)
+		}
+
+		sql, args, err = query.ToSql()
+		if err != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "ReplaceUserRecoveryCodesDB-InsertToSql", err)
+			return err
+		}
+		if _, err = tx.Exec(ctx, sql, args...); err != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "ReplaceUserRecoveryCodesDB-InsertExec", err)
+			return err
+		}
+		return nil
+	})
+}
+
+// UseRecoveryCodeDB Помечает неиспользованный код восстановления использованным.
+// Возвращает false, если код не найден или уже использован.
+func (u *RecoveryCodeDB) UseRecoveryCodeDB(ctx context.Context, userID, codeHash string) (bool, *errm.Error) {
+	// logrus.Info("🩵 UseRecoveryCodeDB")
+	sql, args, err := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).
+		Update(dbcoretablenames.TableNameRecoveryCodes.ToString()).
+		Set("used_at", time.Now().UTC()).
+		Where(squirrel.Eq{"user_id": userID}).
+		Where(squirrel.Eq{"code_hash": codeHash}).
+		Where(squirrel.Eq{"used_at": nil}).
+		ToSql()
+	if err != nil {
+		return false, errm.NewError(
+			"error_update",
+			fmt.Errorf("failed to create UPDATE %s SQL: %v", dbcoretablenames.TableNameRecoveryCodes.ToString(), err),
+		)
+	}
+
+	tag, err := u.pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return false, errm.NewError(
+			"error_update",
+			fmt.Errorf("failed to execute UPDATE %s SQL: %v", dbcoretablenames.TableNameRecoveryCodes.ToString(), err),
+		)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
@@ -0,0 +1,369 @@
+package dbcore
+
+import (
+	dbcoretablenames "authentication_service/core/database/table_names"
+	errm "authentication_service/core/errmodule"
+	"authentication_service/core/typescore"
+	dbutils "authentication_service/core/utilscore/db"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrRefreshTokenNotFound = errors.New("refresh token not found")      // Токен не найден или хеш не совпадает
+	ErrRefreshTokenRevoked  = errors.New("refresh token revoked")        // Токен (или его семейство) отозван
+	ErrRefreshTokenExpired  = errors.New("refresh token expired")        // Срок действия токена истек
+	ErrRefreshTokenReused   = errors.New("refresh token reuse detected") // Повторное использование уже ротированного токена
+)
+
+type RefreshTokenDB struct {
+	pool *pgxpool.Pool
+}
+
+func NewRefreshTokenDB(pool *pgxpool.Pool) *RefreshTokenDB {
+	return &RefreshTokenDB{pool: pool}
+}
+
+type RefreshTokenDBI interface {
+	GetRefreshTokensListDB(ctx context.Context, options ...typescore.ListDbOptions) ([]*typescore.RefreshToken, uint64, *errm.Error)
+	CreateRefreshTokenDB(ctx context.Context, tx pgx.Tx, tokenObj *typescore.RefreshToken, returnObj ...bool) (*typescore.RefreshToken, pgx.Tx, *errm.Error)
+	UpdateRefreshTokenDB(ctx context.Context, tx pgx.Tx, paramsUpdate *typescore.RefreshToken, returnObj ...bool) (*typescore.RefreshToken, pgx.Tx, *errm.Error)
+	RotateRefreshTokenDB(ctx context.Context, jti, tokenHash string, newTokenObj *typescore.RefreshToken) (*typescore.RefreshToken, *errm.Error)
+	RevokeRefreshTokenFamilyDB(ctx context.Context, tx pgx.Tx, familyID string) *errm.Error
+	RevokeUserRefreshTokensDB(ctx context.Context, tx pgx.Tx, userID string) *errm.Error
+}
+
+// GetRefreshTokensListDB Получение refresh токенов
+func (u *RefreshTokenDB) GetRefreshTokensListDB(ctx context.Context, options ...typescore.ListDbOptions) ([]*typescore.RefreshToken, uint64, *errm.Error) {
+	// logrus.Info("🩵 GetRefreshTokensListDB")
+	fields := dbutils.GetStructFieldsDB(&typescore.RefreshToken{}, nil)
+	// Добавляем поле total_count
+	selectFields := append(fields, "COUNT(*) OVER() AS total_count")
+
+	opts, filter, err := dbutils.GetOptionsDB[typescore.RefreshToken](options...)
+	if err != nil {
+		return nil, 0, errm.NewError(
+			"error_get",
+			fmt.Errorf("get_options: failed to create SELECT %s SQL: %w", dbcoretablenames.TableNameRefreshTokens.ToString(), err),
+		)
+	}
+
+	query := dbutils.BuildSelectQuery(dbcoretablenames.TableNameRefreshTokens.ToString(), selectFields)
+	query = dbutils.SetterLimitAndOffsetQuery(query, opts.Offset, opts.Limit)
+	query = dbutils.ApplyFilters(query, filter, opts.LikeFields)
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, 0, errm.NewError(
+			"error_select",
+			fmt.Errorf("sql: failed to create SELECT %s SQL: %v", dbcoretablenames.TableNameRefreshTokens.ToString(), err),
+		)
+	}
+
+	rows, err := u.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, 0, errm.NewError(
+			"error_select",
+			fmt.Errorf("query: failed to create SELECT %s SQL: %v", dbcoretablenames.TableNameRefreshTokens.ToString(), err),
+		)
+	}
+	defer rows.Close()
+
+	var tokens []*typescore.RefreshToken
+	var totalCount uint64
+	for rows.Next() {
+		token := &typescore.RefreshToken{}
+		if err := dbutils.ScanRowsToStructRows(rows, token, &totalCount); err != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "GetRefreshTokensListDB-ScanRowsToStructRows", err)
+			continue
+		}
+
+		tokens = append(tokens, token)
+	}
+
+	return tokens, totalCount, nil
+}
+
+// CreateRefreshTokenDB Сохраняет новый refresh токен
+func (u *RefreshTokenDB) CreateRefreshTokenDB(ctx context.Context, tx pgx.Tx, tokenObj *typescore.RefreshToken, returnObj ...bool) (*typescore.RefreshToken, pgx.Tx, *errm.Error) {
+	// logrus.Info("🩵 CreateRefreshTokenDB")
+	if tokenObj == nil || tokenObj.JTI == nil {
+		return nil, nil, errm.NewError(
+			"error_insert",
+			fmt.Errorf("failed to create INSERT %s SQL: %v", dbcoretablenames.TableNameRefreshTokens.ToString(), errors.New("tokenObj or jti is nil")),
+		)
+	}
+
+	err := dbutils.ExecuteTx(ctx, u.pool, tx, func(tx pgx.Tx) error {
+		return u.insertRefreshToken(ctx, tx, tokenObj)
+	})
+
+	if err != nil {
+		return nil, tx, err
+	}
+
+	returnObjBool := false
+	if len(returnObj) > 0 {
+		returnObjBool = returnObj[0]
+	}
+
+	if returnObjBool {
+		tokens, _, err := u.GetRefreshTokensListDB(ctx, typescore.ListDbOptions{Filtering: &typescore.RefreshToken{
+			JTI: tokenObj.JTI,
+		}})
+		if err != nil {
+			return nil, tx, err
+		}
+		if len(tokens) > 0 {
+			return tokens[0], tx, nil
+		}
+	}
+
+	return nil, tx, nil
+}
+
+// UpdateRefreshTokenDB Обновление refresh токена
+func (u *RefreshTokenDB) UpdateRefreshTokenDB(ctx context.Context, tx pgx.Tx, paramsUpdate *typescore.RefreshToken, returnObj ...bool) (*typescore.RefreshToken, pgx.Tx, *errm.Error) {
+	// logrus.Info("🩵 UpdateRefreshTokenDB")
+	if paramsUpdate == nil || paramsUpdate.JTI == nil {
+		logrus.Errorf("❌ UpdateRefreshTokenDB error: %s", errors.New("jti is nil"))
+		return nil, nil, errm.NewError(
+			"error_update",
+			fmt.Errorf("failed to create UPDATE %s SQL: %v", dbcoretablenames.TableNameRefreshTokens.ToString(), errors.New("jti is nil")),
+		)
+	}
+
+	err := dbutils.ExecuteTx(ctx, u.pool, tx, func(tx pgx.Tx) error {
+		query := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).Update(dbcoretablenames.TableNameRefreshTokens.ToString())
+
+		// Используем функцию для добавления ненулевых полей в запрос
+		query = dbutils.AddNonNullFieldsToQueryUpdate(query, *paramsUpdate)
+
+		// Добавляем условие WHERE
+		query = query.Where(squirrel.Eq{"jti": paramsUpdate.JTI})
+
+		sql, args, err := query.ToSql()
+		if err != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "UpdateRefreshTokenDB-ToSql", err)
+			return err
+		}
+
+		_, err = tx.Exec(ctx, sql, args...)
+		if err != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "UpdateRefreshTokenDB-Exec", err)
+			return err
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, tx, err
+	}
+
+	returnObjBool := false
+	if len(returnObj) > 0 {
+		returnObjBool = returnObj[0]
+	}
+
+	if returnObjBool {
+		options := typescore.ListDbOptions{Filtering: &typescore.RefreshToken{
+			JTI: paramsUpdate.JTI,
+		}}
+		getInfoUp, _, errW := u.GetRefreshTokensListDB(ctx, options)
+		if errW != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "UpdateRefreshTokenDB-GetRefreshTokensListDB", errW)
+			return nil, nil, errW
+		}
+		if len(getInfoUp) > 0 {
+			return getInfoUp[0], tx, nil
+		}
+	}
+
+	return nil, tx, nil
+}
+
+// RotateRefreshTokenDB Одноразовое использование refresh токена: помечает токен jti использованным
+// и сохраняет newTokenObj в том же семействе. Если токен уже был использован,
+// всё семейство отзывается и возвращается ErrRefreshTokenReused.
+// Возвращает запись использованного токена.
+func (u *RefreshTokenDB) RotateRefreshTokenDB(ctx context.Context, jti, tokenHash string, newTokenObj *typescore.RefreshToken) (*typescore.RefreshToken, *errm.Error) {
+	// logrus.Info("🩵 RotateRefreshTokenDB")
+	if newTokenObj == nil || newTokenObj.JTI == nil {
+		return nil, errm.NewError(
+			"error_rotate",
+			fmt.Errorf("failed to rotate %s: %v", dbcoretablenames.TableNameRefreshTokens.ToString(), errors.New("newTokenObj or jti is nil")),
+		)
+	}
+
+	current := &typescore.RefreshToken{}
+	reused := false
+
+	err := dbutils.ExecuteTx(ctx, u.pool, nil, func(tx pgx.Tx) error {
+		// Блокируем строку, чтобы параллельные запросы с тем же токеном не прошли ротацию дважды
+		query := dbutils.BuildSelectQuery(
+			dbcoretablenames.TableNameRefreshTokens.ToString(),
+			dbutils.GetStructFieldsDB(&typescore.RefreshToken{}, nil),
+		).Where(squirrel.Eq{"jti": jti}).Suffix("FOR UPDATE")
+
+		sql, args, err := query.ToSql()
+		if err != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "RotateRefreshTokenDB-ToSql", err)
+			return err
+		}
+
+		rows, err := tx.Query(ctx, sql, args...)
+		if err != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "RotateRefreshTokenDB-Query", err)
+			return err
+		}
+		found := rows.Next()
+		if found {
+			err = dbutils.ScanRowsToStructRows(rows, current, nil)
+		}
+		rows.Close()
+		if err != nil {
+			return err
+		}
+
+		if !found {
+			current = nil
+		}
+		switch errCheck := checkRefreshTokenRotation(current, tokenHash, time.Now()); {
+		case errors.Is(errCheck, ErrRefreshTokenReused):
+			// Токен уже был ротирован: кто-то повторно предъявил старый токен.
+			// Отзываем всё семейство и фиксируем транзакцию.
+			reused = true
+			return u.revokeRefreshTokenFamily(ctx, tx, *current.FamilyID)
+		case errCheck != nil:
+			return errCheck
+		}
+
+		updateSQL, updateArgs, err := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).
+			Update(dbcoretablenames.TableNameRefreshTokens.ToString()).
+			Set("used_at", time.Now().UTC()).
+			Set("replaced_by", *newTokenObj.JTI).
+			Where(squirrel.Eq{"jti": jti}).
+			ToSql()
+		if err != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "RotateRefreshTokenDB-UpdateToSql", err)
+			return err
+		}
+		if _, err = tx.Exec(ctx, updateSQL, updateArgs...); err != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "RotateRefreshTokenDB-UpdateExec", err)
+			return err
+		}
+
+		newTokenObj.FamilyID = current.FamilyID
+		newTokenObj.UserID = current.UserID
+		newTokenObj.SessionID = current.SessionID
+		return u.insertRefreshToken(ctx, tx, newTokenObj)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if reused {
+		return current, errm.NewError("refresh_token_reused", ErrRefreshTokenReused)
+	}
+
+	return current, nil
+}
+
+// checkRefreshTokenRotation проверяет, можно ли ротировать сохраненный токен (nil — не найден).
+// ErrRefreshTokenReused — токен уже ротирован: семейство нужно отозвать
+func checkRefreshTokenRotation(current *typescore.RefreshToken, tokenHash string, now time.Time) error {
+	if current == nil || current.TokenHash == nil || *current.TokenHash != tokenHash {
+		return ErrRefreshTokenNotFound
+	}
+	if current.RevokedAt != nil {
+		return ErrRefreshTokenRevoked
+	}
+	if current.UsedAt != nil {
+		if current.FamilyID == nil {
+			return ErrRefreshTokenRevoked
+		}
+		return ErrRefreshTokenReused
+	}
+	if current.ExpiresAt != nil && current.ExpiresAt.Before(now) {
+		return ErrRefreshTokenExpired
+	}
+	return nil
+}
+
+// RevokeRefreshTokenFamilyDB Отзывает все токены семейства
+func (u *RefreshTokenDB) RevokeRefreshTokenFamilyDB(ctx context.Context, tx pgx.Tx, familyID string) *errm.Error {
+	// logrus.Info("🩵 RevokeRefreshTokenFamilyDB")
+	return dbutils.ExecuteTx(ctx, u.pool, tx, func(tx pgx.Tx) error {
+		return u.revokeRefreshTokenFamily(ctx, tx, familyID)
+	})
+}
+
+// RevokeUserRefreshTokensDB Отзывает все refresh токены пользователя
+func (u *RefreshTokenDB) RevokeUserRefreshTokensDB(ctx context.Context, tx pgx.Tx, userID string) *errm.Error {
+	// logrus.Info("🩵 RevokeUserRefreshTokensDB")
+	return dbutils.ExecuteTx(ctx, u.pool, tx, func(tx pgx.Tx) error {
+		sql, args, err := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).
+			Update(dbcoretablenames.TableNameRefreshTokens.ToString()).
+			Set("revoked_at", time.Now().UTC()).
+			Where(squirrel.Eq{"user_id": userID}).
+			Where(squirrel.Eq{"revoked_at": nil}).
+			ToSql()
+		if err != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "RevokeUserRefreshTokensDB-ToSql", err)
+			return err
+		}
+
+		if _, err = tx.Exec(ctx, sql, args...); err != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "RevokeUserRefreshTokensDB-Exec", err)
+			return err
+		}
+		return nil
+	})
+}
+
+func (u *RefreshTokenDB) revokeRefreshTokenFamily(ctx context.Context, tx pgx.Tx, familyID string) error {
+	sql, args, err := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).
+		Update(dbcoretablenames.TableNameRefreshTokens.ToString()).
+		Set("revoked_at", time.Now().UTC()).
+		Where(squirrel.Eq{"family_id": familyID}).
+		Where(squirrel.Eq{"revoked_at": nil}).
+		ToSql()
+	if err != nil {
+		logrus.Errorf("🔴 error: %s: %+v", "revokeRefreshTokenFamily-ToSql", err)
+		return err
+	}
+
+	if _, err = tx.Exec(ctx, sql, args...); err != nil {
+		logrus.Errorf("🔴 error: %s: %+v", "revokeRefreshTokenFamily-Exec", err)
+		return err
+	}
+	return nil
+}
+
+func (u *RefreshTokenDB) insertRefreshToken(ctx context.Context, tx pgx.Tx, tokenObj *typescore.RefreshToken) error {
+	query := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).Insert(dbcoretablenames.TableNameRefreshTokens.ToString())
+
+	sqlV, args, errW := dbutils.GenerateInsertRequest(query, tokenObj, typescore.InsertOptions{
+		IgnoreConflict: false,
+	})
+	if errW != nil {
+		return errW
+	}
+
+	_, err := tx.Exec(ctx, *sqlV, args...)
+	if err != nil {
+		logrus.Errorf("🔴 error: %s: %+v", "insertRefreshToken-Exec", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package dbcore
+
+import (
+	tablesmigration "authentication_service/core/database/db_migration/tables"
+	"authentication_service/core/securecore"
+	"authentication_service/core/typescore"
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestCheckRefreshTokenRotation(t *testing.T) {
+	now := time.Now()
+	hash := "hash"
+	otherHash := "other"
+	family := "family"
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Hour)
+
+	tests := []struct {
+		name    string
+		current *typescore.RefreshToken
+		hash    string
+		want    error
+	}{
+		{"not found", nil, hash, ErrRefreshTokenNotFound},
+		{"hash mismatch", &typescore.RefreshToken{TokenHash: &otherHash, FamilyID: &family, ExpiresAt: &future}, hash, ErrRefreshTokenNotFound},
+		{"no hash", &typescore.RefreshToken{FamilyID: &family, ExpiresAt: &future}, hash, ErrRefreshTokenNotFound},
+		{"revoked", &typescore.RefreshToken{TokenHash: &hash, FamilyID: &family, ExpiresAt: &future, RevokedAt: &past}, hash, ErrRefreshTokenRevoked},
+		{"revoked and used", &typescore.RefreshToken{TokenHash: &hash, FamilyID: &family, ExpiresAt: &future, UsedAt: &past, RevokedAt: &past}, hash, ErrRefreshTokenRevoked},
+		{"reused", &typescore.RefreshToken{TokenHash: &hash, FamilyID: &family, ExpiresAt: &future, UsedAt: &past}, hash, ErrRefreshTokenReused},
+		{"reused expired", &typescore.RefreshToken{TokenHash: &hash, FamilyID: &family, ExpiresAt: &past, UsedAt: &past}, hash, ErrRefreshTokenReused},
+		{"used without family", &typescore.RefreshToken{TokenHash: &hash, ExpiresAt: &future, UsedAt: &past}, hash, ErrRefreshTokenRevoked},
+		{"expired", &typescore.RefreshToken{TokenHash: &hash, FamilyID: &family, ExpiresAt: &past}, hash, ErrRefreshTokenExpired},
+		{"valid", &typescore.RefreshToken{TokenHash: &hash, FamilyID: &family, ExpiresAt: &future}, hash, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkRefreshTokenRotation(tt.current, tt.hash, now); !errors.Is(err, tt.want) {
+				t.Fatalf("checkRefreshTokenRotation() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+// TestRotateRefreshTokenDB проверяет ротацию и отзыв семейства на реальной базе.
+// Запускается, если задана переменная TEST_DATABASE_URL (DSN PostgreSQL)
+func TestRotateRefreshTokenDB(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	ctx := context.Background()
+
+	gormDB, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("gorm.Open: %v", err)
+	}
+	if err := tablesmigration.RefreshTokenTableMigrate(gormDB); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		t.Fatalf("pgxpool.New: %v", err)
+	}
+	defer pool.Close()
+	db := NewRefreshTokenDB(pool)
+
+	newToken := func(familyID, userID string) (*typescore.RefreshToken, string) {
+		jti, _ := securecore.GenerateUUID()
+		raw, _ := securecore.GenerateUUID()
+		hash := securecore.HashToken(raw)
+		expiresAt := time.Now().Add(time.Hour).UTC()
+		return &typescore.RefreshToken{JTI: &jti, FamilyID: &familyID, UserID: &userID, TokenHash: &hash, ExpiresAt: &expiresAt}, hash
+	}
+	familyID, _ := securecore.GenerateUUID()
+	userID, _ := securecore.GenerateUUID()
+
+	first, firstHash := newToken(familyID, userID)
+	if _, _, errObj := db.CreateRefreshTokenDB(ctx, nil, first); errObj != nil {
+		t.Fatalf("CreateRefreshTokenDB: %v", errObj.Error)
+	}
+
+	if _, errObj := db.RotateRefreshTokenDB(ctx, *first.JTI, "wrong", &typescore.RefreshToken{JTI: first.JTI}); errObj == nil || !errors.Is(errObj.Error, ErrRefreshTokenNotFound) {
+		t.Fatalf("rotate with wrong hash: got %v, want %v", errObj, ErrRefreshTokenNotFound)
+	}
+
+	second, _ := newToken("", "")
+	if _, errObj := db.RotateRefreshTokenDB(ctx, *first.JTI, firstHash, second); errObj != nil {
+		t.Fatalf("first rotation: %v", errObj.Error)
+	}
+	if second.FamilyID == nil || *second.FamilyID != familyID {
+		t.Fatalf("rotated token must inherit family %s", familyID)
+	}
+
+	// Повторное предъявление ротированного токена отзывает всё семейство
+	third, _ := newToken("", "")
+	if _, errObj := db.RotateRefreshTokenDB(ctx, *first.JTI, firstHash, third); errObj == nil || !errors.Is(errObj.Error, ErrRefreshTokenReused) {
+		t.Fatalf("reuse: got %v, want %v", errObj, ErrRefreshTokenReused)
+	}
+	tokens, _, errObj := db.GetRefreshTokensListDB(ctx, typescore.ListDbOptions{Filtering: &typescore.RefreshToken{FamilyID: &familyID}})
+	if errObj != nil {
+		t.Fatalf("GetRefreshTokensListDB: %v", errObj.Error)
+	}
+	if len(tokens) != 2 {
+		t.Fatalf("family has %d tokens, want 2", len(tokens))
+	}
+	for _, token := range tokens {
+		if token.RevokedAt == nil {
+			t.Fatalf("token %s is not revoked after reuse", *token.JTI)
+		}
+	}
+}
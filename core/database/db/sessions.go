@@ -0,0 +1,290 @@
+package dbcore
+
+import (
+	dbcoretablenames "authentication_service/core/database/table_names"
+	errm "authentication_service/core/errmodule"
+	"authentication_service/core/typescore"
+	dbutils "authentication_service/core/utilscore/db"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+type SessionDB struct {
+	pool *pgxpool.Pool
+}
+
+func NewSessionDB(pool *pgxpool.Pool) *SessionDB {
+	return &SessionDB{pool: pool}
+}
+
+type SessionDBI interface {
+	GetSessionsListDB(ctx context.Context, options ...typescore.ListDbOptions) ([]*typescore.Session, uint64, *errm.Error)
+	CreateSessionDB(ctx context.Context, tx pgx.Tx, sessionObj *typescore.Session, returnObj ...bool) (*typescore.Session, pgx.Tx, *errm.Error)
+	UpdateSessionDB(ctx context.Context, tx pgx.Tx, paramsUpdate *typescore.Session, returnObj ...bool) (*typescore.Session, pgx.Tx, *errm.Error)
+	RevokeSessionDB(ctx context.Context, tx pgx.Tx, sessionID string) *errm.Error
+	RevokeUserSessionsDB(ctx context.Context, tx pgx.Tx, userID string, exceptSessionID string) ([]string, *errm.Error)
+}
+
+// GetSessionsListDB Получение сессий
+func (u *SessionDB) GetSessionsListDB(ctx context.Context, options ...typescore.ListDbOptions) ([]*typescore.Session, uint64, *errm.Error) {
+	// logrus.Info("🩵 GetSessionsListDB")
+	fields := dbutils.GetStructFieldsDB(&typescore.Session{}, nil)
+	// Добавляем поле total_count
+	selectFields := append(fields, "COUNT(*) OVER() AS total_count")
+
+	opts, filter, err := dbutils.GetOptionsDB[typescore.Session](options...)
+	if err != nil {
+		return nil, 0, errm.NewError(
+			"error_get",
+			fmt.Errorf("get_options: failed to create SELECT %s SQL: %w", dbcoretablenames.TableNameSessions.ToString(), err),
+		)
+	}
+
+	query := dbutils.BuildSelectQuery(dbcoretablenames.TableNameSessions.ToString(), selectFields)
+	query = dbutils.SetterLimitAndOffsetQuery(query, opts.Offset, opts.Limit)
+	query = dbutils.ApplyFilters(query, filter, opts.LikeFields)
+	query = query.OrderBy("last_seen_at DESC NULLS LAST", "created_at DESC")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, 0, errm.NewError(
+			"error_select",
+			fmt.Errorf("sql: failed to create SELECT %s SQL: %v", dbcoretablenames.TableNameSessions.ToString(), err),
+		)
+	}
+
+	rows, err := u.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, 0, errm.NewError(
+			"error_select",
+			fmt.Errorf("query: failed to create SELECT %s SQL: %v", dbcoretablenames.TableNameSessions.ToString(), err),
+		)
+	}
+	defer rows.Close()
+
+	var sessions []*typescore.Session
+	var totalCount uint64
+	for rows.Next() {
+		session := &typescore.Session{}
+		if err := dbutils.ScanRowsToStructRows(rows, session, &totalCount); err != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "GetSessionsListDB-ScanRowsToStructRows", err)
+			continue
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	return sessions, totalCount, nil
+}
+
+// CreateSessionDB Создание сессии
+func (u *SessionDB) CreateSessionDB(ctx context.Context, tx pgx.Tx, sessionObj *typescore.Session, returnObj ...bool) (*typescore.Session, pgx.Tx, *errm.Error) {
+	// logrus.Info("🩵 CreateSessionDB")
+	if sessionObj == nil || sessionObj.ID == nil {
+		return nil, nil, errm.NewError(
+			"error_insert",
+			fmt.Errorf("failed to create INSERT %s SQL: %v", dbcoretablenames.TableNameSessions.ToString(), errors.New("sessionObj or id is nil")),
+		)
+	}
+
+	err := dbutils.ExecuteTx(ctx, u.pool, tx, func(tx pgx.Tx) error {
+		query := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).Insert(dbcoretablenames.TableNameSessions.ToString())
+
+		sqlV, args, errW := dbutils.GenerateInsertRequest(query, sessionObj, typescore.InsertOptions{
+			IgnoreConflict: false,
+		})
+		if errW != nil {
+			return errW
+		}
+
+		if _, err := tx.Exec(ctx, *sqlV, args...); err != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "CreateSessionDB-Exec", err)
+			return err
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, tx, err
+	}
+
+	returnObjBool := false
+	if len(returnObj) > 0 {
+		returnObjBool = returnObj[0]
+	}
+
+	if returnObjBool {
+		sessions, _, err := u.GetSessionsListDB(ctx, typescore.ListDbOptions{Filtering: &typescore.Session{
+			ID: sessionObj.ID,
+		}})
+		if err != nil {
+			return nil, tx, err
+		}
+		if len(sessions) > 0 {
+			return sessions[0], tx, nil
+		}
+	}
+
+	return nil, tx, nil
+}
+
+// UpdateSessionDB Обновление сессии
+func (u *SessionDB) UpdateSessionDB(ctx context.Context, tx pgx.Tx, paramsUpdate *typescore.Session, returnObj ...bool) (*typescore.Session, pgx.Tx, *errm.Error) {
+	// logrus.Info("🩵 UpdateSessionDB")
+	if paramsUpdate == nil || paramsUpdate.ID == nil {
+		logrus.Errorf("❌ UpdateSessionDB error: %s", errors.New("id is nil"))
+		return nil, nil, errm.NewError(
+			"error_update",
+			fmt.Errorf("failed to create UPDATE %s SQL: %v", dbcoretablenames.TableNameSessions.ToString(), errors.New("id is nil")),
+		)
+	}
+
+	err := dbutils.ExecuteTx(ctx, u.pool, tx, func(tx pgx.Tx) error {
+		query := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).Update(dbcoretablenames.TableNameSessions.ToString())
+
+		// Используем функцию для добавления ненулевых полей в запрос
+		query = dbutils.AddNonNullFieldsToQueryUpdate(query, *paramsUpdate)
+
+		// Добавляем условие WHERE
+		query = query.Where(squirrel.Eq{"id": paramsUpdate.ID})
+
+		sql, args, err := query.ToSql()
+		if err != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "UpdateSessionDB-ToSql", err)
+			return err
+		}
+
+		_, err = tx.Exec(ctx, sql, args...)
+		if err != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "UpdateSessionDB-Exec", err)
+			return err
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, tx, err
+	}
+
+	returnObjBool := false
+	if len(returnObj) > 0 {
+		returnObjBool = returnObj[0]
+	}
+
+	if returnObjBool {
+		options := typescore.ListDbOptions{Filtering: &typescore.Session{
+			ID: paramsUpdate.ID,
+		}}
+		getInfoUp, _, errW := u.GetSessionsListDB(ctx, options)
+		if errW != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "UpdateSessionDB-GetSessionsListDB", errW)
+			return nil, nil, errW
+		}
+		if len(getInfoUp) > 0 {
+			return getInfoUp[0], tx, nil
+		}
+	}
+
+	return nil, tx, nil
+}
+
+// RevokeSessionDB Завершает сессию и отзывает её refresh токены
+func (u *SessionDB) RevokeSessionDB(ctx context.Context, tx pgx.Tx, sessionID string) *errm.Error {
+	// logrus.Info("🩵 RevokeSessionDB")
+	return dbutils.ExecuteTx(ctx, u.pool, tx, func(tx pgx.Tx) error {
+		_, err := u.revokeSessions(ctx, tx, squirrel.Eq{"id": sessionID})
+		return err
+	})
+}
+
+// RevokeUserSessionsDB Завершает все сессии пользователя, кроме exceptSessionID (если указан),
+// и отзывает их refresh токены. Возвращает идентификаторы завершенных сессий.
+func (u *SessionDB) RevokeUserSessionsDB(ctx context.Context, tx pgx.Tx, userID string, exceptSessionID string) ([]string, *errm.Error) {
+	// logrus.Info("🩵 RevokeUserSessionsDB")
+	var revokedIDs []string
+
+	where := squirrel.And{squirrel.Eq{"user_id": userID}}
+	if exceptSessionID != "" {
+		where = append(where, squirrel.NotEq{"id": exceptSessionID})
+	}
+
+	err := dbutils.ExecuteTx(ctx, u.pool, tx, func(tx pgx.Tx) error {
+		var err error
+		revokedIDs, err = u.revokeSessions(ctx, tx, where)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return revokedIDs, nil
+}
+
+// revokeSessions завершает активные сессии по условию и отзывает refresh токены этих сессий
+func (u *SessionDB) revokeSessions(ctx context.Context, tx pgx.Tx, where squirrel.Sqlizer) ([]string, error) {
+	now := time.Now().UTC()
+
+	sql, args, err := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).
+		Update(dbcoretablenames.TableNameSessions.ToString()).
+		Set("revoked_at", now).
+		Where(where).
+		Where(squirrel.Eq{"revoked_at": nil}).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		logrus.Errorf("🔴 error: %s: %+v", "revokeSessions-ToSql", err)
+		return nil, err
+	}
+
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		logrus.Errorf("🔴 error: %s: %+v", "revokeSessions-Query", err)
+		return nil, err
+	}
+	var revokedIDs []string
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			break
+		}
+		revokedIDs = append(revokedIDs, id)
+	}
+	rows.Close()
+	if err != nil {
+		logrus.Errorf("🔴 error: %s: %+v", "revokeSessions-Scan", err)
+		return nil, err
+	}
+	if err = rows.Err(); err != nil {
+		logrus.Errorf("🔴 error: %s: %+v", "revokeSessions-Rows", err)
+		return nil, err
+	}
+
+	if len(revokedIDs) == 0 {
+		return nil, nil
+	}
+
+	sql, args, err = squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).
+		Update(dbcoretablenames.TableNameRefreshTokens.ToString()).
+		Set("revoked_at", now).
+		Where(squirrel.Eq{"session_id": revokedIDs}).
+		Where(squirrel.Eq{"revoked_at": nil}).
+		ToSql()
+	if err != nil {
+		logrus.Errorf("🔴 error: %s: %+v", "revokeSessions-RefreshTokensToSql", err)
+		return nil, err
+	}
+	if _, err = tx.Exec(ctx, sql, args...); err != nil {
+		logrus.Errorf("🔴 error: %s: %+v", "revokeSessions-RefreshTokensExec", err)
+		return nil, err
+	}
+
+	return revokedIDs, nil
+}
@@ -0,0 +1,194 @@
+package dbcore
+
+import (
+	dbcoretablenames "authentication_service/core/database/table_names"
+	errm "authentication_service/core/errmodule"
+	"authentication_service/core/typescore"
+	dbutils "authentication_service/core/utilscore/db"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+type UserIdentityDB struct {
+	pool *pgxpool.Pool
+}
+
+func NewUserIdentityDB(pool *pgxpool.Pool) *UserIdentityDB {
+	return &UserIdentityDB{pool: pool}
+}
+
+type UserIdentityDBI interface {
+	GetUserIdentitiesListDB(ctx context.Context, options ...typescore.ListDbOptions) ([]*typescore.UserIdentity, uint64, *errm.Error)
+	CreateUserIdentityDB(ctx context.Context, tx pgx.Tx, identityObj *typescore.UserIdentity, returnObj ...bool) (*typescore.UserIdentity, pgx.Tx, *errm.Error)
+	UpdateUserIdentityDB(ctx context.Context, tx pgx.Tx, paramsUpdate *typescore.UserIdentity, returnObj ...bool) (*typescore.UserIdentity, pgx.Tx, *errm.Error)
+}
+
+// GetUserIdentitiesListDB Получение внешних учетных записей
+func (u *UserIdentityDB) GetUserIdentitiesListDB(ctx context.Context, options ...typescore.ListDbOptions) ([]*typescore.UserIdentity, uint64, *errm.Error) {
+	// logrus.Info("🩵 GetUserIdentitiesListDB")
+	fields := dbutils.GetStructFieldsDB(&typescore.UserIdentity{}, nil)
+	// Добавляем поле total_count
+	selectFields := append(fields, "COUNT(*) OVER() AS total_count")
+
+	opts, filter, err := dbutils.GetOptionsDB[typescore.UserIdentity](options...)
+	if err != nil {
+		return nil, 0, errm.NewError(
+			"error_get",
+			fmt.Errorf("get_options: failed to create SELECT %s SQL: %w", dbcoretablenames.TableNameUserIdentities.ToString(), err),
+		)
+	}
+
+	query := dbutils.BuildSelectQuery(dbcoretablenames.TableNameUserIdentities.ToString(), selectFields)
+	query = dbutils.SetterLimitAndOffsetQuery(query, opts.Offset, opts.Limit)
+	query = dbutils.ApplyFilters(query, filter, opts.LikeFields)
+	query = query.OrderBy("created_at DESC")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, 0, errm.NewError(
+			"error_select",
+			fmt.Errorf("sql: failed to create SELECT %s SQL: %v", dbcoretablenames.TableNameUserIdentities.ToString(), err),
+		)
+	}
+
+	rows, err := u.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, 0, errm.NewError(
+			"error_select",
+			fmt.Errorf("query: failed to create SELECT %s SQL: %v", dbcoretablenames.TableNameUserIdentities.ToString(), err),
+		)
+	}
+	defer rows.Close()
+
+	var identities []*typescore.UserIdentity
+	var totalCount uint64
+	for rows.Next() {
+		identity := &typescore.UserIdentity{}
+		if err := dbutils.ScanRowsToStructRows(rows, identity, &totalCount); err != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "GetUserIdentitiesListDB-ScanRowsToStructRows", err)
+			continue
+		}
+
+		identities = append(identities, identity)
+	}
+
+	return identities, totalCount, nil
+}
+
+// CreateUserIdentityDB Привязка внешней учетной записи к пользователю
+func (u *UserIdentityDB) CreateUserIdentityDB(ctx context.Context, tx pgx.Tx, identityObj *typescore.UserIdentity, returnObj ...bool) (*typescore.UserIdentity, pgx.Tx, *errm.Error) {
+	// logrus.Info("🩵 CreateUserIdentityDB")
+	if identityObj == nil || identityObj.ID == nil {
+		return nil, nil, errm.NewError(
+			"error_insert",
+			fmt.Errorf("failed to create INSERT %s SQL: %v", dbcoretablenames.TableNameUserIdentities.ToString(), errors.New("identityObj or id is nil")),
+		)
+	}
+
+	err := dbutils.ExecuteTx(ctx, u.pool, tx, func(tx pgx.Tx) error {
+		query := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).Insert(dbcoretablenames.TableNameUserIdentities.ToString())
+
+		sqlV, args, errW := dbutils.GenerateInsertRequest(query, identityObj, typescore.InsertOptions{
+			IgnoreConflict: false,
+		})
+		if errW != nil {
+			return errW
+		}
+
+		if _, err := tx.Exec(ctx, *sqlV, args...); err != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "CreateUserIdentityDB-Exec", err)
+			return err
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, tx, err
+	}
+
+	returnObjBool := false
+	if len(returnObj) > 0 {
+		returnObjBool = returnObj[0]
+	}
+
+	if returnObjBool {
+		identities, _, err := u.GetUserIdentitiesListDB(ctx, typescore.ListDbOptions{Filtering: &typescore.UserIdentity{
+			ID: identityObj.ID,
+		}})
+		if err != nil {
+			return nil, tx, err
+		}
+		if len(identities) > 0 {
+			return identities[0], tx, nil
+		}
+	}
+
+	return nil, tx, nil
+}
+
+// UpdateUserIdentityDB Обновление внешней учетной записи (email, время входа)
+func (u *UserIdentityDB) UpdateUserIdentityDB(ctx context.Context, tx pgx.Tx, paramsUpdate *typescore.UserIdentity, returnObj ...bool) (*typescore.UserIdentity, pgx.Tx, *errm.Error) {
+	// logrus.Info("🩵 UpdateUserIdentityDB")
+	if paramsUpdate == nil || paramsUpdate.ID == nil {
+		logrus.Errorf("❌ UpdateUserIdentityDB error: %s", errors.New("id is nil"))
+		return nil, nil, errm.NewError(
+			"error_update",
+			fmt.Errorf("failed to create UPDATE %s SQL: %v", dbcoretablenames.TableNameUserIdentities.ToString(), errors.New("id is nil")),
+		)
+	}
+
+	err := dbutils.ExecuteTx(ctx, u.pool, tx, func(tx pgx.Tx) error {
+		query := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).Update(dbcoretablenames.TableNameUserIdentities.ToString())
+
+		// Используем функцию для добавления ненулевых полей в запрос
+		query = dbutils.AddNonNullFieldsToQueryUpdate(query, *paramsUpdate)
+
+		// Добавляем условие WHERE
+		query = query.Where(squirrel.Eq{"id": paramsUpdate.ID})
+
+		sql, args, err := query.ToSql()
+		if err != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "UpdateUserIdentityDB-ToSql", err)
+			return err
+		}
+
+		_, err = tx.Exec(ctx, sql, args...)
+		if err != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "UpdateUserIdentityDB-Exec", err)
+			return err
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, tx, err
+	}
+
+	returnObjBool := false
+	if len(returnObj) > 0 {
+		returnObjBool = returnObj[0]
+	}
+
+	if returnObjBool {
+		options := typescore.ListDbOptions{Filtering: &typescore.UserIdentity{
+			ID: paramsUpdate.ID,
+		}}
+		getInfoUp, _, errW := u.GetUserIdentitiesListDB(ctx, options)
+		if errW != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "UpdateUserIdentityDB-GetUserIdentitiesListDB", errW)
+			return nil, nil, errW
+		}
+		if len(getInfoUp) > 0 {
+			return getInfoUp[0], tx, nil
+		}
+	}
+
+	return nil, tx, nil
+}
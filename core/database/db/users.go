@@ -29,6 +29,8 @@ type UserDBI interface {
 	CreateUserDB(ctx context.Context, tx pgx.Tx, userObj *typescore.User, returnObj ...bool) (*typescore.User, pgx.Tx, *errm.Error)
 	UpdateUserDB(ctx context.Context, tx pgx.Tx, paramsUpdate *typescore.User, returnObj ...bool) (*typescore.User, pgx.Tx, *errm.Error)
 	DeleteUserDB(ctx context.Context, params *typescore.User) *errm.Error
+	AcceptUserTOTPStepDB(ctx context.Context, userID string, step int64) (bool, *errm.Error)
+	ResetUserTOTPDB(ctx context.Context, tx pgx.Tx, userID string) *errm.Error
 }
 
 // GetUsersListDB Получение пользователей
@@ -241,6 +243,70 @@ func (u *UserDB) DeleteUserDB(ctx context.Context, params *typescore.User) *errm
 	}
 }
 
+// AcceptUserTOTPStepDB Фиксирует использованный временной шаг TOTP.
+// Возвращает false, если код этого или более позднего шага уже был принят (повторное использование).
+func (u *UserDB) AcceptUserTOTPStepDB(ctx context.Context, userID string, step int64) (bool, *errm.Error) {
+	// logrus.Info("🩵 AcceptUserTOTPStepDB")
+	sql, args, err := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).
+		Update(dbcoretablenames.TableNameUsers.ToString()).
+		Set("totp_last_step", step).
+		Where(squirrel.Eq{"system_id": userID}).
+		Where(squirrel.Or{squirrel.Eq{"totp_last_step": nil}, squirrel.Lt{"totp_last_step": step}}).
+		ToSql()
+	if err != nil {
+		return false, errm.NewError(
+			"error_update",
+			fmt.Errorf("failed to create UPDATE %s SQL: %v", dbcoretablenames.TableNameUsers.ToString(), err),
+		)
+	}
+
+	tag, err := u.pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return false, errm.NewError(
+			"error_update",
+			fmt.Errorf("failed to execute UPDATE %s SQL: %v", dbcoretablenames.TableNameUsers.ToString(), err),
+		)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// ResetUserTOTPDB Отключает TOTP пользователя: удаляет секрет и коды восстановления
+func (u *UserDB) ResetUserTOTPDB(ctx context.Context, tx pgx.Tx, userID string) *errm.Error {
+	// logrus.Info("🩵 ResetUserTOTPDB")
+	return dbutils.ExecuteTx(ctx, u.pool, tx, func(tx pgx.Tx) error {
+		sql, args, err := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).
+			Update(dbcoretablenames.TableNameUsers.ToString()).
+			Set("totp_secret", nil).
+			Set("totp_enabled_at", nil).
+			Set("totp_last_step", nil).
+			Where(squirrel.Eq{"system_id": userID}).
+			ToSql()
+		if err != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "ResetUserTOTPDB-ToSql", err)
+			return err
+		}
+		if _, err = tx.Exec(ctx, sql, args...); err != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "ResetUserTOTPDB-Exec", err)
+			return err
+		}
+
+		sql, args, err = squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).
+			Delete(dbcoretablenames.TableNameRecoveryCodes.ToString()).
+			Where(squirrel.Eq{"user_id": userID}).
+			ToSql()
+		if err != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "ResetUserTOTPDB-RecoveryCodesToSql", err)
+			return err
+		}
+		if _, err = tx.Exec(ctx, sql, args...); err != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "ResetUserTOTPDB-RecoveryCodesExec", err)
+			return err
+		}
+		return nil
+	})
+}
+
 // GetUsersIDsDB получает список ID пользователей по параметрам фильтрации
 func (u *UserDB) GetUsersAddressesDB(ctx context.Context, options ...typescore.ListDbOptions) ([]*string, *errm.Error) {
 	// logrus.Info("🩵 GetUsersAddressesDB")
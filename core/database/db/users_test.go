@@ -0,0 +1,62 @@
+package dbcore
+
+import (
+	tablesmigration "authentication_service/core/database/db_migration/tables"
+	"authentication_service/core/securecore"
+	"authentication_service/core/typescore"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestAcceptUserTOTPStepDB(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	ctx := context.Background()
+
+	gormDB, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("gorm.Open: %v", err)
+	}
+	if err := tablesmigration.UserTableMigrate(gormDB); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		t.Fatalf("pgxpool.New: %v", err)
+	}
+	defer pool.Close()
+	db := NewUserDB(pool)
+
+	userID, _ := securecore.GenerateUUID()
+	if _, _, errObj := db.CreateUserDB(ctx, nil, &typescore.User{SystemID: &userID}); errObj != nil {
+		t.Fatalf("CreateUserDB: %v", errObj.Error)
+	}
+	defer db.DeleteUserDB(ctx, &typescore.User{SystemID: &userID})
+
+	// Повторный ввод того же шага и более раннего шага отклоняется
+	steps := []struct {
+		step int64
+		want bool
+	}{
+		{100, true},
+		{100, false},
+		{99, false},
+		{101, true},
+	}
+	for _, s := range steps {
+		accepted, errObj := db.AcceptUserTOTPStepDB(ctx, userID, s.step)
+		if errObj != nil {
+			t.Fatalf("AcceptUserTOTPStepDB(%d): %v", s.step, errObj.Error)
+		}
+		if accepted != s.want {
+			t.Fatalf("AcceptUserTOTPStepDB(%d) = %v, want %v", s.step, accepted, s.want)
+		}
+	}
+}
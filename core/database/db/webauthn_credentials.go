@@ -0,0 +1,222 @@
+package dbcore
+
+import (
+	dbcoretablenames "authentication_service/core/database/table_names"
+	errm "authentication_service/core/errmodule"
+	"authentication_service/core/typescore"
+	dbutils "authentication_service/core/utilscore/db"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+type WebAuthnCredentialDB struct {
+	pool *pgxpool.Pool
+}
+
+func NewWebAuthnCredentialDB(pool *pgxpool.Pool) *WebAuthnCredentialDB {
+	return &WebAuthnCredentialDB{pool: pool}
+}
+
+type WebAuthnCredentialDBI interface {
+	GetWebAuthnCredentialsListDB(ctx context.Context, options ...typescore.ListDbOptions) ([]*typescore.WebAuthnCredential, uint64, *errm.Error)
+	CreateWebAuthnCredentialDB(ctx context.Context, tx pgx.Tx, credentialObj *typescore.WebAuthnCredential, returnObj ...bool) (*typescore.WebAuthnCredential, pgx.Tx, *errm.Error)
+	UpdateWebAuthnCredentialDB(ctx context.Context, tx pgx.Tx, paramsUpdate *typescore.WebAuthnCredential, returnObj ...bool) (*typescore.WebAuthnCredential, pgx.Tx, *errm.Error)
+	DeleteWebAuthnCredentialDB(ctx context.Context, userID, id string) (bool, *errm.Error)
+}
+
+// GetWebAuthnCredentialsListDB Получение ключей доступа WebAuthn
+func (u *WebAuthnCredentialDB) GetWebAuthnCredentialsListDB(ctx context.Context, options ...typescore.ListDbOptions) ([]*typescore.WebAuthnCredential, uint64, *errm.Error) {
+	// logrus.Info("🩵 GetWebAuthnCredentialsListDB")
+	fields := dbutils.GetStructFieldsDB(&typescore.WebAuthnCredential{}, nil)
+	// Добавляем поле total_count
+	selectFields := append(fields, "COUNT(*) OVER() AS total_count")
+
+	opts, filter, err := dbutils.GetOptionsDB[typescore.WebAuthnCredential](options...)
+	if err != nil {
+		return nil, 0, errm.NewError(
+			"error_get",
+			fmt.Errorf("get_options: failed to create SELECT %s SQL: %w", dbcoretablenames.TableNameWebAuthnCredentials.ToString(), err),
+		)
+	}
+
+	query := dbutils.BuildSelectQuery(dbcoretablenames.TableNameWebAuthnCredentials.ToString(), selectFields)
+	query = dbutils.SetterLimitAndOffsetQuery(query, opts.Offset, opts.Limit)
+	query = dbutils.ApplyFilters(query, filter, opts.LikeFields)
+	query = query.OrderBy("created_at DESC")
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return nil, 0, errm.NewError(
+			"error_select",
+			fmt.Errorf("sql: failed to create SELECT %s SQL: %v", dbcoretablenames.TableNameWebAuthnCredentials.ToString(), err),
+		)
+	}
+
+	rows, err := u.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, 0, errm.NewError(
+			"error_select",
+			fmt.Errorf("query: failed to create SELECT %s SQL: %v", dbcoretablenames.TableNameWebAuthnCredentials.ToString(), err),
+		)
+	}
+	defer rows.Close()
+
+	var credentials []*typescore.WebAuthnCredential
+	var totalCount uint64
+	for rows.Next() {
+		credential := &typescore.WebAuthnCredential{}
+		if err := dbutils.ScanRowsToStructRows(rows, credential, &totalCount); err != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "GetWebAuthnCredentialsListDB-ScanRowsToStructRows", err)
+			continue
+		}
+
+		credentials = append(credentials, credential)
+	}
+
+	return credentials, totalCount, nil
+}
+
+// CreateWebAuthnCredentialDB Сохранение ключа доступа WebAuthn
+func (u *WebAuthnCredentialDB) CreateWebAuthnCredentialDB(ctx context.Context, tx pgx.Tx, credentialObj *typescore.WebAuthnCredential, returnObj ...bool) (*typescore.WebAuthnCredential, pgx.Tx, *errm.Error) {
+	// logrus.Info("🩵 CreateWebAuthnCredentialDB")
+	if credentialObj == nil || credentialObj.ID == nil {
+		return nil, nil, errm.NewError(
+			"error_insert",
+			fmt.Errorf("failed to create INSERT %s SQL: %v", dbcoretablenames.TableNameWebAuthnCredentials.ToString(), errors.New("credentialObj or id is nil")),
+		)
+	}
+
+	err := dbutils.ExecuteTx(ctx, u.pool, tx, func(tx pgx.Tx) error {
+		query := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).Insert(dbcoretablenames.TableNameWebAuthnCredentials.ToString())
+
+		sqlV, args, errW := dbutils.GenerateInsertRequest(query, credentialObj, typescore.InsertOptions{
+			IgnoreConflict: false,
+		})
+		if errW != nil {
+			return errW
+		}
+
+		if _, err := tx.Exec(ctx, *sqlV, args...); err != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "CreateWebAuthnCredentialDB-Exec", err)
+			return err
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, tx, err
+	}
+
+	returnObjBool := false
+	if len(returnObj) > 0 {
+		returnObjBool = returnObj[0]
+	}
+
+	if returnObjBool {
+		credentials, _, err := u.GetWebAuthnCredentialsListDB(ctx, typescore.ListDbOptions{Filtering: &typescore.WebAuthnCredential{
+			ID: credentialObj.ID,
+		}})
+		if err != nil {
+			return nil, tx, err
+		}
+		if len(credentials) > 0 {
+			return credentials[0], tx, nil
+		}
+	}
+
+	return nil, tx, nil
+}
+
+// UpdateWebAuthnCredentialDB Обновление ключа доступа WebAuthn (счетчик подписей, время входа)
+func (u *WebAuthnCredentialDB) UpdateWebAuthnCredentialDB(ctx context.Context, tx pgx.Tx, paramsUpdate *typescore.WebAuthnCredential, returnObj ...bool) (*typescore.WebAuthnCredential, pgx.Tx, *errm.Error) {
+	// logrus.Info("🩵 UpdateWebAuthnCredentialDB")
+	if paramsUpdate == nil || paramsUpdate.ID == nil {
+		logrus.Errorf("❌ UpdateWebAuthnCredentialDB error: %s", errors.New("id is nil"))
+		return nil, nil, errm.NewError(
+			"error_update",
+			fmt.Errorf("failed to create UPDATE %s SQL: %v", dbcoretablenames.TableNameWebAuthnCredentials.ToString(), errors.New("id is nil")),
+		)
+	}
+
+	err := dbutils.ExecuteTx(ctx, u.pool, tx, func(tx pgx.Tx) error {
+		query := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).Update(dbcoretablenames.TableNameWebAuthnCredentials.ToString())
+
+		// Используем функцию для добавления ненулевых полей в запрос
+		query = dbutils.AddNonNullFieldsToQueryUpdate(query, *paramsUpdate)
+
+		// Добавляем условие WHERE
+		query = query.Where(squirrel.Eq{"id": paramsUpdate.ID})
+
+		sql, args, err := query.ToSql()
+		if err != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "UpdateWebAuthnCredentialDB-ToSql", err)
+			return err
+		}
+
+		_, err = tx.Exec(ctx, sql, args...)
+		if err != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "UpdateWebAuthnCredentialDB-Exec", err)
+			return err
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, tx, err
+	}
+
+	returnObjBool := false
+	if len(returnObj) > 0 {
+		returnObjBool = returnObj[0]
+	}
+
+	if returnObjBool {
+		options := typescore.ListDbOptions{Filtering: &typescore.WebAuthnCredential{
+			ID: paramsUpdate.ID,
+		}}
+		getInfoUp, _, errW := u.GetWebAuthnCredentialsListDB(ctx, options)
+		if errW != nil {
+			logrus.Errorf("🔴 error: %s: %+v", "UpdateWebAuthnCredentialDB-GetWebAuthnCredentialsListDB", errW)
+			return nil, nil, errW
+		}
+		if len(getInfoUp) > 0 {
+			return getInfoUp[0], tx, nil
+		}
+	}
+
+	return nil, tx, nil
+}
+
+// DeleteWebAuthnCredentialDB Удаление ключа доступа пользователя.
+// Возвращает false, если ключ не найден или принадлежит другому пользователю.
+func (u *WebAuthnCredentialDB) DeleteWebAuthnCredentialDB(ctx context.Context, userID, id string) (bool, *errm.Error) {
+	// logrus.Info("🩵 DeleteWebAuthnCredentialDB")
+	sql, args, err := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar).
+		Delete(dbcoretablenames.TableNameWebAuthnCredentials.ToString()).
+		Where(squirrel.Eq{"id": id}).
+		Where(squirrel.Eq{"user_id": userID}).
+		ToSql()
+	if err != nil {
+		return false, errm.NewError(
+			"error_delete",
+			fmt.Errorf("failed to create DELETE %s SQL: %v", dbcoretablenames.TableNameWebAuthnCredentials.ToString(), err),
+		)
+	}
+
+	tag, err := u.pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return false, errm.NewError(
+			"error_delete",
+			fmt.Errorf("failed to execute DELETE %s SQL: %v", dbcoretablenames.TableNameWebAuthnCredentials.ToString(), err),
+		)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
@@ -17,4 +17,65 @@ func (m *MigrationFunctions) migrateModels() {
 		logrus.Errorf("failed to migrate user table: %v", err)
 		return
 	}
+
+	// Миграция таблицы refresh токенов
+	err = tablesmigration.RefreshTokenTableMigrate(db)
+	if err != nil {
+		logrus.Errorf("failed to migrate refresh tokens table: %v", err)
+		return
+	}
+
+	// Миграция таблицы сессий
+	err = tablesmigration.SessionTableMigrate(db)
+	if err != nil {
+		logrus.Errorf("failed to migrate sessions table: %v", err)
+		return
+	}
+
+	// Миграция таблицы кодов восстановления
+	err = tablesmigration.RecoveryCodeTableMigrate(db)
+	if err != nil {
+		logrus.Errorf("failed to migrate recovery codes table: %v", err)
+		return
+	}
+
+	// Миграция таблицы ключей доступа WebAuthn
+	err = tablesmigration.WebAuthnCredentialTableMigrate(db)
+	if err != nil {
+		logrus.Errorf("failed to migrate webauthn credentials table: %v", err)
+		return
+	}
+
+	// Миграция таблицы внешних учетных записей
+	err = tablesmigration.UserIdentityTableMigrate(db)
+	if err != nil {
+		logrus.Errorf("failed to migrate user identities table: %v", err)
+		return
+	}
+
+	// Миграция таблиц клиентов OAuth 2.0 и согласий пользователей
+	err = tablesmigration.OAuthClientTableMigrate(db)
+	if err != nil {
+		logrus.Errorf("failed to migrate oauth clients table: %v", err)
+		return
+	}
+	err = tablesmigration.OAuthConsentTableMigrate(db)
+	if err != nil {
+		logrus.Errorf("failed to migrate oauth consents table: %v", err)
+		return
+	}
+
+	// Миграция таблицы персональных токенов доступа
+	err = tablesmigration.PersonalAccessTokenTableMigrate(db)
+	if err != nil {
+		logrus.Errorf("failed to migrate personal access tokens table: %v", err)
+		return
+	}
+
+	// Миграция журнала имперсонации
+	err = tablesmigration.ImpersonationTableMigrate(db)
+	if err != nil {
+		logrus.Errorf("failed to migrate impersonations table: %v", err)
+		return
+	}
 }
@@ -0,0 +1,36 @@
+package tablesmigration
+
+import (
+	dbcoretablenames "authentication_service/core/database/table_names"
+	"authentication_service/core/typescore"
+	"gorm.io/gorm"
+	"time"
+)
+
+type LocalImpersonationProvider typescore.Impersonation
+
+func (LocalImpersonationProvider) TableName() string {
+	return dbcoretablenames.TableNameImpersonations.ToString()
+}
+
+func ImpersonationTableMigrate(db *gorm.DB) error {
+	hasTable := db.Migrator().HasTable(&LocalImpersonationProvider{})
+
+	// Выполняем автосоздание таблицы
+	err := db.AutoMigrate(&LocalImpersonationProvider{})
+	if err != nil {
+		return err
+	}
+
+	if !hasTable {
+		time.Sleep(5 * time.Second) // Добавляем задержку
+
+		// Add comments to table and columns
+		db.Exec(`
+            COMMENT ON TABLE impersonations IS 'Журнал выдачи токенов для работы сотрудников от имени пользователей';
+            COMMENT ON COLUMN impersonations.id IS 'Совпадает с jti токена: по нему запросы в логах связываются с записью';
+            COMMENT ON COLUMN impersonations.actor_id IS 'Сотрудник поддержки или администратор (claim act.sub)';
+        `)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package tablesmigration
+
+import (
+	dbcoretablenames "authentication_service/core/database/table_names"
+	"authentication_service/core/typescore"
+	"gorm.io/gorm"
+	"time"
+)
+
+type LocalOAuthClientProvider typescore.OAuthClient
+
+func (LocalOAuthClientProvider) TableName() string {
+	return dbcoretablenames.TableNameOAuthClients.ToString()
+}
+
+type LocalOAuthConsentProvider typescore.OAuthConsent
+
+func (LocalOAuthConsentProvider) TableName() string {
+	return dbcoretablenames.TableNameOAuthConsents.ToString()
+}
+
+func OAuthClientTableMigrate(db *gorm.DB) error {
+	hasTable := db.Migrator().HasTable(&LocalOAuthClientProvider{})
+
+	// Выполняем автосоздание таблицы
+	err := db.AutoMigrate(&LocalOAuthClientProvider{})
+	if err != nil {
+		return err
+	}
+
+	if !hasTable {
+		time.Sleep(5 * time.Second) // Добавляем задержку
+
+		// Add comments to table and columns
+		db.Exec(`
+            COMMENT ON TABLE oauth_clients IS 'Таблица клиентских приложений OAuth 2.0';
+            COMMENT ON COLUMN oauth_clients.client_secret_hash IS 'SHA-256 секрета клиента';
+            COMMENT ON COLUMN oauth_clients.public_key IS 'PEM публичного ключа клиента для client_assertion; без секрета и ключа клиент публичный';
+        `)
+	}
+	return nil
+}
+
+func OAuthConsentTableMigrate(db *gorm.DB) error {
+	hasTable := db.Migrator().HasTable(&LocalOAuthConsentProvider{})
+
+	// Выполняем автосоздание таблицы
+	err := db.AutoMigrate(&LocalOAuthConsentProvider{})
+	if err != nil {
+		return err
+	}
+
+	if !hasTable {
+		time.Sleep(5 * time.Second) // Добавляем задержку
+
+		// Add comments to table and columns
+		db.Exec(`
+            COMMENT ON TABLE oauth_consents IS 'Таблица согласий пользователей на доступ клиентов OAuth 2.0, одна запись на пару user_id и client_id';
+            COMMENT ON COLUMN oauth_consents.scopes IS 'Области доступа, разрешенные пользователем клиенту, через пробел';
+        `)
+	}
+	return nil
+}
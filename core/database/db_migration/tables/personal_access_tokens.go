@@ -0,0 +1,36 @@
+package tablesmigration
+
+import (
+	dbcoretablenames "authentication_service/core/database/table_names"
+	"authentication_service/core/typescore"
+	"gorm.io/gorm"
+	"time"
+)
+
+type LocalPersonalAccessTokenProvider typescore.PersonalAccessToken
+
+func (LocalPersonalAccessTokenProvider) TableName() string {
+	return dbcoretablenames.TableNamePersonalTokens.ToString()
+}
+
+func PersonalAccessTokenTableMigrate(db *gorm.DB) error {
+	hasTable := db.Migrator().HasTable(&LocalPersonalAccessTokenProvider{})
+
+	// Выполняем автосоздание таблицы
+	err := db.AutoMigrate(&LocalPersonalAccessTokenProvider{})
+	if err != nil {
+		return err
+	}
+
+	if !hasTable {
+		time.Sleep(5 * time.Second) // Добавляем задержку
+
+		// Add comments to table and columns
+		db.Exec(`
+            COMMENT ON TABLE personal_access_tokens IS 'Таблица персональных токенов доступа пользователей (API-ключей)';
+            COMMENT ON COLUMN personal_access_tokens.token_hash IS 'SHA-256 токена; сам токен показывается пользователю один раз при создании';
+            COMMENT ON COLUMN personal_access_tokens.last_used_at IS 'Обновляется не чаще раза в минуту';
+        `)
+	}
+	return nil
+}
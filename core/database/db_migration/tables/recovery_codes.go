@@ -0,0 +1,34 @@
+package tablesmigration
+
+import (
+	dbcoretablenames "authentication_service/core/database/table_names"
+	"authentication_service/core/typescore"
+	"gorm.io/gorm"
+	"time"
+)
+
+type LocalRecoveryCodeProvider typescore.RecoveryCode
+
+func (LocalRecoveryCodeProvider) TableName() string {
+	return dbcoretablenames.TableNameRecoveryCodes.ToString()
+}
+
+func RecoveryCodeTableMigrate(db *gorm.DB) error {
+	hasTable := db.Migrator().HasTable(&LocalRecoveryCodeProvider{})
+
+	// Выполняем автосоздание таблицы
+	err := db.AutoMigrate(&LocalRecoveryCodeProvider{})
+	if err != nil {
+		return err
+	}
+
+	if !hasTable {
+		time.Sleep(5 * time.Second) // Добавляем задержку
+
+		// Add comments to table and columns
+		db.Exec(`
+            COMMENT ON TABLE recovery_codes IS 'Таблица хешей одноразовых кодов восстановления двухфакторной аутентификации';
+        `)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package tablesmigration
+
+import (
+	dbcoretablenames "authentication_service/core/database/table_names"
+	"authentication_service/core/typescore"
+	"gorm.io/gorm"
+	"time"
+)
+
+type LocalRefreshTokenProvider typescore.RefreshToken
+
+func (LocalRefreshTokenProvider) TableName() string {
+	return dbcoretablenames.TableNameRefreshTokens.ToString()
+}
+
+func RefreshTokenTableMigrate(db *gorm.DB) error {
+	hasTable := db.Migrator().HasTable(&LocalRefreshTokenProvider{})
+
+	// Выполняем автосоздание таблицы
+	err := db.AutoMigrate(&LocalRefreshTokenProvider{})
+	if err != nil {
+		return err
+	}
+
+	if !hasTable {
+		time.Sleep(5 * time.Second) // Добавляем задержку
+
+		// Add comments to table and columns
+		db.Exec(`
+            COMMENT ON TABLE refresh_tokens IS 'Таблица для хранения хешей выданных refresh токенов';
+            COMMENT ON COLUMN refresh_tokens.family_id IS 'Семейство токенов: при повторном использовании токена отзывается всё семейство';
+        `)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package tablesmigration
+
+import (
+	dbcoretablenames "authentication_service/core/database/table_names"
+	"authentication_service/core/typescore"
+	"gorm.io/gorm"
+	"time"
+)
+
+type LocalSessionProvider typescore.Session
+
+func (LocalSessionProvider) TableName() string {
+	return dbcoretablenames.TableNameSessions.ToString()
+}
+
+func SessionTableMigrate(db *gorm.DB) error {
+	hasTable := db.Migrator().HasTable(&LocalSessionProvider{})
+
+	// Выполняем автосоздание таблицы
+	err := db.AutoMigrate(&LocalSessionProvider{})
+	if err != nil {
+		return err
+	}
+
+	if !hasTable {
+		time.Sleep(5 * time.Second) // Добавляем задержку
+
+		// Add comments to table and columns
+		db.Exec(`
+            COMMENT ON TABLE sessions IS 'Таблица сессий пользователей на устройствах';
+            COMMENT ON COLUMN sessions.revoked_at IS 'Время завершения сессии: токены сессии перестают приниматься';
+        `)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package tablesmigration
+
+import (
+	dbcoretablenames "authentication_service/core/database/table_names"
+	"authentication_service/core/typescore"
+	"gorm.io/gorm"
+	"time"
+)
+
+type LocalUserIdentityProvider typescore.UserIdentity
+
+func (LocalUserIdentityProvider) TableName() string {
+	return dbcoretablenames.TableNameUserIdentities.ToString()
+}
+
+func UserIdentityTableMigrate(db *gorm.DB) error {
+	hasTable := db.Migrator().HasTable(&LocalUserIdentityProvider{})
+
+	// Выполняем автосоздание таблицы
+	err := db.AutoMigrate(&LocalUserIdentityProvider{})
+	if err != nil {
+		return err
+	}
+
+	if !hasTable {
+		time.Sleep(5 * time.Second) // Добавляем задержку
+
+		// Add comments to table and columns
+		db.Exec(`
+            COMMENT ON TABLE user_identities IS 'Таблица внешних учетных записей OpenID Connect, привязанных к users.system_id';
+            COMMENT ON COLUMN user_identities.subject IS 'Claim sub провайдера: уникален в пределах provider и не меняется при смене email';
+        `)
+	}
+	return nil
+}
@@ -15,6 +15,7 @@ func (LocalUserProvider) TableName() string {
 
 func UserTableMigrate(db *gorm.DB) error {
 	hasTable := db.Migrator().HasTable(&LocalUserProvider{})
+	hasEmailVerifiedAt := hasTable && db.Migrator().HasColumn(&LocalUserProvider{}, "email_verified_at")
 
 	// Выполняем автосоздание таблицы
 	err := db.AutoMigrate(&LocalUserProvider{})
@@ -30,5 +31,14 @@ func UserTableMigrate(db *gorm.DB) error {
             COMMENT ON TABLE users IS 'Таблица для хранения данных о пользователях';
         `)
 	}
+
+	if hasTable && !hasEmailVerifiedAt {
+		// Адреса пользователей, созданных до появления подтверждения, считаются подтвержденными,
+		// чтобы их токены не получили ограниченные области доступа
+		err = db.Exec(`UPDATE users SET email_verified_at = created_at WHERE email IS NOT NULL AND email_verified_at IS NULL`).Error
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
@@ -0,0 +1,35 @@
+package tablesmigration
+
+import (
+	dbcoretablenames "authentication_service/core/database/table_names"
+	"authentication_service/core/typescore"
+	"gorm.io/gorm"
+	"time"
+)
+
+type LocalWebAuthnCredentialProvider typescore.WebAuthnCredential
+
+func (LocalWebAuthnCredentialProvider) TableName() string {
+	return dbcoretablenames.TableNameWebAuthnCredentials.ToString()
+}
+
+func WebAuthnCredentialTableMigrate(db *gorm.DB) error {
+	hasTable := db.Migrator().HasTable(&LocalWebAuthnCredentialProvider{})
+
+	// Выполняем автосоздание таблицы
+	err := db.AutoMigrate(&LocalWebAuthnCredentialProvider{})
+	if err != nil {
+		return err
+	}
+
+	if !hasTable {
+		time.Sleep(5 * time.Second) // Добавляем задержку
+
+		// Add comments to table and columns
+		db.Exec(`
+            COMMENT ON TABLE webauthn_credentials IS 'Таблица ключей доступа WebAuthn (passkeys), привязанных к users.system_id';
+            COMMENT ON COLUMN webauthn_credentials.sign_count IS 'Последний принятый счетчик подписей: уменьшение счетчика указывает на клонированный аутентификатор';
+        `)
+	}
+	return nil
+}
@@ -3,8 +3,17 @@ package dbcoretablenames
 type TableName string
 
 const (
-	TableNameUsers        TableName = "users" // Пользователи
-	TableNameNotification TableName = "notifications"
+	TableNameUsers               TableName = "users" // Пользователи
+	TableNameNotification        TableName = "notifications"
+	TableNameRefreshTokens       TableName = "refresh_tokens"         // Выданные refresh токены
+	TableNameSessions            TableName = "sessions"               // Сессии пользователей на устройствах
+	TableNameRecoveryCodes       TableName = "recovery_codes"         // Коды восстановления двухфакторной аутентификации
+	TableNameWebAuthnCredentials TableName = "webauthn_credentials"   // Ключи доступа WebAuthn (passkeys)
+	TableNameUserIdentities      TableName = "user_identities"        // Внешние учетные записи (OpenID Connect)
+	TableNameOAuthClients        TableName = "oauth_clients"          // Клиентские приложения OAuth 2.0
+	TableNameOAuthConsents       TableName = "oauth_consents"         // Согласия пользователей на доступ клиентов OAuth 2.0
+	TableNamePersonalTokens      TableName = "personal_access_tokens" // Персональные токены доступа пользователей
+	TableNameImpersonations      TableName = "impersonations"         // Журнал работы сотрудников от имени пользователей
 )
 
 func (t TableName) ToString() string {
@@ -0,0 +1,63 @@
+package redismodule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type ConfigConnectRedis struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+}
+
+var (
+	client *redis.Client
+	once   sync.Once
+)
+
+// ConnectRedis подключается к Redis и проверяет соединение (Singleton)
+func ConnectRedis(configObj *ConfigConnectRedis) (*redis.Client, error) {
+	var err error
+	once.Do(func() {
+		if configObj == nil || configObj.Host == "" {
+			err = errors.New("ConnectRedis: redis is not configured")
+			return
+		}
+
+		log.Println("🚀 ConnectRedis")
+		redisClient := redis.NewClient(&redis.Options{
+			Addr:     fmt.Sprintf("%s:%d", configObj.Host, configObj.Port),
+			Username: configObj.User,
+			Password: configObj.Password,
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if pingErr := redisClient.Ping(ctx).Err(); pingErr != nil {
+			_ = redisClient.Close()
+			err = errors.New("ConnectRedis: ping failed: " + pingErr.Error())
+			return
+		}
+
+		client = redisClient
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if client == nil {
+		return nil, errors.New("ConnectRedis: failed to connect to redis: client is nil")
+	}
+
+	return client, nil
+}
@@ -0,0 +1,112 @@
+package denylist
+
+import (
+	"authentication_service/core/lib/internally/kvstore"
+	"context"
+	"strconv"
+	"time"
+)
+
+const (
+	jtiKeyPrefix        = "denylist:jti:"         // отозванные токены по jti
+	userBeforeKeyPrefix = "denylist:user_before:" // токены пользователя, выданные ранее указанного времени
+	sessionKeyPrefix    = "denylist:sid:"         // завершенные сессии
+
+	// отметки user_before до перехода на миллисекунды хранились в секундах Unix
+	legacySecondsLimit = 100_000_000_000
+)
+
+// TokenDenylist список отозванных токенов.
+// Хранит отозванные jti до истечения срока жизни токена, завершенные сессии
+// и отметку "все токены пользователя, выданные до момента T, недействительны".
+type TokenDenylist struct {
+	store kvstore.Store
+}
+
+func NewTokenDenylist(store kvstore.Store) *TokenDenylist {
+	return &TokenDenylist{store: store}
+}
+
+// RevokeJTI отзывает токен по jti до момента его истечения
+func (d *TokenDenylist) RevokeJTI(ctx context.Context, jti string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		// Токен уже истек, отзывать нечего
+		return nil
+	}
+	return d.store.Set(ctx, jtiKeyPrefix+jti, "1", ttl)
+}
+
+// IsJTIRevoked проверяет, отозван ли токен по jti
+func (d *TokenDenylist) IsJTIRevoked(ctx context.Context, jti string) (bool, error) {
+	_, ok, err := d.store.Get(ctx, jtiKeyPrefix+jti)
+	return ok, err
+}
+
+// RevokeUserTokensBefore отзывает все токены пользователя, выданные до before.
+// ttl должен быть не меньше максимального времени жизни токенов.
+func (d *TokenDenylist) RevokeUserTokensBefore(ctx context.Context, userID string, before time.Time, ttl time.Duration) error {
+	return d.store.Set(ctx, userBeforeKeyPrefix+userID, strconv.FormatInt(before.UnixMilli(), 10), ttl)
+}
+
+// UserTokensRevokedBefore возвращает момент, до которого токены пользователя считаются отозванными
+func (d *TokenDenylist) UserTokensRevokedBefore(ctx context.Context, userID string) (*time.Time, error) {
+	value, ok, err := d.store.Get(ctx, userBeforeKeyPrefix+userID)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	millis, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	before := time.UnixMilli(millis)
+	if millis < legacySecondsLimit {
+		// Старая отметка в секундах: отозванными остаются все токены этой секунды
+		before = time.Unix(millis+1, 0)
+	}
+	return &before, nil
+}
+
+// RevokeSession отзывает все токены сессии.
+// ttl должен быть не меньше максимального времени жизни токенов.
+func (d *TokenDenylist) RevokeSession(ctx context.Context, sessionID string, ttl time.Duration) error {
+	return d.store.Set(ctx, sessionKeyPrefix+sessionID, "1", ttl)
+}
+
+// IsSessionRevoked проверяет, завершена ли сессия
+func (d *TokenDenylist) IsSessionRevoked(ctx context.Context, sessionID string) (bool, error) {
+	_, ok, err := d.store.Get(ctx, sessionKeyPrefix+sessionID)
+	return ok, err
+}
+
+// IsTokenRevoked проверяет токен по jti, по сессии и по времени выдачи для пользователя
+func (d *TokenDenylist) IsTokenRevoked(ctx context.Context, jti, userID, sessionID string, issuedAt time.Time) (bool, error) {
+	if jti != "" {
+		revoked, err := d.IsJTIRevoked(ctx, jti)
+		if err != nil || revoked {
+			return revoked, err
+		}
+	}
+
+	if sessionID != "" {
+		revoked, err := d.IsSessionRevoked(ctx, sessionID)
+		if err != nil || revoked {
+			return revoked, err
+		}
+	}
+
+	if userID != "" {
+		before, err := d.UserTokensRevokedBefore(ctx, userID)
+		if err != nil {
+			return false, err
+		}
+		// iat и отметка хранятся с точностью до миллисекунды: токен, выданный сразу после отзыва
+		// (новая пара после сброса пароля), остается действительным
+		if before != nil && issuedAt.Before(*before) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
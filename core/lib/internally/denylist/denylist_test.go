@@ -0,0 +1,141 @@
+package denylist
+
+import (
+	"authentication_service/core/lib/internally/kvstore"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestIsTokenRevoked(t *testing.T) {
+	ctx := context.Background()
+	revokedAt := time.Unix(1_700_000_000, 0)
+
+	tests := []struct {
+		name      string
+		setup     func(d *TokenDenylist)
+		jti       string
+		userID    string
+		sessionID string
+		issuedAt  time.Time
+		want      bool
+	}{
+		{
+			name:     "not revoked",
+			setup:    func(d *TokenDenylist) {},
+			jti:      "jti-1",
+			userID:   "user-1",
+			issuedAt: revokedAt,
+		},
+		{
+			name: "revoked by jti",
+			setup: func(d *TokenDenylist) {
+				_ = d.RevokeJTI(ctx, "jti-1", time.Now().Add(time.Hour))
+			},
+			jti:      "jti-1",
+			issuedAt: revokedAt,
+			want:     true,
+		},
+		{
+			name: "other jti",
+			setup: func(d *TokenDenylist) {
+				_ = d.RevokeJTI(ctx, "jti-2", time.Now().Add(time.Hour))
+			},
+			jti:      "jti-1",
+			issuedAt: revokedAt,
+		},
+		{
+			name: "expired token is not stored",
+			setup: func(d *TokenDenylist) {
+				_ = d.RevokeJTI(ctx, "jti-1", time.Now().Add(-time.Second))
+			},
+			jti:      "jti-1",
+			issuedAt: revokedAt,
+		},
+		{
+			name: "revoked by session",
+			setup: func(d *TokenDenylist) {
+				_ = d.RevokeSession(ctx, "sid-1", time.Hour)
+			},
+			jti:       "jti-1",
+			sessionID: "sid-1",
+			issuedAt:  revokedAt,
+			want:      true,
+		},
+		{
+			name: "issued before user revocation",
+			setup: func(d *TokenDenylist) {
+				_ = d.RevokeUserTokensBefore(ctx, "user-1", revokedAt, time.Hour)
+			},
+			userID:   "user-1",
+			issuedAt: revokedAt.Add(-time.Second),
+			want:     true,
+		},
+		{
+			name: "issued earlier in the same second as user revocation",
+			setup: func(d *TokenDenylist) {
+				_ = d.RevokeUserTokensBefore(ctx, "user-1", revokedAt.Add(500*time.Millisecond), time.Hour)
+			},
+			userID:   "user-1",
+			issuedAt: revokedAt.Add(499 * time.Millisecond),
+			want:     true,
+		},
+		{
+			// Новая пара токенов, выданная сразу после сброса пароля или logout-all
+			name: "issued later in the same second as user revocation",
+			setup: func(d *TokenDenylist) {
+				_ = d.RevokeUserTokensBefore(ctx, "user-1", revokedAt.Add(500*time.Millisecond), time.Hour)
+			},
+			userID:   "user-1",
+			issuedAt: revokedAt.Add(501 * time.Millisecond),
+		},
+		{
+			name: "issued in the same millisecond as user revocation",
+			setup: func(d *TokenDenylist) {
+				_ = d.RevokeUserTokensBefore(ctx, "user-1", revokedAt.Add(500*time.Millisecond), time.Hour)
+			},
+			userID:   "user-1",
+			issuedAt: revokedAt.Add(500 * time.Millisecond),
+		},
+		{
+			name: "legacy mark in seconds revokes the whole second",
+			setup: func(d *TokenDenylist) {
+				_ = d.store.Set(ctx, userBeforeKeyPrefix+"user-1", "1700000000", time.Hour)
+			},
+			userID:   "user-1",
+			issuedAt: revokedAt.Add(999 * time.Millisecond),
+			want:     true,
+		},
+		{
+			name: "issued after user revocation",
+			setup: func(d *TokenDenylist) {
+				_ = d.RevokeUserTokensBefore(ctx, "user-1", revokedAt, time.Hour)
+			},
+			userID:   "user-1",
+			issuedAt: revokedAt.Add(time.Second),
+		},
+		{
+			name: "other user revoked",
+			setup: func(d *TokenDenylist) {
+				_ = d.RevokeUserTokensBefore(ctx, "user-2", revokedAt, time.Hour)
+			},
+			userID:   "user-1",
+			issuedAt: revokedAt.Add(-time.Hour),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewTokenDenylist(kvstore.NewMemoryStore())
+			tt.setup(d)
+
+			got, err := d.IsTokenRevoked(ctx, tt.jti, tt.userID, tt.sessionID, tt.issuedAt)
+			if err != nil {
+				t.Fatalf("IsTokenRevoked() error = %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("IsTokenRevoked() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
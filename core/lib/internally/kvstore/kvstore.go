@@ -0,0 +1,38 @@
+package kvstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+// Store хранилище ключ-значение с временем жизни записей.
+// Основная реализация — Redis (общая для всех процессов сервисов),
+// при его недоступности используется хранилище в памяти процесса.
+type Store interface {
+	// Set сохраняет значение на время ttl (0 — без ограничения)
+	Set(ctx context.Context, key, value string, ttl time.Duration) error
+	// Get возвращает значение и признак его наличия
+	Get(ctx context.Context, key string) (string, bool, error)
+	// Delete удаляет значение
+	Delete(ctx context.Context, key string) error
+	// SetIfAbsent сохраняет значение, только если ключ отсутствует; возвращает true, если значение сохранено
+	SetIfAbsent(ctx context.Context, key, value string, ttl time.Duration) (bool, error)
+	// Take атомарно возвращает и удаляет значение: из параллельных вызовов значение получает только один
+	Take(ctx context.Context, key string) (string, bool, error)
+	// Incr атомарно увеличивает счетчик и возвращает новое значение.
+	// Время жизни ttl задается при создании счетчика и не продлевается последующими вызовами
+	Incr(ctx context.Context, key string, ttl time.Duration) (int64, error)
+}
+
+// NewStore возвращает хранилище на основе Redis,
+// либо хранилище в памяти, если клиент Redis не передан
+func NewStore(client *redis.Client) Store {
+	if client == nil {
+		logrus.Warn("⚠️ kvstore: redis client is nil, using in-memory store")
+		return NewMemoryStore()
+	}
+	return NewRedisStore(client)
+}
@@ -0,0 +1,127 @@
+package kvstore
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// stores возвращает реализации хранилища: в памяти и Redis (miniredis в процессе теста)
+func stores(t *testing.T) map[string]Store {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	return map[string]Store{
+		"memory": NewMemoryStore(),
+		"redis":  NewRedisStore(client),
+	}
+}
+
+func TestStoreSetGetDelete(t *testing.T) {
+	ctx := context.Background()
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			if _, ok, err := store.Get(ctx, "missing"); err != nil || ok {
+				t.Fatalf("Get(missing) = ok %v, err %v", ok, err)
+			}
+			if err := store.Set(ctx, "key", "value", time.Minute); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+			if value, ok, err := store.Get(ctx, "key"); err != nil || !ok || value != "value" {
+				t.Fatalf("Get(key) = %q, %v, %v", value, ok, err)
+			}
+			if err := store.Delete(ctx, "key"); err != nil {
+				t.Fatalf("Delete() error = %v", err)
+			}
+			if _, ok, _ := store.Get(ctx, "key"); ok {
+				t.Fatal("value is present after Delete")
+			}
+		})
+	}
+}
+
+func TestStoreSetIfAbsent(t *testing.T) {
+	ctx := context.Background()
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			if ok, err := store.SetIfAbsent(ctx, "key", "first", time.Minute); err != nil || !ok {
+				t.Fatalf("first SetIfAbsent() = %v, %v", ok, err)
+			}
+			if ok, err := store.SetIfAbsent(ctx, "key", "second", time.Minute); err != nil || ok {
+				t.Fatalf("second SetIfAbsent() = %v, %v", ok, err)
+			}
+			if value, _, _ := store.Get(ctx, "key"); value != "first" {
+				t.Fatalf("Get() = %q, want first", value)
+			}
+		})
+	}
+}
+
+func TestStoreTakeSingleWinner(t *testing.T) {
+	ctx := context.Background()
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			if err := store.Set(ctx, "key", "value", time.Minute); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+
+			var winners atomic.Int32
+			var wg sync.WaitGroup
+			for i := 0; i < 20; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					if value, ok, err := store.Take(ctx, "key"); err == nil && ok && value == "value" {
+						winners.Add(1)
+					}
+				}()
+			}
+			wg.Wait()
+
+			if got := winners.Load(); got != 1 {
+				t.Fatalf("Take() succeeded %d times, want 1", got)
+			}
+		})
+	}
+}
+
+func TestStoreIncrConcurrent(t *testing.T) {
+	ctx := context.Background()
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			const workers = 50
+			var wg sync.WaitGroup
+			seen := make([]atomic.Int32, workers+1)
+			for i := 0; i < workers; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					n, err := store.Incr(ctx, "counter", time.Minute)
+					if err != nil {
+						t.Errorf("Incr() error = %v", err)
+						return
+					}
+					if n < 1 || n > workers {
+						t.Errorf("Incr() = %d, out of range", n)
+						return
+					}
+					seen[n].Add(1)
+				}()
+			}
+			wg.Wait()
+
+			for n := 1; n <= workers; n++ {
+				if got := seen[n].Load(); got != 1 {
+					t.Fatalf("value %d returned %d times, want once", n, got)
+				}
+			}
+		})
+	}
+}
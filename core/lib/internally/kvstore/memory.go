@@ -0,0 +1,129 @@
+package kvstore
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// интервал очистки просроченных записей
+const memoryCleanupInterval = time.Minute
+
+type memoryItem struct {
+	value     string
+	expiresAt time.Time // нулевое значение — без ограничения
+}
+
+func (i memoryItem) expired(now time.Time) bool {
+	return !i.expiresAt.IsZero() && now.After(i.expiresAt)
+}
+
+// MemoryStore хранилище в памяти процесса.
+// Данные не разделяются между экземплярами сервисов.
+type MemoryStore struct {
+	mu    sync.RWMutex
+	items map[string]memoryItem
+}
+
+func NewMemoryStore() *MemoryStore {
+	s := &MemoryStore{items: make(map[string]memoryItem)}
+	go s.cleanupLoop()
+	return s
+}
+
+func (s *MemoryStore) Set(_ context.Context, key, value string, ttl time.Duration) error {
+	item := memoryItem{value: value}
+	if ttl > 0 {
+		item.expiresAt = time.Now().Add(ttl)
+	}
+
+	s.mu.Lock()
+	s.items[key] = item
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *MemoryStore) Get(_ context.Context, key string) (string, bool, error) {
+	s.mu.RLock()
+	item, ok := s.items[key]
+	s.mu.RUnlock()
+
+	if !ok || item.expired(time.Now()) {
+		return "", false, nil
+	}
+	return item.value, true, nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	delete(s.items, key)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *MemoryStore) SetIfAbsent(_ context.Context, key, value string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	item := memoryItem{value: value}
+	if ttl > 0 {
+		item.expiresAt = now.Add(ttl)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, ok := s.items[key]; ok && !existing.expired(now) {
+		return false, nil
+	}
+	s.items[key] = item
+	return true, nil
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string) (string, bool, error) {
+	s.mu.Lock()
+	item, ok := s.items[key]
+	delete(s.items, key)
+	s.mu.Unlock()
+
+	if !ok || item.expired(time.Now()) {
+		return "", false, nil
+	}
+	return item.value, true, nil
+}
+
+func (s *MemoryStore) Incr(_ context.Context, key string, ttl time.Duration) (int64, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item, ok := s.items[key]
+	if !ok || item.expired(now) {
+		item = memoryItem{value: "0"}
+		if ttl > 0 {
+			item.expiresAt = now.Add(ttl)
+		}
+	}
+	value, err := strconv.ParseInt(item.value, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	value++
+	item.value = strconv.FormatInt(value, 10)
+	s.items[key] = item
+	return value, nil
+}
+
+// cleanupLoop периодически удаляет просроченные записи
+func (s *MemoryStore) cleanupLoop() {
+	ticker := time.NewTicker(memoryCleanupInterval)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		s.mu.Lock()
+		for key, item := range s.items {
+			if item.expired(now) {
+				delete(s.items, key)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
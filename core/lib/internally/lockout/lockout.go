@@ -0,0 +1,254 @@
+package lockout
+
+import (
+	"authentication_service/core/lib/internally/kvstore"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	failuresKeyPrefix = "lockout:failures:" // неудачные попытки в текущем окне
+	untilKeyPrefix    = "lockout:until:"    // время, до которого попытки отклоняются
+	levelKeyPrefix    = "lockout:level:"    // число блокировок подряд: определяет длительность следующей
+
+	// сколько помнится число блокировок подряд
+	levelTTL = 24 * time.Hour
+
+	accountKind = "account:"
+	ipKind      = "ip:"
+)
+
+// Значения по умолчанию для незаданных параметров
+const (
+	DefaultAccountMaxFailures = 5
+	DefaultIPMaxFailures      = 50
+	DefaultFailureWindow      = 15 * time.Minute
+	DefaultBaseDelay          = time.Second
+	DefaultMaxDelay           = 30 * time.Second
+	DefaultBaseLockout        = 5 * time.Minute
+	DefaultMaxLockout         = time.Hour
+)
+
+// ErrLocked попытки аутентификации временно отклоняются
+var ErrLocked = errors.New("too many failed attempts")
+
+// LockedError попытка отклонена до истечения задержки или блокировки
+type LockedError struct {
+	RetryAfter time.Duration
+	Lockout    bool // true — временная блокировка, false — задержка после неудачной попытки
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("%v: retry after %s", ErrLocked, e.RetryAfter.Round(time.Second))
+}
+
+func (e *LockedError) Unwrap() error {
+	return ErrLocked
+}
+
+// Params пороги и длительности задержек и блокировок; нулевые значения заменяются значениями по умолчанию
+type Params struct {
+	AccountMaxFailures int           // неудачных попыток по аккаунту до блокировки
+	IPMaxFailures      int           // неудачных попыток с IP-адреса до блокировки
+	FailureWindow      time.Duration // окно подсчета неудачных попыток
+	BaseDelay          time.Duration // задержка аккаунта после первой неудачи, удваивается с каждой следующей
+	MaxDelay           time.Duration
+	BaseLockout        time.Duration // первая блокировка, удваивается с каждой следующей в течение суток
+	MaxLockout         time.Duration
+}
+
+// Result результат учета неудачной попытки
+type Result struct {
+	AccountLocked bool // аккаунт заблокирован этой попыткой: пользователя нужно уведомить
+	IPLocked      bool // IP-адрес заблокирован этой попыткой
+}
+
+// Tracker учет неудачных попыток аутентификации по аккаунту и по IP-адресу
+// с экспоненциальными задержками и временными блокировками.
+// Счетчики хранятся в общем хранилище (Redis); при ошибке хранилища используется память процесса,
+// чтобы сбой Redis не отключал защиту от перебора.
+type Tracker struct {
+	store    kvstore.Store
+	fallback kvstore.Store
+	params   Params
+}
+
+func NewTracker(store kvstore.Store, p Params) *Tracker {
+	if p.AccountMaxFailures <= 0 {
+		p.AccountMaxFailures = DefaultAccountMaxFailures
+	}
+	if p.IPMaxFailures <= 0 {
+		p.IPMaxFailures = DefaultIPMaxFailures
+	}
+	if p.FailureWindow <= 0 {
+		p.FailureWindow = DefaultFailureWindow
+	}
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = DefaultBaseDelay
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = DefaultMaxDelay
+	}
+	if p.BaseLockout <= 0 {
+		p.BaseLockout = DefaultBaseLockout
+	}
+	if p.MaxLockout <= 0 {
+		p.MaxLockout = DefaultMaxLockout
+	}
+	if store == nil {
+		store = kvstore.NewMemoryStore()
+	}
+	return &Tracker{store: store, fallback: kvstore.NewMemoryStore(), params: p}
+}
+
+// Check возвращает *LockedError, если аккаунт или IP-адрес заблокирован или не истекла задержка.
+// Пустой account или ip не проверяется
+func (t *Tracker) Check(ctx context.Context, account, ip string) error {
+	for _, subject := range subjects(account, ip) {
+		value, ok := t.get(ctx, untilKeyPrefix+subject)
+		if !ok {
+			continue
+		}
+		until, lockout := parseUntil(value)
+		if retryAfter := time.Until(until); retryAfter > 0 {
+			return &LockedError{RetryAfter: retryAfter, Lockout: lockout}
+		}
+	}
+	return nil
+}
+
+// Failure учитывает неудачную попытку: назначает аккаунту задержку, удваивающуюся с каждой неудачей,
+// а по достижении порога блокирует аккаунт или IP-адрес на время, удваивающееся с каждой блокировкой
+func (t *Tracker) Failure(ctx context.Context, account, ip string) *Result {
+	result := &Result{}
+	if account != "" {
+		result.AccountLocked = t.failure(ctx, accountKind+account, t.params.AccountMaxFailures, true)
+	}
+	if ip != "" {
+		result.IPLocked = t.failure(ctx, ipKind+ip, t.params.IPMaxFailures, false)
+	}
+	return result
+}
+
+// Success сбрасывает неудачные попытки и задержку аккаунта после успешной аутентификации.
+// Число блокировок подряд сохраняется: повторный перебор снова получит удлиненную блокировку
+func (t *Tracker) Success(ctx context.Context, account string) {
+	if account == "" {
+		return
+	}
+	t.delete(ctx, failuresKeyPrefix+accountKind+account)
+	if value, ok := t.get(ctx, untilKeyPrefix+accountKind+account); ok {
+		// Блокировку успешная попытка не снимает: до нее запрос не доходит
+		if _, lockout := parseUntil(value); !lockout {
+			t.delete(ctx, untilKeyPrefix+accountKind+account)
+		}
+	}
+}
+
+// Unlock снимает блокировку аккаунта и сбрасывает его счетчики (разблокировка администратором)
+func (t *Tracker) Unlock(ctx context.Context, account string) {
+	for _, prefix := range []string{failuresKeyPrefix, untilKeyPrefix, levelKeyPrefix} {
+		t.delete(ctx, prefix+accountKind+account)
+	}
+}
+
+// failure учитывает неудачу субъекта; возвращает true, если субъект заблокирован этой попыткой
+func (t *Tracker) failure(ctx context.Context, subject string, maxFailures int, delay bool) bool {
+	failures := t.incr(ctx, failuresKeyPrefix+subject, t.params.FailureWindow)
+
+	if failures >= int64(maxFailures) {
+		level := t.incr(ctx, levelKeyPrefix+subject, levelTTL)
+		duration := backoff(t.params.BaseLockout, t.params.MaxLockout, level)
+		t.set(ctx, untilKeyPrefix+subject, formatUntil(time.Now().Add(duration), true), duration)
+		t.delete(ctx, failuresKeyPrefix+subject)
+		logrus.Warnf("🔒 lockout: %s locked for %s after %d failed attempts", subject, duration, failures)
+		return true
+	}
+
+	if delay {
+		duration := backoff(t.params.BaseDelay, t.params.MaxDelay, failures)
+		t.set(ctx, untilKeyPrefix+subject, formatUntil(time.Now().Add(duration), false), duration)
+	}
+	return false
+}
+
+// backoff возвращает base * 2^(n-1), не больше limit
+func backoff(base, limit time.Duration, n int64) time.Duration {
+	duration := base
+	for i := int64(1); i < n && duration < limit; i++ {
+		duration *= 2
+	}
+	if duration > limit {
+		return limit
+	}
+	return duration
+}
+
+func subjects(account, ip string) []string {
+	result := make([]string, 0, 2)
+	if account != "" {
+		result = append(result, accountKind+account)
+	}
+	if ip != "" {
+		result = append(result, ipKind+ip)
+	}
+	return result
+}
+
+// formatUntil кодирует время окончания и признак блокировки: "<unix ms>:lock" или "<unix ms>:delay"
+func formatUntil(until time.Time, lockout bool) string {
+	kind := "delay"
+	if lockout {
+		kind = "lock"
+	}
+	return strconv.FormatInt(until.UnixMilli(), 10) + ":" + kind
+}
+
+func parseUntil(value string) (time.Time, bool) {
+	millis, kind, _ := strings.Cut(value, ":")
+	n, err := strconv.ParseInt(millis, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.UnixMilli(n), kind == "lock"
+}
+
+// Операции с хранилищем: при ошибке основного хранилища используется память процесса
+
+func (t *Tracker) incr(ctx context.Context, key string, ttl time.Duration) int64 {
+	value, err := t.store.Incr(ctx, key, ttl)
+	if err != nil {
+		logrus.Warnf("lockout: store unavailable, using in-memory fallback: %v", err)
+		value, _ = t.fallback.Incr(ctx, key, ttl)
+	}
+	return value
+}
+
+func (t *Tracker) get(ctx context.Context, key string) (string, bool) {
+	value, ok, err := t.store.Get(ctx, key)
+	if err != nil {
+		logrus.Warnf("lockout: store unavailable, using in-memory fallback: %v", err)
+		value, ok, _ = t.fallback.Get(ctx, key)
+	}
+	return value, ok
+}
+
+func (t *Tracker) set(ctx context.Context, key, value string, ttl time.Duration) {
+	if err := t.store.Set(ctx, key, value, ttl); err != nil {
+		logrus.Warnf("lockout: store unavailable, using in-memory fallback: %v", err)
+		_ = t.fallback.Set(ctx, key, value, ttl)
+	}
+}
+
+func (t *Tracker) delete(ctx context.Context, key string) {
+	if err := t.store.Delete(ctx, key); err != nil {
+		logrus.Warnf("lockout: store unavailable, using in-memory fallback: %v", err)
+	}
+	_ = t.fallback.Delete(ctx, key)
+}
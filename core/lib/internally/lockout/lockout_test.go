@@ -0,0 +1,179 @@
+package lockout
+
+import (
+	"authentication_service/core/lib/internally/kvstore"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		name  string
+		base  time.Duration
+		limit time.Duration
+		n     int64
+		want  time.Duration
+	}{
+		{"first", time.Second, 30 * time.Second, 1, time.Second},
+		{"second doubles", time.Second, 30 * time.Second, 2, 2 * time.Second},
+		{"fifth", time.Second, 30 * time.Second, 5, 16 * time.Second},
+		{"capped", time.Second, 30 * time.Second, 6, 30 * time.Second},
+		{"far beyond limit does not overflow", time.Second, 30 * time.Second, 1000, 30 * time.Second},
+		{"zero counts as first", 5 * time.Minute, time.Hour, 0, 5 * time.Minute},
+		{"base above limit", 2 * time.Hour, time.Hour, 1, time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := backoff(tt.base, tt.limit, tt.n); got != tt.want {
+				t.Fatalf("backoff(%s, %s, %d) = %s, want %s", tt.base, tt.limit, tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatParseUntil(t *testing.T) {
+	until := time.UnixMilli(1700000000123)
+	for _, lockout := range []bool{true, false} {
+		got, gotLockout := parseUntil(formatUntil(until, lockout))
+		if !got.Equal(until) || gotLockout != lockout {
+			t.Fatalf("parseUntil(formatUntil(%v, %v)) = %v, %v", until, lockout, got, gotLockout)
+		}
+	}
+	if got, _ := parseUntil("garbage"); !got.IsZero() {
+		t.Fatalf("parseUntil(garbage) = %v, want zero time", got)
+	}
+}
+
+// lockedFor проверяет, что Check отклоняет попытку на время около want
+func lockedFor(t *testing.T, err error, want time.Duration, lockout bool) {
+	t.Helper()
+	var locked *LockedError
+	if !errors.As(err, &locked) || !errors.Is(err, ErrLocked) {
+		t.Fatalf("Check() error = %v, want *LockedError", err)
+	}
+	if locked.Lockout != lockout {
+		t.Fatalf("Lockout = %v, want %v", locked.Lockout, lockout)
+	}
+	if locked.RetryAfter <= want-time.Second || locked.RetryAfter > want {
+		t.Fatalf("RetryAfter = %s, want about %s", locked.RetryAfter, want)
+	}
+}
+
+func newTestTracker(store kvstore.Store) *Tracker {
+	return NewTracker(store, Params{
+		AccountMaxFailures: 3,
+		IPMaxFailures:      5,
+		BaseDelay:          10 * time.Second,
+		MaxDelay:           time.Minute,
+		BaseLockout:        10 * time.Minute,
+		MaxLockout:         time.Hour,
+	})
+}
+
+func TestTrackerAccountDelayAndLockout(t *testing.T) {
+	ctx := context.Background()
+	tracker := newTestTracker(kvstore.NewMemoryStore())
+
+	if err := tracker.Check(ctx, "user", ""); err != nil {
+		t.Fatalf("Check() before failures error = %v", err)
+	}
+
+	// Задержка удваивается с каждой неудачей
+	tracker.Failure(ctx, "user", "")
+	lockedFor(t, tracker.Check(ctx, "user", ""), 10*time.Second, false)
+	tracker.Failure(ctx, "user", "")
+	lockedFor(t, tracker.Check(ctx, "user", ""), 20*time.Second, false)
+
+	// Порог достигнут: блокировка вместо задержки
+	if result := tracker.Failure(ctx, "user", ""); !result.AccountLocked {
+		t.Fatal("Failure() at threshold did not lock the account")
+	}
+	lockedFor(t, tracker.Check(ctx, "user", ""), 10*time.Minute, true)
+
+	// Успешный вход блокировку не снимает
+	tracker.Success(ctx, "user")
+	lockedFor(t, tracker.Check(ctx, "user", ""), 10*time.Minute, true)
+
+	// Следующая блокировка в течение суток вдвое длиннее
+	for i := 0; i < 3; i++ {
+		tracker.Failure(ctx, "user", "")
+	}
+	lockedFor(t, tracker.Check(ctx, "user", ""), 20*time.Minute, true)
+
+	tracker.Unlock(ctx, "user")
+	if err := tracker.Check(ctx, "user", ""); err != nil {
+		t.Fatalf("Check() after Unlock error = %v", err)
+	}
+}
+
+func TestTrackerSuccessClearsDelay(t *testing.T) {
+	ctx := context.Background()
+	tracker := newTestTracker(kvstore.NewMemoryStore())
+
+	tracker.Failure(ctx, "user", "")
+	tracker.Failure(ctx, "user", "")
+	tracker.Success(ctx, "user")
+	if err := tracker.Check(ctx, "user", ""); err != nil {
+		t.Fatalf("Check() after Success error = %v", err)
+	}
+
+	// Счетчик тоже сброшен: следующая неудача снова дает базовую задержку
+	tracker.Failure(ctx, "user", "")
+	lockedFor(t, tracker.Check(ctx, "user", ""), 10*time.Second, false)
+}
+
+func TestTrackerIPLockout(t *testing.T) {
+	ctx := context.Background()
+	tracker := newTestTracker(kvstore.NewMemoryStore())
+
+	// Перебор разных аккаунтов с одного адреса: задержка по IP не назначается, только блокировка
+	for i := 0; i < 4; i++ {
+		if result := tracker.Failure(ctx, "", "203.0.113.7"); result.IPLocked {
+			t.Fatalf("IP locked after %d failures, want 5", i+1)
+		}
+		if err := tracker.Check(ctx, "", "203.0.113.7"); err != nil {
+			t.Fatalf("Check() before IP threshold error = %v", err)
+		}
+	}
+	if result := tracker.Failure(ctx, "", "203.0.113.7"); !result.IPLocked {
+		t.Fatal("Failure() at IP threshold did not lock the address")
+	}
+	lockedFor(t, tracker.Check(ctx, "other-user", "203.0.113.7"), 10*time.Minute, true)
+
+	if err := tracker.Check(ctx, "other-user", "198.51.100.1"); err != nil {
+		t.Fatalf("Check() from another address error = %v", err)
+	}
+}
+
+// failingStore хранилище, все операции которого завершаются ошибкой (недоступный Redis)
+type failingStore struct{}
+
+var errStoreDown = errors.New("store is down")
+
+func (failingStore) Set(context.Context, string, string, time.Duration) error { return errStoreDown }
+func (failingStore) Get(context.Context, string) (string, bool, error) {
+	return "", false, errStoreDown
+}
+func (failingStore) Delete(context.Context, string) error { return errStoreDown }
+func (failingStore) SetIfAbsent(context.Context, string, string, time.Duration) (bool, error) {
+	return false, errStoreDown
+}
+func (failingStore) Take(context.Context, string) (string, bool, error) {
+	return "", false, errStoreDown
+}
+func (failingStore) Incr(context.Context, string, time.Duration) (int64, error) {
+	return 0, errStoreDown
+}
+
+func TestTrackerFallbackWhenStoreIsDown(t *testing.T) {
+	ctx := context.Background()
+	tracker := newTestTracker(failingStore{})
+
+	for i := 0; i < 3; i++ {
+		tracker.Failure(ctx, "user", "")
+	}
+	lockedFor(t, tracker.Check(ctx, "user", ""), 10*time.Minute, true)
+}
@@ -0,0 +1,198 @@
+package onetimecode
+
+import (
+	"authentication_service/core/lib/internally/kvstore"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+const (
+	codeKeyPrefix     = "otc:code:"     // действующий код по назначению и субъекту
+	attemptsKeyPrefix = "otc:attempts:" // счетчик попыток ввода действующего кода
+	cooldownKeyPrefix = "otc:cooldown:" // запрет повторной отправки кода
+)
+
+var (
+	ErrCodeNotFound    = errors.New("code not found or expired")           // Код не выдавался или истек
+	ErrCodeInvalid     = errors.New("invalid code")                        // Код не совпадает
+	ErrTooManyAttempts = errors.New("too many attempts")                   // Исчерпаны попытки ввода, код аннулирован
+	ErrResendTooSoon   = errors.New("code was sent recently, retry later") // Повторная отправка раньше допустимого интервала
+)
+
+// Params время жизни кода и ограничения на ввод и повторную отправку
+type Params struct {
+	TTL            time.Duration
+	MaxAttempts    int
+	ResendInterval time.Duration // 0 — без ограничения
+}
+
+// Store одноразовые коды подтверждения (email, сброс пароля, ссылки входа).
+// Хранится только хеш кода; на каждый субъект и назначение действует один код.
+// Попытки считаются атомарным Incr, а верный код забирается через Take,
+// поэтому параллельные запросы не превышают лимит попыток и не погашают код дважды.
+type Store struct {
+	store kvstore.Store
+}
+
+func NewStore(store kvstore.Store) *Store {
+	return &Store{store: store}
+}
+
+type codeRecord struct {
+	Hash        string `json:"hash"`
+	MaxAttempts int    `json:"max_attempts"`
+	ExpiresAt   int64  `json:"expires_at"`
+}
+
+// Issue сохраняет код для субъекта, заменяя выданный ранее.
+// Возвращает ErrResendTooSoon, если предыдущий код отправлен раньше ResendInterval.
+func (s *Store) Issue(ctx context.Context, purpose, subject, code string, p Params) error {
+	cooldownKey := cooldownKeyPrefix + purpose + ":" + subject
+	if p.ResendInterval > 0 {
+		_, ok, err := s.store.Get(ctx, cooldownKey)
+		if err != nil {
+			return err
+		}
+		if ok {
+			return ErrResendTooSoon
+		}
+	}
+
+	record := codeRecord{
+		Hash:        hashCode(code),
+		MaxAttempts: p.MaxAttempts,
+		ExpiresAt:   time.Now().Add(p.TTL).Unix(),
+	}
+	value, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if err := s.store.Set(ctx, codeKeyPrefix+purpose+":"+subject, string(value), p.TTL); err != nil {
+		return err
+	}
+	// Новый код получает полный запас попыток
+	if err := s.store.Delete(ctx, attemptsKeyPrefix+purpose+":"+subject); err != nil {
+		return err
+	}
+
+	if p.ResendInterval > 0 {
+		return s.store.Set(ctx, cooldownKey, "1", p.ResendInterval)
+	}
+	return nil
+}
+
+// Verify проверяет код. Верный код аннулируется, неверный расходует попытку;
+// после исчерпания попыток код аннулируется и возвращается ErrTooManyAttempts.
+func (s *Store) Verify(ctx context.Context, purpose, subject, code string) error {
+	key := codeKeyPrefix + purpose + ":" + subject
+	attemptsKey := attemptsKeyPrefix + purpose + ":" + subject
+
+	value, ok, err := s.store.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrCodeNotFound
+	}
+
+	var record codeRecord
+	if err := json.Unmarshal([]byte(value), &record); err != nil {
+		return err
+	}
+	ttl := time.Until(time.Unix(record.ExpiresAt, 0))
+	if ttl <= 0 {
+		_ = s.store.Delete(ctx, key)
+		return ErrCodeNotFound
+	}
+
+	// Попытка резервируется до сравнения: параллельные запросы получают разные номера
+	attempt := int64(0)
+	if record.MaxAttempts > 0 {
+		attempt, err = s.store.Incr(ctx, attemptsKey, ttl)
+		if err != nil {
+			return err
+		}
+		if attempt > int64(record.MaxAttempts) {
+			_ = s.store.Delete(ctx, key)
+			return ErrTooManyAttempts
+		}
+	}
+
+	if subtle.ConstantTimeCompare([]byte(record.Hash), []byte(hashCode(code))) != 1 {
+		if record.MaxAttempts > 0 && attempt >= int64(record.MaxAttempts) {
+			if err := s.store.Delete(ctx, key); err != nil {
+				return err
+			}
+			return ErrTooManyAttempts
+		}
+		return ErrCodeInvalid
+	}
+
+	// Код гасит только тот запрос, который атомарно забрал запись
+	taken, ok, err := s.store.Take(ctx, key)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrCodeNotFound
+	}
+	if taken != value {
+		// Между чтением и Take выдан новый код: возвращаем его на место
+		if ttl := ttlOf(taken); ttl > 0 {
+			_, _ = s.store.SetIfAbsent(ctx, key, taken, ttl)
+		}
+		return ErrCodeNotFound
+	}
+	_ = s.store.Delete(ctx, attemptsKey)
+	return nil
+}
+
+// Revoke аннулирует действующий код субъекта
+func (s *Store) Revoke(ctx context.Context, purpose, subject string) error {
+	if err := s.store.Delete(ctx, codeKeyPrefix+purpose+":"+subject); err != nil {
+		return err
+	}
+	return s.store.Delete(ctx, attemptsKeyPrefix+purpose+":"+subject)
+}
+
+// GenerateNumericCode генерирует случайный цифровой код заданной длины
+func GenerateNumericCode(digits int) (string, error) {
+	limit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(digits)), nil)
+	n, err := rand.Int(rand.Reader, limit)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", digits, n), nil
+}
+
+// GenerateLinkToken генерирует случайный токен для ссылок подтверждения (256 бит, base64url)
+func GenerateLinkToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+// ttlOf оставшееся время жизни сохраненной записи кода
+func ttlOf(value string) time.Duration {
+	var record codeRecord
+	if err := json.Unmarshal([]byte(value), &record); err != nil {
+		return 0
+	}
+	return time.Until(time.Unix(record.ExpiresAt, 0))
+}
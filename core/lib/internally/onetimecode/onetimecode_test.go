@@ -0,0 +1,185 @@
+package onetimecode
+
+import (
+	"authentication_service/core/lib/internally/kvstore"
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// stores возвращает хранилища, на которых работает Store: в памяти и Redis (miniredis)
+func stores(t *testing.T) map[string]kvstore.Store {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	return map[string]kvstore.Store{
+		"memory": kvstore.NewMemoryStore(),
+		"redis":  kvstore.NewRedisStore(client),
+	}
+}
+
+func TestVerify(t *testing.T) {
+	params := Params{TTL: time.Minute, MaxAttempts: 3}
+
+	tests := []struct {
+		name   string
+		inputs []string
+		want   []error
+	}{
+		{"correct", []string{"123456"}, []error{nil}},
+		{"single use", []string{"123456", "123456"}, []error{nil, ErrCodeNotFound}},
+		{"wrong then correct", []string{"000000", "123456"}, []error{ErrCodeInvalid, nil}},
+		{
+			"attempts exhausted",
+			[]string{"000000", "111111", "222222", "123456"},
+			[]error{ErrCodeInvalid, ErrCodeInvalid, ErrTooManyAttempts, ErrCodeNotFound},
+		},
+	}
+
+	ctx := context.Background()
+	for name, store := range stores(t) {
+		for _, tt := range tests {
+			t.Run(name+"/"+tt.name, func(t *testing.T) {
+				codes := NewStore(store)
+				subject := name + tt.name
+				if err := codes.Issue(ctx, "test", subject, "123456", params); err != nil {
+					t.Fatalf("Issue() error = %v", err)
+				}
+				for i, input := range tt.inputs {
+					if err := codes.Verify(ctx, "test", subject, input); !errors.Is(err, tt.want[i]) {
+						t.Fatalf("Verify #%d (%q) error = %v, want %v", i+1, input, err, tt.want[i])
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestVerifyMissing(t *testing.T) {
+	codes := NewStore(kvstore.NewMemoryStore())
+	if err := codes.Verify(context.Background(), "test", "nobody", "123456"); !errors.Is(err, ErrCodeNotFound) {
+		t.Fatalf("Verify() error = %v, want %v", err, ErrCodeNotFound)
+	}
+}
+
+func TestIssueResetsAttemptsAndReplacesCode(t *testing.T) {
+	ctx := context.Background()
+	codes := NewStore(kvstore.NewMemoryStore())
+	params := Params{TTL: time.Minute, MaxAttempts: 2}
+
+	if err := codes.Issue(ctx, "test", "user", "111111", params); err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	if err := codes.Verify(ctx, "test", "user", "000000"); !errors.Is(err, ErrCodeInvalid) {
+		t.Fatalf("Verify(wrong) error = %v, want %v", err, ErrCodeInvalid)
+	}
+	if err := codes.Issue(ctx, "test", "user", "222222", params); err != nil {
+		t.Fatalf("second Issue() error = %v", err)
+	}
+	if err := codes.Verify(ctx, "test", "user", "111111"); !errors.Is(err, ErrCodeInvalid) {
+		t.Fatalf("Verify(old code) error = %v, want %v", err, ErrCodeInvalid)
+	}
+	if err := codes.Verify(ctx, "test", "user", "222222"); err != nil {
+		t.Fatalf("Verify(new code) error = %v", err)
+	}
+}
+
+func TestIssueResendInterval(t *testing.T) {
+	ctx := context.Background()
+	codes := NewStore(kvstore.NewMemoryStore())
+	params := Params{TTL: time.Minute, ResendInterval: time.Minute}
+
+	if err := codes.Issue(ctx, "test", "user", "111111", params); err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	if err := codes.Issue(ctx, "test", "user", "222222", params); !errors.Is(err, ErrResendTooSoon) {
+		t.Fatalf("second Issue() error = %v, want %v", err, ErrResendTooSoon)
+	}
+}
+
+func TestRevoke(t *testing.T) {
+	ctx := context.Background()
+	codes := NewStore(kvstore.NewMemoryStore())
+
+	if err := codes.Issue(ctx, "test", "user", "111111", Params{TTL: time.Minute}); err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	if err := codes.Revoke(ctx, "test", "user"); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+	if err := codes.Verify(ctx, "test", "user", "111111"); !errors.Is(err, ErrCodeNotFound) {
+		t.Fatalf("Verify() error = %v, want %v", err, ErrCodeNotFound)
+	}
+}
+
+// Параллельная проверка верного кода гасит его ровно один раз
+func TestVerifyConcurrentSingleUse(t *testing.T) {
+	ctx := context.Background()
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			codes := NewStore(store)
+			if err := codes.Issue(ctx, "test", "user", "123456", Params{TTL: time.Minute, MaxAttempts: 100}); err != nil {
+				t.Fatalf("Issue() error = %v", err)
+			}
+
+			var wg sync.WaitGroup
+			var success atomic.Int32
+			for i := 0; i < 20; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					if err := codes.Verify(ctx, "test", "user", "123456"); err == nil {
+						success.Add(1)
+					}
+				}()
+			}
+			wg.Wait()
+
+			if got := success.Load(); got != 1 {
+				t.Fatalf("code accepted %d times, want once", got)
+			}
+		})
+	}
+}
+
+// Параллельный перебор не получает больше попыток, чем MaxAttempts
+func TestVerifyConcurrentAttemptLimit(t *testing.T) {
+	ctx := context.Background()
+	const maxAttempts = 5
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			codes := NewStore(store)
+			if err := codes.Issue(ctx, "test", "user", "123456", Params{TTL: time.Minute, MaxAttempts: maxAttempts}); err != nil {
+				t.Fatalf("Issue() error = %v", err)
+			}
+
+			var wg sync.WaitGroup
+			var invalid atomic.Int32
+			for i := 0; i < 50; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					if err := codes.Verify(ctx, "test", "user", "000000"); errors.Is(err, ErrCodeInvalid) {
+						invalid.Add(1)
+					}
+				}()
+			}
+			wg.Wait()
+
+			if got := invalid.Load(); got > maxAttempts-1 {
+				t.Fatalf("%d guesses compared, want at most %d", got, maxAttempts-1)
+			}
+			if err := codes.Verify(ctx, "test", "user", "123456"); err == nil {
+				t.Fatal("code is still valid after attempts are exhausted")
+			}
+		})
+	}
+}
@@ -24,39 +24,154 @@ var File_service_AuthService_proto protoreflect.FileDescriptor
 var file_service_AuthService_proto_rawDesc = []byte{
 	0x0a, 0x19, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2f, 0x41, 0x75, 0x74, 0x68, 0x53, 0x65,
 	0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x03, 0x6d, 0x73, 0x67,
-	0x1a, 0x1a, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2f, 0x49, 0x73, 0x73, 0x75, 0x65,
-	0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1c, 0x6d, 0x65,
-	0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2f, 0x52, 0x65, 0x66, 0x72, 0x65, 0x73, 0x68, 0x54, 0x6f,
-	0x6b, 0x65, 0x6e, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x32, 0x97, 0x01, 0x0a, 0x0b, 0x41,
-	0x75, 0x74, 0x68, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x40, 0x0a, 0x0b, 0x49, 0x73,
-	0x73, 0x75, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x12, 0x17, 0x2e, 0x6d, 0x73, 0x67, 0x2e,
-	0x49, 0x73, 0x73, 0x75, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x1a, 0x18, 0x2e, 0x6d, 0x73, 0x67, 0x2e, 0x49, 0x73, 0x73, 0x75, 0x65, 0x54, 0x6f,
-	0x6b, 0x65, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x46, 0x0a, 0x0d,
-	0x52, 0x65, 0x66, 0x72, 0x65, 0x73, 0x68, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x12, 0x19, 0x2e,
-	0x6d, 0x73, 0x67, 0x2e, 0x52, 0x65, 0x66, 0x72, 0x65, 0x73, 0x68, 0x54, 0x6f, 0x6b, 0x65, 0x6e,
-	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x6d, 0x73, 0x67, 0x2e, 0x52,
-	0x65, 0x66, 0x72, 0x65, 0x73, 0x68, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70,
-	0x6f, 0x6e, 0x73, 0x65, 0x42, 0x12, 0x5a, 0x10, 0x2e, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x3b,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x6f, 0x62, 0x6a, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x1a, 0x1c, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2f, 0x49, 0x6d, 0x70, 0x65, 0x72,
+	0x73, 0x6f, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1e,
+	0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2f, 0x49, 0x6e, 0x74, 0x72, 0x6f, 0x73, 0x70,
+	0x65, 0x63, 0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1a,
+	0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2f, 0x49, 0x73, 0x73, 0x75, 0x65, 0x54, 0x6f,
+	0x6b, 0x65, 0x6e, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x12, 0x6d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x73, 0x2f, 0x4d, 0x46, 0x41, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1a,
+	0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2f, 0x4f, 0x41, 0x75, 0x74, 0x68, 0x43, 0x6c,
+	0x69, 0x65, 0x6e, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1c, 0x6d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x73, 0x2f, 0x52, 0x65, 0x66, 0x72, 0x65, 0x73, 0x68, 0x54, 0x6f, 0x6b, 0x65,
+	0x6e, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1b, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67,
+	0x65, 0x73, 0x2f, 0x52, 0x65, 0x76, 0x6f, 0x6b, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x17, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2f,
+	0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x15,
+	0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2f, 0x53, 0x74, 0x65, 0x70, 0x55, 0x70, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x32, 0x86, 0x08, 0x0a, 0x0b, 0x41, 0x75, 0x74, 0x68, 0x53, 0x65,
+	0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x40, 0x0a, 0x0b, 0x49, 0x73, 0x73, 0x75, 0x65, 0x54, 0x6f,
+	0x6b, 0x65, 0x6e, 0x73, 0x12, 0x17, 0x2e, 0x6d, 0x73, 0x67, 0x2e, 0x49, 0x73, 0x73, 0x75, 0x65,
+	0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e,
+	0x6d, 0x73, 0x67, 0x2e, 0x49, 0x73, 0x73, 0x75, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x46, 0x0a, 0x0d, 0x49, 0x73, 0x73, 0x75, 0x65,
+	0x4d, 0x46, 0x41, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x19, 0x2e, 0x6d, 0x73, 0x67, 0x2e, 0x49,
+	0x73, 0x73, 0x75, 0x65, 0x4d, 0x46, 0x41, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x6d, 0x73, 0x67, 0x2e, 0x49, 0x73, 0x73, 0x75, 0x65, 0x4d,
+	0x46, 0x41, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x4f, 0x0a, 0x10, 0x49, 0x73, 0x73, 0x75, 0x65, 0x53, 0x74, 0x65, 0x70, 0x55, 0x70, 0x54, 0x6f,
+	0x6b, 0x65, 0x6e, 0x12, 0x1c, 0x2e, 0x6d, 0x73, 0x67, 0x2e, 0x49, 0x73, 0x73, 0x75, 0x65, 0x53,
+	0x74, 0x65, 0x70, 0x55, 0x70, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x1d, 0x2e, 0x6d, 0x73, 0x67, 0x2e, 0x49, 0x73, 0x73, 0x75, 0x65, 0x53, 0x74, 0x65,
+	0x70, 0x55, 0x70, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x4c, 0x0a, 0x0f, 0x49, 0x6d, 0x70, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x61, 0x74, 0x65, 0x55,
+	0x73, 0x65, 0x72, 0x12, 0x1b, 0x2e, 0x6d, 0x73, 0x67, 0x2e, 0x49, 0x6d, 0x70, 0x65, 0x72, 0x73,
+	0x6f, 0x6e, 0x61, 0x74, 0x65, 0x55, 0x73, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x1c, 0x2e, 0x6d, 0x73, 0x67, 0x2e, 0x49, 0x6d, 0x70, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x61,
+	0x74, 0x65, 0x55, 0x73, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4f,
+	0x0a, 0x10, 0x49, 0x73, 0x73, 0x75, 0x65, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x54, 0x6f, 0x6b,
+	0x65, 0x6e, 0x12, 0x1c, 0x2e, 0x6d, 0x73, 0x67, 0x2e, 0x49, 0x73, 0x73, 0x75, 0x65, 0x43, 0x6c,
+	0x69, 0x65, 0x6e, 0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x1d, 0x2e, 0x6d, 0x73, 0x67, 0x2e, 0x49, 0x73, 0x73, 0x75, 0x65, 0x43, 0x6c, 0x69, 0x65,
+	0x6e, 0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x46, 0x0a, 0x0d, 0x52, 0x65, 0x66, 0x72, 0x65, 0x73, 0x68, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x73,
+	0x12, 0x19, 0x2e, 0x6d, 0x73, 0x67, 0x2e, 0x52, 0x65, 0x66, 0x72, 0x65, 0x73, 0x68, 0x54, 0x6f,
+	0x6b, 0x65, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x6d, 0x73,
+	0x67, 0x2e, 0x52, 0x65, 0x66, 0x72, 0x65, 0x73, 0x68, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x40, 0x0a, 0x0b, 0x52, 0x65, 0x76, 0x6f, 0x6b,
+	0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x17, 0x2e, 0x6d, 0x73, 0x67, 0x2e, 0x52, 0x65, 0x76,
+	0x6f, 0x6b, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x18, 0x2e, 0x6d, 0x73, 0x67, 0x2e, 0x52, 0x65, 0x76, 0x6f, 0x6b, 0x65, 0x54, 0x6f, 0x6b, 0x65,
+	0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x31, 0x0a, 0x06, 0x4c, 0x6f, 0x67,
+	0x6f, 0x75, 0x74, 0x12, 0x12, 0x2e, 0x6d, 0x73, 0x67, 0x2e, 0x4c, 0x6f, 0x67, 0x6f, 0x75, 0x74,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x6d, 0x73, 0x67, 0x2e, 0x4c, 0x6f,
+	0x67, 0x6f, 0x75, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3a, 0x0a, 0x09,
+	0x4c, 0x6f, 0x67, 0x6f, 0x75, 0x74, 0x41, 0x6c, 0x6c, 0x12, 0x15, 0x2e, 0x6d, 0x73, 0x67, 0x2e,
+	0x4c, 0x6f, 0x67, 0x6f, 0x75, 0x74, 0x41, 0x6c, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x16, 0x2e, 0x6d, 0x73, 0x67, 0x2e, 0x4c, 0x6f, 0x67, 0x6f, 0x75, 0x74, 0x41, 0x6c, 0x6c,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4f, 0x0a, 0x10, 0x52, 0x65, 0x76, 0x6f,
+	0x6b, 0x65, 0x55, 0x73, 0x65, 0x72, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x12, 0x1c, 0x2e, 0x6d,
+	0x73, 0x67, 0x2e, 0x52, 0x65, 0x76, 0x6f, 0x6b, 0x65, 0x55, 0x73, 0x65, 0x72, 0x54, 0x6f, 0x6b,
+	0x65, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x6d, 0x73, 0x67,
+	0x2e, 0x52, 0x65, 0x76, 0x6f, 0x6b, 0x65, 0x55, 0x73, 0x65, 0x72, 0x54, 0x6f, 0x6b, 0x65, 0x6e,
+	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4c, 0x0a, 0x0f, 0x49, 0x6e, 0x74,
+	0x72, 0x6f, 0x73, 0x70, 0x65, 0x63, 0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x1b, 0x2e, 0x6d,
+	0x73, 0x67, 0x2e, 0x49, 0x6e, 0x74, 0x72, 0x6f, 0x73, 0x70, 0x65, 0x63, 0x74, 0x54, 0x6f, 0x6b,
+	0x65, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x6d, 0x73, 0x67, 0x2e,
+	0x49, 0x6e, 0x74, 0x72, 0x6f, 0x73, 0x70, 0x65, 0x63, 0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x43, 0x0a, 0x0c, 0x43, 0x68, 0x65, 0x63, 0x6b,
+	0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x18, 0x2e, 0x6d, 0x73, 0x67, 0x2e, 0x43, 0x68,
+	0x65, 0x63, 0x6b, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x19, 0x2e, 0x6d, 0x73, 0x67, 0x2e, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x53, 0x65, 0x73,
+	0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x46, 0x0a, 0x0d,
+	0x52, 0x65, 0x76, 0x6f, 0x6b, 0x65, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x19, 0x2e,
+	0x6d, 0x73, 0x67, 0x2e, 0x52, 0x65, 0x76, 0x6f, 0x6b, 0x65, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x6d, 0x73, 0x67, 0x2e, 0x52,
+	0x65, 0x76, 0x6f, 0x6b, 0x65, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x58, 0x0a, 0x13, 0x52, 0x65, 0x76, 0x6f, 0x6b, 0x65, 0x4f, 0x74,
+	0x68, 0x65, 0x72, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x1f, 0x2e, 0x6d, 0x73,
+	0x67, 0x2e, 0x52, 0x65, 0x76, 0x6f, 0x6b, 0x65, 0x4f, 0x74, 0x68, 0x65, 0x72, 0x53, 0x65, 0x73,
+	0x73, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x20, 0x2e, 0x6d,
+	0x73, 0x67, 0x2e, 0x52, 0x65, 0x76, 0x6f, 0x6b, 0x65, 0x4f, 0x74, 0x68, 0x65, 0x72, 0x53, 0x65,
+	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x12,
+	0x5a, 0x10, 0x2e, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x3b, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x6f,
+	0x62, 0x6a, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var file_service_AuthService_proto_goTypes = []any{
-	(*IssueTokensRequest)(nil),    // 0: msg.IssueTokensRequest
-	(*RefreshTokensRequest)(nil),  // 1: msg.RefreshTokensRequest
-	(*IssueTokensResponse)(nil),   // 2: msg.IssueTokensResponse
-	(*RefreshTokensResponse)(nil), // 3: msg.RefreshTokensResponse
+	(*IssueTokensRequest)(nil),          // 0: msg.IssueTokensRequest
+	(*IssueMFATokenRequest)(nil),        // 1: msg.IssueMFATokenRequest
+	(*IssueStepUpTokenRequest)(nil),     // 2: msg.IssueStepUpTokenRequest
+	(*ImpersonateUserRequest)(nil),      // 3: msg.ImpersonateUserRequest
+	(*IssueClientTokenRequest)(nil),     // 4: msg.IssueClientTokenRequest
+	(*RefreshTokensRequest)(nil),        // 5: msg.RefreshTokensRequest
+	(*RevokeTokenRequest)(nil),          // 6: msg.RevokeTokenRequest
+	(*LogoutRequest)(nil),               // 7: msg.LogoutRequest
+	(*LogoutAllRequest)(nil),            // 8: msg.LogoutAllRequest
+	(*RevokeUserTokensRequest)(nil),     // 9: msg.RevokeUserTokensRequest
+	(*IntrospectTokenRequest)(nil),      // 10: msg.IntrospectTokenRequest
+	(*CheckSessionRequest)(nil),         // 11: msg.CheckSessionRequest
+	(*RevokeSessionRequest)(nil),        // 12: msg.RevokeSessionRequest
+	(*RevokeOtherSessionsRequest)(nil),  // 13: msg.RevokeOtherSessionsRequest
+	(*IssueTokensResponse)(nil),         // 14: msg.IssueTokensResponse
+	(*IssueMFATokenResponse)(nil),       // 15: msg.IssueMFATokenResponse
+	(*IssueStepUpTokenResponse)(nil),    // 16: msg.IssueStepUpTokenResponse
+	(*ImpersonateUserResponse)(nil),     // 17: msg.ImpersonateUserResponse
+	(*IssueClientTokenResponse)(nil),    // 18: msg.IssueClientTokenResponse
+	(*RefreshTokensResponse)(nil),       // 19: msg.RefreshTokensResponse
+	(*RevokeTokenResponse)(nil),         // 20: msg.RevokeTokenResponse
+	(*LogoutResponse)(nil),              // 21: msg.LogoutResponse
+	(*LogoutAllResponse)(nil),           // 22: msg.LogoutAllResponse
+	(*RevokeUserTokensResponse)(nil),    // 23: msg.RevokeUserTokensResponse
+	(*IntrospectTokenResponse)(nil),     // 24: msg.IntrospectTokenResponse
+	(*CheckSessionResponse)(nil),        // 25: msg.CheckSessionResponse
+	(*RevokeSessionResponse)(nil),       // 26: msg.RevokeSessionResponse
+	(*RevokeOtherSessionsResponse)(nil), // 27: msg.RevokeOtherSessionsResponse
 }
 var file_service_AuthService_proto_depIdxs = []int32{
-	0, // 0: msg.AuthService.IssueTokens:input_type -> msg.IssueTokensRequest
-	1, // 1: msg.AuthService.RefreshTokens:input_type -> msg.RefreshTokensRequest
-	2, // 2: msg.AuthService.IssueTokens:output_type -> msg.IssueTokensResponse
-	3, // 3: msg.AuthService.RefreshTokens:output_type -> msg.RefreshTokensResponse
-	2, // [2:4] is the sub-list for method output_type
-	0, // [0:2] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	0,  // 0: msg.AuthService.IssueTokens:input_type -> msg.IssueTokensRequest
+	1,  // 1: msg.AuthService.IssueMFAToken:input_type -> msg.IssueMFATokenRequest
+	2,  // 2: msg.AuthService.IssueStepUpToken:input_type -> msg.IssueStepUpTokenRequest
+	3,  // 3: msg.AuthService.ImpersonateUser:input_type -> msg.ImpersonateUserRequest
+	4,  // 4: msg.AuthService.IssueClientToken:input_type -> msg.IssueClientTokenRequest
+	5,  // 5: msg.AuthService.RefreshTokens:input_type -> msg.RefreshTokensRequest
+	6,  // 6: msg.AuthService.RevokeToken:input_type -> msg.RevokeTokenRequest
+	7,  // 7: msg.AuthService.Logout:input_type -> msg.LogoutRequest
+	8,  // 8: msg.AuthService.LogoutAll:input_type -> msg.LogoutAllRequest
+	9,  // 9: msg.AuthService.RevokeUserTokens:input_type -> msg.RevokeUserTokensRequest
+	10, // 10: msg.AuthService.IntrospectToken:input_type -> msg.IntrospectTokenRequest
+	11, // 11: msg.AuthService.CheckSession:input_type -> msg.CheckSessionRequest
+	12, // 12: msg.AuthService.RevokeSession:input_type -> msg.RevokeSessionRequest
+	13, // 13: msg.AuthService.RevokeOtherSessions:input_type -> msg.RevokeOtherSessionsRequest
+	14, // 14: msg.AuthService.IssueTokens:output_type -> msg.IssueTokensResponse
+	15, // 15: msg.AuthService.IssueMFAToken:output_type -> msg.IssueMFATokenResponse
+	16, // 16: msg.AuthService.IssueStepUpToken:output_type -> msg.IssueStepUpTokenResponse
+	17, // 17: msg.AuthService.ImpersonateUser:output_type -> msg.ImpersonateUserResponse
+	18, // 18: msg.AuthService.IssueClientToken:output_type -> msg.IssueClientTokenResponse
+	19, // 19: msg.AuthService.RefreshTokens:output_type -> msg.RefreshTokensResponse
+	20, // 20: msg.AuthService.RevokeToken:output_type -> msg.RevokeTokenResponse
+	21, // 21: msg.AuthService.Logout:output_type -> msg.LogoutResponse
+	22, // 22: msg.AuthService.LogoutAll:output_type -> msg.LogoutAllResponse
+	23, // 23: msg.AuthService.RevokeUserTokens:output_type -> msg.RevokeUserTokensResponse
+	24, // 24: msg.AuthService.IntrospectToken:output_type -> msg.IntrospectTokenResponse
+	25, // 25: msg.AuthService.CheckSession:output_type -> msg.CheckSessionResponse
+	26, // 26: msg.AuthService.RevokeSession:output_type -> msg.RevokeSessionResponse
+	27, // 27: msg.AuthService.RevokeOtherSessions:output_type -> msg.RevokeOtherSessionsResponse
+	14, // [14:28] is the sub-list for method output_type
+	0,  // [0:14] is the sub-list for method input_type
+	0,  // [0:0] is the sub-list for extension type_name
+	0,  // [0:0] is the sub-list for extension extendee
+	0,  // [0:0] is the sub-list for field type_name
 }
 
 func init() { file_service_AuthService_proto_init() }
@@ -64,8 +179,15 @@ func file_service_AuthService_proto_init() {
 	if File_service_AuthService_proto != nil {
 		return
 	}
+	file_messages_Impersonation_proto_init()
+	file_messages_IntrospectToken_proto_init()
 	file_messages_IssueTokens_proto_init()
+	file_messages_MFA_proto_init()
+	file_messages_OAuthClient_proto_init()
 	file_messages_RefreshTokens_proto_init()
+	file_messages_RevokeTokens_proto_init()
+	file_messages_Sessions_proto_init()
+	file_messages_StepUp_proto_init()
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
@@ -0,0 +1,236 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        v5.27.1
+// source: messages/MFA.proto
+
+package protoobj
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Выдача промежуточного токена входа: первый фактор проверен, ожидается второй
+type IssueMFATokenRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	UserId   string   `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	ClientIp string   `protobuf:"bytes,2,opt,name=client_ip,json=clientIp,proto3" json:"client_ip,omitempty"`
+	Amr      []string `protobuf:"bytes,3,rep,name=amr,proto3" json:"amr,omitempty"` // методы первого фактора, переносятся в токены после ввода второго
+}
+
+func (x *IssueMFATokenRequest) Reset() {
+	*x = IssueMFATokenRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_messages_MFA_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *IssueMFATokenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IssueMFATokenRequest) ProtoMessage() {}
+
+func (x *IssueMFATokenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_messages_MFA_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IssueMFATokenRequest.ProtoReflect.Descriptor instead.
+func (*IssueMFATokenRequest) Descriptor() ([]byte, []int) {
+	return file_messages_MFA_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *IssueMFATokenRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *IssueMFATokenRequest) GetClientIp() string {
+	if x != nil {
+		return x.ClientIp
+	}
+	return ""
+}
+
+func (x *IssueMFATokenRequest) GetAmr() []string {
+	if x != nil {
+		return x.Amr
+	}
+	return nil
+}
+
+type IssueMFATokenResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	MfaToken  string `protobuf:"bytes,1,opt,name=mfa_token,json=mfaToken,proto3" json:"mfa_token,omitempty"`
+	ExpiresIn int64  `protobuf:"varint,2,opt,name=expires_in,json=expiresIn,proto3" json:"expires_in,omitempty"` // время жизни токена в секундах
+}
+
+func (x *IssueMFATokenResponse) Reset() {
+	*x = IssueMFATokenResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_messages_MFA_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *IssueMFATokenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IssueMFATokenResponse) ProtoMessage() {}
+
+func (x *IssueMFATokenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_messages_MFA_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IssueMFATokenResponse.ProtoReflect.Descriptor instead.
+func (*IssueMFATokenResponse) Descriptor() ([]byte, []int) {
+	return file_messages_MFA_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *IssueMFATokenResponse) GetMfaToken() string {
+	if x != nil {
+		return x.MfaToken
+	}
+	return ""
+}
+
+func (x *IssueMFATokenResponse) GetExpiresIn() int64 {
+	if x != nil {
+		return x.ExpiresIn
+	}
+	return 0
+}
+
+var File_messages_MFA_proto protoreflect.FileDescriptor
+
+var file_messages_MFA_proto_rawDesc = []byte{
+	0x0a, 0x12, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x2f, 0x4d, 0x46, 0x41, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x12, 0x03, 0x6d, 0x73, 0x67, 0x22, 0x5e, 0x0a, 0x14, 0x49, 0x73, 0x73,
+	0x75, 0x65, 0x4d, 0x46, 0x41, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x17, 0x0a, 0x07, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x75, 0x73, 0x65, 0x72, 0x49, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x63, 0x6c,
+	0x69, 0x65, 0x6e, 0x74, 0x5f, 0x69, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x63,
+	0x6c, 0x69, 0x65, 0x6e, 0x74, 0x49, 0x70, 0x12, 0x10, 0x0a, 0x03, 0x61, 0x6d, 0x72, 0x18, 0x03,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x03, 0x61, 0x6d, 0x72, 0x22, 0x53, 0x0a, 0x15, 0x49, 0x73, 0x73,
+	0x75, 0x65, 0x4d, 0x46, 0x41, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x6d, 0x66, 0x61, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6d, 0x66, 0x61, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12,
+	0x1d, 0x0a, 0x0a, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x5f, 0x69, 0x6e, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x09, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x49, 0x6e, 0x42, 0x12,
+	0x5a, 0x10, 0x2e, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x3b, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x6f,
+	0x62, 0x6a, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_messages_MFA_proto_rawDescOnce sync.Once
+	file_messages_MFA_proto_rawDescData = file_messages_MFA_proto_rawDesc
+)
+
+func file_messages_MFA_proto_rawDescGZIP() []byte {
+	file_messages_MFA_proto_rawDescOnce.Do(func() {
+		file_messages_MFA_proto_rawDescData = protoimpl.X.CompressGZIP(file_messages_MFA_proto_rawDescData)
+	})
+	return file_messages_MFA_proto_rawDescData
+}
+
+var file_messages_MFA_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_messages_MFA_proto_goTypes = []any{
+	(*IssueMFATokenRequest)(nil),  // 0: msg.IssueMFATokenRequest
+	(*IssueMFATokenResponse)(nil), // 1: msg.IssueMFATokenResponse
+}
+var file_messages_MFA_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_messages_MFA_proto_init() }
+func file_messages_MFA_proto_init() {
+	if File_messages_MFA_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_messages_MFA_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*IssueMFATokenRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_messages_MFA_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*IssueMFATokenResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_messages_MFA_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_messages_MFA_proto_goTypes,
+		DependencyIndexes: file_messages_MFA_proto_depIdxs,
+		MessageInfos:      file_messages_MFA_proto_msgTypes,
+	}.Build()
+	File_messages_MFA_proto = out.File
+	file_messages_MFA_proto_rawDesc = nil
+	file_messages_MFA_proto_goTypes = nil
+	file_messages_MFA_proto_depIdxs = nil
+}
@@ -0,0 +1,19 @@
+package securecore
+
+import "github.com/golang-jwt/jwt/v5"
+
+// ActClaims возвращает claim act (RFC 8693, раздел 4.1): токен выдан для работы от имени пользователя (guid),
+// а действует субъект actorID
+func ActClaims(actorID string) jwt.MapClaims {
+	return jwt.MapClaims{"act": map[string]interface{}{"sub": actorID}}
+}
+
+// ClaimActor возвращает субъект claim act или пустую строку, если токен получен самим пользователем
+func ClaimActor(claims jwt.MapClaims) string {
+	act, ok := claims["act"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	actorID, _ := act["sub"].(string)
+	return actorID
+}
@@ -0,0 +1,47 @@
+package securecore
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Методы аутентификации (claim amr, RFC 8176)
+const (
+	AMRPassword    = "pwd"   // пароль
+	AMROTP         = "otp"   // код TOTP или код восстановления
+	AMRMultiFactor = "mfa"   // проверено несколько факторов
+	AMRHardwareKey = "hwk"   // ключ доступа WebAuthn
+	AMREmail       = "email" // одноразовая ссылка или код из письма
+	AMRFederated   = "fed"   // внешний провайдер (OpenID Connect, Telegram)
+)
+
+// AuthClaims возвращает claims auth_time и amr: время и методы аутентификации пользователя
+// (OpenID Connect Core, раздел 2). Нулевое время и пустой список не выставляются.
+func AuthClaims(authTime time.Time, amr []string) jwt.MapClaims {
+	claims := jwt.MapClaims{}
+	if !authTime.IsZero() {
+		claims["auth_time"] = authTime.Unix()
+	}
+	if len(amr) > 0 {
+		claims["amr"] = amr
+	}
+	return claims
+}
+
+// ClaimAMR извлекает из claims методы аутентификации
+func ClaimAMR(claims jwt.MapClaims) []string {
+	switch value := claims["amr"].(type) {
+	case []string:
+		return value
+	case []interface{}:
+		amr := make([]string, 0, len(value))
+		for _, item := range value {
+			if method, ok := item.(string); ok {
+				amr = append(amr, method)
+			}
+		}
+		return amr
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package securecore
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ClientAssertionType тип client_assertion: JWT, подписанный ключом клиента (RFC 7523, раздел 2.2)
+const ClientAssertionType = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+// максимальный срок действия client_assertion: короткий срок ограничивает хранение jti для защиты от повтора
+const maxClientAssertionLifetime = 5 * time.Minute
+
+// ErrInvalidClientAssertion client_assertion не прошел проверку
+var ErrInvalidClientAssertion = errors.New("invalid client assertion")
+
+// ClientAssertion проверенный client_assertion
+type ClientAssertion struct {
+	JTI       string
+	ExpiresAt time.Time
+}
+
+// ParseClientPublicKey разбирает публичный ключ клиента в формате PEM (PKIX).
+// Поддерживаются Ed25519, ECDSA P-256 и RSA не короче 2048 бит
+func ParseClientPublicKey(data string) (crypto.PublicKey, error) {
+	publicKey, err := parsePublicKeyPEM(data)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := clientKeyMethod(publicKey); err != nil {
+		return nil, err
+	}
+	return publicKey, nil
+}
+
+// VerifyClientAssertion проверяет client_assertion клиента (RFC 7523, раздел 3):
+// подпись зарегистрированным ключом, iss и sub равны clientID, aud содержит один из audiences,
+// срок действия не больше 5 минут и задан jti. Повторное использование jti проверяет вызывающая сторона
+func VerifyClientAssertion(assertion, clientID, publicKeyPEM string, audiences []string, leeway time.Duration, now time.Time) (*ClientAssertion, error) {
+	publicKey, err := ParseClientPublicKey(publicKeyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("client public key: %w", err)
+	}
+	method, err := clientKeyMethod(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := jwt.Parse(assertion, func(token *jwt.Token) (interface{}, error) {
+		return publicKey, nil
+	}, jwt.WithValidMethods([]string{method}), jwt.WithoutClaimsValidation())
+	if err != nil || !token.Valid {
+		return nil, ErrInvalidClientAssertion
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, ErrInvalidClientAssertion
+	}
+
+	if iss, _ := claims["iss"].(string); iss != clientID {
+		return nil, fmt.Errorf("%w: iss must be client_id", ErrInvalidClientAssertion)
+	}
+	if sub, _ := claims["sub"].(string); sub != clientID {
+		return nil, fmt.Errorf("%w: sub must be client_id", ErrInvalidClientAssertion)
+	}
+
+	audienceOK := false
+	for _, audience := range audiences {
+		if audience != "" && claimAudienceContains(claims["aud"], audience) {
+			audienceOK = true
+			break
+		}
+	}
+	if !audienceOK {
+		return nil, fmt.Errorf("%w: invalid audience", ErrInvalidClientAssertion)
+	}
+
+	exp, ok := ClaimTime(claims, "exp")
+	if !ok || now.After(exp.Add(leeway)) || exp.Sub(now) > maxClientAssertionLifetime+leeway {
+		return nil, fmt.Errorf("%w: exp is missing, expired or too far in the future", ErrInvalidClientAssertion)
+	}
+	if nbf, ok := ClaimTime(claims, "nbf"); ok && now.Add(leeway).Before(nbf) {
+		return nil, fmt.Errorf("%w: not valid yet", ErrInvalidClientAssertion)
+	}
+
+	jti, _ := claims["jti"].(string)
+	if jti == "" {
+		return nil, fmt.Errorf("%w: jti is required", ErrInvalidClientAssertion)
+	}
+
+	return &ClientAssertion{JTI: jti, ExpiresAt: exp}, nil
+}
+
+// clientKeyMethod возвращает алгоритм подписи, соответствующий типу ключа клиента
+func clientKeyMethod(publicKey crypto.PublicKey) (string, error) {
+	switch key := publicKey.(type) {
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA.Alg(), nil
+	case *ecdsa.PublicKey:
+		if key.Curve == elliptic.P256() {
+			return jwt.SigningMethodES256.Alg(), nil
+		}
+	case *rsa.PublicKey:
+		if key.N.BitLen() < minRSAKeyBits {
+			return "", fmt.Errorf("rsa key must be at least %d bits", minRSAKeyBits)
+		}
+		return jwt.SigningMethodRS256.Alg(), nil
+	}
+	return "", errors.New("unsupported client key type: Ed25519, ECDSA P-256 or RSA is required")
+}
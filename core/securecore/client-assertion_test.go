@@ -0,0 +1,132 @@
+package securecore
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testAssertionClient   = "service-client"
+	testAssertionAudience = "https://auth.example.com/oauth/token"
+)
+
+func TestVerifyClientAssertion(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	edKey, ecKey := testKeys(t)
+	_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("ed25519.GenerateKey: %v", err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey: %v", err)
+	}
+
+	claims := func(modify func(jwt.MapClaims)) jwt.MapClaims {
+		c := jwt.MapClaims{
+			"iss": testAssertionClient,
+			"sub": testAssertionClient,
+			"aud": testAssertionAudience,
+			"exp": now.Add(time.Minute).Unix(),
+			"iat": now.Unix(),
+			"jti": "assertion-1",
+		}
+		if modify != nil {
+			modify(c)
+		}
+		return c
+	}
+	sign := func(method jwt.SigningMethod, key interface{}, c jwt.MapClaims) string {
+		token, err := jwt.NewWithClaims(method, c).SignedString(key)
+		if err != nil {
+			t.Fatalf("SignedString: %v", err)
+		}
+		return token
+	}
+
+	edPEM := publicKeyPEM(t, edKey)
+	tests := []struct {
+		name      string
+		assertion string
+		keyPEM    string
+		wantErr   bool
+	}{
+		{"ed25519", sign(jwt.SigningMethodEdDSA, edKey, claims(nil)), edPEM, false},
+		{"ecdsa p-256", sign(jwt.SigningMethodES256, ecKey, claims(nil)), publicKeyPEM(t, ecKey), false},
+		{"rsa", sign(jwt.SigningMethodRS256, rsaKey, claims(nil)), publicKeyPEM(t, rsaKey), false},
+		{"audience in list", sign(jwt.SigningMethodEdDSA, edKey, claims(func(c jwt.MapClaims) {
+			c["aud"] = []string{"https://other.example.com", testAssertionAudience}
+		})), edPEM, false},
+		{"exp within leeway", sign(jwt.SigningMethodEdDSA, edKey, claims(func(c jwt.MapClaims) { c["exp"] = now.Add(-10 * time.Second).Unix() })), edPEM, false},
+		{"signed by other key", sign(jwt.SigningMethodEdDSA, otherKey, claims(nil)), edPEM, true},
+		{"algorithm does not match key", sign(jwt.SigningMethodHS256, []byte(edPEM), claims(nil)), edPEM, true},
+		{"iss is not client", sign(jwt.SigningMethodEdDSA, edKey, claims(func(c jwt.MapClaims) { c["iss"] = "other-client" })), edPEM, true},
+		{"sub is not client", sign(jwt.SigningMethodEdDSA, edKey, claims(func(c jwt.MapClaims) { c["sub"] = "user" })), edPEM, true},
+		{"foreign audience", sign(jwt.SigningMethodEdDSA, edKey, claims(func(c jwt.MapClaims) { c["aud"] = "https://other.example.com" })), edPEM, true},
+		{"expired", sign(jwt.SigningMethodEdDSA, edKey, claims(func(c jwt.MapClaims) { c["exp"] = now.Add(-time.Minute).Unix() })), edPEM, true},
+		{"missing exp", sign(jwt.SigningMethodEdDSA, edKey, claims(func(c jwt.MapClaims) { delete(c, "exp") })), edPEM, true},
+		{"lifetime too long", sign(jwt.SigningMethodEdDSA, edKey, claims(func(c jwt.MapClaims) { c["exp"] = now.Add(time.Hour).Unix() })), edPEM, true},
+		{"not valid yet", sign(jwt.SigningMethodEdDSA, edKey, claims(func(c jwt.MapClaims) { c["nbf"] = now.Add(time.Minute).Unix() })), edPEM, true},
+		{"missing jti", sign(jwt.SigningMethodEdDSA, edKey, claims(func(c jwt.MapClaims) { delete(c, "jti") })), edPEM, true},
+		{"garbage", "not-a-jwt", edPEM, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := VerifyClientAssertion(tt.assertion, testAssertionClient, tt.keyPEM, []string{"", testAssertionAudience}, 30*time.Second, now)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidClientAssertion) {
+					t.Fatalf("VerifyClientAssertion() error = %v, want %v", err, ErrInvalidClientAssertion)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyClientAssertion() error = %v", err)
+			}
+			if got.JTI != "assertion-1" {
+				t.Fatalf("VerifyClientAssertion() jti = %q, want %q", got.JTI, "assertion-1")
+			}
+		})
+	}
+}
+
+func TestParseClientPublicKey(t *testing.T) {
+	edKey, ecKey := testKeys(t)
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatalf("ecdsa.GenerateKey: %v", err)
+	}
+	weakRSA, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		pem     string
+		wantErr bool
+	}{
+		{"ed25519", publicKeyPEM(t, edKey), false},
+		{"ecdsa p-256", publicKeyPEM(t, ecKey), false},
+		{"ecdsa p-384", publicKeyPEM(t, p384), true},
+		{"rsa 1024", publicKeyPEM(t, weakRSA), true},
+		{"private key", privateKeyPEM(t, edKey), true},
+		{"not pem", "public key", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseClientPublicKey(tt.pem); (err != nil) != tt.wantErr {
+				t.Fatalf("ParseClientPublicKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package securecore
+
+import (
+	"authentication_service/core/configcore"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// Режимы привязки токена к IP-адресу клиента
+const (
+	IPBindingStrict = "strict" // точное совпадение адреса
+	IPBindingSubnet = "subnet" // совпадение подсети (/24 для IPv4, /64 для IPv6)
+	IPBindingASN    = "asn"    // совпадение автономной системы (провайдера)
+	IPBindingOff    = "off"    // без привязки
+)
+
+// Действия при несовпадении IP-адреса
+const (
+	IPMismatchBlock  = "block"   // отклонить запрос
+	IPMismatchStepUp = "step_up" // потребовать повторную аутентификацию
+	IPMismatchNotify = "notify"  // пропустить запрос и уведомить пользователя
+)
+
+const (
+	defaultSubnetV4Bits = 24
+	defaultSubnetV6Bits = 64
+)
+
+// ErrIPMismatch IP-адрес клиента не соответствует адресу, к которому привязан токен
+var ErrIPMismatch = errors.New("client IP mismatch")
+
+// IPMatcher сравнивает адрес, к которому привязан токен, с текущим адресом клиента
+type IPMatcher interface {
+	Match(tokenIP, clientIP net.IP) bool
+}
+
+// IPBinding политика привязки токенов к IP-адресу клиента
+type IPBinding struct {
+	matcher IPMatcher // nil — привязка отключена
+	action  string
+}
+
+// NewIPBinding создает политику привязки из конфигурации.
+// По умолчанию используется точное совпадение с блокировкой запроса.
+func NewIPBinding(cfg configcore.IPBindingConfig) (*IPBinding, error) {
+	binding := &IPBinding{action: cfg.Action}
+	switch cfg.Action {
+	case "":
+		binding.action = IPMismatchBlock
+	case IPMismatchBlock, IPMismatchStepUp, IPMismatchNotify:
+	default:
+		return nil, fmt.Errorf("unsupported ip binding action %q", cfg.Action)
+	}
+
+	switch cfg.Mode {
+	case "", IPBindingStrict:
+		binding.matcher = strictIPMatcher{}
+	case IPBindingSubnet:
+		matcher := subnetIPMatcher{v4Bits: cfg.SubnetV4Bits, v6Bits: cfg.SubnetV6Bits}
+		if matcher.v4Bits == 0 {
+			matcher.v4Bits = defaultSubnetV4Bits
+		}
+		if matcher.v6Bits == 0 {
+			matcher.v6Bits = defaultSubnetV6Bits
+		}
+		if matcher.v4Bits < 0 || matcher.v4Bits > 32 || matcher.v6Bits < 0 || matcher.v6Bits > 128 {
+			return nil, errors.New("invalid ip binding subnet size")
+		}
+		binding.matcher = matcher
+	case IPBindingASN:
+		if cfg.ASNDatabase == "" {
+			return nil, errors.New("asn_database is required for asn ip binding")
+		}
+		reader, err := maxminddb.Open(cfg.ASNDatabase)
+		if err != nil {
+			return nil, fmt.Errorf("open asn database: %w", err)
+		}
+		binding.matcher = &asnIPMatcher{reader: reader}
+	case IPBindingOff:
+	default:
+		return nil, fmt.Errorf("unsupported ip binding mode %q", cfg.Mode)
+	}
+
+	return binding, nil
+}
+
+// Check сравнивает адреса и возвращает действие при несовпадении.
+// Пустая строка означает, что адреса соответствуют политике.
+func (b *IPBinding) Check(tokenIP, clientIP string) string {
+	if b == nil {
+		// Политика не задана — точное совпадение с блокировкой
+		if tokenIP == clientIP {
+			return ""
+		}
+		return IPMismatchBlock
+	}
+	if b.matcher == nil {
+		return ""
+	}
+
+	tokenAddr := net.ParseIP(tokenIP)
+	clientAddr := net.ParseIP(clientIP)
+	if tokenAddr == nil || clientAddr == nil {
+		if tokenIP == clientIP {
+			return ""
+		}
+		return b.action
+	}
+
+	if b.matcher.Match(tokenAddr, clientAddr) {
+		return ""
+	}
+	return b.action
+}
+
+// strictIPMatcher точное совпадение адреса
+type strictIPMatcher struct{}
+
+func (strictIPMatcher) Match(tokenIP, clientIP net.IP) bool {
+	return tokenIP.Equal(clientIP)
+}
+
+// subnetIPMatcher совпадение подсети
+type subnetIPMatcher struct {
+	v4Bits int
+	v6Bits int
+}
+
+func (m subnetIPMatcher) Match(tokenIP, clientIP net.IP) bool {
+	tokenV4, clientV4 := tokenIP.To4(), clientIP.To4()
+	switch {
+	case tokenV4 != nil && clientV4 != nil:
+		mask := net.CIDRMask(m.v4Bits, 32)
+		return tokenV4.Mask(mask).Equal(clientV4.Mask(mask))
+	case tokenV4 == nil && clientV4 == nil:
+		mask := net.CIDRMask(m.v6Bits, 128)
+		return tokenIP.Mask(mask).Equal(clientIP.Mask(mask))
+	default:
+		// Смена семейства адресов (IPv4 <-> IPv6) считается сменой сети
+		return false
+	}
+}
+
+// asnIPMatcher совпадение автономной системы по офлайн базе MaxMind DB (GeoLite2-ASN и совместимые)
+type asnIPMatcher struct {
+	reader *maxminddb.Reader
+}
+
+type asnRecord struct {
+	AutonomousSystemNumber uint `maxminddb:"autonomous_system_number"`
+}
+
+func (m *asnIPMatcher) Match(tokenIP, clientIP net.IP) bool {
+	if tokenIP.Equal(clientIP) {
+		return true
+	}
+
+	tokenASN, ok := m.lookup(tokenIP)
+	if !ok {
+		return false
+	}
+	clientASN, ok := m.lookup(clientIP)
+	if !ok {
+		return false
+	}
+	return tokenASN == clientASN
+}
+
+func (m *asnIPMatcher) lookup(ip net.IP) (uint, bool) {
+	var record asnRecord
+	if err := m.reader.Lookup(ip, &record); err != nil || record.AutonomousSystemNumber == 0 {
+		return 0, false
+	}
+	return record.AutonomousSystemNumber, true
+}
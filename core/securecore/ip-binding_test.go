@@ -0,0 +1,74 @@
+package securecore
+
+import (
+	"authentication_service/core/configcore"
+	"testing"
+)
+
+func TestIPBindingCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      configcore.IPBindingConfig
+		tokenIP  string
+		clientIP string
+		want     string
+	}{
+		{"strict same", configcore.IPBindingConfig{}, "203.0.113.7", "203.0.113.7", ""},
+		{"strict other", configcore.IPBindingConfig{}, "203.0.113.7", "203.0.113.8", IPMismatchBlock},
+		{"strict ipv6 same", configcore.IPBindingConfig{}, "2001:db8::1", "2001:db8:0:0::1", ""},
+		{"strict ipv6 other", configcore.IPBindingConfig{}, "2001:db8::1", "2001:db9::1", IPMismatchBlock},
+		{"subnet v4 same /24", configcore.IPBindingConfig{Mode: IPBindingSubnet}, "203.0.113.7", "203.0.113.200", ""},
+		{"subnet v4 other /24", configcore.IPBindingConfig{Mode: IPBindingSubnet}, "203.0.113.7", "203.0.114.7", IPMismatchBlock},
+		{"subnet v6 same /64", configcore.IPBindingConfig{Mode: IPBindingSubnet}, "2001:db8:1:2::1", "2001:db8:1:2:ffff::9", ""},
+		{"subnet v6 other /64", configcore.IPBindingConfig{Mode: IPBindingSubnet}, "2001:db8:1:2::1", "2001:db8:1:3::1", IPMismatchBlock},
+		{"subnet v4 and v6", configcore.IPBindingConfig{Mode: IPBindingSubnet}, "203.0.113.7", "2001:db8::1", IPMismatchBlock},
+		{"subnet custom size", configcore.IPBindingConfig{Mode: IPBindingSubnet, SubnetV4Bits: 16}, "203.0.113.7", "203.0.200.7", ""},
+		{"step up", configcore.IPBindingConfig{Action: IPMismatchStepUp}, "203.0.113.7", "203.0.113.8", IPMismatchStepUp},
+		{"notify", configcore.IPBindingConfig{Action: IPMismatchNotify}, "203.0.113.7", "203.0.113.8", IPMismatchNotify},
+		{"off", configcore.IPBindingConfig{Mode: IPBindingOff}, "203.0.113.7", "198.51.100.1", ""},
+		{"unparsable same", configcore.IPBindingConfig{}, "unknown", "unknown", ""},
+		{"unparsable other", configcore.IPBindingConfig{Action: IPMismatchStepUp}, "unknown", "203.0.113.7", IPMismatchStepUp},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			binding, err := NewIPBinding(tt.cfg)
+			if err != nil {
+				t.Fatalf("NewIPBinding() error = %v", err)
+			}
+			if got := binding.Check(tt.tokenIP, tt.clientIP); got != tt.want {
+				t.Fatalf("Check(%q, %q) = %q, want %q", tt.tokenIP, tt.clientIP, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewIPBindingErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  configcore.IPBindingConfig
+	}{
+		{"unknown mode", configcore.IPBindingConfig{Mode: "country"}},
+		{"unknown action", configcore.IPBindingConfig{Action: "ignore"}},
+		{"subnet too large", configcore.IPBindingConfig{Mode: IPBindingSubnet, SubnetV4Bits: 33}},
+		{"asn without database", configcore.IPBindingConfig{Mode: IPBindingASN}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewIPBinding(tt.cfg); err == nil {
+				t.Fatal("NewIPBinding() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestNilIPBindingIsStrict(t *testing.T) {
+	var binding *IPBinding
+	if got := binding.Check("203.0.113.7", "203.0.113.7"); got != "" {
+		t.Fatalf("Check(same) = %q, want empty", got)
+	}
+	if got := binding.Check("203.0.113.7", "203.0.113.8"); got != IPMismatchBlock {
+		t.Fatalf("Check(other) = %q, want %q", got, IPMismatchBlock)
+	}
+}
@@ -2,25 +2,69 @@ package securecore
 
 import (
 	"errors"
+	"math"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func GenerateTokenJWT(
+// Типы токенов (claim token_use)
+const (
+	TokenUseAccess     = "access"
+	TokenUseRefresh    = "refresh"
+	TokenUseMFAPending = "mfa_pending" // промежуточный токен входа: пароль проверен, ожидается второй фактор
+	TokenUseID         = "id"          // ID токен OpenID Connect, не принимается вместо access токена
+)
+
+// TokenPolicy параметры стандартных claims при выпуске и проверке токенов
+type TokenPolicy struct {
+	Issuer   string        // iss; NewTokenPolicy требует значение, пусто — только в тестах
+	Audience string        // aud; NewTokenPolicy требует значение, пусто — только в тестах
+	Leeway   time.Duration // допустимое расхождение часов при проверке exp, nbf и iat
+	// Привязка к IP-адресу клиента; nil — точное совпадение с блокировкой запроса
+	IPBinding *IPBinding
+}
+
+// GenerateToken генерирует токен в указанном формате (JWT или PASETO).
+// extraClaims добавляются к базовым claims; jti генерируется, если не передан.
+func GenerateToken(
 	guid string,
 	clientIP string,
-	secretJWT string,
+	tokenUse string,
+	format TokenFormat,
+	policy TokenPolicy,
 	expirationTime time.Duration,
-	signingMethod *jwt.SigningMethodHMAC,
+	extraClaims ...jwt.MapClaims,
 ) (string, error) {
-	token := jwt.NewWithClaims(signingMethod, jwt.MapClaims{
+	now := time.Now()
+	claims := jwt.MapClaims{
 		"guid":      guid,
 		"client_ip": clientIP,
-		"exp":       time.Now().Add(expirationTime).Unix(),
-	})
+		"token_use": tokenUse,
+		"iat":       NumericDate(now), // с миллисекундами: отзыв "всех токенов до T" не задевает токены той же секунды
+		"nbf":       now.Unix(),
+		"exp":       now.Add(expirationTime).Unix(),
+	}
+	if policy.Issuer != "" {
+		claims["iss"] = policy.Issuer
+	}
+	if policy.Audience != "" {
+		claims["aud"] = policy.Audience
+	}
+	for _, extra := range extraClaims {
+		for key, value := range extra {
+			claims[key] = value
+		}
+	}
+	if _, ok := claims["jti"]; !ok {
+		jti, err := GenerateUUID()
+		if err != nil {
+			return "", err
+		}
+		claims["jti"] = jti
+	}
 
-	t, err := token.SignedString([]byte(secretJWT))
+	t, err := format.Sign(claims)
 	if err != nil {
 		return "", err
 	}
@@ -28,47 +72,116 @@ func GenerateTokenJWT(
 	return t, nil
 }
 
-// VerifyToken верифицирует JWT токен и возвращает данные по токену или ошибку
+// ParseToken проверяет подлинность, срок действия, издателя, аудиторию и тип токена
+// без проверки IP-адреса клиента. Пустой tokenUse допускает токен любого типа.
+func ParseToken(
+	tokenString string,
+	format TokenFormat,
+	policy TokenPolicy,
+	tokenUse string,
+) (jwt.MapClaims, error) {
+	claims, err := format.Parse(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateClaims(claims, policy, tokenUse, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// VerifyToken верифицирует токен и его привязку к IP-адресу клиента.
+// Возвращает действие политики привязки при несовпадении адреса (step_up или notify)
+// или ErrIPMismatch, если политика требует отклонить запрос.
 func VerifyToken(
 	tokenString string,
-	jwtSecret string,
+	format TokenFormat,
+	policy TokenPolicy,
+	tokenUse string,
 	clientIP string,
-	signingMethod *jwt.SigningMethodHMAC,
-) (*jwt.Token, jwt.MapClaims, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if token.Method != signingMethod {
-			return nil, errors.New("unexpected signing method")
-		}
-		return []byte(jwtSecret), nil
-	})
-
-	if err != nil || !token.Valid {
-		return nil, nil, errors.New("invalid token")
+) (jwt.MapClaims, string, error) {
+	claims, err := ParseToken(tokenString, format, policy, tokenUse)
+	if err != nil {
+		return nil, "", err
 	}
 
-	// Извлекаем claims
-	claims, ok := token.Claims.(jwt.MapClaims)
+	// Проверка IP-адреса клиента
+	tokenIP, ok := claims["client_ip"].(string)
 	if !ok {
-		return nil, nil, errors.New("invalid token claims")
+		return nil, "", errors.New("missing client IP in token")
 	}
 
+	action := policy.IPBinding.Check(tokenIP, clientIP)
+	if action == IPMismatchBlock {
+		return nil, action, ErrIPMismatch
+	}
+
+	return claims, action, nil
+}
+
+// validateClaims проверяет стандартные claims токена
+func validateClaims(claims jwt.MapClaims, policy TokenPolicy, tokenUse string, now time.Time) error {
 	// Проверка, что ExpiresAt не истек
-	if exp, ok := claims["exp"].(float64); ok {
-		if time.Unix(int64(exp), 0).Before(time.Now()) {
-			return nil, nil, errors.New("token expired")
+	exp, ok := ClaimTime(claims, "exp")
+	if !ok {
+		return errors.New("missing expiration time in token")
+	}
+	if now.After(exp.Add(policy.Leeway)) {
+		return errors.New("token expired")
+	}
+
+	if nbf, ok := ClaimTime(claims, "nbf"); ok && now.Add(policy.Leeway).Before(nbf) {
+		return errors.New("token not valid yet")
+	}
+	if iat, ok := ClaimTime(claims, "iat"); ok && now.Add(policy.Leeway).Before(iat) {
+		return errors.New("token issued in the future")
+	}
+
+	if policy.Issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != policy.Issuer {
+			return errors.New("invalid token issuer")
 		}
-	} else {
-		return nil, nil, errors.New("missing expiration time in token")
+	}
+	if policy.Audience != "" && !claimAudienceContains(claims["aud"], policy.Audience) {
+		return errors.New("invalid token audience")
 	}
 
-	// Проверка IP-адреса клиента
-	if ip, ok := claims["client_ip"].(string); ok {
-		if ip != clientIP {
-			return nil, nil, errors.New("client IP mismatch")
+	if tokenUse != "" {
+		if use, _ := claims["token_use"].(string); use != tokenUse {
+			return errors.New("unexpected token type")
+		}
+	}
+
+	return nil
+}
+
+// claimAudienceContains проверяет aud, заданный строкой или массивом строк
+func claimAudienceContains(aud interface{}, audience string) bool {
+	switch value := aud.(type) {
+	case string:
+		return value == audience
+	case []interface{}:
+		for _, item := range value {
+			if item == audience {
+				return true
+			}
 		}
-	} else {
-		return nil, nil, errors.New("missing client IP in token")
 	}
+	return false
+}
+
+// ClaimTime извлекает из claims время в формате Unix (exp, iat, nbf) с точностью до миллисекунды
+func ClaimTime(claims jwt.MapClaims, name string) (time.Time, bool) {
+	value, ok := claims[name].(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.UnixMilli(int64(math.Round(value * 1000))), true
+}
 
-	return token, claims, nil
+// NumericDate время в секундах Unix с дробной частью до миллисекунд (NumericDate, RFC 7519)
+func NumericDate(t time.Time) float64 {
+	return float64(t.UnixMilli()) / 1000
 }
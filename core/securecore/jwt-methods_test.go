@@ -0,0 +1,130 @@
+package securecore
+
+import (
+	"authentication_service/core/configcore"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestNewTokenPolicyRequiresIssuerAndAudience(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     configcore.GrpcServiceConfig
+		wantErr bool
+	}{
+		{"issuer and audience", configcore.GrpcServiceConfig{Issuer: "https://auth.example.com", Audience: "authentication_service"}, false},
+		{"no issuer", configcore.GrpcServiceConfig{Audience: "authentication_service"}, true},
+		{"no audience", configcore.GrpcServiceConfig{Issuer: "https://auth.example.com"}, true},
+		{"blank values", configcore.GrpcServiceConfig{Issuer: " ", Audience: " "}, true},
+		{"empty", configcore.GrpcServiceConfig{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := NewTokenPolicy(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewTokenPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (policy.Issuer != tt.cfg.Issuer || policy.Audience != tt.cfg.Audience) {
+				t.Fatalf("NewTokenPolicy() = %+v", policy)
+			}
+		})
+	}
+}
+
+func TestValidateClaims(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	policy := TokenPolicy{Issuer: "issuer", Audience: "audience", Leeway: 30 * time.Second}
+	unix := func(d time.Duration) float64 { return float64(now.Add(d).Unix()) }
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":       "issuer",
+			"aud":       "audience",
+			"exp":       unix(time.Minute),
+			"iat":       unix(0),
+			"nbf":       unix(0),
+			"token_use": TokenUseAccess,
+		}
+	}
+	with := func(key string, value interface{}) jwt.MapClaims {
+		claims := valid()
+		if value == nil {
+			delete(claims, key)
+		} else {
+			claims[key] = value
+		}
+		return claims
+	}
+
+	tests := []struct {
+		name     string
+		claims   jwt.MapClaims
+		tokenUse string
+		wantErr  bool
+	}{
+		{"valid", valid(), TokenUseAccess, false},
+		{"any token type", valid(), "", false},
+		{"audience array", with("aud", []interface{}{"other", "audience"}), TokenUseAccess, false},
+		{"expired within leeway", with("exp", unix(-20*time.Second)), TokenUseAccess, false},
+		{"expired", with("exp", unix(-time.Minute)), TokenUseAccess, true},
+		{"no exp", with("exp", nil), TokenUseAccess, true},
+		{"not valid yet", with("nbf", unix(time.Minute)), TokenUseAccess, true},
+		{"issued in the future", with("iat", unix(time.Minute)), TokenUseAccess, true},
+		{"wrong issuer", with("iss", "other"), TokenUseAccess, true},
+		{"no issuer", with("iss", nil), TokenUseAccess, true},
+		{"wrong audience", with("aud", "other"), TokenUseAccess, true},
+		{"no audience", with("aud", nil), TokenUseAccess, true},
+		{"audience array without match", with("aud", []interface{}{"other"}), TokenUseAccess, true},
+		{"refresh as access", with("token_use", TokenUseRefresh), TokenUseAccess, true},
+		{"no token type", with("token_use", nil), TokenUseAccess, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateClaims(tt.claims, policy, tt.tokenUse, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateClaims() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerateTokenIssuedAtMillis(t *testing.T) {
+	edKey, _ := testKeys(t)
+	keyring, err := NewKeyring(configcore.AuthJWTConfig{
+		ActiveKeyID: "k1",
+		SigningKeys: []configcore.JWTSigningKeyConfig{{KID: "k1", Algorithm: "EdDSA", PrivateKey: privateKeyPEM(t, edKey)}},
+	})
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+	formats := pasetoFormats(t, nil)
+	formats[TokenFormatJWT] = keyring
+	policy := TokenPolicy{Issuer: "issuer", Audience: "audience"}
+
+	for name, format := range formats {
+		t.Run(name, func(t *testing.T) {
+			before := time.Now().Truncate(time.Millisecond)
+			token, err := GenerateToken("user", "203.0.113.7", TokenUseAccess, format, policy, time.Minute)
+			if err != nil {
+				t.Fatalf("GenerateToken() error = %v", err)
+			}
+			after := time.Now()
+
+			claims, err := ParseToken(token, format, policy, TokenUseAccess)
+			if err != nil {
+				t.Fatalf("ParseToken() error = %v", err)
+			}
+			iat, ok := ClaimTime(claims, "iat")
+			if !ok {
+				t.Fatalf("iat is missing: %v", claims)
+			}
+			// iat не округляется до секунды: отзыв "до T" отличает токены одной секунды
+			if iat.Before(before) || iat.After(after) {
+				t.Fatalf("iat = %s, want between %s and %s", iat.Format(time.RFC3339Nano), before.Format(time.RFC3339Nano), after.Format(time.RFC3339Nano))
+			}
+		})
+	}
+}
@@ -0,0 +1,271 @@
+package securecore
+
+import (
+	"authentication_service/core/configcore"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// минимальный размер RSA ключа
+const minRSAKeyBits = 2048
+
+var (
+	// ErrSymmetricSigningKey активный ключ симметричный: подпись нельзя проверить по JWKS
+	ErrSymmetricSigningKey = errors.New("active signing key is symmetric")
+	// ErrVerifyOnlyKeyring набор ключей создан только для проверки токенов
+	ErrVerifyOnlyKeyring = errors.New("keyring is verify-only")
+)
+
+// SigningKey ключ подписи и проверки JWT
+type SigningKey struct {
+	KID       string
+	Method    jwt.SigningMethod
+	signKey   interface{} // nil для ключей, оставленных только для проверки
+	verifyKey interface{}
+}
+
+// Keyring набор ключей подписи JWT.
+// Новые токены подписываются активным ключом, проверка выполняется
+// любым ключом набора по заголовку kid, что позволяет ротировать ключи.
+type Keyring struct {
+	keys   map[string]*SigningKey
+	active *SigningKey
+}
+
+// NewKeyring создает набор ключей из конфигурации для сервиса, выпускающего токены:
+// активный ключ должен содержать приватный ключ.
+// Если задан user_secret, в набор добавляется устаревший ключ HS512 без kid:
+// он становится активным при отсутствии асимметричных ключей
+// и иначе используется только для проверки ранее выданных токенов.
+func NewKeyring(cfg configcore.AuthJWTConfig) (*Keyring, error) {
+	k, err := newKeyring(cfg, false)
+	if err != nil {
+		return nil, err
+	}
+	if k.active.signKey == nil {
+		return nil, fmt.Errorf("active signing key %q has no private key", k.active.KID)
+	}
+	return k, nil
+}
+
+// NewVerifyKeyring создает набор ключей для сервиса, который только проверяет токены и публикует JWKS:
+// для асимметричных ключей достаточно public_key, приватные ключи в набор не попадают и Sign недоступен.
+// Устаревший ключ HS512 симметричный: для проверки ранее выданных им токенов нужен user_secret.
+func NewVerifyKeyring(cfg configcore.AuthJWTConfig) (*Keyring, error) {
+	return newKeyring(cfg, true)
+}
+
+func newKeyring(cfg configcore.AuthJWTConfig, verifyOnly bool) (*Keyring, error) {
+	k := &Keyring{keys: make(map[string]*SigningKey)}
+
+	for _, keyCfg := range cfg.SigningKeys {
+		key, err := parseSigningKey(keyCfg, verifyOnly)
+		if err != nil {
+			return nil, fmt.Errorf("signing key %q: %w", keyCfg.KID, err)
+		}
+		if _, exists := k.keys[key.KID]; exists {
+			return nil, fmt.Errorf("signing key %q: duplicate kid", keyCfg.KID)
+		}
+		k.keys[key.KID] = key
+	}
+
+	if cfg.UserSecret != "" {
+		legacy := &SigningKey{
+			Method:    jwt.SigningMethodHS512,
+			verifyKey: []byte(cfg.UserSecret),
+		}
+		if !verifyOnly {
+			legacy.signKey = []byte(cfg.UserSecret)
+		}
+		k.keys[legacy.KID] = legacy
+	}
+
+	switch {
+	case cfg.ActiveKeyID != "":
+		active, ok := k.keys[cfg.ActiveKeyID]
+		if !ok {
+			return nil, fmt.Errorf("active signing key %q not found", cfg.ActiveKeyID)
+		}
+		k.active = active
+	case len(cfg.SigningKeys) == 0 && cfg.UserSecret != "":
+		k.active = k.keys[""]
+	default:
+		return nil, errors.New("active signing key is not configured")
+	}
+
+	return k, nil
+}
+
+// Sign подписывает claims активным ключом и проставляет заголовок kid
+func (k *Keyring) Sign(claims jwt.MapClaims) (string, error) {
+	if k.active.signKey == nil {
+		return "", ErrVerifyOnlyKeyring
+	}
+	token := jwt.NewWithClaims(k.active.Method, claims)
+	if k.active.KID != "" {
+		token.Header["kid"] = k.active.KID
+	}
+	return token.SignedString(k.active.signKey)
+}
+
+// PublicAlgorithm возвращает алгоритм активного ключа, если подпись можно проверить
+// по опубликованному набору ключей (JWKS); для симметричного ключа возвращается ошибка
+func (k *Keyring) PublicAlgorithm() (string, error) {
+	if _, ok := k.active.verifyKey.([]byte); ok {
+		return "", ErrSymmetricSigningKey
+	}
+	return k.active.Method.Alg(), nil
+}
+
+// Parse проверяет подпись JWT токена и возвращает его claims.
+// Claims проверяются в ParseToken с учетом допустимого расхождения часов.
+func (k *Keyring) Parse(tokenString string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, k.Keyfunc, jwt.WithoutClaimsValidation())
+	if err != nil || !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, errors.New("invalid token claims")
+	}
+	return claims, nil
+}
+
+// Keyfunc возвращает ключ проверки по заголовку kid.
+// Алгоритм токена должен совпадать с алгоритмом ключа.
+func (k *Keyring) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	key, ok := k.keys[kid]
+	if !ok {
+		return nil, errors.New("unknown signing key")
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, errors.New("unexpected signing method")
+	}
+	return key.verifyKey, nil
+}
+
+// parseSigningKey разбирает ключ из конфигурации и проверяет его соответствие алгоритму.
+// Для verifyOnly предпочитается public_key, а приватный ключ используется только для получения публичного
+func parseSigningKey(cfg configcore.JWTSigningKeyConfig, verifyOnly bool) (*SigningKey, error) {
+	if cfg.KID == "" {
+		return nil, errors.New("kid is required")
+	}
+
+	key := &SigningKey{KID: cfg.KID}
+	switch cfg.Algorithm {
+	case jwt.SigningMethodEdDSA.Alg():
+		key.Method = jwt.SigningMethodEdDSA
+	case jwt.SigningMethodES256.Alg():
+		key.Method = jwt.SigningMethodES256
+	case jwt.SigningMethodRS256.Alg():
+		key.Method = jwt.SigningMethodRS256
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", cfg.Algorithm)
+	}
+
+	var publicKey crypto.PublicKey
+	switch {
+	case cfg.PrivateKey != "" && !(verifyOnly && cfg.PublicKey != ""):
+		privateKey, err := parsePrivateKeyPEM(cfg.PrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		if !verifyOnly {
+			key.signKey = privateKey
+		}
+		publicKey = privateKey.Public()
+	case cfg.PublicKey != "":
+		var err error
+		publicKey, err = parsePublicKeyPEM(cfg.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New("private_key or public_key is required")
+	}
+
+	if err := checkKeyAlgorithm(key.Method, publicKey); err != nil {
+		return nil, err
+	}
+	key.verifyKey = publicKey
+
+	return key, nil
+}
+
+// checkKeyAlgorithm проверяет, что тип ключа соответствует алгоритму подписи
+func checkKeyAlgorithm(method jwt.SigningMethod, publicKey crypto.PublicKey) error {
+	switch method {
+	case jwt.SigningMethodEdDSA:
+		if _, ok := publicKey.(ed25519.PublicKey); ok {
+			return nil
+		}
+	case jwt.SigningMethodES256:
+		if key, ok := publicKey.(*ecdsa.PublicKey); ok && key.Curve == elliptic.P256() {
+			return nil
+		}
+	case jwt.SigningMethodRS256:
+		if key, ok := publicKey.(*rsa.PublicKey); ok {
+			if key.N.BitLen() < minRSAKeyBits {
+				return fmt.Errorf("rsa key must be at least %d bits", minRSAKeyBits)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("key type does not match algorithm %s", method.Alg())
+}
+
+// parsePrivateKeyPEM разбирает приватный ключ в формате PKCS#8, PKCS#1 или SEC 1
+func parsePrivateKeyPEM(data string) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, errors.New("invalid private key PEM")
+	}
+
+	var (
+		key interface{}
+		err error
+	)
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse private key: %w", err)
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("unsupported private key type")
+	}
+	return signer, nil
+}
+
+// parsePublicKeyPEM разбирает публичный ключ в формате PKIX
+func parsePublicKeyPEM(data string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, errors.New("invalid public key PEM")
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse public key: %w", err)
+	}
+	return key, nil
+}
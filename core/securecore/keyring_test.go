@@ -0,0 +1,250 @@
+package securecore
+
+import (
+	"authentication_service/core/configcore"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func privateKeyPEM(t *testing.T, key crypto.Signer) string {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey: %v", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+}
+
+func publicKeyPEM(t *testing.T, key crypto.Signer) string {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey: %v", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+// testKeys ключи Ed25519 (активный) и ES256 (выведенный из оборота)
+func testKeys(t *testing.T) (ed25519.PrivateKey, *ecdsa.PrivateKey) {
+	t.Helper()
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("ed25519.GenerateKey: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("ecdsa.GenerateKey: %v", err)
+	}
+	return edKey, ecKey
+}
+
+func signWith(t *testing.T, method jwt.SigningMethod, kid string, key interface{}) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, jwt.MapClaims{"guid": "user"})
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("SignedString: %v", err)
+	}
+	return signed
+}
+
+func TestNewKeyringErrors(t *testing.T) {
+	edKey, ecKey := testKeys(t)
+	smallRSA, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		cfg  configcore.AuthJWTConfig
+	}{
+		{"empty", configcore.AuthJWTConfig{}},
+		{"active not found", configcore.AuthJWTConfig{ActiveKeyID: "missing", SigningKeys: []configcore.JWTSigningKeyConfig{
+			{KID: "a", Algorithm: "EdDSA", PrivateKey: privateKeyPEM(t, edKey)},
+		}}},
+		{"active without private key", configcore.AuthJWTConfig{ActiveKeyID: "a", SigningKeys: []configcore.JWTSigningKeyConfig{
+			{KID: "a", Algorithm: "EdDSA", PublicKey: publicKeyPEM(t, edKey)},
+		}}},
+		{"duplicate kid", configcore.AuthJWTConfig{ActiveKeyID: "a", SigningKeys: []configcore.JWTSigningKeyConfig{
+			{KID: "a", Algorithm: "EdDSA", PrivateKey: privateKeyPEM(t, edKey)},
+			{KID: "a", Algorithm: "ES256", PrivateKey: privateKeyPEM(t, ecKey)},
+		}}},
+		{"empty kid", configcore.AuthJWTConfig{ActiveKeyID: "", SigningKeys: []configcore.JWTSigningKeyConfig{
+			{Algorithm: "EdDSA", PrivateKey: privateKeyPEM(t, edKey)},
+		}}},
+		{"algorithm mismatch", configcore.AuthJWTConfig{ActiveKeyID: "a", SigningKeys: []configcore.JWTSigningKeyConfig{
+			{KID: "a", Algorithm: "ES256", PrivateKey: privateKeyPEM(t, edKey)},
+		}}},
+		{"unsupported algorithm", configcore.AuthJWTConfig{ActiveKeyID: "a", SigningKeys: []configcore.JWTSigningKeyConfig{
+			{KID: "a", Algorithm: "HS256", PrivateKey: privateKeyPEM(t, edKey)},
+		}}},
+		{"small rsa key", configcore.AuthJWTConfig{ActiveKeyID: "a", SigningKeys: []configcore.JWTSigningKeyConfig{
+			{KID: "a", Algorithm: "RS256", PrivateKey: privateKeyPEM(t, smallRSA)},
+		}}},
+		{"keys without active id", configcore.AuthJWTConfig{UserSecret: "secret", SigningKeys: []configcore.JWTSigningKeyConfig{
+			{KID: "a", Algorithm: "EdDSA", PrivateKey: privateKeyPEM(t, edKey)},
+		}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewKeyring(tt.cfg); err == nil {
+				t.Fatal("NewKeyring() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestKeyringKIDSelection(t *testing.T) {
+	edKey, ecKey := testKeys(t)
+	const secret = "legacy-secret"
+
+	keyring, err := NewKeyring(configcore.AuthJWTConfig{
+		UserSecret:  secret,
+		ActiveKeyID: "2025-01",
+		SigningKeys: []configcore.JWTSigningKeyConfig{
+			{KID: "2025-01", Algorithm: "EdDSA", PrivateKey: privateKeyPEM(t, edKey)},
+			{KID: "2024-07", Algorithm: "ES256", PublicKey: publicKeyPEM(t, ecKey)},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+
+	signed, err := keyring.Sign(jwt.MapClaims{"guid": "user"})
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	parsed, _, err := jwt.NewParser().ParseUnverified(signed, jwt.MapClaims{})
+	if err != nil {
+		t.Fatalf("ParseUnverified: %v", err)
+	}
+	if parsed.Header["kid"] != "2025-01" || parsed.Method.Alg() != "EdDSA" {
+		t.Fatalf("token header = %v, want kid 2025-01 and EdDSA", parsed.Header)
+	}
+
+	otherEd, _ := testKeys(t)
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{"active key", signed, false},
+		{"retired key by kid", signWith(t, jwt.SigningMethodES256, "2024-07", ecKey), false},
+		{"legacy HS512 without kid", signWith(t, jwt.SigningMethodHS512, "", []byte(secret)), false},
+		{"legacy HS512 wrong secret", signWith(t, jwt.SigningMethodHS512, "", []byte("other")), true},
+		{"unknown kid", signWith(t, jwt.SigningMethodEdDSA, "2023-01", edKey), true},
+		{"wrong key for kid", signWith(t, jwt.SigningMethodEdDSA, "2025-01", otherEd), true},
+		{"algorithm mismatch for kid", signWith(t, jwt.SigningMethodHS512, "2025-01", []byte(secret)), true},
+		{"HS256 without kid", signWith(t, jwt.SigningMethodHS256, "", []byte(secret)), true},
+		{"alg none", signWith(t, jwt.SigningMethodNone, "", jwt.UnsafeAllowNoneSignatureType), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := keyring.Parse(tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && claims["guid"] != "user" {
+				t.Fatalf("Parse() claims = %v", claims)
+			}
+		})
+	}
+}
+
+func TestKeyringLegacyOnly(t *testing.T) {
+	keyring, err := NewKeyring(configcore.AuthJWTConfig{UserSecret: "legacy-secret"})
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+
+	signed, err := keyring.Sign(jwt.MapClaims{"guid": "user"})
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	parsed, _, _ := jwt.NewParser().ParseUnverified(signed, jwt.MapClaims{})
+	if _, ok := parsed.Header["kid"]; ok || parsed.Method.Alg() != "HS512" {
+		t.Fatalf("legacy token header = %v, want HS512 without kid", parsed.Header)
+	}
+	if _, err := keyring.Parse(signed); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if _, err := keyring.PublicAlgorithm(); !errors.Is(err, ErrSymmetricSigningKey) {
+		t.Fatalf("PublicAlgorithm() error = %v, want %v", err, ErrSymmetricSigningKey)
+	}
+	if len(keyring.JWKS().Keys) != 0 {
+		t.Fatal("symmetric key must not be published in JWKS")
+	}
+}
+
+func TestVerifyKeyring(t *testing.T) {
+	edKey, ecKey := testKeys(t)
+	signing, err := NewKeyring(configcore.AuthJWTConfig{
+		ActiveKeyID: "2025-01",
+		SigningKeys: []configcore.JWTSigningKeyConfig{
+			{KID: "2025-01", Algorithm: "EdDSA", PrivateKey: privateKeyPEM(t, edKey)},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+	signed, err := signing.Sign(jwt.MapClaims{"guid": "user"})
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		cfg  configcore.AuthJWTConfig
+	}{
+		{"public keys only", configcore.AuthJWTConfig{
+			ActiveKeyID: "2025-01",
+			SigningKeys: []configcore.JWTSigningKeyConfig{
+				{KID: "2025-01", Algorithm: "EdDSA", PublicKey: publicKeyPEM(t, edKey)},
+				{KID: "2024-07", Algorithm: "ES256", PublicKey: publicKeyPEM(t, ecKey)},
+			},
+		}},
+		{"private key is not kept", configcore.AuthJWTConfig{
+			ActiveKeyID: "2025-01",
+			SigningKeys: []configcore.JWTSigningKeyConfig{
+				{KID: "2025-01", Algorithm: "EdDSA", PrivateKey: privateKeyPEM(t, edKey)},
+			},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verify, err := NewVerifyKeyring(tt.cfg)
+			if err != nil {
+				t.Fatalf("NewVerifyKeyring() error = %v", err)
+			}
+			if _, err := verify.Parse(signed); err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if _, err := verify.Sign(jwt.MapClaims{"guid": "user"}); !errors.Is(err, ErrVerifyOnlyKeyring) {
+				t.Fatalf("Sign() error = %v, want %v", err, ErrVerifyOnlyKeyring)
+			}
+			if alg, err := verify.PublicAlgorithm(); err != nil || alg != "EdDSA" {
+				t.Fatalf("PublicAlgorithm() = %q, %v", alg, err)
+			}
+			if len(verify.JWKS().Keys) != len(tt.cfg.SigningKeys) {
+				t.Fatalf("JWKS() has %d keys, want %d", len(verify.JWKS().Keys), len(tt.cfg.SigningKeys))
+			}
+		})
+	}
+}
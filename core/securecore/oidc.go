@@ -0,0 +1,94 @@
+package securecore
+
+import (
+	"authentication_service/core/typescore"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// IDTokenParams параметры ID токена OpenID Connect
+type IDTokenParams struct {
+	User     *typescore.User
+	ClientID string   // aud и azp
+	Nonce    string   // nonce из запроса авторизации; пусто — не выставляется (например, при обновлении токенов)
+	Scopes   []string // области доступа, разрешенные клиенту: определяют claims профиля
+	TTL      time.Duration
+	AuthTime time.Time // время аутентификации пользователя; нулевое — auth_time не выставляется
+	AMR      []string  // методы аутентификации
+}
+
+// GenerateIDToken выпускает ID токен OpenID Connect.
+// ID токен всегда подписывается как JWT ключом, опубликованным в JWKS, независимо от формата access токенов,
+// чтобы его могли проверить стандартные библиотеки клиентов.
+func GenerateIDToken(keyring *Keyring, policy TokenPolicy, p IDTokenParams) (string, error) {
+	if keyring == nil {
+		return "", errors.New("jwt signing keys are not configured")
+	}
+	if _, err := keyring.PublicAlgorithm(); err != nil {
+		return "", err
+	}
+	if p.User == nil || p.User.SystemID == nil || p.ClientID == "" {
+		return "", errors.New("user and client_id are required")
+	}
+
+	now := time.Now()
+	claims := OIDCUserClaims(p.User, p.Scopes)
+	claims["aud"] = p.ClientID
+	claims["azp"] = p.ClientID
+	claims["token_use"] = TokenUseID
+	claims["iat"] = now.Unix()
+	claims["exp"] = now.Add(p.TTL).Unix()
+	if policy.Issuer != "" {
+		claims["iss"] = policy.Issuer
+	}
+	if p.Nonce != "" {
+		claims["nonce"] = p.Nonce
+	}
+	for key, value := range AuthClaims(p.AuthTime, p.AMR) {
+		claims[key] = value
+	}
+
+	return keyring.Sign(claims)
+}
+
+// OIDCUserClaims возвращает стандартные claims пользователя (OpenID Connect Core, раздел 5.1),
+// доступные при разрешенных областях доступа profile и email. Claim sub выставляется всегда.
+func OIDCUserClaims(user *typescore.User, scopes []string) jwt.MapClaims {
+	claims := jwt.MapClaims{}
+	if user.SystemID != nil {
+		claims["sub"] = *user.SystemID
+	}
+
+	granted := make(map[string]struct{}, len(scopes))
+	for _, scope := range scopes {
+		granted[scope] = struct{}{}
+	}
+
+	if _, ok := granted[string(typescore.ProfileScope)]; ok {
+		names := make([]string, 0, 2)
+		if user.FirstName != nil && *user.FirstName != "" {
+			claims["given_name"] = *user.FirstName
+			names = append(names, *user.FirstName)
+		}
+		if user.LastName != nil && *user.LastName != "" {
+			claims["family_name"] = *user.LastName
+			names = append(names, *user.LastName)
+		}
+		if len(names) > 0 {
+			claims["name"] = strings.Join(names, " ")
+		}
+		if user.Nickname != nil && *user.Nickname != "" {
+			claims["preferred_username"] = *user.Nickname
+		}
+	}
+
+	if _, ok := granted[string(typescore.EmailScope)]; ok && user.Email != nil {
+		claims["email"] = *user.Email
+		claims["email_verified"] = user.EmailVerifiedAt != nil
+	}
+
+	return claims
+}
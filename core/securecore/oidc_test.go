@@ -0,0 +1,98 @@
+package securecore
+
+import (
+	"authentication_service/core/configcore"
+	"authentication_service/core/typescore"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGenerateIDToken(t *testing.T) {
+	edKey, _ := testKeys(t)
+	keyring, err := NewKeyring(configcore.AuthJWTConfig{
+		ActiveKeyID: "2025-01",
+		SigningKeys: []configcore.JWTSigningKeyConfig{
+			{KID: "2025-01", Algorithm: "EdDSA", PrivateKey: privateKeyPEM(t, edKey)},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+	policy := TokenPolicy{Issuer: "https://auth.example.com", Audience: "authentication-service"}
+
+	userID, email := "5b7c1e0a-3f2d-4c8e-9a61-2d4f8b0c7e13", "user@example.com"
+	user := &typescore.User{SystemID: &userID, Email: &email}
+	authTime := time.Now().Add(-time.Minute).Truncate(time.Second)
+
+	tests := []struct {
+		name      string
+		nonce     string
+		scopes    []string
+		wantEmail bool
+	}{
+		{"with nonce", "n-0S6_WzA2Mj", []string{"openid"}, false},
+		{"refresh without nonce", "", []string{"openid"}, false},
+		{"email scope", "n-0S6_WzA2Mj", []string{"openid", "email"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := GenerateIDToken(keyring, policy, IDTokenParams{
+				User:     user,
+				ClientID: "client-app",
+				Nonce:    tt.nonce,
+				Scopes:   tt.scopes,
+				TTL:      time.Minute,
+				AuthTime: authTime,
+				AMR:      []string{AMRPassword},
+			})
+			if err != nil {
+				t.Fatalf("GenerateIDToken() error = %v", err)
+			}
+
+			// Клиент проверяет ID токен по своему client_id в aud
+			claims, err := ParseToken(token, keyring, TokenPolicy{Issuer: policy.Issuer, Audience: "client-app"}, TokenUseID)
+			if err != nil {
+				t.Fatalf("ParseToken() error = %v", err)
+			}
+			if claims["sub"] != userID || claims["aud"] != "client-app" || claims["azp"] != "client-app" {
+				t.Fatalf("claims = %v, want sub %s, aud and azp client-app", claims, userID)
+			}
+			if nonce, ok := claims["nonce"]; ok != (tt.nonce != "") || (ok && nonce != tt.nonce) {
+				t.Fatalf("nonce = %v, want %q", claims["nonce"], tt.nonce)
+			}
+			if got, ok := ClaimTime(claims, "auth_time"); !ok || !got.Equal(authTime) {
+				t.Fatalf("auth_time = %v, want %v", got, authTime)
+			}
+			if _, ok := claims["email"]; ok != tt.wantEmail {
+				t.Fatalf("email present = %v, want %v", ok, tt.wantEmail)
+			}
+
+			// ID токен не принимается вместо access токена и другим клиентом
+			if _, err := ParseToken(token, keyring, policy, TokenUseAccess); err == nil {
+				t.Fatal("ID token accepted as access token")
+			}
+			if _, err := ParseToken(token, keyring, TokenPolicy{Issuer: policy.Issuer, Audience: "other-client"}, TokenUseID); err == nil {
+				t.Fatal("ID token accepted with another audience")
+			}
+		})
+	}
+}
+
+func TestGenerateIDTokenErrors(t *testing.T) {
+	userID := "5b7c1e0a-3f2d-4c8e-9a61-2d4f8b0c7e13"
+	user := &typescore.User{SystemID: &userID}
+	legacy, err := NewKeyring(configcore.AuthJWTConfig{UserSecret: "secret"})
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+
+	// Симметричный ключ не опубликован в JWKS: клиент не смог бы проверить подпись
+	if _, err := GenerateIDToken(legacy, TokenPolicy{}, IDTokenParams{User: user, ClientID: "client-app"}); !errors.Is(err, ErrSymmetricSigningKey) {
+		t.Fatalf("GenerateIDToken() error = %v, want %v", err, ErrSymmetricSigningKey)
+	}
+	if _, err := GenerateIDToken(nil, TokenPolicy{}, IDTokenParams{User: user, ClientID: "client-app"}); err == nil {
+		t.Fatal("GenerateIDToken() without keyring error = nil")
+	}
+}
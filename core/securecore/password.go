@@ -0,0 +1,123 @@
+package securecore
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Ограничения длины пароля. Верхняя граница защищает от дорогого хеширования слишком длинных строк.
+const (
+	PasswordMinLength = 8
+	PasswordMaxLength = 128
+)
+
+// ErrInvalidPasswordHash хеш пароля имеет неизвестный формат
+var ErrInvalidPasswordHash = errors.New("invalid password hash")
+
+// PasswordParams параметры Argon2id
+type PasswordParams struct {
+	Memory      uint32 // память в КиБ
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultPasswordParams параметры по умолчанию (RFC 9106, второй рекомендуемый вариант)
+var DefaultPasswordParams = PasswordParams{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 4,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+// HashPassword хеширует пароль Argon2id с параметрами по умолчанию.
+// Результат в формате PHC хранит параметры и соль рядом с хешем:
+// $argon2id$v=19$m=65536,t=3,p=4$<соль>$<хеш>
+func HashPassword(password string) (string, error) {
+	return HashPasswordWithParams(password, DefaultPasswordParams)
+}
+
+// HashPasswordWithParams хеширует пароль Argon2id с указанными параметрами
+func HashPasswordWithParams(password string, p PasswordParams) (string, error) {
+	salt := make([]byte, p.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, p.Memory, p.Iterations, p.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// VerifyPassword сравнивает пароль с хешем, используя параметры, сохраненные в хеше
+func VerifyPassword(password, encodedHash string) (bool, error) {
+	p, salt, key, err := decodePasswordHash(encodedHash)
+	if err != nil {
+		return false, err
+	}
+
+	otherKey := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+	return subtle.ConstantTimeCompare(key, otherKey) == 1, nil
+}
+
+// PasswordNeedsRehash сообщает, что хеш создан с параметрами, отличными от текущих
+func PasswordNeedsRehash(encodedHash string) bool {
+	p, _, _, err := decodePasswordHash(encodedHash)
+	if err != nil {
+		return true
+	}
+	return p != DefaultPasswordParams
+}
+
+// DummyVerifyPassword выполняет хеширование впустую, чтобы время ответа
+// для несуществующего пользователя не отличалось от проверки настоящего пароля
+func DummyVerifyPassword(password string) {
+	p := DefaultPasswordParams
+	argon2.IDKey([]byte(password), make([]byte, p.SaltLength), p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+}
+
+// decodePasswordHash разбирает хеш в формате PHC
+func decodePasswordHash(encodedHash string) (PasswordParams, []byte, []byte, error) {
+	var p PasswordParams
+
+	parts := strings.Split(encodedHash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return p, nil, nil, ErrInvalidPasswordHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return p, nil, nil, ErrInvalidPasswordHash
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Iterations, &p.Parallelism); err != nil {
+		return p, nil, nil, ErrInvalidPasswordHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return p, nil, nil, ErrInvalidPasswordHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return p, nil, nil, ErrInvalidPasswordHash
+	}
+	if p.Iterations == 0 || p.Parallelism == 0 || len(key) == 0 {
+		return p, nil, nil, ErrInvalidPasswordHash
+	}
+
+	p.SaltLength = uint32(len(salt))
+	p.KeyLength = uint32(len(key))
+	return p, salt, key, nil
+}
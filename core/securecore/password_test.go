@@ -0,0 +1,141 @@
+package securecore
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// testPasswordParams облегченные параметры, чтобы тесты не тратили 64 МиБ на каждый хеш
+var testPasswordParams = PasswordParams{
+	Memory:      1024,
+	Iterations:  1,
+	Parallelism: 1,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+func TestPasswordRoundTrip(t *testing.T) {
+	hash, err := HashPasswordWithParams("correct horse battery staple", testPasswordParams)
+	if err != nil {
+		t.Fatalf("HashPasswordWithParams() error = %v", err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Fatalf("hash = %q, want PHC argon2id prefix", hash)
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		want     bool
+	}{
+		{"correct", "correct horse battery staple", true},
+		{"wrong", "correct horse battery stapler", false},
+		{"empty", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := VerifyPassword(tt.password, hash)
+			if err != nil {
+				t.Fatalf("VerifyPassword() error = %v", err)
+			}
+			if ok != tt.want {
+				t.Fatalf("VerifyPassword() = %v, want %v", ok, tt.want)
+			}
+		})
+	}
+
+	other, err := HashPasswordWithParams("correct horse battery staple", testPasswordParams)
+	if err != nil {
+		t.Fatalf("HashPasswordWithParams() error = %v", err)
+	}
+	if other == hash {
+		t.Fatal("two hashes of the same password are equal, salt is not random")
+	}
+}
+
+func TestDecodePasswordHash(t *testing.T) {
+	valid, err := HashPasswordWithParams("secret", testPasswordParams)
+	if err != nil {
+		t.Fatalf("HashPasswordWithParams() error = %v", err)
+	}
+	parts := strings.Split(valid, "$")
+	salt, key := parts[4], parts[5]
+
+	tests := []struct {
+		name    string
+		hash    string
+		wantErr bool
+	}{
+		{"valid", valid, false},
+		{"empty", "", true},
+		{"bcrypt", "$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy", true},
+		{"argon2i", fmt.Sprintf("$argon2i$v=19$m=1024,t=1,p=1$%s$%s", salt, key), true},
+		{"old version", fmt.Sprintf("$argon2id$v=16$m=1024,t=1,p=1$%s$%s", salt, key), true},
+		{"broken params", fmt.Sprintf("$argon2id$v=19$m=1024;t=1;p=1$%s$%s", salt, key), true},
+		{"zero iterations", fmt.Sprintf("$argon2id$v=19$m=1024,t=0,p=1$%s$%s", salt, key), true},
+		{"zero parallelism", fmt.Sprintf("$argon2id$v=19$m=1024,t=1,p=0$%s$%s", salt, key), true},
+		{"bad salt", fmt.Sprintf("$argon2id$v=19$m=1024,t=1,p=1$%s$%s", "!!", key), true},
+		{"empty key", fmt.Sprintf("$argon2id$v=19$m=1024,t=1,p=1$%s$", salt), true},
+		{"extra segment", valid + "$x", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _, _, err := decodePasswordHash(tt.hash)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidPasswordHash) {
+					t.Fatalf("decodePasswordHash() error = %v, want %v", err, ErrInvalidPasswordHash)
+				}
+				if _, err := VerifyPassword("secret", tt.hash); err == nil {
+					t.Fatal("VerifyPassword() error = nil for invalid hash")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decodePasswordHash() error = %v", err)
+			}
+			if p != testPasswordParams {
+				t.Fatalf("decodePasswordHash() params = %+v, want %+v", p, testPasswordParams)
+			}
+		})
+	}
+}
+
+func TestPasswordNeedsRehash(t *testing.T) {
+	current, err := HashPassword("secret")
+	if err != nil {
+		t.Fatalf("HashPassword() error = %v", err)
+	}
+	weak, err := HashPasswordWithParams("secret", testPasswordParams)
+	if err != nil {
+		t.Fatalf("HashPasswordWithParams() error = %v", err)
+	}
+	longerKey := DefaultPasswordParams
+	longerKey.KeyLength = 64
+	longer, err := HashPasswordWithParams("secret", longerKey)
+	if err != nil {
+		t.Fatalf("HashPasswordWithParams() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		hash string
+		want bool
+	}{
+		{"current params", current, false},
+		{"weaker params", weak, true},
+		{"different key length", longer, true},
+		{"unknown format", "plaintext", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PasswordNeedsRehash(tt.hash); got != tt.want {
+				t.Fatalf("PasswordNeedsRehash() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package securecore
+
+import (
+	"crypto/rand"
+	"hash/crc32"
+	"strings"
+)
+
+// PersonalAccessTokenPrefix префикс персональных токенов доступа.
+// Отличает их от JWT и PASETO и позволяет сканерам секретов находить утекшие токены
+const PersonalAccessTokenPrefix = "aspat_"
+
+const (
+	personalTokenAlphabet    = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	personalTokenRandomLen   = 40 // ~238 бит энтропии
+	personalTokenChecksumLen = 6  // CRC32 в base62
+)
+
+// GeneratePersonalAccessToken генерирует персональный токен доступа:
+// префикс, 40 случайных символов base62 и контрольная сумма CRC32 случайной части.
+// Контрольная сумма позволяет отбросить опечатки и ложные срабатывания сканеров без обращения к базе
+func GeneratePersonalAccessToken() (string, error) {
+	random := make([]byte, 0, personalTokenRandomLen)
+	buf := make([]byte, personalTokenRandomLen)
+	for len(random) < personalTokenRandomLen {
+		if _, err := rand.Read(buf); err != nil {
+			return "", err
+		}
+		for _, b := range buf {
+			// Отбрасываем значения, дающие смещение распределения
+			if int(b) >= 256-256%len(personalTokenAlphabet) {
+				continue
+			}
+			random = append(random, personalTokenAlphabet[int(b)%len(personalTokenAlphabet)])
+			if len(random) == personalTokenRandomLen {
+				break
+			}
+		}
+	}
+	return PersonalAccessTokenPrefix + string(random) + personalTokenChecksum(string(random)), nil
+}
+
+// IsPersonalAccessToken проверяет префикс, длину и контрольную сумму персонального токена доступа
+func IsPersonalAccessToken(token string) bool {
+	body, ok := strings.CutPrefix(token, PersonalAccessTokenPrefix)
+	if !ok || len(body) != personalTokenRandomLen+personalTokenChecksumLen {
+		return false
+	}
+	for _, c := range body {
+		if !strings.ContainsRune(personalTokenAlphabet, c) {
+			return false
+		}
+	}
+	random := body[:personalTokenRandomLen]
+	return body[personalTokenRandomLen:] == personalTokenChecksum(random)
+}
+
+// personalTokenChecksum возвращает CRC32 строки в base62 фиксированной длины
+func personalTokenChecksum(value string) string {
+	sum := crc32.ChecksumIEEE([]byte(value))
+	out := make([]byte, personalTokenChecksumLen)
+	for i := personalTokenChecksumLen - 1; i >= 0; i-- {
+		out[i] = personalTokenAlphabet[sum%uint32(len(personalTokenAlphabet))]
+		sum /= uint32(len(personalTokenAlphabet))
+	}
+	return string(out)
+}
@@ -0,0 +1,70 @@
+package securecore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidCiphertext зашифрованное значение повреждено или зашифровано другим ключом
+var ErrInvalidCiphertext = errors.New("invalid ciphertext")
+
+// SecretCipher шифрует секреты, хранимые в базе данных (AES-GCM).
+// Результат — base64 от nonce и шифротекста.
+type SecretCipher struct {
+	aead cipher.AEAD
+}
+
+// NewSecretCipher создает шифр из ключа secrets.aes_bucket_key.
+// Ключ задается в hex (32, 48 или 64 символа) либо строкой длиной 16, 24 или 32 байта.
+func NewSecretCipher(key string) (*SecretCipher, error) {
+	raw, err := hex.DecodeString(key)
+	if err != nil || !isAESKeySize(len(raw)) {
+		raw = []byte(key)
+	}
+	if !isAESKeySize(len(raw)) {
+		return nil, fmt.Errorf("aes key must be 16, 24 or 32 bytes, got %d", len(raw))
+	}
+
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &SecretCipher{aead: aead}, nil
+}
+
+// Encrypt шифрует значение
+func (c *SecretCipher) Encrypt(plaintext string) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := c.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt расшифровывает значение, полученное Encrypt
+func (c *SecretCipher) Decrypt(ciphertext string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil || len(sealed) < c.aead.NonceSize() {
+		return "", ErrInvalidCiphertext
+	}
+	nonce, data := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	plaintext, err := c.aead.Open(nil, nonce, data, nil)
+	if err != nil {
+		return "", ErrInvalidCiphertext
+	}
+	return string(plaintext), nil
+}
+
+func isAESKeySize(n int) bool {
+	return n == 16 || n == 24 || n == 32
+}
@@ -0,0 +1,150 @@
+package securecore
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// допустимое опережение auth_date относительно часов сервера
+const telegramClockSkew = time.Minute
+
+var (
+	// ErrInvalidTelegramHash подпись данных Telegram не совпадает
+	ErrInvalidTelegramHash = errors.New("invalid telegram data hash")
+	// ErrTelegramAuthExpired данные Telegram подписаны слишком давно
+	ErrTelegramAuthExpired = errors.New("telegram auth data expired")
+	// ErrInvalidTelegramData данные Telegram имеют неверный формат
+	ErrInvalidTelegramData = errors.New("invalid telegram auth data")
+)
+
+// TelegramIdentity пользователь Telegram, подтвержденный подписью бота
+type TelegramIdentity struct {
+	ID        int64
+	FirstName string
+	LastName  string
+	Username  string
+	AuthDate  time.Time
+}
+
+// telegramWebAppUser поле user в initData Mini App
+type telegramWebAppUser struct {
+	ID        int64  `json:"id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Username  string `json:"username"`
+}
+
+// VerifyTelegramLogin проверяет данные Telegram Login Widget.
+// Ключ HMAC-SHA256 — SHA-256 от токена бота; fields содержит все поля виджета, включая hash.
+func VerifyTelegramLogin(botToken string, fields map[string]string, maxAge time.Duration, now time.Time) (*TelegramIdentity, error) {
+	secret := sha256.Sum256([]byte(botToken))
+	if err := checkTelegramHash(secret[:], fields); err != nil {
+		return nil, err
+	}
+
+	id, err := strconv.ParseInt(fields["id"], 10, 64)
+	if err != nil || id == 0 {
+		return nil, ErrInvalidTelegramData
+	}
+	authDate, err := checkTelegramAuthDate(fields["auth_date"], maxAge, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TelegramIdentity{
+		ID:        id,
+		FirstName: fields["first_name"],
+		LastName:  fields["last_name"],
+		Username:  fields["username"],
+		AuthDate:  authDate,
+	}, nil
+}
+
+// VerifyTelegramInitData проверяет initData Telegram Mini App.
+// Ключ HMAC-SHA256 — HMAC-SHA256 токена бота с ключом "WebAppData".
+func VerifyTelegramInitData(botToken, initData string, maxAge time.Duration, now time.Time) (*TelegramIdentity, error) {
+	values, err := url.ParseQuery(initData)
+	if err != nil {
+		return nil, ErrInvalidTelegramData
+	}
+	fields := make(map[string]string, len(values))
+	for key, value := range values {
+		if len(value) != 1 {
+			return nil, ErrInvalidTelegramData
+		}
+		fields[key] = value[0]
+	}
+
+	mac := hmac.New(sha256.New, []byte("WebAppData"))
+	mac.Write([]byte(botToken))
+	if err := checkTelegramHash(mac.Sum(nil), fields); err != nil {
+		return nil, err
+	}
+
+	var user telegramWebAppUser
+	if err := json.Unmarshal([]byte(fields["user"]), &user); err != nil || user.ID == 0 {
+		return nil, ErrInvalidTelegramData
+	}
+	authDate, err := checkTelegramAuthDate(fields["auth_date"], maxAge, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TelegramIdentity{
+		ID:        user.ID,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Username:  user.Username,
+		AuthDate:  authDate,
+	}, nil
+}
+
+// checkTelegramHash сравнивает hash с подписью строки проверки:
+// все поля, кроме hash, в виде key=value, отсортированные по ключу и разделенные переводом строки
+func checkTelegramHash(secret []byte, fields map[string]string) error {
+	expected, err := hex.DecodeString(fields["hash"])
+	if err != nil || len(expected) != sha256.Size {
+		return ErrInvalidTelegramHash
+	}
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		if key != "hash" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+"="+fields[key])
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(strings.Join(pairs, "\n")))
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return ErrInvalidTelegramHash
+	}
+	return nil
+}
+
+// checkTelegramAuthDate проверяет, что данные подписаны не раньше maxAge
+func checkTelegramAuthDate(value string, maxAge time.Duration, now time.Time) (time.Time, error) {
+	unix, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || unix <= 0 {
+		return time.Time{}, ErrInvalidTelegramData
+	}
+	authDate := time.Unix(unix, 0)
+	if authDate.After(now.Add(telegramClockSkew)) || now.Sub(authDate) > maxAge {
+		return time.Time{}, ErrTelegramAuthExpired
+	}
+	return authDate, nil
+}
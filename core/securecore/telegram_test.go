@@ -0,0 +1,141 @@
+package securecore
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testBotToken = "123456:test-bot-token"
+
+// signTelegramFields подписывает поля так же, как это делает Telegram
+func signTelegramFields(secret []byte, fields map[string]string) string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+"="+fields[key])
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(strings.Join(pairs, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func signedWidgetFields(botToken string, fields map[string]string) map[string]string {
+	secret := sha256.Sum256([]byte(botToken))
+	signed := make(map[string]string, len(fields)+1)
+	for key, value := range fields {
+		signed[key] = value
+	}
+	signed["hash"] = signTelegramFields(secret[:], fields)
+	return signed
+}
+
+func TestVerifyTelegramLogin(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	authDate := strconv.FormatInt(now.Add(-time.Minute).Unix(), 10)
+	base := map[string]string{
+		"id":         "42",
+		"first_name": "Ivan",
+		"username":   "ivan",
+		"auth_date":  authDate,
+	}
+	with := func(key, value string) map[string]string {
+		fields := map[string]string{}
+		for k, v := range base {
+			fields[k] = v
+		}
+		fields[key] = value
+		return fields
+	}
+
+	tampered := signedWidgetFields(testBotToken, base)
+	tampered["id"] = "43"
+	noHash := signedWidgetFields(testBotToken, base)
+	delete(noHash, "hash")
+
+	tests := []struct {
+		name    string
+		fields  map[string]string
+		wantErr error
+	}{
+		{"valid", signedWidgetFields(testBotToken, base), nil},
+		{"other bot", signedWidgetFields("654321:other-token", base), ErrInvalidTelegramHash},
+		{"tampered field", tampered, ErrInvalidTelegramHash},
+		{"missing hash", noHash, ErrInvalidTelegramHash},
+		{"expired", signedWidgetFields(testBotToken, with("auth_date", strconv.FormatInt(now.Add(-2*time.Hour).Unix(), 10))), ErrTelegramAuthExpired},
+		{"from the future", signedWidgetFields(testBotToken, with("auth_date", strconv.FormatInt(now.Add(10*time.Minute).Unix(), 10))), ErrTelegramAuthExpired},
+		{"within clock skew", signedWidgetFields(testBotToken, with("auth_date", strconv.FormatInt(now.Add(30*time.Second).Unix(), 10))), nil},
+		{"zero id", signedWidgetFields(testBotToken, with("id", "0")), ErrInvalidTelegramData},
+		{"bad auth_date", signedWidgetFields(testBotToken, with("auth_date", "yesterday")), ErrInvalidTelegramData},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity, err := VerifyTelegramLogin(testBotToken, tt.fields, time.Hour, now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("VerifyTelegramLogin() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (identity.ID != 42 || identity.Username != "ivan") {
+				t.Fatalf("VerifyTelegramLogin() identity = %+v", identity)
+			}
+		})
+	}
+}
+
+func TestVerifyTelegramInitData(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	mac := hmac.New(sha256.New, []byte("WebAppData"))
+	mac.Write([]byte(testBotToken))
+	secret := mac.Sum(nil)
+
+	initData := func(user string, authDate time.Time) string {
+		fields := map[string]string{
+			"query_id":  "AAHdF6IQAAAAAN0XohDhrOrc",
+			"user":      user,
+			"auth_date": strconv.FormatInt(authDate.Unix(), 10),
+		}
+		values := url.Values{}
+		for key, value := range fields {
+			values.Set(key, value)
+		}
+		values.Set("hash", signTelegramFields(secret, fields))
+		return values.Encode()
+	}
+	user := `{"id":42,"first_name":"Ivan","username":"ivan"}`
+
+	tests := []struct {
+		name     string
+		initData string
+		wantErr  error
+	}{
+		{"valid", initData(user, now.Add(-time.Minute)), nil},
+		{"tampered", strings.Replace(initData(user, now.Add(-time.Minute)), "ivan", "petr", 1), ErrInvalidTelegramHash},
+		{"expired", initData(user, now.Add(-2*time.Hour)), ErrTelegramAuthExpired},
+		{"user without id", initData(`{"first_name":"Ivan"}`, now.Add(-time.Minute)), ErrInvalidTelegramData},
+		{"duplicated field", initData(user, now.Add(-time.Minute)) + "&user=x", ErrInvalidTelegramData},
+		{"widget signature", "id=42&auth_date=1700000000&hash=" + signedWidgetFields(testBotToken, map[string]string{"id": "42", "auth_date": "1700000000"})["hash"], ErrInvalidTelegramHash},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity, err := VerifyTelegramInitData(testBotToken, tt.initData, time.Hour, now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("VerifyTelegramInitData() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (identity.ID != 42 || identity.Username != "ivan") {
+				t.Fatalf("VerifyTelegramInitData() identity = %+v", identity)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package securecore
+
+import (
+	"authentication_service/core/configcore"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Форматы токенов
+const (
+	TokenFormatJWT            = "jwt"
+	TokenFormatPASETOV4Local  = "paseto_v4_local"
+	TokenFormatPASETOV4Public = "paseto_v4_public"
+)
+
+// TokenFormat формат выпуска и проверки токенов.
+// Claims во всех форматах представлены одинаково: время (exp, iat, nbf) — в секундах Unix.
+type TokenFormat interface {
+	// Sign выпускает токен с указанными claims
+	Sign(claims jwt.MapClaims) (string, error)
+	// Parse проверяет подлинность токена и возвращает его claims.
+	// Срок действия и остальные claims проверяются в ParseToken.
+	Parse(token string) (jwt.MapClaims, error)
+}
+
+// NewTokenFormat возвращает формат токенов, выбранный в конфигурации сервиса авторизации
+func NewTokenFormat(cfg configcore.GrpcServiceConfig, keyring *Keyring) (TokenFormat, error) {
+	switch cfg.TokenFormat {
+	case "", TokenFormatJWT:
+		if keyring == nil {
+			return nil, errors.New("jwt signing keys are not configured")
+		}
+		return keyring, nil
+	case TokenFormatPASETOV4Local:
+		if cfg.PASETO.SymmetricKeyHex == "" {
+			return nil, errors.New("paseto symmetric_key is not configured")
+		}
+		return &PASETOLocalFormat{key: cfg.PASETO.SymmetricKey, implicit: cfg.PASETO.ImplicitBytes}, nil
+	case TokenFormatPASETOV4Public:
+		if cfg.PASETO.PublicKey == nil {
+			return nil, errors.New("paseto secret_key or public_key is not configured")
+		}
+		return &PASETOPublicFormat{secretKey: cfg.PASETO.SecretKey, publicKey: *cfg.PASETO.PublicKey, implicit: cfg.PASETO.ImplicitBytes}, nil
+	default:
+		return nil, fmt.Errorf("unsupported token format %q", cfg.TokenFormat)
+	}
+}
+
+// NewTokenPolicy возвращает параметры стандартных claims и привязки к IP
+// из конфигурации сервиса авторизации. issuer и audience обязательны:
+// без них токен, выпущенный другим сервисом с теми же ключами, прошел бы проверку
+func NewTokenPolicy(cfg configcore.GrpcServiceConfig) (TokenPolicy, error) {
+	if strings.TrimSpace(cfg.Issuer) == "" || strings.TrimSpace(cfg.Audience) == "" {
+		return TokenPolicy{}, errors.New("token issuer and audience must be configured")
+	}
+
+	ipBinding, err := NewIPBinding(cfg.IPBinding)
+	if err != nil {
+		return TokenPolicy{}, err
+	}
+
+	return TokenPolicy{
+		Issuer:    cfg.Issuer,
+		Audience:  cfg.Audience,
+		Leeway:    cfg.Leeway,
+		IPBinding: ipBinding,
+	}, nil
+}
@@ -0,0 +1,119 @@
+package securecore
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"aidanwoods.dev/go-paseto"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// claims со временем: в PASETO хранятся в RFC 3339, в claims — в секундах Unix (с миллисекундами)
+var pasetoTimeClaims = []string{"exp", "iat", "nbf", "auth_time"}
+
+// PASETOLocalFormat токены PASETO v4.local (симметричное шифрование)
+type PASETOLocalFormat struct {
+	key      paseto.V4SymmetricKey
+	implicit []byte
+}
+
+func (f *PASETOLocalFormat) Sign(claims jwt.MapClaims) (string, error) {
+	token, err := newPASETOToken(claims)
+	if err != nil {
+		return "", err
+	}
+	return token.V4Encrypt(f.key, f.implicit), nil
+}
+
+func (f *PASETOLocalFormat) Parse(tokenString string) (jwt.MapClaims, error) {
+	token, err := paseto.NewParserWithoutExpiryCheck().ParseV4Local(f.key, tokenString, f.implicit)
+	if err != nil {
+		return nil, errors.New("invalid token")
+	}
+	return pasetoClaims(token)
+}
+
+// PASETOPublicFormat токены PASETO v4.public (подпись Ed25519).
+// Для проверки достаточно публичного ключа.
+type PASETOPublicFormat struct {
+	secretKey *paseto.V4AsymmetricSecretKey // nil, если сервис только проверяет токены
+	publicKey paseto.V4AsymmetricPublicKey
+	implicit  []byte
+}
+
+func (f *PASETOPublicFormat) Sign(claims jwt.MapClaims) (string, error) {
+	if f.secretKey == nil {
+		return "", errors.New("paseto secret key is not configured")
+	}
+
+	token, err := newPASETOToken(claims)
+	if err != nil {
+		return "", err
+	}
+	return token.V4Sign(*f.secretKey, f.implicit), nil
+}
+
+func (f *PASETOPublicFormat) Parse(tokenString string) (jwt.MapClaims, error) {
+	token, err := paseto.NewParserWithoutExpiryCheck().ParseV4Public(f.publicKey, tokenString, f.implicit)
+	if err != nil {
+		return nil, errors.New("invalid token")
+	}
+	return pasetoClaims(token)
+}
+
+// newPASETOToken переносит claims в токен PASETO
+func newPASETOToken(claims jwt.MapClaims) (*paseto.Token, error) {
+	token := paseto.NewToken()
+	for key, value := range claims {
+		if err := token.Set(key, value); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, name := range pasetoTimeClaims {
+		if value, ok := claims[name]; ok {
+			t, ok := timeClaim(value)
+			if !ok {
+				return nil, errors.New("invalid time claim " + name)
+			}
+			// RFC3339Nano сохраняет доли секунды; SetTime их отбрасывает
+			token.SetString(name, t.UTC().Format(time.RFC3339Nano))
+		}
+	}
+
+	return &token, nil
+}
+
+// pasetoClaims возвращает claims токена PASETO с временем в секундах Unix (с миллисекундами)
+func pasetoClaims(token *paseto.Token) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims(token.Claims())
+
+	for _, name := range pasetoTimeClaims {
+		if _, ok := claims[name]; !ok {
+			continue
+		}
+		value, err := token.GetTime(name)
+		if err != nil {
+			return nil, errors.New("invalid time claim " + name)
+		}
+		claims[name] = NumericDate(value)
+	}
+
+	return claims, nil
+}
+
+// timeClaim приводит значение времени claims (секунды Unix или time.Time) ко времени
+func timeClaim(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case int64:
+		return time.Unix(v, 0), true
+	case int:
+		return time.Unix(int64(v), 0), true
+	case float64:
+		return time.UnixMilli(int64(math.Round(v * 1000))), true
+	case time.Time:
+		return v, true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,143 @@
+package securecore
+
+import (
+	"authentication_service/core/configcore"
+	"reflect"
+	"testing"
+	"time"
+
+	"aidanwoods.dev/go-paseto"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func pasetoFormats(t *testing.T, implicit []byte) map[string]TokenFormat {
+	t.Helper()
+	symmetricKey := paseto.NewV4SymmetricKey()
+	secretKey := paseto.NewV4AsymmetricSecretKey()
+	publicKey := secretKey.Public()
+
+	local, err := NewTokenFormat(configcore.GrpcServiceConfig{
+		TokenFormat: TokenFormatPASETOV4Local,
+		PASETO:      configcore.PASETOConfig{SymmetricKeyHex: symmetricKey.ExportHex(), SymmetricKey: symmetricKey, ImplicitBytes: implicit},
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewTokenFormat(local) error = %v", err)
+	}
+	public, err := NewTokenFormat(configcore.GrpcServiceConfig{
+		TokenFormat: TokenFormatPASETOV4Public,
+		PASETO:      configcore.PASETOConfig{SecretKey: &secretKey, PublicKey: &publicKey, ImplicitBytes: implicit},
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewTokenFormat(public) error = %v", err)
+	}
+	return map[string]TokenFormat{TokenFormatPASETOV4Local: local, TokenFormatPASETOV4Public: public}
+}
+
+func TestPASETORoundTrip(t *testing.T) {
+	policy := TokenPolicy{Issuer: "issuer", Audience: "audience"}
+	authTime := time.Now().Add(-time.Minute).Truncate(time.Second)
+
+	for name, format := range pasetoFormats(t, []byte("auth-service")) {
+		t.Run(name, func(t *testing.T) {
+			token, err := GenerateToken("user", "203.0.113.7", TokenUseAccess, format, policy, time.Minute,
+				jwt.MapClaims{"role": "user", "scope": "profile:read"},
+				AuthClaims(authTime, []string{AMRPassword, AMROTP}),
+				ActClaims("actor"),
+			)
+			if err != nil {
+				t.Fatalf("GenerateToken() error = %v", err)
+			}
+
+			claims, err := ParseToken(token, format, policy, TokenUseAccess)
+			if err != nil {
+				t.Fatalf("ParseToken() error = %v", err)
+			}
+			if claims["guid"] != "user" || claims["client_ip"] != "203.0.113.7" || claims["role"] != "user" {
+				t.Fatalf("claims = %v", claims)
+			}
+			if jti, _ := claims["jti"].(string); !IsValidUUID(jti) {
+				t.Fatalf("jti = %v, want uuid", claims["jti"])
+			}
+			for _, name := range pasetoTimeClaims {
+				if _, ok := claims[name].(float64); !ok {
+					t.Fatalf("time claim %s = %T, want float64", name, claims[name])
+				}
+			}
+			if got, ok := ClaimTime(claims, "auth_time"); !ok || !got.Equal(authTime) {
+				t.Fatalf("auth_time = %v, want %v", got, authTime)
+			}
+			if got := ClaimAMR(claims); !reflect.DeepEqual(got, []string{AMRPassword, AMROTP}) {
+				t.Fatalf("amr = %v", got)
+			}
+			if got := ClaimActor(claims); got != "actor" {
+				t.Fatalf("act.sub = %q, want actor", got)
+			}
+
+			if _, err := ParseToken(token, format, policy, TokenUseRefresh); err == nil {
+				t.Fatal("access token accepted as refresh token")
+			}
+		})
+	}
+}
+
+func TestPASETORejectsForeignTokens(t *testing.T) {
+	formats := pasetoFormats(t, []byte("auth-service"))
+	otherKeys := pasetoFormats(t, []byte("auth-service"))
+	otherImplicit := pasetoFormats(t, []byte("other-service"))
+	policy := TokenPolicy{}
+
+	for name, format := range formats {
+		t.Run(name, func(t *testing.T) {
+			token, err := GenerateToken("user", "203.0.113.7", TokenUseAccess, format, policy, time.Minute)
+			if err != nil {
+				t.Fatalf("GenerateToken() error = %v", err)
+			}
+			expired, err := GenerateToken("user", "203.0.113.7", TokenUseAccess, format, policy, -time.Minute)
+			if err != nil {
+				t.Fatalf("GenerateToken() error = %v", err)
+			}
+
+			tests := []struct {
+				name   string
+				token  string
+				format TokenFormat
+			}{
+				{"other key", token, otherKeys[name]},
+				{"other implicit assertion", token, otherImplicit[name]},
+				{"tampered", token[:len(token)-2] + "AA", format},
+				{"expired", expired, format},
+			}
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					if _, err := ParseToken(tt.token, tt.format, policy, TokenUseAccess); err == nil {
+						t.Fatal("ParseToken() error = nil, want error")
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestPASETOPublicVerifyOnly(t *testing.T) {
+	secretKey := paseto.NewV4AsymmetricSecretKey()
+	publicKey := secretKey.Public()
+	signing := &PASETOPublicFormat{secretKey: &secretKey, publicKey: publicKey}
+	verify, err := NewTokenFormat(configcore.GrpcServiceConfig{
+		TokenFormat: TokenFormatPASETOV4Public,
+		PASETO:      configcore.PASETOConfig{PublicKey: &publicKey},
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewTokenFormat() error = %v", err)
+	}
+
+	token, err := GenerateToken("user", "203.0.113.7", TokenUseAccess, signing, TokenPolicy{}, time.Minute)
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	if _, err := ParseToken(token, verify, TokenPolicy{}, TokenUseAccess); err != nil {
+		t.Fatalf("ParseToken() error = %v", err)
+	}
+	if _, err := verify.Sign(jwt.MapClaims{"guid": "user"}); err == nil {
+		t.Fatal("Sign() without secret key error = nil, want error")
+	}
+}
@@ -0,0 +1,137 @@
+package securecore
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Параметры TOTP (RFC 6238). Используются значения по умолчанию,
+// которые поддерживают все распространенные приложения-аутентификаторы.
+const (
+	TOTPPeriod     = 30 // длительность временного шага в секундах
+	TOTPDigits     = 6
+	TOTPSkew       = 1  // допустимое отклонение в шагах в каждую сторону
+	totpSecretSize = 20 // 160 бит, рекомендуемый размер ключа HMAC-SHA1 (RFC 4226)
+)
+
+// Коды восстановления
+const (
+	RecoveryCodesCount   = 10
+	recoveryCodeLength   = 10 // символов без разделителя
+	recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// ErrInvalidTOTPSecret секрет TOTP имеет неверный формат
+var ErrInvalidTOTPSecret = errors.New("invalid totp secret")
+
+// GenerateTOTPSecret генерирует случайный секрет TOTP в base32 без выравнивания
+func GenerateTOTPSecret() (string, error) {
+	b := make([]byte, totpSecretSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return totpEncoding.EncodeToString(b), nil
+}
+
+// TOTPURI формирует URI otpauth://totp/ для добавления секрета в приложение-аутентификатор (QR-код)
+func TOTPURI(issuer, account, secret string) string {
+	label := account
+	if issuer != "" {
+		label = issuer + ":" + account
+	}
+
+	query := url.Values{}
+	query.Set("secret", secret)
+	if issuer != "" {
+		query.Set("issuer", issuer)
+	}
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(TOTPDigits))
+	query.Set("period", fmt.Sprint(TOTPPeriod))
+
+	return "otpauth://totp/" + url.PathEscape(label) + "?" + query.Encode()
+}
+
+// ValidateTOTP проверяет код TOTP на момент now с допуском TOTPSkew шагов.
+// Возвращает номер принятого временного шага: его нужно сохранить,
+// чтобы не принимать тот же код повторно.
+func ValidateTOTP(secret, code string, now time.Time) (int64, bool, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil || len(key) == 0 {
+		return 0, false, ErrInvalidTOTPSecret
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) != TOTPDigits {
+		return 0, false, nil
+	}
+
+	current := now.Unix() / TOTPPeriod
+	for step := current - TOTPSkew; step <= current+TOTPSkew; step++ {
+		if subtle.ConstantTimeCompare([]byte(totpCode(key, step)), []byte(code)) == 1 {
+			return step, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// totpCode вычисляет код HOTP (RFC 4226) для временного шага
+func totpCode(key []byte, step int64) string {
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < TOTPDigits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", TOTPDigits, value%mod)
+}
+
+// GenerateRecoveryCodes генерирует одноразовые коды восстановления вида xxxxx-xxxxx
+func GenerateRecoveryCodes(count int) ([]string, error) {
+	codes := make([]string, 0, count)
+	buf := make([]byte, recoveryCodeLength)
+	for i := 0; i < count; i++ {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, err
+		}
+		code := make([]byte, recoveryCodeLength)
+		for j, b := range buf {
+			// 256 % 31 дает пренебрежимо малое смещение распределения для одноразовых кодов
+			code[j] = recoveryCodeAlphabet[int(b)%len(recoveryCodeAlphabet)]
+		}
+		half := recoveryCodeLength / 2
+		codes = append(codes, string(code[:half])+"-"+string(code[half:]))
+	}
+	return codes, nil
+}
+
+// HashRecoveryCode возвращает хеш кода восстановления.
+// Регистр, пробелы и разделители при вводе не учитываются.
+func HashRecoveryCode(code string) string {
+	normalized := strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(strings.TrimSpace(code)))
+	return HashToken(normalized)
+}
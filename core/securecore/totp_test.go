@@ -0,0 +1,121 @@
+package securecore
+
+import (
+	"encoding/base32"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret ключ из тестовых векторов RFC 6238 (SHA1) в base32
+var rfc6238Secret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestTOTPCodeRFC6238(t *testing.T) {
+	// Векторы RFC 6238, приложение B: восьмизначные значения, сокращенные до шести младших цифр
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	key := []byte("12345678901234567890")
+	for _, tt := range tests {
+		if got := totpCode(key, tt.unix/TOTPPeriod); got != tt.want {
+			t.Errorf("totpCode(T=%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	current := now.Unix() / TOTPPeriod
+	key := []byte("12345678901234567890")
+	codeAt := func(step int64) string { return totpCode(key, step) }
+
+	tests := []struct {
+		name     string
+		secret   string
+		code     string
+		wantOK   bool
+		wantStep int64
+		wantErr  error
+	}{
+		{"current step", rfc6238Secret, codeAt(current), true, current, nil},
+		{"previous step within skew", rfc6238Secret, codeAt(current - 1), true, current - 1, nil},
+		{"next step within skew", rfc6238Secret, codeAt(current + 1), true, current + 1, nil},
+		{"outside skew in the past", rfc6238Secret, codeAt(current - 2), false, 0, nil},
+		{"outside skew in the future", rfc6238Secret, codeAt(current + 2), false, 0, nil},
+		{"surrounding spaces", rfc6238Secret, " " + codeAt(current) + " ", true, current, nil},
+		{"lowercase padded secret", strings.ToLower(rfc6238Secret) + "====", codeAt(current), true, current, nil},
+		{"wrong length", rfc6238Secret, codeAt(current)[:5], false, 0, nil},
+		{"invalid secret", "not base32!", codeAt(current), false, 0, ErrInvalidTOTPSecret},
+		{"empty secret", "", codeAt(current), false, 0, ErrInvalidTOTPSecret},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok, err := ValidateTOTP(tt.secret, tt.code, now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ValidateTOTP() error = %v, want %v", err, tt.wantErr)
+			}
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Fatalf("ValidateTOTP() = %d, %v, want %d, %v", step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+// Один и тот же код внутри окна допуска указывает на один и тот же шаг,
+// поэтому сохраненный шаг отсекает его повторный ввод на соседних шагах
+func TestValidateTOTPStepIsStableAcrossWindow(t *testing.T) {
+	key := []byte("12345678901234567890")
+	issued := time.Unix(1234567890, 0)
+	step := issued.Unix() / TOTPPeriod
+	code := totpCode(key, step)
+
+	for _, offset := range []time.Duration{-TOTPPeriod * time.Second, 0, TOTPPeriod * time.Second} {
+		got, ok, err := ValidateTOTP(rfc6238Secret, code, issued.Add(offset))
+		if err != nil || !ok {
+			t.Fatalf("ValidateTOTP(offset %v) = %v, %v", offset, ok, err)
+		}
+		if got != step {
+			t.Fatalf("ValidateTOTP(offset %v) step = %d, want %d", offset, got, step)
+		}
+	}
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret() error = %v", err)
+	}
+	code := totpCode(mustDecodeTOTPSecret(t, secret), time.Now().Unix()/TOTPPeriod)
+	if _, ok, err := ValidateTOTP(secret, code, time.Now()); err != nil || !ok {
+		t.Fatalf("ValidateTOTP(generated secret) = %v, %v", ok, err)
+	}
+}
+
+func TestHashRecoveryCodeNormalization(t *testing.T) {
+	want := HashRecoveryCode("abcde-fghjk")
+	for _, input := range []string{"ABCDE-FGHJK", "abcdefghjk", " abcde fghjk "} {
+		if got := HashRecoveryCode(input); got != want {
+			t.Errorf("HashRecoveryCode(%q) differs from canonical form", input)
+		}
+	}
+}
+
+func mustDecodeTOTPSecret(t *testing.T, secret string) []byte {
+	t.Helper()
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil {
+		t.Fatalf("decode secret: %v", err)
+	}
+	return key
+}
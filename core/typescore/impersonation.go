@@ -0,0 +1,16 @@
+package typescore
+
+import "time"
+
+// Impersonation - запись журнала имперсонации: сотрудник поддержки или администратор (actor)
+// получил токен для работы от имени пользователя. Идентификатор совпадает с jti выданного токена.
+type Impersonation struct {
+	ID        *string    `gorm:"type:uuid;primaryKey;column:id" ignore_update_db:"true" json:"id" db:"id" mapstructure:"id"` // Идентификатор (jti токена)
+	ActorID   *string    `gorm:"type:uuid;index;not null;column:actor_id" json:"actor_id" db:"actor_id"`                     // Сотрудник, получивший токен
+	UserID    *string    `gorm:"type:uuid;index;not null;column:user_id" json:"user_id" db:"user_id" mapstructure:"user_id"` // Пользователь, от имени которого выдан токен
+	Reason    *string    `gorm:"type:text;not null;column:reason" json:"reason" db:"reason"`                                 // Причина (номер обращения и т.п.)
+	Scopes    *string    `gorm:"type:text;not null;column:scopes" json:"scopes" db:"scopes"`                                 // Области доступа токена
+	ClientIP  *string    `gorm:"type:varchar(45);column:client_ip" json:"client_ip" db:"client_ip"`                          // IP-адрес сотрудника
+	ExpiresAt *time.Time `gorm:"not null;column:expires_at" json:"expires_at" db:"expires_at"`                               // Срок действия токена
+	CreatedAt *time.Time `gorm:"default:CURRENT_TIMESTAMP;column:created_at" json:"created_at" db:"created_at"`              // Дата и время выдачи
+}
@@ -11,14 +11,20 @@ type ParamsSendMail struct {
 type NotifyCategory string
 
 const (
-	InfoNotifyCategory      NotifyCategory = "info"   // Информационное уведомление(от админа)
-	DeviceNewNotifyCategory NotifyCategory = "ip_new" // Новый IP
+	InfoNotifyCategory          NotifyCategory = "info"           // Информационное уведомление(от админа)
+	DeviceNewNotifyCategory     NotifyCategory = "ip_new"         // Новый IP
+	EmailVerifyNotifyCategory   NotifyCategory = "email_verify"   // Код подтверждения email
+	PasswordResetNotifyCategory NotifyCategory = "password_reset" // Ссылка сброса пароля
+	MagicLinkNotifyCategory     NotifyCategory = "magic_link"     // Ссылка входа без пароля
+	AccountLockedNotifyCategory NotifyCategory = "account_locked" // Вход временно заблокирован после неудачных попыток
+	ImpersonationNotifyCategory NotifyCategory = "impersonation"  // Сотрудник поддержки получил доступ к аккаунту
 )
 
 type NotifyParams struct {
 	Text         *string         // Текст уведомления
 	Title        *string         // Заголовок уведомления
 	ImageURLPath *string         // Ссылка на картинку в уведомлении
+	LinkURL      *string         // Ссылка для перехода из уведомления
 	UsersIDs     []*string       // Список идентификаторов пользователей
 	Category     *NotifyCategory // Категория уведомления
 
@@ -0,0 +1,37 @@
+package typescore
+
+import "time"
+
+// Типы разрешений OAuth 2.0 (RFC 6749), доступные клиентам
+const (
+	GrantTypeAuthorizationCode = "authorization_code"
+	GrantTypeRefreshToken      = "refresh_token"
+	GrantTypeClientCredentials = "client_credentials"
+)
+
+// OAuthClient - клиентское приложение OAuth 2.0 (мобильное приложение, интеграция партнера)
+// или сервисный аккаунт (только grant client_credentials).
+// Конфиденциальный клиент аутентифицируется секретом или ключом; без секрета и ключа клиент публичный.
+// Списки хранятся через пробел, как параметр scope в OAuth 2.0.
+type OAuthClient struct {
+	ID               *string    `gorm:"type:uuid;primaryKey;column:id" ignore_update_db:"true" json:"id" db:"id" mapstructure:"id"`                      // Идентификатор записи
+	ClientID         *string    `gorm:"type:varchar(64);uniqueIndex;not null;column:client_id" json:"client_id" db:"client_id" mapstructure:"client_id"` // Публичный идентификатор клиента
+	ClientSecretHash *string    `gorm:"type:varchar(64);column:client_secret_hash" json:"-" db:"client_secret_hash"`                                     // SHA-256 секрета клиента
+	PublicKey        *string    `gorm:"type:text;column:public_key" json:"public_key,omitempty" db:"public_key"`                                         // PEM публичного ключа для client_assertion (private_key_jwt) вместо секрета
+	Name             *string    `gorm:"type:varchar(100);not null;column:name" json:"name" db:"name"`                                                    // Название, отображаемое на экране согласия
+	RedirectURIs     *string    `gorm:"type:text;column:redirect_uris" json:"redirect_uris" db:"redirect_uris"`                                          // Разрешенные адреса возврата (точное совпадение)
+	Scopes           *string    `gorm:"type:text;column:scopes" json:"scopes" db:"scopes"`                                                               // Области доступа, которые может запрашивать клиент
+	GrantTypes       *string    `gorm:"type:varchar(255);column:grant_types" json:"grant_types" db:"grant_types"`                                        // Разрешенные типы grant
+	IsDisabled       *bool      `gorm:"default:false;column:is_disabled" json:"is_disabled" db:"is_disabled"`                                            // Клиент отключен
+	CreatedAt        *time.Time `gorm:"default:CURRENT_TIMESTAMP;column:created_at" json:"created_at" db:"created_at"`                                   // Дата и время регистрации
+}
+
+// OAuthConsent - согласие пользователя на доступ клиента к областям доступа
+type OAuthConsent struct {
+	ID        *string    `gorm:"type:uuid;primaryKey;column:id" ignore_update_db:"true" json:"id" db:"id" mapstructure:"id"`                                                     // Идентификатор записи
+	UserID    *string    `gorm:"type:uuid;not null;uniqueIndex:idx_oauth_consents_user_client;column:user_id" json:"user_id" db:"user_id" mapstructure:"user_id"`                // Системный идентификатор пользователя
+	ClientID  *string    `gorm:"type:varchar(64);not null;uniqueIndex:idx_oauth_consents_user_client;column:client_id" json:"client_id" db:"client_id" mapstructure:"client_id"` // Идентификатор клиента
+	Scopes    *string    `gorm:"type:text;column:scopes" json:"scopes" db:"scopes"`                                                                                              // Разрешенные области доступа через пробел
+	CreatedAt *time.Time `gorm:"default:CURRENT_TIMESTAMP;column:created_at" json:"created_at" db:"created_at"`                                                                  // Дата и время первого согласия
+	UpdatedAt *time.Time `gorm:"column:updated_at" json:"updated_at,omitempty" db:"updated_at"`                                                                                  // Дата и время последнего изменения
+}
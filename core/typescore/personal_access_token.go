@@ -0,0 +1,17 @@
+package typescore
+
+import "time"
+
+// PersonalAccessToken - персональный токен доступа пользователя для скриптов и интеграций.
+// Сам токен не хранится, только его хеш. Области доступа хранятся через пробел.
+type PersonalAccessToken struct {
+	ID         *string    `gorm:"type:uuid;primaryKey;column:id" ignore_update_db:"true" json:"id" db:"id" mapstructure:"id"` // Идентификатор токена
+	UserID     *string    `gorm:"type:uuid;index;not null;column:user_id" json:"-" db:"user_id" mapstructure:"user_id"`       // Системный идентификатор пользователя
+	Name       *string    `gorm:"type:varchar(100);not null;column:name" json:"name" db:"name"`                               // Название токена, заданное пользователем
+	TokenHash  *string    `gorm:"type:varchar(64);uniqueIndex;not null;column:token_hash" json:"-" db:"token_hash"`           // SHA-256 хеш токена
+	Scopes     *string    `gorm:"type:text;not null;column:scopes" json:"scopes" db:"scopes"`                                 // Области доступа токена
+	ExpiresAt  *time.Time `gorm:"column:expires_at" json:"expires_at,omitempty" db:"expires_at"`                              // Срок действия; пусто — бессрочный
+	LastUsedAt *time.Time `gorm:"column:last_used_at" json:"last_used_at,omitempty" db:"last_used_at"`                        // Дата и время последнего использования
+	LastUsedIP *string    `gorm:"type:varchar(45);column:last_used_ip" json:"last_used_ip,omitempty" db:"last_used_ip"`       // IP-адрес последнего использования
+	CreatedAt  *time.Time `gorm:"default:CURRENT_TIMESTAMP;column:created_at" json:"created_at" db:"created_at"`              // Дата и время создания
+}
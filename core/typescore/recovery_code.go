@@ -0,0 +1,13 @@
+package typescore
+
+import "time"
+
+// RecoveryCode - одноразовый код восстановления для входа без TOTP.
+// Сам код не хранится, только его хеш.
+type RecoveryCode struct {
+	ID        *string    `gorm:"type:uuid;primaryKey;column:id" ignore_update_db:"true" json:"id" db:"id" mapstructure:"id"` // Идентификатор кода
+	UserID    *string    `gorm:"type:uuid;index;not null;column:user_id" json:"user_id" db:"user_id" mapstructure:"user_id"` // Системный идентификатор пользователя
+	CodeHash  *string    `gorm:"type:varchar(64);not null;column:code_hash" json:"-" db:"code_hash"`                         // SHA-256 хеш кода
+	UsedAt    *time.Time `gorm:"column:used_at" json:"used_at,omitempty" db:"used_at"`                                       // Дата и время использования кода
+	CreatedAt *time.Time `gorm:"default:CURRENT_TIMESTAMP;column:created_at" json:"created_at" db:"created_at"`              // Дата и время создания записи
+}
@@ -0,0 +1,19 @@
+package typescore
+
+import "time"
+
+// RefreshToken - структура для хранения выданных refresh токенов.
+// Сам токен не хранится, только его хеш.
+type RefreshToken struct {
+	JTI        *string    `gorm:"type:uuid;primaryKey;column:jti" ignore_update_db:"true" json:"jti" db:"jti" mapstructure:"jti"`     // Идентификатор токена (claim jti)
+	FamilyID   *string    `gorm:"type:uuid;index;not null;column:family_id" json:"family_id" db:"family_id" mapstructure:"family_id"` // Идентификатор семейства токенов (цепочка ротаций)
+	UserID     *string    `gorm:"type:uuid;index;not null;column:user_id" json:"user_id" db:"user_id" mapstructure:"user_id"`         // Системный идентификатор пользователя
+	SessionID  *string    `gorm:"type:uuid;index;column:session_id" json:"session_id,omitempty" db:"session_id"`                      // Сессия, к которой относится семейство токенов
+	TokenHash  *string    `gorm:"type:varchar(64);uniqueIndex;not null;column:token_hash" json:"-" db:"token_hash"`                   // SHA-256 хеш токена
+	ClientIP   *string    `gorm:"type:varchar(45);column:client_ip" json:"client_ip" db:"client_ip"`                                  // IP-адрес клиента на момент выдачи
+	ReplacedBy *string    `gorm:"type:uuid;column:replaced_by" json:"replaced_by,omitempty" db:"replaced_by"`                         // jti токена, выданного взамен при ротации
+	ExpiresAt  *time.Time `gorm:"not null;column:expires_at" json:"expires_at" db:"expires_at"`                                       // Дата и время истечения токена
+	UsedAt     *time.Time `gorm:"column:used_at" json:"used_at,omitempty" db:"used_at"`                                               // Дата и время использования (ротации) токена
+	RevokedAt  *time.Time `gorm:"column:revoked_at" json:"revoked_at,omitempty" db:"revoked_at"`                                      // Дата и время отзыва токена
+	CreatedAt  *time.Time `gorm:"default:CURRENT_TIMESTAMP;column:created_at" json:"created_at" db:"created_at"`                      // Дата и время создания записи
+}
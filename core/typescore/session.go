@@ -0,0 +1,16 @@
+package typescore
+
+import "time"
+
+// Session - сессия пользователя на устройстве.
+// Каждая выдача токенов открывает сессию, к которой привязано семейство refresh токенов.
+type Session struct {
+	ID         *string    `gorm:"type:uuid;primaryKey;column:id" ignore_update_db:"true" json:"id" db:"id" mapstructure:"id"` // Идентификатор сессии (claim sid)
+	UserID     *string    `gorm:"type:uuid;index;not null;column:user_id" json:"user_id" db:"user_id" mapstructure:"user_id"` // Системный идентификатор пользователя
+	DeviceName *string    `gorm:"type:varchar(255);column:device_name" json:"device_name,omitempty" db:"device_name"`         // Название устройства
+	UserAgent  *string    `gorm:"type:text;column:user_agent" json:"user_agent,omitempty" db:"user_agent"`                    // User-Agent клиента
+	IP         *string    `gorm:"type:varchar(45);column:ip" json:"ip" db:"ip"`                                               // Последний IP-адрес клиента
+	CreatedAt  *time.Time `gorm:"default:CURRENT_TIMESTAMP;column:created_at" json:"created_at" db:"created_at"`              // Дата и время создания сессии
+	LastSeenAt *time.Time `gorm:"column:last_seen_at" json:"last_seen_at" db:"last_seen_at"`                                  // Дата и время последнего обновления токенов
+	RevokedAt  *time.Time `gorm:"index;column:revoked_at" json:"revoked_at,omitempty" db:"revoked_at"`                        // Дата и время завершения сессии
+}
@@ -12,14 +12,66 @@ const (
 	SupportRole    UserRoleTypes = "support"     // поддержка
 )
 
+// ScopeTypes - области доступа, выдаваемые в токенах
+type ScopeTypes string
+
+const (
+	ProfileReadScope  ScopeTypes = "profile:read"  // чтение своего профиля
+	ProfileWriteScope ScopeTypes = "profile:write" // изменение своего профиля
+	UsersReadScope    ScopeTypes = "users:read"    // чтение данных других пользователей
+	UsersWriteScope   ScopeTypes = "users:write"   // изменение данных других пользователей
+	AdminScope        ScopeTypes = "admin"         // управление системой
+	EmailVerifyScope  ScopeTypes = "email:verify"  // подтверждение email (выдается только неподтвержденным аккаунтам)
+)
+
+// Области доступа OpenID Connect: запрашиваются клиентами OAuth 2.0
+// и определяют claims ID токена и ответа /oauth/userinfo
+const (
+	OpenIDScope  ScopeTypes = "openid"  // выдача ID токена
+	ProfileScope ScopeTypes = "profile" // имя и никнейм
+	EmailScope   ScopeTypes = "email"   // email и признак его подтверждения
+)
+
+// OIDCScopes - области доступа OpenID Connect, доступные пользователю любой роли
+var OIDCScopes = []ScopeTypes{OpenIDScope, ProfileScope, EmailScope}
+
+// RoleScopes - области доступа, выдаваемые каждой роли
+var RoleScopes = map[UserRoleTypes][]ScopeTypes{
+	UserRole:       {ProfileReadScope, ProfileWriteScope},
+	SupportRole:    {ProfileReadScope, ProfileWriteScope, UsersReadScope},
+	AdminRole:      {ProfileReadScope, ProfileWriteScope, UsersReadScope, UsersWriteScope},
+	SuperAdminRole: {ProfileReadScope, ProfileWriteScope, UsersReadScope, UsersWriteScope, AdminScope},
+}
+
+// UnverifiedEmailScopes - области доступа аккаунта с неподтвержденным email, независимо от роли
+var UnverifiedEmailScopes = []ScopeTypes{ProfileReadScope, EmailVerifyScope}
+
+// UserRoleAndScopes возвращает роль пользователя и её области доступа.
+// Пока email не подтвержден, аккаунт получает ограниченные области доступа
+func UserRoleAndScopes(user *User) (UserRoleTypes, []ScopeTypes) {
+	role := UserRole
+	if user.Role != nil {
+		role = *user.Role
+	}
+	if user.Email != nil && user.EmailVerifiedAt == nil {
+		return role, UnverifiedEmailScopes
+	}
+	return role, RoleScopes[role]
+}
+
 // User - структура для управления пользователями + данные пользователя
 type User struct {
 	SystemID            *string        `gorm:"type:uuid;default:uuid_generate_v4();primaryKey;column:system_id" json:"system_id,omitempty" db:"system_id" mapstructure:"system_id"` // Системный идентификатор записи
 	SerialID            *uint64        `gorm:"type:bigint;index;autoIncrement;unique;column:serial_id" json:"serial_id" db:"serial_id" mapstructure:"serial_id"`                    // Уникальный порядковый идентификатор записи
 	Role                *UserRoleTypes `gorm:"type:varchar(20);index;default:'user';column:role" json:"role" db:"role"`                                                             // Роль пользователя
 	Email               *string        `gorm:"type:varchar(255);index;unique;column:email" json:"email,omitempty" db:"email" mapstructure:"email"`                                  // Адрес электронной почты пользователя
+	EmailVerifiedAt     *time.Time     `gorm:"column:email_verified_at" json:"email_verified_at,omitempty" db:"email_verified_at"`                                                  // Дата и время подтверждения email
 	TelegramID          *int64         `gorm:"unique;index;column:telegram_id" json:"telegram_id" db:"telegram_id"`                                                                 // Идентификатор пользователя в Telegram
 	Nickname            *string        `gorm:"type:varchar(50);index;unique;column:nickname" json:"nickname,omitempty" db:"nickname" mapstructure:"nickname"`                       // Псевдоним или никнейм пользователя
+	PasswordHash        *string        `gorm:"type:varchar(255);column:password_hash" json:"-" db:"password_hash"`                                                                  // Хеш пароля Argon2id в формате PHC (параметры хранятся вместе с хешем)
+	TOTPSecret          *string        `gorm:"type:text;column:totp_secret" json:"-" db:"totp_secret"`                                                                              // Секрет TOTP, зашифрованный AES-GCM
+	TOTPEnabledAt       *time.Time     `gorm:"column:totp_enabled_at" json:"totp_enabled_at,omitempty" db:"totp_enabled_at"`                                                        // Дата и время включения TOTP; пусто — TOTP не подтвержден
+	TOTPLastStep        *int64         `gorm:"column:totp_last_step" json:"-" db:"totp_last_step"`                                                                                  // Последний принятый временной шаг TOTP (защита от повторного использования кода)
 	FirstName           *string        `gorm:"type:varchar(50);column:first_name" json:"first_name,omitempty" db:"first_name"`                                                      // Имя пользователя
 	LastName            *string        `gorm:"type:varchar(50);column:last_name" json:"last_name,omitempty" db:"last_name"`                                                         // Фамилия пользователя
 	NotificationEnabled *bool          `gorm:"default:true;column:notification_enabled" json:"notification_enabled" db:"notification_enabled"`                                      // Включены ли разрешения на push-уведомления
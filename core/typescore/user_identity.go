@@ -0,0 +1,15 @@
+package typescore
+
+import "time"
+
+// UserIdentity - внешняя учетная запись (провайдер OpenID Connect), привязанная к пользователю.
+// Пара provider + subject однозначно определяет учетную запись у провайдера.
+type UserIdentity struct {
+	ID          *string    `gorm:"type:uuid;primaryKey;column:id" ignore_update_db:"true" json:"id" db:"id" mapstructure:"id"`                               // Идентификатор записи
+	UserID      *string    `gorm:"type:uuid;index;not null;column:user_id" json:"user_id" db:"user_id" mapstructure:"user_id"`                               // Системный идентификатор пользователя
+	Provider    *string    `gorm:"type:varchar(64);not null;uniqueIndex:idx_user_identities_provider_subject;column:provider" json:"provider" db:"provider"` // Имя провайдера из конфигурации
+	Subject     *string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_user_identities_provider_subject;column:subject" json:"-" db:"subject"`         // Claim sub ID токена провайдера
+	Email       *string    `gorm:"type:varchar(255);column:email" json:"email,omitempty" db:"email"`                                                         // Email, сообщенный провайдером при последнем входе
+	CreatedAt   *time.Time `gorm:"default:CURRENT_TIMESTAMP;column:created_at" json:"created_at" db:"created_at"`                                            // Дата и время привязки
+	LastLoginAt *time.Time `gorm:"column:last_login_at" json:"last_login_at,omitempty" db:"last_login_at"`                                                   // Дата и время последнего входа через провайдера
+}
@@ -0,0 +1,21 @@
+package typescore
+
+import "time"
+
+// WebAuthnCredential - ключ доступа (passkey) пользователя, зарегистрированный по WebAuthn.
+// Двоичные значения хранятся в base64url без выравнивания.
+type WebAuthnCredential struct {
+	ID              *string    `gorm:"type:uuid;primaryKey;column:id" ignore_update_db:"true" json:"id" db:"id" mapstructure:"id"` // Идентификатор записи
+	UserID          *string    `gorm:"type:uuid;index;not null;column:user_id" json:"user_id" db:"user_id" mapstructure:"user_id"` // Системный идентификатор пользователя
+	CredentialID    *string    `gorm:"type:varchar(1400);uniqueIndex;not null;column:credential_id" json:"-" db:"credential_id"`   // Идентификатор учетных данных аутентификатора
+	PublicKey       *string    `gorm:"type:text;not null;column:public_key" json:"-" db:"public_key"`                              // Открытый ключ в формате COSE
+	AttestationType *string    `gorm:"type:varchar(32);column:attestation_type" json:"-" db:"attestation_type"`                    // Формат аттестации при регистрации
+	Transports      *string    `gorm:"type:varchar(255);column:transports" json:"transports,omitempty" db:"transports"`            // Способы связи с аутентификатором через запятую
+	AAGUID          *string    `gorm:"type:varchar(64);column:aaguid" json:"aaguid,omitempty" db:"aaguid"`                         // Модель аутентификатора
+	SignCount       *int64     `gorm:"default:0;not null;column:sign_count" json:"-" db:"sign_count"`                              // Счетчик подписей аутентификатора
+	BackupEligible  *bool      `gorm:"default:false;column:backup_eligible" json:"backup_eligible" db:"backup_eligible"`           // Ключ может синхронизироваться между устройствами
+	BackupState     *bool      `gorm:"default:false;column:backup_state" json:"backup_state" db:"backup_state"`                    // Ключ синхронизирован
+	Name            *string    `gorm:"type:varchar(100);column:name" json:"name,omitempty" db:"name"`                              // Название ключа, заданное пользователем
+	CreatedAt       *time.Time `gorm:"default:CURRENT_TIMESTAMP;column:created_at" json:"created_at" db:"created_at"`              // Дата и время регистрации
+	LastUsedAt      *time.Time `gorm:"column:last_used_at" json:"last_used_at,omitempty" db:"last_used_at"`                        // Дата и время последнего входа
+}
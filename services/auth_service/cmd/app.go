@@ -7,6 +7,10 @@ import (
 	"authentication_service/core/database"
 	pgxpoolmodule "authentication_service/core/lib/external/pgxpool"
 	rabbitmqlib "authentication_service/core/lib/external/rabbitmq-lib"
+	redismodule "authentication_service/core/lib/external/redis"
+	"authentication_service/core/lib/internally/denylist"
+	"authentication_service/core/lib/internally/kvstore"
+	"authentication_service/core/securecore"
 	"authentication_service/core/variables"
 	"errors"
 	"fmt"
@@ -41,7 +45,12 @@ func main() {
 func getConfig() (*configcore.Config, error) {
 	options := &configcore.ConfigLoadOptions{
 		Database:       true,
+		Redis:          true,
 		RabbitMQConfig: true,
+		Secrets: configcore.SecretsOptions{
+			User:                 true,
+			IntrospectionClients: true,
+		},
 		ExposedServiceConfig: configcore.ExposedServiceOptions{
 			AuthService: true,
 		},
@@ -85,9 +94,52 @@ func initInternalProvider(configObj *configcore.Config) (*typesm.InternalProvide
 		return nil, errors.New("❌ failed to init db pool: nil")
 	}
 
+	keyring, err := securecore.NewKeyring(configObj.Secrets.AuthJWT)
+	if err != nil {
+		logrus.Errorln("❌ Failed to init signing keys: ", err)
+		return nil, err
+	}
+
+	tokenFormat, err := securecore.NewTokenFormat(configObj.ExposedServiceConfig.AuthService, keyring)
+	if err != nil {
+		logrus.Errorln("❌ Failed to init token format: ", err)
+		return nil, err
+	}
+
+	tokenPolicy, err := securecore.NewTokenPolicy(configObj.ExposedServiceConfig.AuthService)
+	if err != nil {
+		logrus.Errorln("❌ Failed to init token policy: ", err)
+		return nil, err
+	}
+
+	// Отзыв токенов должен быть виден rest_user_service, который их проверяет.
+	// Без Redis сервис запускается с хранилищем в памяти процесса, если не задан redis.required
+	redisClient, err := redismodule.ConnectRedis(&redismodule.ConfigConnectRedis{
+		Host:     configObj.Redis.Host,
+		Port:     configObj.Redis.Port,
+		User:     configObj.Redis.User,
+		Password: configObj.Redis.Password,
+	})
+	if err != nil || redisClient == nil {
+		if configObj.Redis.Required {
+			logrus.Errorln("❌ Failed to init Redis client, redis.required is set: ", err)
+			return nil, errors.New("redis is required for the shared token denylist")
+		}
+		logrus.Errorln("❌ Failed to init Redis client, token denylist is process-local: "+
+			"tokens revoked here stay valid in rest_user_service until they expire: ", err)
+		redisClient = nil
+	}
+
+	store := kvstore.NewStore(redisClient)
+
 	return &typesm.InternalProviderControl{
-		Config:   configObj,
-		RabbitMQ: rabbitMQClient,
-		Database: db,
+		Config:        configObj,
+		RabbitMQ:      rabbitMQClient,
+		Database:      db,
+		TokenDenylist: denylist.NewTokenDenylist(store),
+		KVStore:       store,
+		Keyring:       keyring,
+		TokenFormat:   tokenFormat,
+		TokenPolicy:   tokenPolicy,
 	}, nil
 }
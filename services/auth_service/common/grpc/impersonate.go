@@ -0,0 +1,187 @@
+package grpcpayment
+
+import (
+	rabbitmqlib "authentication_service/core/lib/external/rabbitmq-lib"
+	protoobj "authentication_service/core/proto"
+	"authentication_service/core/securecore"
+	"authentication_service/core/typescore"
+	"authentication_service/core/variables"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// время жизни токена имперсонации; refresh токен не выдается
+	impersonationTokenLifeTime = time.Minute * 15
+	// максимальная длина причины имперсонации
+	impersonationReasonMaxLength = 500
+)
+
+// области доступа токена имперсонации: только чтение профиля. Изменяющие маршруты требуют
+// токен сессии (RequireSessionToken), поэтому токен с claim act для них бесполезен
+var impersonationAllowedScopes = []typescore.ScopeTypes{typescore.ProfileReadScope}
+
+// ImpersonateUser выдает сотруднику поддержки или администратору короткоживущий access токен
+// для работы от имени пользователя. Токен содержит claim act (RFC 8693) с идентификатором сотрудника,
+// не привязан к сессии и не продлевается. Выдача сохраняется в журнале, пользователь получает уведомление.
+func (s *AuthServiceServiceProto) ImpersonateUser(ctx context.Context, req *protoobj.ImpersonateUserRequest) (*protoobj.ImpersonateUserResponse, error) {
+	if s.ipc == nil {
+		logrus.Error("module is nil")
+		return nil, errors.New("module is nil")
+	}
+
+	// Проверка входных данных
+	actorID := req.GetActorId()
+	userID := req.GetUserId()
+	clientIP := req.GetClientIp()
+	reason := strings.TrimSpace(req.GetReason())
+	if actorID == "" || userID == "" || clientIP == "" {
+		logrus.Error("invalid input: actor_id, user_id or client_ip is empty")
+		return nil, status.Error(codes.InvalidArgument, "actor_id, user_id and client_ip are required")
+	}
+	if reason == "" || utf8.RuneCountInString(reason) > impersonationReasonMaxLength {
+		return nil, status.Errorf(codes.InvalidArgument, "reason is required (up to %d characters)", impersonationReasonMaxLength)
+	}
+	if actorID == userID {
+		return nil, status.Error(codes.InvalidArgument, "cannot impersonate yourself")
+	}
+
+	// Права сотрудника проверяются по текущей роли, а не по claims его токена
+	actor, err := s.getTokenUser(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	_, actorScopes := typescore.UserRoleAndScopes(actor)
+	if !slices.Contains(actorScopes, typescore.UsersReadScope) {
+		logrus.Warnf("🎭 impersonation denied: actor_id=%s has no %s scope", actorID, typescore.UsersReadScope)
+		return nil, status.Error(codes.PermissionDenied, "impersonation is not allowed")
+	}
+
+	user, err := s.getTokenUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	userRole, userScopes, err := impersonationTarget(user)
+	if err != nil {
+		logrus.Warnf("🎭 impersonation denied: actor_id=%s user_id=%s role=%s", actorID, userID, userRole)
+		return nil, err
+	}
+
+	scopes, err := impersonationScopes(req.GetScopes(), userScopes)
+	if err != nil {
+		return nil, err
+	}
+
+	impersonationID, errGen := securecore.GenerateUUID()
+	if errGen != nil {
+		logrus.Errorf("failed to generate impersonation id: %v", errGen)
+		return nil, status.Error(codes.Internal, "failed to generate access token")
+	}
+	scope := strings.Join(scopes, " ")
+	accessToken, errGen := securecore.GenerateToken(
+		userID,
+		clientIP,
+		securecore.TokenUseAccess,
+		s.ipc.TokenFormat,
+		s.ipc.TokenPolicy,
+		impersonationTokenLifeTime,
+		jwt.MapClaims{"role": string(userRole), "scope": scope, "jti": impersonationID},
+		securecore.ActClaims(actorID),
+	)
+	if errGen != nil {
+		logrus.Errorf("failed to generate impersonation token: %v", errGen)
+		return nil, status.Error(codes.Internal, "failed to generate access token")
+	}
+
+	// Токен выдается только после сохранения записи в журнале
+	now := time.Now().UTC()
+	expiresAt := now.Add(impersonationTokenLifeTime)
+	_, _, errW := s.ipc.Database.Impersonations.CreateImpersonationDB(ctx, nil, &typescore.Impersonation{
+		ID:        &impersonationID,
+		ActorID:   &actorID,
+		UserID:    &userID,
+		Reason:    &reason,
+		Scopes:    &scope,
+		ClientIP:  &clientIP,
+		ExpiresAt: &expiresAt,
+		CreatedAt: &now,
+	})
+	if errW != nil {
+		logrus.Errorf("failed to save impersonation: %v", errW.Error)
+		return nil, status.Error(codes.Internal, "failed to save impersonation")
+	}
+	logrus.Infof("🎭 impersonation issued: id=%s actor_id=%s user_id=%s scope=%q ip=%s reason=%q",
+		impersonationID, actorID, userID, scope, clientIP, reason)
+
+	s.notifyImpersonation(userID, reason)
+
+	return &protoobj.ImpersonateUserResponse{
+		AccessToken:     accessToken,
+		ExpiresIn:       int64(impersonationTokenLifeTime.Seconds()),
+		Scope:           scope,
+		ImpersonationId: impersonationID,
+	}, nil
+}
+
+// impersonationTarget возвращает роль и области доступа пользователя, от имени которого работает сотрудник.
+// Сотрудники не могут получить доступ к аккаунтам с расширенными правами
+func impersonationTarget(user *typescore.User) (typescore.UserRoleTypes, []typescore.ScopeTypes, error) {
+	userRole, userScopes := typescore.UserRoleAndScopes(user)
+	if userRole != typescore.UserRole {
+		return userRole, nil, status.Error(codes.PermissionDenied, "privileged accounts cannot be impersonated")
+	}
+	return userRole, userScopes, nil
+}
+
+// impersonationScopes проверяет запрошенные области доступа: допускается только чтение профиля
+// в пределах областей пользователя
+func impersonationScopes(requested []string, userScopes []typescore.ScopeTypes) ([]string, error) {
+	if len(requested) == 0 {
+		requested = make([]string, 0, len(impersonationAllowedScopes))
+		for _, scope := range impersonationAllowedScopes {
+			requested = append(requested, string(scope))
+		}
+	}
+
+	scopes := make([]string, 0, len(requested))
+	for _, value := range requested {
+		scope := typescore.ScopeTypes(value)
+		if !slices.Contains(impersonationAllowedScopes, scope) || !slices.Contains(userScopes, scope) {
+			return nil, status.Errorf(codes.InvalidArgument, "scope %q is not allowed for impersonation", value)
+		}
+		if !slices.Contains(scopes, value) {
+			scopes = append(scopes, value)
+		}
+	}
+	return scopes, nil
+}
+
+// notifyImpersonation уведомляет пользователя о доступе сотрудника поддержки к аккаунту
+func (s *AuthServiceServiceProto) notifyImpersonation(userID, reason string) {
+	sendTo := []*string{&userID}
+	category := typescore.ImpersonationNotifyCategory
+	notify := &typescore.NotifyParams{
+		Text:      &reason,
+		IsEmail:   true,
+		Emergency: true,
+		UsersIDs:  sendTo,
+		Category:  &category,
+	}
+
+	err := rabbitmqlib.PublishMessage(s.ipc.RabbitMQ,
+		variables.RabbitMQExchangeNotifications,
+		variables.RabbitMQNotificationsServiceRoute,
+		notify)
+	if err != nil {
+		logrus.Errorf("failed to send notification %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package grpcpayment
+
+import (
+	"authentication_service/core/typescore"
+	"slices"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestImpersonationScopes(t *testing.T) {
+	userScopes := typescore.RoleScopes[typescore.UserRole]
+
+	tests := []struct {
+		name       string
+		requested  []string
+		userScopes []typescore.ScopeTypes
+		want       []string
+		wantErr    bool
+	}{
+		{"default read only", nil, userScopes, []string{"profile:read"}, false},
+		{"explicit read", []string{"profile:read"}, userScopes, []string{"profile:read"}, false},
+		{"duplicates collapsed", []string{"profile:read", "profile:read"}, userScopes, []string{"profile:read"}, false},
+		{"write is not available", []string{"profile:write"}, userScopes, nil, true},
+		{"read and write", []string{"profile:read", "profile:write"}, userScopes, nil, true},
+		{"staff scope", []string{"users:read"}, userScopes, nil, true},
+		{"scope the user does not have", nil, []typescore.ScopeTypes{typescore.EmailVerifyScope}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := impersonationScopes(tt.requested, tt.userScopes)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("impersonationScopes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && status.Code(err) != codes.InvalidArgument {
+				t.Fatalf("impersonationScopes() code = %v, want %v", status.Code(err), codes.InvalidArgument)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("impersonationScopes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImpersonationTarget(t *testing.T) {
+	role := func(r typescore.UserRoleTypes) *typescore.UserRoleTypes { return &r }
+	email := "user@example.com"
+	verifiedAt := time.Now()
+
+	tests := []struct {
+		name    string
+		user    *typescore.User
+		wantErr bool
+	}{
+		{"user", &typescore.User{Role: role(typescore.UserRole)}, false},
+		{"user without role", &typescore.User{}, false},
+		{"unverified user", &typescore.User{Email: &email}, false},
+		{"support", &typescore.User{Role: role(typescore.SupportRole), Email: &email, EmailVerifiedAt: &verifiedAt}, true},
+		{"admin", &typescore.User{Role: role(typescore.AdminRole)}, true},
+		{"super admin", &typescore.User{Role: role(typescore.SuperAdminRole)}, true},
+		// Неподтвержденный email ограничивает области доступа, но не снимает защиту аккаунта сотрудника
+		{"unverified admin", &typescore.User{Role: role(typescore.AdminRole), Email: &email}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, scopes, err := impersonationTarget(tt.user)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("impersonationTarget() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if status.Code(err) != codes.PermissionDenied {
+					t.Fatalf("impersonationTarget() code = %v, want %v", status.Code(err), codes.PermissionDenied)
+				}
+				if scopes != nil {
+					t.Fatalf("impersonationTarget() scopes = %v, want nil", scopes)
+				}
+			}
+		})
+	}
+}
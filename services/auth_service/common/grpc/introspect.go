@@ -0,0 +1,118 @@
+package grpcpayment
+
+import (
+	protoobj "authentication_service/core/proto"
+	"authentication_service/core/securecore"
+	"authentication_service/core/typescore"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// IntrospectToken возвращает состояние токена (RFC 7662).
+// Невалидный, истекший или отозванный токен возвращается с active=false.
+func (s *AuthServiceServiceProto) IntrospectToken(ctx context.Context, req *protoobj.IntrospectTokenRequest) (*protoobj.IntrospectTokenResponse, error) {
+	if s.ipc == nil {
+		logrus.Error("module is nil")
+		return nil, errors.New("module is nil")
+	}
+
+	if !s.checkIntrospectionClient(req.GetClientId(), req.GetClientSecret()) {
+		logrus.Errorf("introspect: invalid client credentials: %s", req.GetClientId())
+		return nil, status.Error(codes.Unauthenticated, "invalid client credentials")
+	}
+
+	token := req.GetToken()
+	if token == "" {
+		logrus.Error("invalid input: token is empty")
+		return nil, status.Error(codes.InvalidArgument, "token is required")
+	}
+
+	inactive := &protoobj.IntrospectTokenResponse{Active: false}
+
+	claims, err := s.parseToken(token, "")
+	if err != nil {
+		return inactive, nil
+	}
+
+	// Промежуточный токен входа не подтверждает аутентификацию
+	if use, _ := claims["token_use"].(string); use == securecore.TokenUseMFAPending {
+		return inactive, nil
+	}
+
+	userID, _ := claims["guid"].(string)
+	jti, _ := claims["jti"].(string)
+	sessionID, _ := claims["sid"].(string)
+	issuedAt, _ := securecore.ClaimTime(claims, "iat")
+	expiresAt, _ := securecore.ClaimTime(claims, "exp")
+
+	revoked, err := s.ipc.TokenDenylist.IsTokenRevoked(ctx, jti, userID, sessionID, issuedAt)
+	if err != nil {
+		logrus.Errorf("failed to check token denylist: %v", err)
+		return nil, status.Error(codes.Internal, "failed to check token")
+	}
+	if revoked {
+		return inactive, nil
+	}
+
+	resp := &protoobj.IntrospectTokenResponse{
+		Active:    true,
+		Sub:       userID,
+		Exp:       expiresAt.Unix(),
+		Iat:       issuedAt.Unix(),
+		TokenType: tokenTypeFromClaims(claims),
+		Sid:       sessionID,
+		Jti:       jti,
+	}
+	resp.Scope, _ = claims["scope"].(string)
+	resp.ClientId, _ = claims["client_id"].(string)
+	resp.ActSub = securecore.ClaimActor(claims)
+	// Токен client_credentials выдан клиенту от его собственного имени
+	if resp.Sub == "" {
+		resp.Sub = resp.ClientId
+	}
+	if role, ok := claims["role"].(string); ok && role != "" {
+		resp.Roles = []string{role}
+	}
+
+	// Refresh токен активен, пока не использован и не отозван в хранилище
+	if resp.TokenType == tokenTypeRefresh {
+		tokens, _, errW := s.ipc.Database.RefreshTokens.GetRefreshTokensListDB(ctx, typescore.ListDbOptions{
+			Filtering: &typescore.RefreshToken{JTI: &jti},
+		})
+		if errW != nil {
+			logrus.Errorf("failed to get refresh token: %v", errW)
+			return nil, status.Error(codes.Internal, "failed to check token")
+		}
+		if len(tokens) == 0 {
+			return inactive, nil
+		}
+		refreshTokenObj := tokens[0]
+		if refreshTokenObj.UsedAt != nil || refreshTokenObj.RevokedAt != nil ||
+			(refreshTokenObj.ExpiresAt != nil && refreshTokenObj.ExpiresAt.Before(time.Now())) {
+			return inactive, nil
+		}
+	}
+
+	return resp, nil
+}
+
+// checkIntrospectionClient проверяет учетные данные клиента интроспекции
+func (s *AuthServiceServiceProto) checkIntrospectionClient(clientID, clientSecret string) bool {
+	if clientID == "" || clientSecret == "" {
+		return false
+	}
+
+	for _, client := range s.ipc.Config.Secrets.IntrospectionClients {
+		if client.ClientID == clientID &&
+			subtle.ConstantTimeCompare([]byte(client.ClientSecret), []byte(clientSecret)) == 1 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package grpcpayment
+
+import (
+	"authentication_service/core/configcore"
+	"authentication_service/core/lib/internally/denylist"
+	"authentication_service/core/lib/internally/kvstore"
+	protoobj "authentication_service/core/proto"
+	"context"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIntrospectTokenClientAuth(t *testing.T) {
+	s, _ := newTokenTestServer(t, testUsers())
+	s.ipc.Config = &configcore.Config{Secrets: configcore.SecretsConfig{
+		IntrospectionClients: []configcore.ClientCredentialsConfig{{ClientID: "gateway", ClientSecret: "gateway-secret"}},
+	}}
+	s.ipc.TokenDenylist = denylist.NewTokenDenylist(kvstore.NewMemoryStore())
+
+	user := testUsers().users[testActiveUser]
+	accessToken, err := s.newAccessToken(user, "", testClientIP)
+	if err != nil {
+		t.Fatalf("newAccessToken() error = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		clientID     string
+		clientSecret string
+		want         codes.Code
+	}{
+		{"valid client", "gateway", "gateway-secret", codes.OK},
+		{"wrong secret", "gateway", "other-secret", codes.Unauthenticated},
+		{"unknown client", "other", "gateway-secret", codes.Unauthenticated},
+		{"empty secret", "gateway", "", codes.Unauthenticated},
+		{"no credentials", "", "", codes.Unauthenticated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := s.IntrospectToken(context.Background(), &protoobj.IntrospectTokenRequest{
+				Token:        accessToken,
+				ClientId:     tt.clientID,
+				ClientSecret: tt.clientSecret,
+			})
+			if status.Code(err) != tt.want {
+				t.Fatalf("IntrospectToken() error = %v, want %v", err, tt.want)
+			}
+			if err != nil {
+				if resp != nil {
+					t.Fatalf("IntrospectToken() = %v, want no token state for unauthenticated client", resp)
+				}
+				return
+			}
+			if !resp.GetActive() || resp.GetSub() != testActiveUser {
+				t.Fatalf("IntrospectToken() = %v, want active token of %s", resp, testActiveUser)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package grpcpayment
+
+import (
+	protoobj "authentication_service/core/proto"
+	"authentication_service/core/securecore"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// время на ввод второго фактора после проверки пароля
+const mfaTokenLifeTime = time.Minute * 5
+
+// IssueMFAToken выдает промежуточный токен входа (token_use=mfa_pending).
+// Токен не дает доступа к API: его принимает только подтверждение второго фактора.
+func (s *AuthServiceServiceProto) IssueMFAToken(ctx context.Context, req *protoobj.IssueMFATokenRequest) (*protoobj.IssueMFATokenResponse, error) {
+	if s.ipc == nil {
+		logrus.Error("module is nil")
+		return nil, errors.New("module is nil")
+	}
+
+	// Проверка входных данных
+	userID := req.GetUserId()
+	clientIP := req.GetClientIp()
+	if userID == "" || clientIP == "" {
+		logrus.Error("invalid input: user_id or client_ip is empty")
+		return nil, status.Error(codes.InvalidArgument, "user_id and client_ip are required")
+	}
+
+	// Заблокированный пользователь не может начать вход
+	if _, err := s.getTokenUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	mfaToken, err := securecore.GenerateToken(
+		userID,
+		clientIP,
+		securecore.TokenUseMFAPending,
+		s.ipc.TokenFormat,
+		s.ipc.TokenPolicy,
+		mfaTokenLifeTime,
+		securecore.AuthClaims(time.Time{}, req.GetAmr()),
+	)
+	if err != nil {
+		logrus.Errorf("failed to generate mfa token: %v", err)
+		return nil, status.Error(codes.Internal, "failed to generate mfa token")
+	}
+
+	return &protoobj.IssueMFATokenResponse{
+		MfaToken:  mfaToken,
+		ExpiresIn: int64(mfaTokenLifeTime.Seconds()),
+	}, nil
+}
@@ -0,0 +1,257 @@
+package grpcpayment
+
+import (
+	protoobj "authentication_service/core/proto"
+	"authentication_service/core/securecore"
+	"authentication_service/core/typescore"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// путь эндпоинта токенов относительно issuer: допустимый aud client_assertion
+	tokenEndpointPath = "/oauth/token"
+
+	clientAssertionKeyPrefix = "oauth:assertion:"
+)
+
+// IssueClientToken выдает access токен клиенту OAuth 2.0 или сервисному аккаунту от его собственного имени (grant client_credentials).
+// Клиент аутентифицируется секретом или client_assertion, подписанным зарегистрированным ключом.
+// Токен не связан с пользователем: claim guid пуст, субъект определяется claim client_id.
+func (s *AuthServiceServiceProto) IssueClientToken(ctx context.Context, req *protoobj.IssueClientTokenRequest) (*protoobj.IssueClientTokenResponse, error) {
+	if s.ipc == nil {
+		logrus.Error("module is nil")
+		return nil, errors.New("module is nil")
+	}
+
+	// Проверка входных данных
+	clientID := req.GetClientId()
+	clientIP := req.GetClientIp()
+	if clientID == "" || clientIP == "" {
+		logrus.Error("invalid input: client_id or client_ip is empty")
+		return nil, status.Error(codes.InvalidArgument, "client_id and client_ip are required")
+	}
+
+	client, err := s.getOAuthClient(ctx, clientID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authenticateClient(ctx, client, req.GetClientSecret(), req.GetClientAssertion()); err != nil {
+		return nil, err
+	}
+	if !hasField(client.GrantTypes, typescore.GrantTypeClientCredentials) {
+		return nil, status.Error(codes.PermissionDenied, "client_credentials grant is not allowed for client")
+	}
+
+	scopes := req.GetScopes()
+	if len(scopes) == 0 {
+		scopes = fields(client.Scopes)
+	}
+	if err := checkClientScopes(client, scopes); err != nil {
+		return nil, err
+	}
+	scope := strings.Join(scopes, " ")
+
+	accessToken, err := securecore.GenerateToken(
+		"",
+		clientIP,
+		securecore.TokenUseAccess,
+		s.ipc.TokenFormat,
+		s.ipc.TokenPolicy,
+		accessTokenLifeTime,
+		jwt.MapClaims{"client_id": clientID, "scope": scope},
+	)
+	if err != nil {
+		logrus.Errorf("failed to generate client access token: %v", err)
+		return nil, status.Error(codes.Internal, "failed to generate access token")
+	}
+
+	return &protoobj.IssueClientTokenResponse{
+		AccessToken: accessToken,
+		ExpiresIn:   int64(accessTokenLifeTime.Seconds()),
+		Scope:       scope,
+	}, nil
+}
+
+// authenticateClient проверяет секрет клиента или client_assertion (RFC 7523).
+// jti client_assertion запоминается до истечения его срока, повторное использование отклоняется.
+// Публичный клиент (без секрета и ключа) аутентифицироваться не может
+func (s *AuthServiceServiceProto) authenticateClient(ctx context.Context, client *typescore.OAuthClient, secret, assertion string) error {
+	clientID := *client.ClientID
+
+	switch {
+	case assertion != "":
+		if client.PublicKey == nil {
+			return status.Error(codes.Unauthenticated, "invalid client credentials")
+		}
+		issuer := strings.TrimSuffix(s.ipc.TokenPolicy.Issuer, "/")
+		if issuer == "" {
+			logrus.Error("client assertion rejected: auth_service.issuer is not configured")
+			return status.Error(codes.Unauthenticated, "invalid client credentials")
+		}
+
+		verified, err := securecore.VerifyClientAssertion(assertion, clientID, *client.PublicKey,
+			[]string{issuer, issuer + tokenEndpointPath}, s.ipc.TokenPolicy.Leeway, time.Now())
+		if err != nil {
+			logrus.Warnf("invalid client assertion: client_id=%s: %v", clientID, err)
+			return status.Error(codes.Unauthenticated, "invalid client credentials")
+		}
+
+		ttl := time.Until(verified.ExpiresAt) + s.ipc.TokenPolicy.Leeway
+		stored, err := s.ipc.KVStore.SetIfAbsent(ctx, clientAssertionKeyPrefix+clientID+":"+verified.JTI, "1", ttl)
+		if err != nil {
+			logrus.Errorf("failed to store client assertion jti: %v", err)
+			return status.Error(codes.Internal, "failed to check client assertion")
+		}
+		if !stored {
+			logrus.Warnf("client assertion replay: client_id=%s jti=%s", clientID, verified.JTI)
+			return status.Error(codes.Unauthenticated, "invalid client credentials")
+		}
+		return nil
+
+	case secret != "":
+		if client.ClientSecretHash == nil ||
+			subtle.ConstantTimeCompare([]byte(securecore.HashToken(secret)), []byte(*client.ClientSecretHash)) != 1 {
+			logrus.Warnf("invalid client secret: client_id=%s", clientID)
+			return status.Error(codes.Unauthenticated, "invalid client credentials")
+		}
+		return nil
+
+	default:
+		return status.Error(codes.Unauthenticated, "client authentication is required")
+	}
+}
+
+// getOAuthClient возвращает активного клиента OAuth 2.0.
+// Неизвестный клиент — NotFound, отключенный — PermissionDenied.
+func (s *AuthServiceServiceProto) getOAuthClient(ctx context.Context, clientID string) (*typescore.OAuthClient, error) {
+	clients, _, errW := s.ipc.Database.OAuthClients.GetOAuthClientsListDB(ctx, typescore.ListDbOptions{
+		Filtering: &typescore.OAuthClient{ClientID: &clientID},
+	})
+	if errW != nil {
+		logrus.Errorf("failed to get oauth client: %v", errW.Error)
+		return nil, status.Error(codes.Internal, "failed to get client")
+	}
+	if len(clients) == 0 {
+		return nil, status.Error(codes.NotFound, "client not found")
+	}
+
+	client := clients[0]
+	if client.IsDisabled != nil && *client.IsDisabled {
+		logrus.Warnf("token issue denied for disabled client: %s", clientID)
+		return nil, status.Error(codes.PermissionDenied, "client is disabled")
+	}
+	return client, nil
+}
+
+// checkClientScopes проверяет, что запрошенные области доступа разрешены клиенту
+func checkClientScopes(client *typescore.OAuthClient, scopes []string) error {
+	for _, scope := range scopes {
+		if !hasField(client.Scopes, scope) {
+			return status.Errorf(codes.InvalidArgument, "scope %q is not allowed for client", scope)
+		}
+	}
+	return nil
+}
+
+// clientClaims возвращает claims токенов, выданных клиенту OAuth 2.0 от имени пользователя:
+// области доступа access токена ограничиваются разрешенными пользователем клиенту
+// (области доступа OpenID Connect доступны пользователю любой роли).
+// Для собственных приложений сервиса (пустой clientID) claims пусты.
+func clientClaims(user *typescore.User, clientID string, scopes []string) jwt.MapClaims {
+	if clientID == "" {
+		return jwt.MapClaims{}
+	}
+
+	userScope, _ := userClaims(user)["scope"].(string)
+	granted := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if hasField(&userScope, scope) || isOIDCScope(scope) {
+			granted = append(granted, scope)
+		}
+	}
+	return jwt.MapClaims{"client_id": clientID, "scope": strings.Join(granted, " ")}
+}
+
+// idToken выпускает ID токен OpenID Connect, если клиенту разрешена область доступа openid.
+// Для остальных токенов возвращается пустая строка
+func (s *AuthServiceServiceProto) idToken(user *typescore.User, client jwt.MapClaims, nonce string, authTime time.Time, amr []string) (string, error) {
+	clientID, _ := client["client_id"].(string)
+	scope, _ := client["scope"].(string)
+	if clientID == "" || !hasField(&scope, string(typescore.OpenIDScope)) {
+		return "", nil
+	}
+
+	idToken, err := securecore.GenerateIDToken(s.ipc.Keyring, s.ipc.TokenPolicy, securecore.IDTokenParams{
+		User:     user,
+		ClientID: clientID,
+		Nonce:    nonce,
+		Scopes:   strings.Fields(scope),
+		TTL:      accessTokenLifeTime,
+		AuthTime: authTime,
+		AMR:      amr,
+	})
+	if err != nil {
+		logrus.Errorf("failed to generate id token: %v", err)
+		if errors.Is(err, securecore.ErrSymmetricSigningKey) {
+			return "", status.Error(codes.FailedPrecondition, "id tokens require an asymmetric signing key")
+		}
+		return "", status.Error(codes.Internal, "failed to generate id token")
+	}
+	return idToken, nil
+}
+
+// isOIDCScope проверяет, что область доступа относится к OpenID Connect
+func isOIDCScope(scope string) bool {
+	for _, oidcScope := range typescore.OIDCScopes {
+		if scope == string(oidcScope) {
+			return true
+		}
+	}
+	return false
+}
+
+// refreshClientClaims возвращает claims refresh токена клиента OAuth 2.0.
+// Разрешенные области доступа сохраняются в токене и применяются при каждом обновлении
+// с учетом текущей роли пользователя.
+func refreshClientClaims(clientID string, scopes []string) jwt.MapClaims {
+	if clientID == "" {
+		return jwt.MapClaims{}
+	}
+	return jwt.MapClaims{"client_id": clientID, "scope": strings.Join(scopes, " ")}
+}
+
+// tokenScope возвращает области доступа access токена пользователя
+func tokenScope(user *typescore.User, client jwt.MapClaims) string {
+	if scope, ok := client["scope"].(string); ok {
+		return scope
+	}
+	scope, _ := userClaims(user)["scope"].(string)
+	return scope
+}
+
+// fields разбирает список, разделенный пробелами
+func fields(list *string) []string {
+	if list == nil {
+		return nil
+	}
+	return strings.Fields(*list)
+}
+
+// hasField проверяет, что значение входит в список, разделенный пробелами
+func hasField(list *string, value string) bool {
+	for _, item := range fields(list) {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -1,17 +1,20 @@
 package grpcpayment
 
 import (
+	dbcore "authentication_service/core/database/db"
 	rabbitmqlib "authentication_service/core/lib/external/rabbitmq-lib"
 	protoobj "authentication_service/core/proto"
 	"authentication_service/core/securecore"
 	"authentication_service/core/typescore"
+	dbutils "authentication_service/core/utilscore/db"
 	"authentication_service/core/variables"
 	"context"
 	"errors"
-	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5"
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"strings"
 	"time"
 )
 
@@ -34,36 +37,108 @@ func (s *AuthServiceServiceProto) IssueTokens(ctx context.Context, req *protoobj
 		return nil, status.Error(codes.InvalidArgument, "user_id and client_ip are required")
 	}
 
+	// Роль и области доступа пользователя попадают в access токен
+	user, err := s.getTokenUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Токены клиента OAuth 2.0 ограничены областями доступа, разрешенными пользователем
+	clientID := req.GetClientId()
+	if clientID == "" && len(req.GetScopes()) > 0 {
+		return nil, status.Error(codes.InvalidArgument, "scopes require client_id")
+	}
+	if clientID != "" {
+		oauthClient, err := s.getOAuthClient(ctx, clientID)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkClientScopes(oauthClient, req.GetScopes()); err != nil {
+			return nil, err
+		}
+	}
+	client := clientClaims(user, clientID, req.GetScopes())
+
+	// Время аутентификации сохраняется при обновлении токенов; для кода авторизации
+	// передается время входа пользователя, давшего согласие
+	now := time.Now().UTC()
+	authTime := now
+	if req.GetAuthTime() > 0 && req.GetAuthTime() <= now.Unix() {
+		authTime = time.Unix(req.GetAuthTime(), 0)
+	}
+	auth := securecore.AuthClaims(authTime, req.GetAmr())
+
+	// Каждая выдача открывает новую сессию на устройстве
+	sessionID, err := securecore.GenerateUUID()
+	if err != nil {
+		logrus.Errorf("failed to generate session id: %v", err)
+		return nil, status.Error(codes.Internal, "failed to create session")
+	}
+	session := &typescore.Session{
+		ID:         &sessionID,
+		UserID:     &userID,
+		IP:         &clientIP,
+		CreatedAt:  &now,
+		LastSeenAt: &now,
+	}
+	if deviceName := req.GetDeviceName(); deviceName != "" {
+		session.DeviceName = &deviceName
+	}
+	if userAgent := req.GetUserAgent(); userAgent != "" {
+		session.UserAgent = &userAgent
+	}
+
 	// Генерация Access токена
-	accessToken, err := securecore.GenerateTokenJWT(
-		userID,
-		clientIP,
-		s.ipc.Config.Secrets.AuthJWT.UserSecret,
-		accessTokenLifeTime, // Время жизни Access токена
-		jwt.SigningMethodHS512,
-	)
+	accessToken, err := s.newAccessToken(user, sessionID, clientIP, client, auth)
 	if err != nil {
 		logrus.Errorf("failed to generate access token: %v", err)
 		return nil, status.Error(codes.Internal, "failed to generate access token")
 	}
 
 	// Генерация Refresh токена
-	refreshToken, err := securecore.GenerateTokenJWT(
-		userID,
-		clientIP,
-		s.ipc.Config.Secrets.AuthJWT.UserSecret,
-		refreshTokenLifeTime,
-		jwt.SigningMethodHS512,
-	)
+	refreshToken, refreshTokenObj, err := s.newRefreshToken(userID, sessionID, clientIP, refreshClientClaims(clientID, req.GetScopes()), auth)
 	if err != nil {
 		logrus.Errorf("failed to generate refresh token: %v", err)
 		return nil, status.Error(codes.Internal, "failed to generate refresh token")
 	}
 
+	// ID токен OpenID Connect для клиента с областью доступа openid
+	idToken, err := s.idToken(user, client, req.GetNonce(), authTime, req.GetAmr())
+	if err != nil {
+		return nil, err
+	}
+
+	// Каждая сессия открывает новое семейство refresh токенов
+	familyID, err := securecore.GenerateUUID()
+	if err != nil {
+		logrus.Errorf("failed to generate token family id: %v", err)
+		return nil, status.Error(codes.Internal, "failed to generate refresh token")
+	}
+	refreshTokenObj.FamilyID = &familyID
+
+	// Сессия и первый refresh токен сохраняются в одной транзакции
+	errW := dbutils.ExecuteTx(ctx, s.ipc.Database.Pool, nil, func(tx pgx.Tx) error {
+		if _, _, errW := s.ipc.Database.Sessions.CreateSessionDB(ctx, tx, session); errW != nil {
+			return errW.Error
+		}
+		if _, _, errW := s.ipc.Database.RefreshTokens.CreateRefreshTokenDB(ctx, tx, refreshTokenObj); errW != nil {
+			return errW.Error
+		}
+		return nil
+	})
+	if errW != nil {
+		logrus.Errorf("failed to store session: %v", errW.Error)
+		return nil, status.Error(codes.Internal, "failed to store refresh token")
+	}
+
 	// Возвращаем ответ
 	return &protoobj.IssueTokensResponse{
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
+		SessionId:    sessionID,
+		ExpiresIn:    int64(accessTokenLifeTime.Seconds()),
+		Scope:        tokenScope(user, client),
+		IdToken:      idToken,
 	}, nil
 }
 
@@ -81,13 +156,8 @@ func (s *AuthServiceServiceProto) RefreshTokens(ctx context.Context, req *protoo
 		return nil, status.Error(codes.InvalidArgument, "refresh_token and client_ip are required")
 	}
 
-	// Проверка Refresh токена
-	_, claims, err := securecore.VerifyToken(
-		refreshToken,
-		s.ipc.Config.Secrets.AuthJWT.UserSecret,
-		clientIP,
-		jwt.SigningMethodHS512,
-	)
+	// Проверка Refresh токена. Привязка к IP проверяется ниже, после определения пользователя
+	claims, err := s.parseToken(refreshToken, securecore.TokenUseRefresh)
 	if err != nil {
 		logrus.Errorf("failed to verify refresh token: %v", err)
 		return nil, status.Error(codes.Unauthenticated, "invalid refresh token")
@@ -100,59 +170,197 @@ func (s *AuthServiceServiceProto) RefreshTokens(ctx context.Context, req *protoo
 		return nil, status.Error(codes.Internal, "failed to extract user_id from token")
 	}
 
-	// Проверка изменения IP-адреса
-	tokenIP, ok := claims["client_ip"].(string)
-	if !ok || tokenIP != clientIP {
-		// Отправка email warning (если требуется)
-		sendTo := []*string{&userID}
-		category := typescore.DeviceNewNotifyCategory
-		notify := &typescore.NotifyParams{
-			IsEmail:   true,
-			Emergency: true,
-			UsersIDs:  sendTo,
-			Category:  &category,
+	// Извлечение идентификатора refresh токена
+	jti, ok := claims["jti"].(string)
+	if !ok || jti == "" {
+		logrus.Error("failed to extract jti from refresh token claims")
+		return nil, status.Error(codes.Unauthenticated, "invalid refresh token")
+	}
+
+	// Refresh токен клиента OAuth 2.0 принимается только от этого клиента,
+	// токен собственного приложения — только без client_id
+	clientID, _ := claims["client_id"].(string)
+	if clientID != req.GetClientId() {
+		logrus.Warnf("refresh token client mismatch: user_id=%s jti=%s", userID, jti)
+		return nil, status.Error(codes.Unauthenticated, "invalid refresh token")
+	}
+	var clientScopes []string
+	if clientID != "" {
+		if _, err := s.getOAuthClient(ctx, clientID); err != nil {
+			return nil, err
 		}
+		scope, _ := claims["scope"].(string)
+		clientScopes = strings.Fields(scope)
+	}
 
-		err := rabbitmqlib.PublishMessage(s.ipc.RabbitMQ,
-			variables.RabbitMQExchangeNotifications,
-			variables.RabbitMQNotificationsServiceRoute,
-			notify)
-		if err != nil {
-			logrus.Errorf("failed to send notification %v", err)
+	// Пользователь перечитывается: изменение роли попадает в новые токены,
+	// а блокировка или удаление завершает все сессии пользователя
+	user, err := s.getTokenUser(ctx, userID)
+	if err != nil {
+		if code := status.Code(err); code == codes.NotFound || code == codes.PermissionDenied {
+			if errW := s.ipc.Database.RefreshTokens.RevokeUserRefreshTokensDB(ctx, nil, userID); errW != nil {
+				logrus.Errorf("failed to revoke user refresh tokens: %v", errW.Error)
+			}
 		}
+		return nil, err
+	}
+
+	// Проверка изменения IP-адреса по политике привязки
+	tokenIP, _ := claims["client_ip"].(string)
+	ipAction := s.ipc.TokenPolicy.IPBinding.Check(tokenIP, clientIP)
+	if ipAction != "" {
+		// Отправка email warning
+		s.notifyNewDevice(userID)
 
-		return nil, status.Error(codes.PermissionDenied, "client IP mismatch")
+		switch ipAction {
+		case securecore.IPMismatchBlock:
+			return nil, status.Error(codes.PermissionDenied, "client IP mismatch")
+		case securecore.IPMismatchStepUp:
+			// Повторная аутентификация с нового адреса привязывает к нему сессию: обновление продолжается
+			sessionID, _ := claims["sid"].(string)
+			if !s.sessionReauthenticatedFrom(ctx, sessionID, clientIP) {
+				return nil, status.Error(codes.FailedPrecondition, "step-up authentication required")
+			}
+		}
 	}
 
-	// Генерация новой пары токенов
-	newAccessToken, err := securecore.GenerateTokenJWT(
-		userID,
-		clientIP,
-		s.ipc.Config.Secrets.AuthJWT.UserSecret,
-		accessTokenLifeTime,
-		jwt.SigningMethodHS512,
-	)
+	// Сессия должна быть активна. Токены, выданные до появления сессий, sid не содержат
+	sessionID, _ := claims["sid"].(string)
+	if sessionID != "" {
+		if err := s.checkSession(ctx, sessionID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Генерация новой пары токенов. Обновление не является аутентификацией:
+	// auth_time и amr переносятся из refresh токена без изменений
+	client := clientClaims(user, clientID, clientScopes)
+	authTime, _ := securecore.ClaimTime(claims, "auth_time")
+	amr := securecore.ClaimAMR(claims)
+	auth := securecore.AuthClaims(authTime, amr)
+	newAccessToken, err := s.newAccessToken(user, sessionID, clientIP, client, auth)
 	if err != nil {
 		logrus.Errorf("failed to generate new access token: %v", err)
 		return nil, status.Error(codes.Internal, "failed to generate new access token")
 	}
 
-	newRefreshToken, err := securecore.GenerateTokenJWT(
-		userID,
-		clientIP,
-		s.ipc.Config.Secrets.AuthJWT.UserSecret,
-		refreshTokenLifeTime,
-		jwt.SigningMethodHS512,
-	)
+	newRefreshToken, newRefreshTokenObj, err := s.newRefreshToken(userID, sessionID, clientIP, refreshClientClaims(clientID, clientScopes), auth)
 	if err != nil {
 		logrus.Errorf("failed to generate new refresh token: %v", err)
 		return nil, status.Error(codes.Internal, "failed to generate new refresh token")
 	}
 
+	// При обновлении ID токен выпускается без nonce (OpenID Connect Core, раздел 12.2)
+	idToken, err := s.idToken(user, client, "", authTime, amr)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ротация: старый токен становится использованным, новый сохраняется в том же семействе
+	_, errW := s.ipc.Database.RefreshTokens.RotateRefreshTokenDB(ctx, jti, securecore.HashToken(refreshToken), newRefreshTokenObj)
+	if errW != nil {
+		switch {
+		case errors.Is(errW.Error, dbcore.ErrRefreshTokenReused):
+			logrus.Warnf("refresh token reuse detected: user_id=%s jti=%s, token family revoked", userID, jti)
+			// Семейство уже отозвано; сессия завершается, чтобы access токены сессии перестали приниматься сразу
+			if sessionID != "" {
+				if err := s.revokeSession(ctx, sessionID); err != nil {
+					logrus.Errorf("failed to revoke session after refresh token reuse: %v", err)
+				}
+			}
+			return nil, status.Error(codes.Unauthenticated, "refresh token reuse detected")
+		case errors.Is(errW.Error, dbcore.ErrRefreshTokenNotFound),
+			errors.Is(errW.Error, dbcore.ErrRefreshTokenRevoked),
+			errors.Is(errW.Error, dbcore.ErrRefreshTokenExpired):
+			return nil, status.Error(codes.Unauthenticated, "invalid refresh token")
+		default:
+			logrus.Errorf("failed to rotate refresh token: %v", errW.Error)
+			return nil, status.Error(codes.Internal, "failed to rotate refresh token")
+		}
+	}
+
+	// Активность сессии обновляется только после успешной ротации. Ошибка не прерывает ответ:
+	// новый refresh токен уже сохранен, а старый при повторе считался бы переиспользованным
+	if sessionID != "" {
+		_ = s.updateSessionActivity(ctx, sessionID, clientIP)
+	}
+
 	// Возвращаем ответ
 	return &protoobj.RefreshTokensResponse{
 		AccessToken:  newAccessToken,
 		RefreshToken: newRefreshToken,
 		IpChanged:    tokenIP != clientIP,
+		ExpiresIn:    int64(accessTokenLifeTime.Seconds()),
+		Scope:        tokenScope(user, client),
+		IdToken:      idToken,
 	}, nil
 }
+
+// touchSession проверяет, что сессия активна и принадлежит пользователю,
+// и обновляет время последней активности и IP-адрес сессии
+func (s *AuthServiceServiceProto) touchSession(ctx context.Context, sessionID, userID, clientIP string) error {
+	if err := s.checkSession(ctx, sessionID, userID); err != nil {
+		return err
+	}
+	return s.updateSessionActivity(ctx, sessionID, clientIP)
+}
+
+// checkSession проверяет, что сессия активна и принадлежит пользователю
+func (s *AuthServiceServiceProto) checkSession(ctx context.Context, sessionID, userID string) error {
+	session, err := s.getSession(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if session == nil || session.RevokedAt != nil || session.UserID == nil || *session.UserID != userID {
+		return status.Error(codes.Unauthenticated, "session is not active")
+	}
+	return nil
+}
+
+// updateSessionActivity обновляет время последней активности и IP-адрес сессии
+func (s *AuthServiceServiceProto) updateSessionActivity(ctx context.Context, sessionID, clientIP string) error {
+	now := time.Now().UTC()
+	_, _, errW := s.ipc.Database.Sessions.UpdateSessionDB(ctx, nil, &typescore.Session{
+		ID:         &sessionID,
+		IP:         &clientIP,
+		LastSeenAt: &now,
+	})
+	if errW != nil {
+		logrus.Errorf("failed to update session: %v", errW.Error)
+		return status.Error(codes.Internal, "failed to update session")
+	}
+	return nil
+}
+
+// sessionReauthenticatedFrom проверяет, что сессия после повторной аутентификации привязана к адресу клиента
+// (IssueStepUpToken обновляет адрес сессии)
+func (s *AuthServiceServiceProto) sessionReauthenticatedFrom(ctx context.Context, sessionID, clientIP string) bool {
+	if sessionID == "" {
+		return false
+	}
+	session, err := s.getSession(ctx, sessionID)
+	if err != nil || session == nil || session.RevokedAt != nil || session.IP == nil {
+		return false
+	}
+	return s.ipc.TokenPolicy.IPBinding.Check(*session.IP, clientIP) == ""
+}
+
+// notifyNewDevice уведомляет пользователя о входе с нового устройства или сети
+func (s *AuthServiceServiceProto) notifyNewDevice(userID string) {
+	sendTo := []*string{&userID}
+	category := typescore.DeviceNewNotifyCategory
+	notify := &typescore.NotifyParams{
+		IsEmail:   true,
+		Emergency: true,
+		UsersIDs:  sendTo,
+		Category:  &category,
+	}
+
+	err := rabbitmqlib.PublishMessage(s.ipc.RabbitMQ,
+		variables.RabbitMQExchangeNotifications,
+		variables.RabbitMQNotificationsServiceRoute,
+		notify)
+	if err != nil {
+		logrus.Errorf("failed to send notification %v", err)
+	}
+}
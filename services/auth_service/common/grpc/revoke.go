@@ -0,0 +1,213 @@
+package grpcpayment
+
+import (
+	protoobj "authentication_service/core/proto"
+	"authentication_service/core/securecore"
+	"authentication_service/core/typescore"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RevokeToken отзывает переданный токен (RFC 7009).
+// Для refresh токена отзывается всё его семейство.
+// Невалидный или уже истекший токен не считается ошибкой.
+func (s *AuthServiceServiceProto) RevokeToken(ctx context.Context, req *protoobj.RevokeTokenRequest) (*protoobj.RevokeTokenResponse, error) {
+	if s.ipc == nil {
+		logrus.Error("module is nil")
+		return nil, errors.New("module is nil")
+	}
+
+	token := req.GetToken()
+	if token == "" {
+		logrus.Error("invalid input: token is empty")
+		return nil, status.Error(codes.InvalidArgument, "token is required")
+	}
+
+	claims, err := s.parseToken(token, "")
+	if err != nil {
+		logrus.Warnf("revoke: token is not valid, nothing to revoke: %v", err)
+		return &protoobj.RevokeTokenResponse{Revoked: false}, nil
+	}
+
+	if err := s.revokeTokenClaims(ctx, claims); err != nil {
+		logrus.Errorf("failed to revoke token: %v", err)
+		return nil, status.Error(codes.Internal, "failed to revoke token")
+	}
+
+	return &protoobj.RevokeTokenResponse{Revoked: true}, nil
+}
+
+// Logout завершает текущую сессию: отзывает access токен, семейство refresh токена и саму сессию
+func (s *AuthServiceServiceProto) Logout(ctx context.Context, req *protoobj.LogoutRequest) (*protoobj.LogoutResponse, error) {
+	if s.ipc == nil {
+		logrus.Error("module is nil")
+		return nil, errors.New("module is nil")
+	}
+
+	accessClaims, userID, err := s.verifyActiveToken(ctx, req.GetAccessToken())
+	if err != nil {
+		return nil, err
+	}
+
+	if refreshToken := req.GetRefreshToken(); refreshToken != "" {
+		refreshClaims, err := s.parseToken(refreshToken, securecore.TokenUseRefresh)
+		if err == nil {
+			// Refresh токен должен принадлежать тому же пользователю
+			if guid, _ := refreshClaims["guid"].(string); guid != userID {
+				logrus.Errorf("logout: refresh token belongs to another user: %s", userID)
+				return nil, status.Error(codes.PermissionDenied, "refresh token does not belong to user")
+			}
+			if err := s.revokeTokenClaims(ctx, refreshClaims); err != nil {
+				logrus.Errorf("failed to revoke refresh token: %v", err)
+				return nil, status.Error(codes.Internal, "failed to revoke refresh token")
+			}
+		}
+	}
+
+	if err := s.revokeTokenClaims(ctx, accessClaims); err != nil {
+		logrus.Errorf("failed to revoke access token: %v", err)
+		return nil, status.Error(codes.Internal, "failed to revoke access token")
+	}
+
+	if sessionID, _ := accessClaims["sid"].(string); sessionID != "" {
+		if err := s.revokeSession(ctx, sessionID); err != nil {
+			logrus.Errorf("failed to revoke session: %v", err)
+			return nil, status.Error(codes.Internal, "failed to revoke session")
+		}
+	}
+
+	return &protoobj.LogoutResponse{Success: true}, nil
+}
+
+// LogoutAll завершает все сессии пользователя: все токены, выданные до текущего момента, становятся недействительными
+func (s *AuthServiceServiceProto) LogoutAll(ctx context.Context, req *protoobj.LogoutAllRequest) (*protoobj.LogoutAllResponse, error) {
+	if s.ipc == nil {
+		logrus.Error("module is nil")
+		return nil, errors.New("module is nil")
+	}
+
+	_, userID, err := s.verifyActiveToken(ctx, req.GetAccessToken())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.revokeAllUserTokens(ctx, userID); err != nil {
+		logrus.Errorf("failed to revoke user tokens: %v", err)
+		return nil, status.Error(codes.Internal, "failed to revoke user tokens")
+	}
+
+	return &protoobj.LogoutAllResponse{Success: true}, nil
+}
+
+// RevokeUserTokens отзывает все токены и сессии пользователя по идентификатору.
+// Вызывается доверенными сервисами после смены или сброса пароля.
+func (s *AuthServiceServiceProto) RevokeUserTokens(ctx context.Context, req *protoobj.RevokeUserTokensRequest) (*protoobj.RevokeUserTokensResponse, error) {
+	if s.ipc == nil {
+		logrus.Error("module is nil")
+		return nil, errors.New("module is nil")
+	}
+
+	userID := req.GetUserId()
+	if userID == "" {
+		logrus.Error("invalid input: user_id is empty")
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	if err := s.revokeAllUserTokens(ctx, userID); err != nil {
+		logrus.Errorf("failed to revoke user tokens: %v", err)
+		return nil, status.Error(codes.Internal, "failed to revoke user tokens")
+	}
+
+	return &protoobj.RevokeUserTokensResponse{Success: true}, nil
+}
+
+// verifyActiveToken проверяет подпись токена и его отсутствие в списке отозванных
+func (s *AuthServiceServiceProto) verifyActiveToken(ctx context.Context, token string) (jwt.MapClaims, string, error) {
+	if token == "" {
+		logrus.Error("invalid input: access_token is empty")
+		return nil, "", status.Error(codes.InvalidArgument, "access_token is required")
+	}
+
+	claims, err := s.parseToken(token, securecore.TokenUseAccess)
+	if err != nil {
+		logrus.Errorf("failed to verify access token: %v", err)
+		return nil, "", status.Error(codes.Unauthenticated, "invalid access token")
+	}
+
+	userID, _ := claims["guid"].(string)
+	if userID == "" {
+		logrus.Error("failed to extract user_id from access token claims")
+		return nil, "", status.Error(codes.Unauthenticated, "invalid access token")
+	}
+
+	jti, _ := claims["jti"].(string)
+	sessionID, _ := claims["sid"].(string)
+	issuedAt, _ := securecore.ClaimTime(claims, "iat")
+	revoked, err := s.ipc.TokenDenylist.IsTokenRevoked(ctx, jti, userID, sessionID, issuedAt)
+	if err != nil {
+		logrus.Errorf("failed to check token denylist: %v", err)
+		return nil, "", status.Error(codes.Internal, "failed to check token")
+	}
+	if revoked {
+		return nil, "", status.Error(codes.Unauthenticated, "token revoked")
+	}
+
+	return claims, userID, nil
+}
+
+// revokeTokenClaims вносит jti токена в список отозванных,
+// а если токен является refresh токеном — отзывает его семейство в базе данных
+func (s *AuthServiceServiceProto) revokeTokenClaims(ctx context.Context, claims jwt.MapClaims) error {
+	jti, _ := claims["jti"].(string)
+	if jti == "" {
+		// Токены без jti выданы до появления отзыва, отозвать их по отдельности нельзя
+		return nil
+	}
+
+	expiresAt, _ := securecore.ClaimTime(claims, "exp")
+	if err := s.ipc.TokenDenylist.RevokeJTI(ctx, jti, expiresAt); err != nil {
+		return err
+	}
+
+	tokens, _, errW := s.ipc.Database.RefreshTokens.GetRefreshTokensListDB(ctx, typescore.ListDbOptions{
+		Filtering: &typescore.RefreshToken{JTI: &jti},
+	})
+	if errW != nil {
+		return errW.Error
+	}
+	if len(tokens) > 0 && tokens[0].FamilyID != nil {
+		if errW := s.ipc.Database.RefreshTokens.RevokeRefreshTokenFamilyDB(ctx, nil, *tokens[0].FamilyID); errW != nil {
+			return errW.Error
+		}
+	}
+
+	return nil
+}
+
+// revokeAllUserTokens отзывает все выданные пользователю токены, включая персональные токены доступа
+func (s *AuthServiceServiceProto) revokeAllUserTokens(ctx context.Context, userID string) error {
+	// Отметка хранится не меньше времени жизни самого долгоживущего токена
+	if err := s.ipc.TokenDenylist.RevokeUserTokensBefore(ctx, userID, time.Now(), refreshTokenLifeTime); err != nil {
+		return err
+	}
+
+	if errW := s.ipc.Database.RefreshTokens.RevokeUserRefreshTokensDB(ctx, nil, userID); errW != nil {
+		return errW.Error
+	}
+
+	if _, errW := s.ipc.Database.Sessions.RevokeUserSessionsDB(ctx, nil, userID, ""); errW != nil {
+		return errW.Error
+	}
+
+	// Персональные токены проверяются по базе, поэтому отметка в denylist их не затрагивает
+	if errW := s.ipc.Database.PersonalTokens.RevokeUserPersonalTokensDB(ctx, nil, userID); errW != nil {
+		return errW.Error
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package grpcpayment
+
+import (
+	protoobj "authentication_service/core/proto"
+	"authentication_service/core/securecore"
+	"authentication_service/core/typescore"
+	"context"
+	"errors"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CheckSession проверяет, активна ли сессия.
+// Неизвестная или завершенная сессия возвращается с active=false.
+func (s *AuthServiceServiceProto) CheckSession(ctx context.Context, req *protoobj.CheckSessionRequest) (*protoobj.CheckSessionResponse, error) {
+	if s.ipc == nil {
+		logrus.Error("module is nil")
+		return nil, errors.New("module is nil")
+	}
+
+	sessionID := req.GetSessionId()
+	if sessionID == "" {
+		logrus.Error("invalid input: session_id is empty")
+		return nil, status.Error(codes.InvalidArgument, "session_id is required")
+	}
+
+	session, err := s.getSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil || session.RevokedAt != nil {
+		return &protoobj.CheckSessionResponse{Active: false}, nil
+	}
+
+	resp := &protoobj.CheckSessionResponse{Active: true}
+	if session.UserID != nil {
+		resp.UserId = *session.UserID
+	}
+	if session.LastSeenAt != nil {
+		resp.LastSeenAt = session.LastSeenAt.Unix()
+	}
+	return resp, nil
+}
+
+// RevokeSession завершает сессию пользователя: отзывает её refresh токены
+// и вносит сессию в список отозванных, чтобы access токены перестали приниматься
+func (s *AuthServiceServiceProto) RevokeSession(ctx context.Context, req *protoobj.RevokeSessionRequest) (*protoobj.RevokeSessionResponse, error) {
+	if s.ipc == nil {
+		logrus.Error("module is nil")
+		return nil, errors.New("module is nil")
+	}
+
+	userID := req.GetUserId()
+	sessionID := req.GetSessionId()
+	if userID == "" || sessionID == "" {
+		logrus.Error("invalid input: user_id or session_id is empty")
+		return nil, status.Error(codes.InvalidArgument, "user_id and session_id are required")
+	}
+
+	session, err := s.getSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	// Чужая сессия не раскрывается: для пользователя она не существует
+	if session == nil || session.UserID == nil || *session.UserID != userID {
+		return nil, status.Error(codes.NotFound, "session not found")
+	}
+	if session.RevokedAt != nil {
+		return &protoobj.RevokeSessionResponse{Revoked: false}, nil
+	}
+
+	if err := s.revokeSession(ctx, sessionID); err != nil {
+		logrus.Errorf("failed to revoke session: %v", err)
+		return nil, status.Error(codes.Internal, "failed to revoke session")
+	}
+
+	return &protoobj.RevokeSessionResponse{Revoked: true}, nil
+}
+
+// RevokeOtherSessions завершает все сессии пользователя, кроме текущей
+func (s *AuthServiceServiceProto) RevokeOtherSessions(ctx context.Context, req *protoobj.RevokeOtherSessionsRequest) (*protoobj.RevokeOtherSessionsResponse, error) {
+	if s.ipc == nil {
+		logrus.Error("module is nil")
+		return nil, errors.New("module is nil")
+	}
+
+	userID := req.GetUserId()
+	currentSessionID := req.GetCurrentSessionId()
+	if userID == "" || currentSessionID == "" {
+		logrus.Error("invalid input: user_id or current_session_id is empty")
+		return nil, status.Error(codes.InvalidArgument, "user_id and current_session_id are required")
+	}
+
+	revokedIDs, err := s.revokeUserSessions(ctx, userID, currentSessionID)
+	if err != nil {
+		logrus.Errorf("failed to revoke user sessions: %v", err)
+		return nil, status.Error(codes.Internal, "failed to revoke sessions")
+	}
+
+	return &protoobj.RevokeOtherSessionsResponse{RevokedCount: int32(len(revokedIDs))}, nil
+}
+
+// getSession возвращает сессию по идентификатору или nil, если сессия не найдена
+func (s *AuthServiceServiceProto) getSession(ctx context.Context, sessionID string) (*typescore.Session, error) {
+	if !securecore.IsValidUUID(sessionID) {
+		return nil, nil
+	}
+
+	sessions, _, errW := s.ipc.Database.Sessions.GetSessionsListDB(ctx, typescore.ListDbOptions{
+		Filtering: &typescore.Session{ID: &sessionID},
+	})
+	if errW != nil {
+		logrus.Errorf("failed to get session: %v", errW.Error)
+		return nil, status.Error(codes.Internal, "failed to get session")
+	}
+	if len(sessions) == 0 {
+		return nil, nil
+	}
+	return sessions[0], nil
+}
+
+// revokeSession завершает сессию в базе данных и в списке отозванных
+func (s *AuthServiceServiceProto) revokeSession(ctx context.Context, sessionID string) error {
+	if errW := s.ipc.Database.Sessions.RevokeSessionDB(ctx, nil, sessionID); errW != nil {
+		return errW.Error
+	}
+	// Отметка хранится не меньше времени жизни самого долгоживущего токена
+	return s.ipc.TokenDenylist.RevokeSession(ctx, sessionID, refreshTokenLifeTime)
+}
+
+// revokeUserSessions завершает сессии пользователя, кроме exceptSessionID (если указан)
+func (s *AuthServiceServiceProto) revokeUserSessions(ctx context.Context, userID, exceptSessionID string) ([]string, error) {
+	revokedIDs, errW := s.ipc.Database.Sessions.RevokeUserSessionsDB(ctx, nil, userID, exceptSessionID)
+	if errW != nil {
+		return nil, errW.Error
+	}
+
+	for _, sessionID := range revokedIDs {
+		if err := s.ipc.TokenDenylist.RevokeSession(ctx, sessionID, refreshTokenLifeTime); err != nil {
+			return nil, err
+		}
+	}
+	return revokedIDs, nil
+}
@@ -0,0 +1,94 @@
+package grpcpayment
+
+import (
+	dbcore "authentication_service/core/database/db"
+	errm "authentication_service/core/errmodule"
+	"authentication_service/core/lib/internally/denylist"
+	"authentication_service/core/lib/internally/kvstore"
+	protoobj "authentication_service/core/proto"
+	"authentication_service/core/typescore"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeSessionDB сессии по идентификатору; учитывает завершенные сессии
+type fakeSessionDB struct {
+	dbcore.SessionDBI
+	sessions map[string]*typescore.Session
+	revoked  []string
+}
+
+func (f *fakeSessionDB) GetSessionsListDB(_ context.Context, options ...typescore.ListDbOptions) ([]*typescore.Session, uint64, *errm.Error) {
+	filter := options[0].Filtering.(*typescore.Session)
+	if session, ok := f.sessions[*filter.ID]; ok {
+		return []*typescore.Session{session}, 1, nil
+	}
+	return nil, 0, nil
+}
+
+func (f *fakeSessionDB) RevokeSessionDB(_ context.Context, _ pgx.Tx, sessionID string) *errm.Error {
+	f.revoked = append(f.revoked, sessionID)
+	return nil
+}
+
+func TestRevokeSessionOwnership(t *testing.T) {
+	const (
+		ownSession     = "7f3e2a10-5c4b-4d6e-8f90-1a2b3c4d5e01"
+		foreignSession = "7f3e2a10-5c4b-4d6e-8f90-1a2b3c4d5e02"
+		endedSession   = "7f3e2a10-5c4b-4d6e-8f90-1a2b3c4d5e03"
+		unknownSession = "7f3e2a10-5c4b-4d6e-8f90-1a2b3c4d5e04"
+	)
+	owner, other := testActiveUser, testBlockedUser
+	endedAt := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name        string
+		sessionID   string
+		want        codes.Code
+		wantRevoked bool
+	}{
+		{"own session", ownSession, codes.OK, true},
+		{"session of another user", foreignSession, codes.NotFound, false},
+		{"unknown session", unknownSession, codes.NotFound, false},
+		{"invalid session id", "not-a-uuid", codes.NotFound, false},
+		{"already ended", endedSession, codes.OK, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTokenTestServer(t, testUsers())
+			sessions := &fakeSessionDB{sessions: map[string]*typescore.Session{
+				ownSession:     {UserID: &owner},
+				foreignSession: {UserID: &other},
+				endedSession:   {UserID: &owner, RevokedAt: &endedAt},
+			}}
+			s.ipc.Database.Sessions = sessions
+			s.ipc.TokenDenylist = denylist.NewTokenDenylist(kvstore.NewMemoryStore())
+
+			resp, err := s.RevokeSession(context.Background(), &protoobj.RevokeSessionRequest{UserId: owner, SessionId: tt.sessionID})
+			if status.Code(err) != tt.want {
+				t.Fatalf("RevokeSession() error = %v, want %v", err, tt.want)
+			}
+			if err == nil && resp.GetRevoked() != tt.wantRevoked {
+				t.Fatalf("RevokeSession() revoked = %v, want %v", resp.GetRevoked(), tt.wantRevoked)
+			}
+			if got := len(sessions.revoked) > 0; got != tt.wantRevoked {
+				t.Fatalf("sessions revoked in database = %v, want revoked %v", sessions.revoked, tt.wantRevoked)
+			}
+
+			// Завершенная сессия попадает в список отозванных: ее access токены больше не принимаются
+			revoked, err := s.ipc.TokenDenylist.IsTokenRevoked(context.Background(), "", owner, tt.sessionID, time.Now())
+			if err != nil {
+				t.Fatalf("IsTokenRevoked() error = %v", err)
+			}
+			if revoked != tt.wantRevoked {
+				t.Fatalf("session in denylist = %v, want %v", revoked, tt.wantRevoked)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package grpcpayment
+
+import (
+	protoobj "authentication_service/core/proto"
+	"authentication_service/core/securecore"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// время жизни access токена после повторной аутентификации
+const stepUpTokenLifeTime = time.Minute * 5
+
+// IssueStepUpToken выдает короткоживущий access токен с обновленными auth_time и amr
+// после повторной проверки фактора в активной сессии. Refresh токен сессии не меняется:
+// после истечения токена сессия продолжается с исходным временем аутентификации.
+func (s *AuthServiceServiceProto) IssueStepUpToken(ctx context.Context, req *protoobj.IssueStepUpTokenRequest) (*protoobj.IssueStepUpTokenResponse, error) {
+	if s.ipc == nil {
+		logrus.Error("module is nil")
+		return nil, errors.New("module is nil")
+	}
+
+	// Проверка входных данных
+	userID := req.GetUserId()
+	sessionID := req.GetSessionId()
+	clientIP := req.GetClientIp()
+	if userID == "" || sessionID == "" || clientIP == "" {
+		logrus.Error("invalid input: user_id, session_id or client_ip is empty")
+		return nil, status.Error(codes.InvalidArgument, "user_id, session_id and client_ip are required")
+	}
+
+	user, err := s.getTokenUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	// Повторная аутентификация возможна только в активной сессии пользователя
+	if err := s.touchSession(ctx, sessionID, userID, clientIP); err != nil {
+		return nil, err
+	}
+
+	authTime := time.Now().UTC()
+	accessToken, err := securecore.GenerateToken(
+		userID,
+		clientIP,
+		securecore.TokenUseAccess,
+		s.ipc.TokenFormat,
+		s.ipc.TokenPolicy,
+		stepUpTokenLifeTime,
+		userClaims(user),
+		sessionClaims(sessionID),
+		securecore.AuthClaims(authTime, req.GetAmr()),
+	)
+	if err != nil {
+		logrus.Errorf("failed to generate step-up token: %v", err)
+		return nil, status.Error(codes.Internal, "failed to generate access token")
+	}
+
+	return &protoobj.IssueStepUpTokenResponse{
+		AccessToken: accessToken,
+		ExpiresIn:   int64(stepUpTokenLifeTime.Seconds()),
+		AuthTime:    authTime.Unix(),
+	}, nil
+}
@@ -0,0 +1,135 @@
+package grpcpayment
+
+import (
+	"authentication_service/core/securecore"
+	"authentication_service/core/typescore"
+	"context"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Типы токенов (RFC 7662, token_type_hint)
+const (
+	tokenTypeAccess  = "access_token"
+	tokenTypeRefresh = "refresh_token"
+)
+
+// newAccessToken генерирует access токен с ролью и областями доступа пользователя
+// и идентификатором сессии (jti генерируется автоматически)
+func (s *AuthServiceServiceProto) newAccessToken(user *typescore.User, sessionID, clientIP string, extraClaims ...jwt.MapClaims) (string, error) {
+	return securecore.GenerateToken(
+		*user.SystemID,
+		clientIP,
+		securecore.TokenUseAccess,
+		s.ipc.TokenFormat,
+		s.ipc.TokenPolicy,
+		accessTokenLifeTime,
+		append([]jwt.MapClaims{userClaims(user), sessionClaims(sessionID)}, extraClaims...)...,
+	)
+}
+
+// newRefreshToken генерирует refresh токен с уникальным jti и запись для его хранения.
+// FamilyID записи заполняется вызывающей стороной (или при ротации).
+func (s *AuthServiceServiceProto) newRefreshToken(userID, sessionID, clientIP string, extraClaims ...jwt.MapClaims) (string, *typescore.RefreshToken, error) {
+	jti, err := securecore.GenerateUUID()
+	if err != nil {
+		return "", nil, err
+	}
+
+	refreshToken, err := securecore.GenerateToken(
+		userID,
+		clientIP,
+		securecore.TokenUseRefresh,
+		s.ipc.TokenFormat,
+		s.ipc.TokenPolicy,
+		refreshTokenLifeTime,
+		append([]jwt.MapClaims{{"jti": jti}, sessionClaims(sessionID)}, extraClaims...)...,
+	)
+	if err != nil {
+		return "", nil, err
+	}
+
+	tokenHash := securecore.HashToken(refreshToken)
+	expiresAt := time.Now().Add(refreshTokenLifeTime).UTC()
+
+	refreshTokenObj := &typescore.RefreshToken{
+		JTI:       &jti,
+		UserID:    &userID,
+		TokenHash: &tokenHash,
+		ClientIP:  &clientIP,
+		ExpiresAt: &expiresAt,
+	}
+	if sessionID != "" {
+		refreshTokenObj.SessionID = &sessionID
+	}
+	return refreshToken, refreshTokenObj, nil
+}
+
+// getTokenUser возвращает пользователя, для которого выпускаются токены.
+// Неизвестный пользователь — NotFound, заблокированный — PermissionDenied.
+func (s *AuthServiceServiceProto) getTokenUser(ctx context.Context, userID string) (*typescore.User, error) {
+	if !securecore.IsValidUUID(userID) {
+		logrus.Errorf("invalid user_id: %s", userID)
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+
+	users, _, errW := s.ipc.Database.Users.GetUsersListDB(ctx, typescore.ListDbOptions{
+		Filtering: &typescore.User{SystemID: &userID},
+	})
+	if errW != nil {
+		logrus.Errorf("failed to get user: %v", errW.Error)
+		return nil, status.Error(codes.Internal, "failed to get user")
+	}
+	if len(users) == 0 {
+		logrus.Errorf("user not found: %s", userID)
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+
+	user := users[0]
+	if user.IsBlocked != nil && *user.IsBlocked {
+		logrus.Warnf("token issue denied for blocked user: %s", userID)
+		return nil, status.Error(codes.PermissionDenied, "user is blocked")
+	}
+	return user, nil
+}
+
+// userClaims возвращает claims роли и областей доступа пользователя
+func userClaims(user *typescore.User) jwt.MapClaims {
+	role, roleScopes := typescore.UserRoleAndScopes(user)
+	scopes := make([]string, 0, len(roleScopes))
+	for _, scope := range roleScopes {
+		scopes = append(scopes, string(scope))
+	}
+
+	return jwt.MapClaims{
+		"role":  string(role),
+		"scope": strings.Join(scopes, " "),
+	}
+}
+
+// sessionClaims возвращает claim sid. Токены, выданные до появления сессий, его не содержат.
+func sessionClaims(sessionID string) jwt.MapClaims {
+	if sessionID == "" {
+		return jwt.MapClaims{}
+	}
+	return jwt.MapClaims{"sid": sessionID}
+}
+
+// parseToken проверяет подлинность, срок действия и тип токена без привязки к IP.
+// Пустой tokenUse допускает токен любого типа.
+func (s *AuthServiceServiceProto) parseToken(token, tokenUse string) (jwt.MapClaims, error) {
+	return securecore.ParseToken(token, s.ipc.TokenFormat, s.ipc.TokenPolicy, tokenUse)
+}
+
+// tokenTypeFromClaims возвращает тип токена в терминах RFC 7662
+func tokenTypeFromClaims(claims jwt.MapClaims) string {
+	if use, _ := claims["token_use"].(string); use == securecore.TokenUseRefresh {
+		return tokenTypeRefresh
+	}
+	return tokenTypeAccess
+}
@@ -0,0 +1,182 @@
+package grpcpayment
+
+import (
+	typesm "authentication_service/auth_service/types"
+	"authentication_service/core/configcore"
+	"authentication_service/core/database"
+	dbcore "authentication_service/core/database/db"
+	errm "authentication_service/core/errmodule"
+	protoobj "authentication_service/core/proto"
+	"authentication_service/core/securecore"
+	"authentication_service/core/typescore"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	testActiveUser  = "1d0c6a52-7e0b-4f8a-9a3e-2f6c1b5d8e01"
+	testBlockedUser = "1d0c6a52-7e0b-4f8a-9a3e-2f6c1b5d8e02"
+	testUnknownUser = "1d0c6a52-7e0b-4f8a-9a3e-2f6c1b5d8e03"
+	testClientIP    = "203.0.113.7"
+)
+
+// fakeUserDB пользователи по system_id; остальные методы UserDBI не используются
+type fakeUserDB struct {
+	dbcore.UserDBI
+	users map[string]*typescore.User
+	err   error
+}
+
+func (f *fakeUserDB) GetUsersListDB(_ context.Context, options ...typescore.ListDbOptions) ([]*typescore.User, uint64, *errm.Error) {
+	if f.err != nil {
+		return nil, 0, errm.NewError("db_error", f.err)
+	}
+	filter := options[0].Filtering.(*typescore.User)
+	if user, ok := f.users[*filter.SystemID]; ok {
+		return []*typescore.User{user}, 1, nil
+	}
+	return nil, 0, nil
+}
+
+// fakeRefreshTokenDB учитывает ротации и отзыв семейств токенов пользователя
+type fakeRefreshTokenDB struct {
+	dbcore.RefreshTokenDBI
+	rotated      int
+	revokedUsers []string
+}
+
+func (f *fakeRefreshTokenDB) RotateRefreshTokenDB(_ context.Context, _, _ string, newTokenObj *typescore.RefreshToken) (*typescore.RefreshToken, *errm.Error) {
+	f.rotated++
+	return newTokenObj, nil
+}
+
+func (f *fakeRefreshTokenDB) RevokeUserRefreshTokensDB(_ context.Context, _ pgx.Tx, userID string) *errm.Error {
+	f.revokedUsers = append(f.revokedUsers, userID)
+	return nil
+}
+
+func newTokenTestServer(t *testing.T, users *fakeUserDB) (*AuthServiceServiceProto, *fakeRefreshTokenDB) {
+	t.Helper()
+	keyring, err := securecore.NewKeyring(configcore.AuthJWTConfig{UserSecret: "secret"})
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+	// База недоступна: выдача активному пользователю доходит до сохранения сессии и завершается ошибкой
+	pool, err := pgxpool.New(context.Background(), "postgres://test@127.0.0.1:1/test?connect_timeout=1")
+	if err != nil {
+		t.Fatalf("pgxpool.New() error = %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	refreshTokens := &fakeRefreshTokenDB{}
+	return &AuthServiceServiceProto{ipc: &typesm.InternalProviderControl{
+		Database:    &database.ModuleDB{Pool: pool, Users: users, RefreshTokens: refreshTokens},
+		TokenFormat: keyring,
+	}}, refreshTokens
+}
+
+func testUsers() *fakeUserDB {
+	active, blocked := testActiveUser, testBlockedUser
+	isBlocked := true
+	return &fakeUserDB{users: map[string]*typescore.User{
+		active:  {SystemID: &active},
+		blocked: {SystemID: &blocked, IsBlocked: &isBlocked},
+	}}
+}
+
+func TestGetTokenUser(t *testing.T) {
+	tests := []struct {
+		name   string
+		users  *fakeUserDB
+		userID string
+		want   codes.Code
+	}{
+		{"active", testUsers(), testActiveUser, codes.OK},
+		{"blocked", testUsers(), testBlockedUser, codes.PermissionDenied},
+		{"unknown", testUsers(), testUnknownUser, codes.NotFound},
+		{"invalid id", testUsers(), "not-a-uuid", codes.NotFound},
+		{"database error", &fakeUserDB{err: errors.New("connection lost")}, testActiveUser, codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTokenTestServer(t, tt.users)
+			user, err := s.getTokenUser(context.Background(), tt.userID)
+			if status.Code(err) != tt.want {
+				t.Fatalf("getTokenUser() error = %v, want %v", err, tt.want)
+			}
+			if err == nil && *user.SystemID != tt.userID {
+				t.Fatalf("getTokenUser() user = %s, want %s", *user.SystemID, tt.userID)
+			}
+		})
+	}
+}
+
+func TestIssueTokensUserState(t *testing.T) {
+	tests := []struct {
+		name    string
+		userID  string
+		want    codes.Code
+		wantMsg string
+	}{
+		{"unknown", testUnknownUser, codes.NotFound, "user not found"},
+		{"blocked", testBlockedUser, codes.PermissionDenied, "user is blocked"},
+		{"active", testActiveUser, codes.Internal, "failed to store refresh token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTokenTestServer(t, testUsers())
+			_, err := s.IssueTokens(context.Background(), &protoobj.IssueTokensRequest{UserId: tt.userID, ClientIp: testClientIP})
+			if status.Code(err) != tt.want || status.Convert(err).Message() != tt.wantMsg {
+				t.Fatalf("IssueTokens() error = %v, want %v %q", err, tt.want, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestRefreshTokensUserState(t *testing.T) {
+	tests := []struct {
+		name        string
+		userID      string
+		want        codes.Code
+		wantRevoked bool // отзыв всех refresh токенов пользователя
+	}{
+		{"unknown", testUnknownUser, codes.NotFound, true},
+		{"blocked", testBlockedUser, codes.PermissionDenied, true},
+		{"active", testActiveUser, codes.OK, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, refreshTokens := newTokenTestServer(t, testUsers())
+			refreshToken, _, err := s.newRefreshToken(tt.userID, "", testClientIP)
+			if err != nil {
+				t.Fatalf("newRefreshToken() error = %v", err)
+			}
+
+			resp, err := s.RefreshTokens(context.Background(), &protoobj.RefreshTokensRequest{RefreshToken: refreshToken, ClientIp: testClientIP})
+			if status.Code(err) != tt.want {
+				t.Fatalf("RefreshTokens() error = %v, want %v", err, tt.want)
+			}
+			if revoked := len(refreshTokens.revokedUsers) == 1 && refreshTokens.revokedUsers[0] == tt.userID; revoked != tt.wantRevoked {
+				t.Fatalf("revoked users = %v, want revoked %v", refreshTokens.revokedUsers, tt.wantRevoked)
+			}
+			if err != nil {
+				if refreshTokens.rotated != 0 {
+					t.Fatal("refresh token of a rejected user must not be rotated")
+				}
+				return
+			}
+			if refreshTokens.rotated != 1 || resp.GetAccessToken() == "" || resp.GetRefreshToken() == "" {
+				t.Fatalf("RefreshTokens() = %v, rotated %d times", resp, refreshTokens.rotated)
+			}
+		})
+	}
+}
@@ -4,10 +4,23 @@ import (
 	"authentication_service/core/configcore"
 	"authentication_service/core/database"
 	rabbitmqlib "authentication_service/core/lib/external/rabbitmq-lib"
+	"authentication_service/core/lib/internally/denylist"
+	"authentication_service/core/lib/internally/kvstore"
+	"authentication_service/core/securecore"
 )
 
 type InternalProviderControl struct {
 	Config   *configcore.Config
 	RabbitMQ *rabbitmqlib.ConnectionRabitMq
 	Database *database.ModuleDB
+	// Список отозванных токенов
+	TokenDenylist *denylist.TokenDenylist
+	// Хранилище ключ-значение (Redis или память процесса)
+	KVStore kvstore.Store
+	// Ключи подписи JWT
+	Keyring *securecore.Keyring
+	// Формат выпускаемых токенов (JWT или PASETO)
+	TokenFormat securecore.TokenFormat
+	// Издатель, аудитория и допустимое расхождение часов для токенов
+	TokenPolicy securecore.TokenPolicy
 }
@@ -12,7 +12,12 @@ func LoadMailTemplates() *typesm.TemplatesMailSystem {
 	templatesMailObj := &typesm.TemplatesMailSystem{}
 	basePath := "loader/mail-template"
 	mailTemplatesNameMap := map[string]string{
-		"NewDeviceInfoTemplate": "new-device-info.html",
+		"NewDeviceInfoTemplate":     "new-device-info.html",
+		"EmailVerificationTemplate": "email-verification.html",
+		"PasswordResetTemplate":     "password-reset.html",
+		"MagicLinkTemplate":         "magic-link.html",
+		"AccountLockedTemplate":     "account-locked.html",
+		"ImpersonationTemplate":     "impersonation.html",
 	}
 
 	for key, value := range mailTemplatesNameMap {
@@ -34,6 +39,16 @@ func LoadMailTemplates() *typesm.TemplatesMailSystem {
 		switch key {
 		case "NewDeviceInfoTemplate":
 			templatesMailObj.NewDeviceInfoTemplate = t
+		case "EmailVerificationTemplate":
+			templatesMailObj.EmailVerificationTemplate = t
+		case "PasswordResetTemplate":
+			templatesMailObj.PasswordResetTemplate = t
+		case "MagicLinkTemplate":
+			templatesMailObj.MagicLinkTemplate = t
+		case "AccountLockedTemplate":
+			templatesMailObj.AccountLockedTemplate = t
+		case "ImpersonationTemplate":
+			templatesMailObj.ImpersonationTemplate = t
 		}
 	}
 	return templatesMailObj
@@ -41,6 +41,151 @@ func (m *ModuleNotification) DeviceNewNotifyCategoryAction(notifyParams *typesco
 	return err
 }
 
+// Код подтверждения email
+func (m *ModuleNotification) EmailVerifyNotifyCategoryAction(notifyParams *typescore.NotifyParams) error {
+	err := m.checkReqFields(notifyParams)
+	if err != nil {
+		return err
+	}
+
+	t := m.ipc.TemplatesMail.EmailVerificationTemplate
+	title := fmt.Sprintf("Email verification %s", m.ipc.Config.SMTPMailServer.BaseTitle)
+	bodyText := fmt.Sprintf("%s %s", "Code", *notifyParams.Text)
+
+	link := ""
+	if notifyParams.LinkURL != nil {
+		link = *notifyParams.LinkURL
+	}
+	gMail, err := m.CompareMailBody(t, map[string]interface{}{
+		"Code": *notifyParams.Text,
+		"Link": link,
+	}, title)
+	if err != nil {
+		return err
+	}
+
+	msgList, err := m.getUsersAuthGetters(notifyParams.UsersIDs, nil, gMail, title, bodyText, notifyParams.Category)
+	if err != nil {
+		return err
+	}
+	err = m.DistributionNotify(msgList)
+	return err
+}
+
+// Ссылка сброса пароля
+func (m *ModuleNotification) PasswordResetNotifyCategoryAction(notifyParams *typescore.NotifyParams) error {
+	err := m.checkReqFields(notifyParams)
+	if err != nil {
+		return err
+	}
+
+	t := m.ipc.TemplatesMail.PasswordResetTemplate
+	title := fmt.Sprintf("Password reset %s", m.ipc.Config.SMTPMailServer.BaseTitle)
+	bodyText := "Password reset requested"
+
+	link := ""
+	if notifyParams.LinkURL != nil {
+		link = *notifyParams.LinkURL
+	}
+	gMail, err := m.CompareMailBody(t, map[string]interface{}{
+		"Token": *notifyParams.Text,
+		"Link":  link,
+	}, title)
+	if err != nil {
+		return err
+	}
+
+	msgList, err := m.getUsersAuthGetters(notifyParams.UsersIDs, nil, gMail, title, bodyText, notifyParams.Category)
+	if err != nil {
+		return err
+	}
+	err = m.DistributionNotify(msgList)
+	return err
+}
+
+// Ссылка входа без пароля
+func (m *ModuleNotification) MagicLinkNotifyCategoryAction(notifyParams *typescore.NotifyParams) error {
+	err := m.checkReqFields(notifyParams)
+	if err != nil {
+		return err
+	}
+
+	t := m.ipc.TemplatesMail.MagicLinkTemplate
+	title := fmt.Sprintf("Sign in %s", m.ipc.Config.SMTPMailServer.BaseTitle)
+	bodyText := "Sign-in link requested"
+
+	link := ""
+	if notifyParams.LinkURL != nil {
+		link = *notifyParams.LinkURL
+	}
+	gMail, err := m.CompareMailBody(t, map[string]interface{}{
+		"Token": *notifyParams.Text,
+		"Link":  link,
+	}, title)
+	if err != nil {
+		return err
+	}
+
+	msgList, err := m.getUsersAuthGetters(notifyParams.UsersIDs, nil, gMail, title, bodyText, notifyParams.Category)
+	if err != nil {
+		return err
+	}
+	err = m.DistributionNotify(msgList)
+	return err
+}
+
+// Вход временно заблокирован после неудачных попыток
+func (m *ModuleNotification) AccountLockedNotifyCategoryAction(notifyParams *typescore.NotifyParams) error {
+	err := m.checkReqFields(notifyParams)
+	if err != nil {
+		return err
+	}
+
+	t := m.ipc.TemplatesMail.AccountLockedTemplate
+	title := fmt.Sprintf("Sign-in temporarily locked %s", m.ipc.Config.SMTPMailServer.BaseTitle)
+	bodyText := fmt.Sprintf("%s %s", "Too many failed sign-in attempts from IPAddress", *notifyParams.Text)
+
+	gMail, err := m.CompareMailBody(t, map[string]interface{}{
+		"IPAddress": *notifyParams.Text,
+	}, title)
+	if err != nil {
+		return err
+	}
+
+	msgList, err := m.getUsersAuthGetters(notifyParams.UsersIDs, nil, gMail, title, bodyText, notifyParams.Category)
+	if err != nil {
+		return err
+	}
+	err = m.DistributionNotify(msgList)
+	return err
+}
+
+// Сотрудник поддержки получил доступ к аккаунту
+func (m *ModuleNotification) ImpersonationNotifyCategoryAction(notifyParams *typescore.NotifyParams) error {
+	err := m.checkReqFields(notifyParams)
+	if err != nil {
+		return err
+	}
+
+	t := m.ipc.TemplatesMail.ImpersonationTemplate
+	title := fmt.Sprintf("Support accessed your account %s", m.ipc.Config.SMTPMailServer.BaseTitle)
+	bodyText := fmt.Sprintf("%s %s", "A support agent accessed your account. Reason:", *notifyParams.Text)
+
+	gMail, err := m.CompareMailBody(t, map[string]interface{}{
+		"Reason": *notifyParams.Text,
+	}, title)
+	if err != nil {
+		return err
+	}
+
+	msgList, err := m.getUsersAuthGetters(notifyParams.UsersIDs, nil, gMail, title, bodyText, notifyParams.Category)
+	if err != nil {
+		return err
+	}
+	err = m.DistributionNotify(msgList)
+	return err
+}
+
 func (m *ModuleNotification) getUsersAuthGetters(systemUserIDs []*string, mailAddress *string, gMail *gomail.Message, title, bodyText string, typeNotify *typescore.NotifyCategory) ([]MsgNotifyStruct, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -60,8 +205,14 @@ func (m *ModuleNotification) getUsersAuthGetters(systemUserIDs []*string, mailAd
 				continue
 			}
 
+			// На неподтвержденный адрес отправляются только письма, подтверждающие владение им
+			user := userObj[0]
+			if user.EmailVerifiedAt == nil && !isEmailOwnershipCategory(typeNotify) {
+				user.Email = nil
+			}
+
 			msgObj := &MsgNotifyStruct{
-				User:               *userObj[0],
+				User:               *user,
 				TitleText:          title,
 				BodyText:           bodyText,
 				MailMessage:        gMail,
@@ -88,3 +239,15 @@ func (m *ModuleNotification) getUsersAuthGetters(systemUserIDs []*string, mailAd
 
 	return msgNotifyList, nil
 }
+
+// isEmailOwnershipCategory категории писем, получение которых подтверждает владение адресом
+func isEmailOwnershipCategory(category *typescore.NotifyCategory) bool {
+	if category == nil {
+		return false
+	}
+	switch *category {
+	case typescore.EmailVerifyNotifyCategory, typescore.PasswordResetNotifyCategory, typescore.MagicLinkNotifyCategory:
+		return true
+	}
+	return false
+}
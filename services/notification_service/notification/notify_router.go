@@ -29,6 +29,16 @@ func (m *ModuleNotification) NotifyRouting(notifyParams *typescore.NotifyParams)
 	switch *notifyParams.Category {
 	case typescore.DeviceNewNotifyCategory: // Новое устройство
 		return m.DeviceNewNotifyCategoryAction(notifyParams)
+	case typescore.EmailVerifyNotifyCategory: // Код подтверждения email
+		return m.EmailVerifyNotifyCategoryAction(notifyParams)
+	case typescore.PasswordResetNotifyCategory: // Ссылка сброса пароля
+		return m.PasswordResetNotifyCategoryAction(notifyParams)
+	case typescore.MagicLinkNotifyCategory: // Ссылка входа без пароля
+		return m.MagicLinkNotifyCategoryAction(notifyParams)
+	case typescore.AccountLockedNotifyCategory: // Вход временно заблокирован
+		return m.AccountLockedNotifyCategoryAction(notifyParams)
+	case typescore.ImpersonationNotifyCategory: // Доступ сотрудника поддержки к аккаунту
+		return m.ImpersonationNotifyCategoryAction(notifyParams)
 	}
 	return nil
 }
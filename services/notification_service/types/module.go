@@ -9,7 +9,12 @@ import (
 )
 
 type TemplatesMailSystem struct {
-	NewDeviceInfoTemplate *template.Template
+	NewDeviceInfoTemplate     *template.Template
+	EmailVerificationTemplate *template.Template
+	PasswordResetTemplate     *template.Template
+	MagicLinkTemplate         *template.Template
+	AccountLockedTemplate     *template.Template
+	ImpersonationTemplate     *template.Template
 }
 
 type InternalProviderControl struct {
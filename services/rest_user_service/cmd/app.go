@@ -7,22 +7,32 @@ import (
 	"authentication_service/core/lib/external/grpccore"
 	pgxpoolmodule "authentication_service/core/lib/external/pgxpool"
 	rabbitmqlib "authentication_service/core/lib/external/rabbitmq-lib"
+	redismodule "authentication_service/core/lib/external/redis"
+	"authentication_service/core/lib/internally/denylist"
 	grpcservice "authentication_service/core/lib/internally/grpc_service"
+	"authentication_service/core/lib/internally/kvstore"
+	"authentication_service/core/lib/internally/lockout"
+	"authentication_service/core/lib/internally/onetimecode"
+	"authentication_service/core/securecore"
 	_ "authentication_service/rest_user_service/docs"
 	"authentication_service/rest_user_service/handler"
+	adminhandler "authentication_service/rest_user_service/handler/admin"
 	authhandler "authentication_service/rest_user_service/handler/auth"
+	oauthhandler "authentication_service/rest_user_service/handler/oauth"
 	userhandler "authentication_service/rest_user_service/handler/profile"
+	wellknownhandler "authentication_service/rest_user_service/handler/wellknown"
 	typesm "authentication_service/rest_user_service/types"
+	"authentication_service/rest_user_service/upstream"
 	"errors"
 	"fmt"
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
 	"github.com/go-chi/httprate"
 	"github.com/sirupsen/logrus"
 	httpSwagger "github.com/swaggo/http-swagger"
 	"log"
 	"net/http"
+	"slices"
 	"time"
 )
 
@@ -68,15 +78,20 @@ func main() {
 func getConfig() (*configcore.Config, error) {
 	options := &configcore.ConfigLoadOptions{
 		Database: true,
+		Redis:    true,
 		GrpsClients: configcore.GrpsClientsOptions{
 			AuthService: true,
 		},
 		ExposedServiceConfig: configcore.ExposedServiceOptions{
 			UserService: true,
+			// Формат и ключи PASETO токенов, выпускаемых сервисом авторизации
+			AuthService: true,
 		},
 		RabbitMQConfig: true,
+		Telegram:       true,
 		Secrets: configcore.SecretsOptions{
-			User: true,
+			User:         true,
+			AESBucketKey: true,
 		},
 	}
 
@@ -113,10 +128,84 @@ func initInternalProvider(appConfig *configcore.Config) (*typesm.InternalProvide
 		return nil, err
 	}
 
+	keyring, err := securecore.NewVerifyKeyring(appConfig.Secrets.AuthJWT)
+	if err != nil {
+		logrus.Errorln("❌ Failed to init signing keys: ", err)
+		return nil, err
+	}
+
+	tokenFormat, err := securecore.NewTokenFormat(appConfig.ExposedServiceConfig.AuthService, keyring)
+	if err != nil {
+		logrus.Errorln("❌ Failed to init token format: ", err)
+		return nil, err
+	}
+
+	tokenPolicy, err := securecore.NewTokenPolicy(appConfig.ExposedServiceConfig.AuthService)
+	if err != nil {
+		logrus.Errorln("❌ Failed to init token policy: ", err)
+		return nil, err
+	}
+
+	// Список отозванных токенов пополняет auth_service, а проверяет этот сервис.
+	// Без Redis сервис запускается с хранилищем в памяти процесса, если не задан redis.required
+	redisClient, err := redismodule.ConnectRedis(&redismodule.ConfigConnectRedis{
+		Host:     appConfig.Redis.Host,
+		Port:     appConfig.Redis.Port,
+		User:     appConfig.Redis.User,
+		Password: appConfig.Redis.Password,
+	})
+	if err != nil || redisClient == nil {
+		if appConfig.Redis.Required {
+			logrus.Errorln("❌ Failed to init Redis client, redis.required is set: ", err)
+			return nil, errors.New("redis is required for the shared token denylist")
+		}
+		logrus.Errorln("❌ Failed to init Redis client, token denylist, one-time codes and lockout counters are process-local: "+
+			"tokens revoked by auth_service are not seen here until they expire: ", err)
+		redisClient = nil
+	}
+	store := kvstore.NewStore(redisClient)
+
+	// Без ключа шифрования секретов TOTP недоступен, остальные способы входа продолжают работать
+	var secretCipher *securecore.SecretCipher
+	if appConfig.Secrets.AESBucketKey != "" {
+		secretCipher, err = securecore.NewSecretCipher(appConfig.Secrets.AESBucketKey)
+		if err != nil {
+			logrus.Warnln("⚠️ Invalid aes_bucket_key, TOTP is disabled: ", err)
+		}
+	} else {
+		logrus.Warnln("⚠️ aes_bucket_key is not configured, TOTP is disabled")
+	}
+
+	webAuthn, err := handler.NewWebAuthn(appConfig.ExposedServiceConfig.UserService)
+	if err != nil {
+		logrus.Errorln("❌ Failed to init WebAuthn: ", err)
+		return nil, err
+	}
+	if webAuthn == nil {
+		logrus.Warnln("⚠️ webauthn.rp_id is not configured, passkey login is disabled")
+	}
+
+	// Провайдеры OpenID Connect; их конфигурация загружается при первом входе
+	identityProviders, err := upstream.NewRegistry(appConfig.ExposedServiceConfig.UserService.OIDC, nil)
+	if err != nil {
+		logrus.Errorln("❌ Failed to init OIDC providers: ", err)
+		return nil, err
+	}
+
 	return &typesm.InternalProviderControl{
-		Config:   appConfig,
-		DB:       db,
-		RabbitMQ: rabbitMQClient,
+		Config:        appConfig,
+		DB:            db,
+		RabbitMQ:      rabbitMQClient,
+		TokenDenylist: denylist.NewTokenDenylist(store),
+		OneTimeCodes:  onetimecode.NewStore(store),
+		Lockout:       lockout.NewTracker(store, lockoutParams(appConfig.ExposedServiceConfig.UserService.Lockout)),
+		KVStore:       store,
+		SecretCipher:  secretCipher,
+		WebAuthn:      webAuthn,
+		OIDC:          identityProviders,
+		Keyring:       keyring,
+		TokenFormat:   tokenFormat,
+		TokenPolicy:   tokenPolicy,
 	}, nil
 }
 
@@ -138,17 +227,34 @@ func startRestApiServer(ipc *typesm.InternalProviderControl, router *chi.Mux) er
 
 func initBaseApiRouter(ipc *typesm.InternalProviderControl) (*chi.Mux, error) {
 	router := chi.NewRouter()
+
+	// Вход по ссылке и через OIDC хранит состояние в cookie, поэтому запросы с разрешенных origin
+	// передаются с учетными данными. С ними любой origin ("*") не допускается
+	allowedOrigins := ipc.Config.ExposedServiceConfig.UserService.Cors.AllowedOrigins
+	if slices.Contains(allowedOrigins, "*") {
+		logrus.Errorln("❌ cors.allowed_origins must list explicit origins: credentials are allowed")
+		return nil, errors.New("cors.allowed_origins must not contain \"*\"")
+	}
+
 	corsOptions := cors.New(cors.Options{
-		AllowedOrigins:   ipc.Config.ExposedServiceConfig.UserService.Cors.AllowedOrigins, // Список разрешенных origin
+		AllowedOrigins:   allowedOrigins, // Список разрешенных origin
 		AllowedMethods:   []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodPatch, http.MethodOptions},
 		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "Cache-Control", "X-Requested-With"},
 		ExposedHeaders:   []string{"Link", "Cache-Control"},
-		AllowCredentials: false,
-		MaxAge:           300, // Максимальное время жизни предварительных запросов в секундах
+		AllowCredentials: true, // cookie nonce ссылки входа и state OIDC
+		MaxAge:           300,  // Максимальное время жизни предварительных запросов в секундах
 	})
 
+	// Адрес клиента из заголовков принимается только от доверенных прокси:
+	// на нем основаны привязка токенов, ограничение частоты и блокировка по IP
+	trustedProxies, err := handler.ParseTrustedProxies(ipc.Config.ExposedServiceConfig.UserService.TrustedProxies)
+	if err != nil {
+		logrus.Errorf("❌ Invalid trusted proxies: %v", err)
+		return nil, err
+	}
+
 	router.Use(corsOptions.Handler)
-	router.Use(middleware.RealIP)
+	router.Use(handler.RealIP(trustedProxies))
 
 	// Добавляем ограничение по IP
 	router.Use(httprate.LimitByIP(rateLimit, rateWindow))
@@ -158,7 +264,7 @@ func initBaseApiRouter(ipc *typesm.InternalProviderControl) (*chi.Mux, error) {
 		return nil, errors.New("❌ Failed to connect to database")
 	}
 
-	err := registerRoutes(router, ipc)
+	err = registerRoutes(router, ipc)
 	if err != nil {
 		logrus.Errorln("❌ Failed to register routes")
 		return nil, err
@@ -167,6 +273,19 @@ func initBaseApiRouter(ipc *typesm.InternalProviderControl) (*chi.Mux, error) {
 	return router, nil
 }
 
+// lockoutParams параметры защиты от перебора из конфигурации; незаданные значения заменяются значениями по умолчанию
+func lockoutParams(cfg configcore.LockoutConfig) lockout.Params {
+	return lockout.Params{
+		AccountMaxFailures: cfg.AccountMaxFailures,
+		IPMaxFailures:      cfg.IPMaxFailures,
+		FailureWindow:      cfg.FailureWindow,
+		BaseDelay:          cfg.BaseDelay,
+		MaxDelay:           cfg.MaxDelay,
+		BaseLockout:        cfg.BaseLockout,
+		MaxLockout:         cfg.MaxLockout,
+	}
+}
+
 func registerGrpcServices(ipc *typesm.InternalProviderControl) (*typesm.InternalProviderControl, error) {
 	protoOpt := grpccore.CreateDialOptionsProto()
 
@@ -201,6 +320,9 @@ func registerRoutes(router chi.Router, ipc *typesm.InternalProviderControl) erro
 	}{
 		{"auth", authhandler.RegisterAuthRoutes},
 		{"user", userhandler.RegisterUsersRoutes},
+		{"wellknown", wellknownhandler.RegisterWellKnownRoutes},
+		{"oauth", oauthhandler.RegisterOAuthRoutes},
+		{"admin", adminhandler.RegisterAdminRoutes},
 	}
 
 	// Регистрация всех маршрутов
@@ -15,9 +15,123 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/auth/issue": {
+        "/.well-known/jwks.json": {
+            "get": {
+                "description": "Возвращает набор публичных ключей (JWKS) для проверки подписи токенов по заголовку kid",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "well-known"
+                ],
+                "summary": "Публичные ключи проверки токенов",
+                "responses": {
+                    "200": {
+                        "description": "Успех",
+                        "schema": {
+                            "$ref": "#/definitions/securecore.JWKSet"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/.well-known/openid-configuration": {
+            "get": {
+                "description": "Возвращает адреса эндпоинтов и поддерживаемые параметры сервера авторизации для стандартных библиотек OpenID Connect.\nАдреса строятся от issuer сервиса авторизации, поэтому сервис должен быть доступен по этому адресу",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "well-known"
+                ],
+                "summary": "Метаданные провайдера OpenID Connect",
+                "responses": {
+                    "200": {
+                        "description": "Успех",
+                        "schema": {
+                            "$ref": "#/definitions/wellknownhandler.OpenIDConfigurationResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Не настроен issuer или асимметричный ключ подписи",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/impersonations": {
+            "get": {
+                "description": "Возвращает последние выдачи токенов для работы от имени пользователей: сотрудник, пользователь, причина, области доступа и срок действия.\nИдентификатор записи совпадает с jti токена и с impersonation_id в логе запросов. Доступно с областью доступа admin,\nв том числе сервисным аккаунтам (токен client_credentials)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Журнал работы от имени пользователей",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer \u003caccess_token\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Идентификатор пользователя",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Идентификатор сотрудника",
+                        "name": "actor_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успех",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/typescore.Impersonation"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Невалидный или отозванный токен",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/users/{id}/impersonate": {
             "post": {
-                "description": "Выдает Access и Refresh токены для пользователя с указанным GUID",
+                "description": "Выдает сотруднику поддержки или администратору короткоживущий access токен пользователя с claim act (RFC 8693).\nТокен дает только чтение профиля (profile:read).\nТокен не позволяет менять настройки безопасности, выдавать доступ клиентам OAuth 2.0 и не продлевается.\nВыдача сохраняется в журнале, пользователь получает уведомление; каждый запрос с токеном записывается в лог.\nАккаунты сотрудников недоступны. Требуется область доступа users:read и недавняя аутентификация",
                 "consumes": [
                     "application/json"
                 ],
@@ -25,27 +139,155 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
                 ],
-                "summary": "Выдача пары токенов",
+                "summary": "Работа от имени пользователя",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "GUID пользователя",
-                        "name": "guid",
-                        "in": "query",
+                        "description": "Bearer \u003caccess_token\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Идентификатор пользователя",
+                        "name": "id",
+                        "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Причина и области доступа",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/adminhandler.ImpersonateReq"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "Успех",
                         "schema": {
-                            "$ref": "#/definitions/typescore.TokenPair"
+                            "$ref": "#/definitions/adminhandler.ImpersonateResp"
+                        }
+                    },
+                    "401": {
+                        "description": "Невалидный токен или требуется повторная аутентификация (insufficient_user_authentication)",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав или аккаунт сотрудника",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Некорректные параметры, пользователь не найден или ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/users/{id}/unlock": {
+            "post": {
+                "description": "Снимает временную блокировку аккаунта после неудачных попыток входа и ввода второго фактора и сбрасывает счетчики попыток.\nДоступно с областью доступа admin",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Разблокировка аккаунта",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer \u003caccess_token\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Идентификатор пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успех",
+                        "schema": {
+                            "$ref": "#/definitions/adminhandler.UnlockUserResp"
+                        }
+                    },
+                    "401": {
+                        "description": "Невалидный или отозванный токен",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Пользователь не найден или ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/auth/email/confirm": {
+            "post": {
+                "description": "Подтверждает адрес электронной почты кодом из письма. Полные области доступа выдаются после обновления токенов",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Подтверждение email",
+                "parameters": [
+                    {
+                        "description": "Email и код подтверждения",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/authhandler.ConfirmEmailReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успех",
+                        "schema": {
+                            "$ref": "#/definitions/authhandler.ConfirmEmailResp"
                         }
                     },
                     "400": {
-                        "description": "Некорректный запрос",
+                        "description": "Неверный или просроченный код либо исчерпаны попытки ввода кода",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много неудачных попыток: повторить после Retry-After",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -59,9 +301,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/auth/refresh": {
+        "/api/auth/email/resend": {
             "post": {
-                "description": "Обновляет Access и Refresh токены на основе действующего Refresh токена",
+                "description": "Отправляет новый код подтверждения на email пользователя. Предыдущий код аннулируется",
                 "consumes": [
                     "application/json"
                 ],
@@ -71,11 +313,11 @@ const docTemplate = `{
                 "tags": [
                     "auth"
                 ],
-                "summary": "Обновление токенов",
+                "summary": "Повторная отправка кода подтверждения email",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Bearer \u003crefresh_token\u003e",
+                        "description": "Bearer \u003caccess_token\u003e",
                         "name": "Authorization",
                         "in": "header",
                         "required": true
@@ -85,17 +327,29 @@ const docTemplate = `{
                     "200": {
                         "description": "Успех",
                         "schema": {
-                            "$ref": "#/definitions/typescore.TokenPair"
+                            "$ref": "#/definitions/authhandler.ResendEmailCodeResp"
                         }
                     },
                     "400": {
-                        "description": "Некорректный запрос",
+                        "description": "Email уже подтвержден",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
                     "401": {
-                        "description": "Невалидный или просроченный токен",
+                        "description": "Невалидный или отозванный токен",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Код отправлен недавно",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -109,24 +363,39 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/users/profile": {
-            "get": {
-                "description": "Получение профиля пользователя",
+        "/api/auth/introspect": {
+            "post": {
+                "description": "Возвращает состояние токена (RFC 7662). Клиент аутентифицируется через HTTP Basic (client_id:client_secret) или параметры client_id и client_secret",
                 "consumes": [
-                    "application/json"
+                    "application/x-www-form-urlencoded"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "profile"
+                    "auth"
+                ],
+                "summary": "Интроспекция токена",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен",
+                        "name": "token",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "access_token или refresh_token",
+                        "name": "token_type_hint",
+                        "in": "formData"
+                    }
                 ],
-                "summary": "Получение профиля пользователя",
                 "responses": {
                     "200": {
                         "description": "Успех",
                         "schema": {
-                            "$ref": "#/definitions/typescore.User"
+                            "$ref": "#/definitions/authhandler.IntrospectTokenResp"
                         }
                     },
                     "400": {
@@ -135,6 +404,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Неверные учетные данные клиента",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много неудачных попыток: повторить после Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Ошибка сервера",
                         "schema": {
@@ -143,44 +424,3136 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "handler.ErrorResponse": {
-            "type": "object",
-            "properties": {
-                "error_code": {
-                    "description": "Код ошибки",
-                    "type": "integer"
-                },
-                "error_description": {
-                    "description": "Описание ошибки",
-                    "type": "string"
-                }
-            }
-        },
-        "typescore.TokenPair": {
-            "type": "object",
-            "properties": {
-                "access_token": {
-                    "type": "string"
-                },
-                "refresh_token": {
-                    "type": "string"
-                }
-            }
         },
-        "typescore.User": {
-            "type": "object",
-            "properties": {
-                "created_at": {
-                    "description": "Дата и время создания записи",
-                    "type": "string"
+        "/api/auth/login": {
+            "post": {
+                "description": "Проверяет email или никнейм и пароль и выдает Access и Refresh токены.\nЕсли включен TOTP, вместо токенов возвращается промежуточный mfa_token для /api/auth/mfa/verify",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Вход по паролю",
+                "parameters": [
+                    {
+                        "description": "Логин и пароль",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/authhandler.LoginReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успех",
+                        "schema": {
+                            "$ref": "#/definitions/protoobj.IssueTokensResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный запрос",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Неверный логин или пароль",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много неудачных попыток: повторить после Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/auth/logout": {
+            "post": {
+                "description": "Отзывает Access токен и, если передан, Refresh токен текущей сессии",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Выход из текущей сессии",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer \u003caccess_token\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Refresh токен текущей сессии",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/authhandler.LogoutReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успех",
+                        "schema": {
+                            "$ref": "#/definitions/protoobj.LogoutResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный запрос",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Невалидный или отозванный токен",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/auth/logout-all": {
+            "post": {
+                "description": "Отзывает все Access и Refresh токены пользователя, выданные до текущего момента, и удаляет его персональные токены доступа",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Выход из всех сессий",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer \u003caccess_token\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успех",
+                        "schema": {
+                            "$ref": "#/definitions/protoobj.LogoutAllResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Невалидный или отозванный токен",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/auth/magic-link": {
+            "post": {
+                "description": "Отправляет на email одноразовую ссылку входа и устанавливает cookie, без которой ссылка не принимается.\nОтвет не зависит от существования аккаунта; на один адрес письмо отправляется не чаще resend_interval",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Запрос ссылки для входа",
+                "parameters": [
+                    {
+                        "description": "Email аккаунта",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/authhandler.MagicLinkReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успех",
+                        "schema": {
+                            "$ref": "#/definitions/authhandler.MagicLinkResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный запрос",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Ссылка на этот адрес уже запрошена: повторить после Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/auth/magic-link/consume": {
+            "post": {
+                "description": "Принимает токен из ссылки в том же браузере, в котором запрошен вход, и выдает Access и Refresh токены.\nЕсли для аккаунта включен второй фактор, вместо токенов возвращается MFAChallengeResp",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Вход по ссылке из письма",
+                "parameters": [
+                    {
+                        "description": "Токен из ссылки",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/authhandler.MagicLinkConsumeReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успех",
+                        "schema": {
+                            "$ref": "#/definitions/protoobj.IssueTokensResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный запрос",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Неверная или просроченная ссылка либо запрос из другого браузера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много неудачных попыток: повторить после Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/auth/mfa/verify": {
+            "post": {
+                "description": "Проверяет код TOTP или код восстановления по промежуточному токену входа и выдает Access и Refresh токены",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Подтверждение входа вторым фактором",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer \u003cmfa_token\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Код TOTP или код восстановления",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/authhandler.MFAVerifyReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успех",
+                        "schema": {
+                            "$ref": "#/definitions/protoobj.IssueTokensResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный запрос",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Неверный код или промежуточный токен",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много неудачных попыток: повторить после Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/auth/oidc/{provider}/begin": {
+            "post": {
+                "description": "Возвращает адрес страницы авторизации провайдера (authorization code flow с PKCE).\nПосле авторизации провайдер перенаправляет пользователя на redirect_url с параметрами code и state.\nУстанавливает cookie со state: callback принимается только в том же браузере",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Начало входа через провайдера OpenID Connect",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Имя провайдера из конфигурации",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успех",
+                        "schema": {
+                            "$ref": "#/definitions/authhandler.OIDCBeginResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Неизвестный или недоступный провайдер",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/auth/oidc/{provider}/callback": {
+            "post": {
+                "description": "Обменивает код авторизации на ID токен провайдера, находит или создает пользователя по привязанной учетной записи и выдает Access и Refresh токены.\nПринимается только в браузере, где вызван /begin: state сверяется с cookie.\nЕсли включен TOTP, вместо токенов возвращается промежуточный mfa_token для /api/auth/mfa/verify",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Завершение входа через провайдера OpenID Connect",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Имя провайдера из конфигурации",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Параметры из redirect_url",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/authhandler.OIDCCallbackReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успех",
+                        "schema": {
+                            "$ref": "#/definitions/protoobj.IssueTokensResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный запрос",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Неверный state или ошибка провайдера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/auth/password/forgot": {
+            "post": {
+                "description": "Отправляет на email одноразовую ссылку сброса пароля. Ответ не зависит от существования аккаунта",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Запрос сброса пароля",
+                "parameters": [
+                    {
+                        "description": "Email аккаунта",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/authhandler.ForgotPasswordReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успех",
+                        "schema": {
+                            "$ref": "#/definitions/authhandler.ForgotPasswordResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный запрос",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/auth/password/reset": {
+            "post": {
+                "description": "Задает новый пароль по токену из письма и завершает все сессии пользователя",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Сброс пароля",
+                "parameters": [
+                    {
+                        "description": "Токен сброса и новый пароль",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/authhandler.ResetPasswordReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успех",
+                        "schema": {
+                            "$ref": "#/definitions/authhandler.ResetPasswordResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный или просроченный токен",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много неудачных попыток: повторить после Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/auth/reauthenticate": {
+            "post": {
+                "description": "Повторно проверяет факторы пользователя в текущей сессии: пароль, если он задан, и код TOTP или код восстановления, если включен второй фактор.\nВозвращает короткоживущий access токен с обновленными auth_time и amr для операций, требующих недавней аутентификации (ответ 401 insufficient_user_authentication).\nRefresh токен сессии не меняется. Аккаунту без пароля и второго фактора нужно войти заново",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Повторная аутентификация",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer \u003caccess_token\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Пароль и/или код второго фактора",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/authhandler.ReauthenticateReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успех",
+                        "schema": {
+                            "$ref": "#/definitions/authhandler.ReauthenticateResp"
+                        }
+                    },
+                    "401": {
+                        "description": "Невалидный или отозванный токен, неверный пароль или код",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Токен выдан клиенту OAuth 2.0",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много неудачных попыток: повторить после Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/auth/refresh": {
+            "post": {
+                "description": "Обновляет Access и Refresh токены на основе действующего Refresh токена",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Обновление токенов",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer \u003crefresh_token\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успех",
+                        "schema": {
+                            "$ref": "#/definitions/typescore.TokenPair"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный запрос",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Невалидный или просроченный токен; step_up_required — сеть сменилась, нужна повторная аутентификация",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много неудачных попыток: повторить после Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/auth/register": {
+            "post": {
+                "description": "Создает пользователя с email и/или никнеймом и паролем и выдает Access и Refresh токены. На email отправляется код подтверждения",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Регистрация по паролю",
+                "parameters": [
+                    {
+                        "description": "Данные пользователя",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/authhandler.RegisterReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успех",
+                        "schema": {
+                            "$ref": "#/definitions/protoobj.IssueTokensResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный запрос",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/auth/revoke": {
+            "post": {
+                "description": "Отзывает Access или Refresh токен (RFC 7009). При отзыве Refresh токена отзывается вся цепочка ротации",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Отзыв токена",
+                "parameters": [
+                    {
+                        "description": "Токен для отзыва",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/authhandler.RevokeTokenReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успех",
+                        "schema": {
+                            "$ref": "#/definitions/protoobj.RevokeTokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный запрос",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/auth/telegram/webapp": {
+            "post": {
+                "description": "Проверяет подпись initData токеном бота и срок auth_date, находит или создает пользователя по telegram_id и выдает Access и Refresh токены.\nЕсли включен TOTP, вместо токенов возвращается промежуточный mfa_token для /api/auth/mfa/verify",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Вход из Telegram Mini App",
+                "parameters": [
+                    {
+                        "description": "initData Mini App",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/authhandler.TelegramWebAppReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успех",
+                        "schema": {
+                            "$ref": "#/definitions/protoobj.IssueTokensResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный запрос",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много неудачных попыток: повторить после Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Неверная подпись или ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/auth/telegram/widget": {
+            "post": {
+                "description": "Проверяет подпись данных виджета токеном бота и срок auth_date, находит или создает пользователя по telegram_id и выдает Access и Refresh токены.\nЕсли включен TOTP, вместо токенов возвращается промежуточный mfa_token для /api/auth/mfa/verify",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Вход через Telegram Login Widget",
+                "parameters": [
+                    {
+                        "description": "Данные виджета",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/authhandler.TelegramWidgetReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успех",
+                        "schema": {
+                            "$ref": "#/definitions/protoobj.IssueTokensResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный запрос",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много неудачных попыток: повторить после Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Неверная подпись или ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/auth/webauthn/login/begin": {
+            "post": {
+                "description": "Возвращает параметры для navigator.credentials.get(). Без логина выполняется вход выбранным на устройстве ключом (passkey)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Начало входа по ключу доступа",
+                "parameters": [
+                    {
+                        "description": "Логин пользователя",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/authhandler.WebAuthnLoginBeginReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успех",
+                        "schema": {
+                            "$ref": "#/definitions/authhandler.WebAuthnLoginBeginResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/auth/webauthn/login/finish": {
+            "post": {
+                "description": "Проверяет подпись аутентификатора и счетчик подписей и выдает Access и Refresh токены",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Завершение входа по ключу доступа",
+                "parameters": [
+                    {
+                        "description": "Ответ аутентификатора",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/authhandler.WebAuthnLoginFinishReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успех",
+                        "schema": {
+                            "$ref": "#/definitions/protoobj.IssueTokensResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный запрос",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Неверный ключ доступа",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много неудачных попыток: повторить после Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/oauth/clients": {
+            "get": {
+                "description": "Возвращает зарегистрированных клиентов OAuth 2.0. Доступно с областью доступа admin",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "Получение списка клиентов OAuth 2.0",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer \u003caccess_token\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успех",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/typescore.OAuthClient"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Невалидный или отозванный токен",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Регистрирует клиента OAuth 2.0 и возвращает client_id и client_secret. Секрет хранится в виде хэша и возвращается только в этом ответе.\nПубличному клиенту и клиенту с публичным ключом секрет не выдается; grant client_credentials публичному клиенту недоступен. Доступно с областью доступа admin",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "Регистрация клиента OAuth 2.0",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer \u003caccess_token\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Параметры клиента",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/oauthhandler.CreateClientReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успех",
+                        "schema": {
+                            "$ref": "#/definitions/oauthhandler.CreateClientResp"
+                        }
+                    },
+                    "401": {
+                        "description": "Невалидный или отозванный токен",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Некорректные параметры или ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/oauth/service-accounts": {
+            "post": {
+                "description": "Регистрирует сервисный аккаунт для межсервисной аутентификации: клиента OAuth 2.0 только с grant client_credentials.\nСервис аутентифицируется client_assertion, подписанным зарегистрированным ключом, либо секретом, если ключ не передан.\nСекрет хранится в виде хэша и возвращается только в этом ответе. Доступно с областью доступа admin",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "Регистрация сервисного аккаунта",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer \u003caccess_token\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Параметры сервисного аккаунта",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/oauthhandler.CreateServiceAccountReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успех",
+                        "schema": {
+                            "$ref": "#/definitions/oauthhandler.CreateClientResp"
+                        }
+                    },
+                    "401": {
+                        "description": "Невалидный или отозванный токен",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Некорректные параметры или ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/mfa/recovery-codes/regenerate": {
+            "post": {
+                "description": "Заменяет коды восстановления новым набором после проверки кода TOTP или кода восстановления",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Перевыпуск кодов восстановления",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer \u003caccess_token\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Код TOTP или код восстановления",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/userhandler.MFACodeReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успех",
+                        "schema": {
+                            "$ref": "#/definitions/userhandler.RecoveryCodesResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный код",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Невалидный или отозванный токен",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/mfa/totp/confirm": {
+            "post": {
+                "description": "Включает TOTP после проверки первого кода и возвращает одноразовые коды восстановления",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Подтверждение подключения TOTP",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer \u003caccess_token\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Код из приложения-аутентификатора",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/userhandler.MFACodeReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успех",
+                        "schema": {
+                            "$ref": "#/definitions/userhandler.RecoveryCodesResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный код",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Невалидный или отозванный токен",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/mfa/totp/disable": {
+            "post": {
+                "description": "Отключает TOTP после проверки кода TOTP или кода восстановления. Коды восстановления удаляются",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Отключение TOTP",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer \u003caccess_token\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Код TOTP или код восстановления",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/userhandler.MFACodeReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успех",
+                        "schema": {
+                            "$ref": "#/definitions/userhandler.TOTPDisableResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный код",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Невалидный или отозванный токен",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/mfa/totp/enroll": {
+            "post": {
+                "description": "Генерирует секрет TOTP и возвращает URI otpauth для QR-кода. TOTP включается после подтверждения первым кодом",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Начало подключения TOTP",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer \u003caccess_token\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успех",
+                        "schema": {
+                            "$ref": "#/definitions/userhandler.TOTPEnrollResp"
+                        }
+                    },
+                    "401": {
+                        "description": "Невалидный токен или требуется повторная аутентификация (insufficient_user_authentication)",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/profile": {
+            "get": {
+                "description": "Получение профиля пользователя",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Получение профиля пользователя",
+                "responses": {
+                    "200": {
+                        "description": "Успех",
+                        "schema": {
+                            "$ref": "#/definitions/typescore.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный запрос",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/sessions": {
+            "get": {
+                "description": "Возвращает активные сессии пользователя на устройствах. Текущая сессия отмечена флагом current",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Получение активных сессий пользователя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer \u003caccess_token\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успех",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/userhandler.SessionResp"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Невалидный или отозванный токен",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/sessions/revoke-others": {
+            "post": {
+                "description": "Завершает все сессии пользователя, кроме текущей",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Выход на всех остальных устройствах",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer \u003caccess_token\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успех",
+                        "schema": {
+                            "$ref": "#/definitions/protoobj.RevokeOtherSessionsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Невалидный или отозванный токен",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/sessions/{id}": {
+            "delete": {
+                "description": "Завершает сессию пользователя: отзывает её Refresh токены, Access токены сессии перестают приниматься",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Завершение сессии",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer \u003caccess_token\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Идентификатор сессии",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успех",
+                        "schema": {
+                            "$ref": "#/definitions/protoobj.RevokeSessionResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Невалидный или отозванный токен",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/tokens": {
+            "get": {
+                "description": "Возвращает персональные токены доступа пользователя: название, области доступа, срок действия и время последнего использования.\nЗначения токенов не возвращаются. Доступно только с access токеном сессии",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Получение персональных токенов доступа",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer \u003caccess_token\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успех",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/typescore.PersonalAccessToken"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Невалидный или отозванный токен",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Создает именованный персональный токен доступа (API-ключ) для скриптов. Токен передается в заголовке Authorization: Bearer\nи имеет префикс aspat_. Хранится только хэш токена: значение возвращается один раз в этом ответе.\nОбласти доступа токена ограничиваются текущими областями пользователя. Доступно только с access токеном сессии",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Создание персонального токена доступа",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer \u003caccess_token\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Параметры токена",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/userhandler.CreatePersonalTokenReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успех",
+                        "schema": {
+                            "$ref": "#/definitions/userhandler.CreatePersonalTokenResp"
+                        }
+                    },
+                    "401": {
+                        "description": "Невалидный токен или требуется повторная аутентификация (insufficient_user_authentication)",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Некорректные параметры или ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/tokens/{id}": {
+            "delete": {
+                "description": "Отзывает персональный токен доступа: запросы с ним сразу перестают приниматься. Доступно только с access токеном сессии",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Отзыв персонального токена доступа",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer \u003caccess_token\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Идентификатор токена",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успех",
+                        "schema": {
+                            "$ref": "#/definitions/userhandler.PersonalTokenDeleteResp"
+                        }
+                    },
+                    "401": {
+                        "description": "Невалидный токен или требуется повторная аутентификация (insufficient_user_authentication)",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/webauthn/credentials": {
+            "get": {
+                "description": "Возвращает зарегистрированные ключи доступа (passkeys) пользователя",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Получение ключей доступа пользователя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer \u003caccess_token\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успех",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/typescore.WebAuthnCredential"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Невалидный или отозванный токен",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/webauthn/credentials/{id}": {
+            "delete": {
+                "description": "Удаляет ключ доступа пользователя: вход с ним становится невозможен",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Удаление ключа доступа",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer \u003caccess_token\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Идентификатор ключа",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успех",
+                        "schema": {
+                            "$ref": "#/definitions/userhandler.WebAuthnCredentialDeleteResp"
+                        }
+                    },
+                    "401": {
+                        "description": "Невалидный токен или требуется повторная аутентификация (insufficient_user_authentication)",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/webauthn/register/begin": {
+            "post": {
+                "description": "Возвращает параметры для navigator.credentials.create(). Уже зарегистрированные ключи исключаются",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Начало регистрации ключа доступа",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer \u003caccess_token\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Параметры PublicKeyCredentialCreationOptions",
+                        "schema": {
+                            "type": "object"
+                        }
+                    },
+                    "401": {
+                        "description": "Невалидный токен или требуется повторная аутентификация (insufficient_user_authentication)",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/webauthn/register/finish": {
+            "post": {
+                "description": "Проверяет ответ аутентификатора и сохраняет ключ доступа пользователя",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Завершение регистрации ключа доступа",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer \u003caccess_token\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Ответ аутентификатора",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/userhandler.WebAuthnRegisterFinishReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успех",
+                        "schema": {
+                            "$ref": "#/definitions/typescore.WebAuthnCredential"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ответ аутентификатора",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Невалидный или отозванный токен",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/authorize": {
+            "get": {
+                "description": "Проверяет запрос авторизации (authorization code с PKCE S256). Если пользователь уже разрешил клиенту запрошенные области доступа, возвращает redirect_to с кодом авторизации,\nиначе — consent_required и данные для экрана согласия. Решение пользователя передается в POST /oauth/authorize.\nЕсли пользователь аутентифицировался раньше max_age секунд назад, возвращается reauth_required: после /api/auth/reauthenticate запрос повторяется с новым токеном",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "Запрос авторизации клиента OAuth 2.0",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer \u003caccess_token\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "code",
+                        "name": "response_type",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Идентификатор клиента",
+                        "name": "client_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Адрес возврата",
+                        "name": "redirect_uri",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Области доступа через пробел",
+                        "name": "scope",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Значение клиента",
+                        "name": "state",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "PKCE code_challenge",
+                        "name": "code_challenge",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "S256",
+                        "name": "code_challenge_method",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "nonce OpenID Connect для ID токена",
+                        "name": "nonce",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Допустимое время с аутентификации пользователя в секундах",
+                        "name": "max_age",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успех",
+                        "schema": {
+                            "$ref": "#/definitions/oauthhandler.AuthorizeResp"
+                        }
+                    },
+                    "401": {
+                        "description": "Невалидный или отозванный токен",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Токен выдан клиенту OAuth 2.0",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Неизвестный клиент или неверный redirect_uri",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Принимает параметры запроса авторизации и решение пользователя. При согласии области доступа сохраняются для клиента\nи возвращается redirect_to с кодом авторизации, при отказе — redirect_to с error=access_denied",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "Решение пользователя на экране согласия",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer \u003caccess_token\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Параметры запроса авторизации и approve",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/oauthhandler.AuthorizeReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успех",
+                        "schema": {
+                            "$ref": "#/definitions/oauthhandler.AuthorizeResp"
+                        }
+                    },
+                    "401": {
+                        "description": "Невалидный или отозванный токен",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Неизвестный клиент или неверный redirect_uri",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/token": {
+            "post": {
+                "description": "Выдает токены клиенту OAuth 2.0 по grant authorization_code (с code_verifier PKCE), refresh_token или client_credentials.\nКлиент аутентифицируется через HTTP Basic (client_id:client_secret) или параметры client_id и client_secret; публичный клиент передает только client_id.\nДля client_credentials сервисный аккаунт с зарегистрированным ключом может вместо секрета передать client_assertion (RFC 7523)",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "Эндпоинт токенов OAuth 2.0",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "authorization_code, refresh_token или client_credentials",
+                        "name": "grant_type",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Код авторизации",
+                        "name": "code",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Адрес возврата из запроса авторизации",
+                        "name": "redirect_uri",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "PKCE code_verifier",
+                        "name": "code_verifier",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Refresh токен",
+                        "name": "refresh_token",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Области доступа для client_credentials",
+                        "name": "scope",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "urn:ietf:params:oauth:client-assertion-type:jwt-bearer",
+                        "name": "client_assertion_type",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "JWT, подписанный ключом клиента (iss и sub — client_id, aud — issuer или адрес эндпоинта токенов)",
+                        "name": "client_assertion",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успех",
+                        "schema": {
+                            "$ref": "#/definitions/oauthhandler.TokenResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный запрос или неверный grant",
+                        "schema": {
+                            "$ref": "#/definitions/oauthhandler.TokenErrorResp"
+                        }
+                    },
+                    "401": {
+                        "description": "Неверные учетные данные клиента",
+                        "schema": {
+                            "$ref": "#/definitions/oauthhandler.TokenErrorResp"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много неудачных попыток: повторить после Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/oauthhandler.TokenErrorResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/oauthhandler.TokenErrorResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/userinfo": {
+            "get": {
+                "description": "Возвращает стандартные claims пользователя (OpenID Connect Core, раздел 5.3): sub всегда,\nname, given_name, family_name и preferred_username — при области доступа profile, email и email_verified — при области доступа email.\nТребует access токен клиента с областью доступа openid",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "Claims пользователя OpenID Connect",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer \u003caccess_token\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успех",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Невалидный или отозванный токен",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Нет области доступа openid",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Пользователь не найден или заблокирован",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Возвращает стандартные claims пользователя (OpenID Connect Core, раздел 5.3): sub всегда,\nname, given_name, family_name и preferred_username — при области доступа profile, email и email_verified — при области доступа email.\nТребует access токен клиента с областью доступа openid",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "Claims пользователя OpenID Connect",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer \u003caccess_token\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успех",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Невалидный или отозванный токен",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Нет области доступа openid",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Пользователь не найден или заблокирован",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "adminhandler.ImpersonateReq": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "description": "Причина (номер обращения и т.п.): сохраняется в журнале и отправляется пользователю",
+                    "type": "string"
+                },
+                "scopes": {
+                    "description": "Области доступа: только profile:read (по умолчанию)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "adminhandler.ImpersonateResp": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "expires_in": {
+                    "type": "integer"
+                },
+                "impersonation_id": {
+                    "type": "string"
+                },
+                "scope": {
+                    "type": "string"
+                },
+                "token_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "adminhandler.UnlockUserResp": {
+            "type": "object",
+            "properties": {
+                "unlocked": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "authhandler.Actor": {
+            "type": "object",
+            "properties": {
+                "sub": {
+                    "type": "string"
+                }
+            }
+        },
+        "authhandler.ConfirmEmailReq": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "authhandler.ConfirmEmailResp": {
+            "type": "object",
+            "properties": {
+                "verified": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "authhandler.ForgotPasswordReq": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "authhandler.ForgotPasswordResp": {
+            "type": "object",
+            "properties": {
+                "sent": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "authhandler.IntrospectTokenResp": {
+            "type": "object",
+            "properties": {
+                "act": {
+                    "$ref": "#/definitions/authhandler.Actor"
+                },
+                "active": {
+                    "type": "boolean"
+                },
+                "client_id": {
+                    "type": "string"
+                },
+                "exp": {
+                    "type": "integer"
+                },
+                "iat": {
+                    "type": "integer"
+                },
+                "jti": {
+                    "type": "string"
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "scope": {
+                    "type": "string"
+                },
+                "sid": {
+                    "type": "string"
+                },
+                "sub": {
+                    "type": "string"
+                },
+                "token_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "authhandler.LoginReq": {
+            "type": "object",
+            "properties": {
+                "device_name": {
+                    "description": "Название устройства для списка сессий",
+                    "type": "string"
+                },
+                "login": {
+                    "description": "Email или никнейм",
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "authhandler.LogoutReq": {
+            "type": "object",
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "authhandler.MFAVerifyReq": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Код из приложения-аутентификатора",
+                    "type": "string"
+                },
+                "device_name": {
+                    "description": "Название устройства для списка сессий",
+                    "type": "string"
+                },
+                "recovery_code": {
+                    "description": "Одноразовый код восстановления (вместо code)",
+                    "type": "string"
+                }
+            }
+        },
+        "authhandler.MagicLinkConsumeReq": {
+            "type": "object",
+            "properties": {
+                "device_name": {
+                    "description": "Название устройства для списка сессий",
+                    "type": "string"
+                },
+                "token": {
+                    "description": "Токен из ссылки в письме",
+                    "type": "string"
+                }
+            }
+        },
+        "authhandler.MagicLinkReq": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "authhandler.MagicLinkResp": {
+            "type": "object",
+            "properties": {
+                "sent": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "authhandler.OIDCBeginResp": {
+            "type": "object",
+            "properties": {
+                "authorization_url": {
+                    "description": "Страница авторизации провайдера",
+                    "type": "string"
+                }
+            }
+        },
+        "authhandler.OIDCCallbackReq": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Код авторизации из redirect_url",
+                    "type": "string"
+                },
+                "device_name": {
+                    "description": "Название устройства для списка сессий",
+                    "type": "string"
+                },
+                "state": {
+                    "description": "state из redirect_url",
+                    "type": "string"
+                }
+            }
+        },
+        "authhandler.ReauthenticateReq": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Код TOTP; обязателен (или recovery_code), если включен второй фактор",
+                    "type": "string"
+                },
+                "password": {
+                    "description": "Обязателен, если у аккаунта есть пароль",
+                    "type": "string"
+                },
+                "recovery_code": {
+                    "description": "Одноразовый код восстановления (вместо code)",
+                    "type": "string"
+                }
+            }
+        },
+        "authhandler.ReauthenticateResp": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "auth_time": {
+                    "description": "Время повторной аутентификации (Unix)",
+                    "type": "integer"
+                },
+                "expires_in": {
+                    "description": "Время жизни токена в секундах",
+                    "type": "integer"
+                },
+                "token_type": {
+                    "description": "Bearer",
+                    "type": "string"
+                }
+            }
+        },
+        "authhandler.RegisterReq": {
+            "type": "object",
+            "properties": {
+                "device_name": {
+                    "description": "Название устройства для списка сессий",
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "nickname": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "authhandler.ResendEmailCodeResp": {
+            "type": "object",
+            "properties": {
+                "sent": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "authhandler.ResetPasswordReq": {
+            "type": "object",
+            "properties": {
+                "new_password": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "authhandler.ResetPasswordResp": {
+            "type": "object",
+            "properties": {
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "authhandler.RevokeTokenReq": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "authhandler.TelegramWebAppReq": {
+            "type": "object",
+            "properties": {
+                "device_name": {
+                    "description": "Название устройства для списка сессий",
+                    "type": "string"
+                },
+                "init_data": {
+                    "description": "Telegram.WebApp.initData без изменений",
+                    "type": "string"
+                }
+            }
+        },
+        "authhandler.TelegramWidgetReq": {
+            "type": "object",
+            "properties": {
+                "auth_date": {
+                    "type": "integer"
+                },
+                "device_name": {
+                    "description": "Название устройства для списка сессий (не входит в подпись)",
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "hash": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "photo_url": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "authhandler.WebAuthnLoginBeginReq": {
+            "type": "object",
+            "properties": {
+                "login": {
+                    "description": "Email или никнейм; пусто — вход выбранным на устройстве ключом (discoverable)",
+                    "type": "string"
+                }
+            }
+        },
+        "authhandler.WebAuthnLoginBeginResp": {
+            "type": "object",
+            "properties": {
+                "ceremony_id": {
+                    "description": "Передается в /api/auth/webauthn/login/finish",
+                    "type": "string"
+                },
+                "options": {
+                    "description": "Параметры для navigator.credentials.get()",
+                    "type": "object"
+                }
+            }
+        },
+        "authhandler.WebAuthnLoginFinishReq": {
+            "type": "object",
+            "properties": {
+                "ceremony_id": {
+                    "type": "string"
+                },
+                "credential": {
+                    "description": "Ответ navigator.credentials.get()",
+                    "type": "object"
+                },
+                "device_name": {
+                    "description": "Название устройства для списка сессий",
+                    "type": "string"
+                }
+            }
+        },
+        "handler.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error_code": {
+                    "description": "Код ошибки",
+                    "type": "integer"
+                },
+                "error_description": {
+                    "description": "Описание ошибки",
+                    "type": "string"
+                }
+            }
+        },
+        "oauthhandler.AuthorizeReq": {
+            "type": "object",
+            "properties": {
+                "approve": {
+                    "description": "Решение пользователя на экране согласия",
+                    "type": "boolean"
+                },
+                "client_id": {
+                    "description": "Идентификатор клиента",
+                    "type": "string"
+                },
+                "code_challenge": {
+                    "description": "PKCE (RFC 7636)",
+                    "type": "string"
+                },
+                "code_challenge_method": {
+                    "description": "Только S256",
+                    "type": "string"
+                },
+                "max_age": {
+                    "description": "OpenID Connect: допустимое время с аутентификации пользователя в секундах",
+                    "type": "integer"
+                },
+                "nonce": {
+                    "description": "OpenID Connect: передается в ID токен без изменений",
+                    "type": "string"
+                },
+                "redirect_uri": {
+                    "description": "Адрес возврата, зарегистрированный для клиента",
+                    "type": "string"
+                },
+                "response_type": {
+                    "description": "Только code",
+                    "type": "string"
+                },
+                "scope": {
+                    "description": "Области доступа через пробел; пусто — все разрешенные клиенту",
+                    "type": "string"
+                },
+                "state": {
+                    "description": "Значение клиента, возвращается без изменений",
+                    "type": "string"
+                }
+            }
+        },
+        "oauthhandler.AuthorizeResp": {
+            "type": "object",
+            "properties": {
+                "client_name": {
+                    "description": "Название клиента для экрана согласия",
+                    "type": "string"
+                },
+                "consent_required": {
+                    "description": "Нужно показать экран согласия",
+                    "type": "boolean"
+                },
+                "reauth_required": {
+                    "description": "Аутентификация старше max_age: нужна /api/auth/reauthenticate и повтор запроса",
+                    "type": "boolean"
+                },
+                "redirect_to": {
+                    "description": "Адрес возврата клиенту с code и state (или error)",
+                    "type": "string"
+                },
+                "scopes": {
+                    "description": "Запрошенные области доступа",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "oauthhandler.CreateClientReq": {
+            "type": "object",
+            "properties": {
+                "grant_types": {
+                    "description": "authorization_code, refresh_token, client_credentials",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "description": "Название, отображаемое на экране согласия",
+                    "type": "string"
+                },
+                "public": {
+                    "description": "Публичный клиент (мобильное или браузерное приложение) без секрета",
+                    "type": "boolean"
+                },
+                "public_key": {
+                    "description": "Публичный ключ PEM для client_assertion; секрет при этом не выдается",
+                    "type": "string"
+                },
+                "redirect_uris": {
+                    "description": "Адреса возврата (обязательны для authorization_code)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "scopes": {
+                    "description": "Области доступа, которые может запрашивать клиент",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "oauthhandler.CreateClientResp": {
+            "type": "object",
+            "properties": {
+                "client": {
+                    "$ref": "#/definitions/typescore.OAuthClient"
+                },
+                "client_secret": {
+                    "type": "string"
+                }
+            }
+        },
+        "oauthhandler.CreateServiceAccountReq": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "description": "Название сервиса",
+                    "type": "string"
+                },
+                "public_key": {
+                    "description": "Публичный ключ PEM (Ed25519, ECDSA P-256 или RSA); без ключа выдается секрет",
+                    "type": "string"
+                },
+                "scopes": {
+                    "description": "Области доступа, которые может получить сервис",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "oauthhandler.TokenErrorResp": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "error_description": {
+                    "type": "string"
+                }
+            }
+        },
+        "oauthhandler.TokenResp": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "expires_in": {
+                    "description": "Время жизни access токена в секундах",
+                    "type": "integer"
+                },
+                "id_token": {
+                    "description": "ID токен OpenID Connect (при области доступа openid)",
+                    "type": "string"
+                },
+                "refresh_token": {
+                    "type": "string"
+                },
+                "scope": {
+                    "type": "string"
+                },
+                "token_type": {
+                    "description": "Bearer",
+                    "type": "string"
+                }
+            }
+        },
+        "protoobj.IssueTokensResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "expires_in": {
+                    "description": "время жизни access токена в секундах",
+                    "type": "integer"
+                },
+                "id_token": {
+                    "description": "ID токен OpenID Connect; выдается клиенту с областью доступа openid",
+                    "type": "string"
+                },
+                "refresh_token": {
+                    "type": "string"
+                },
+                "scope": {
+                    "description": "области доступа access токена",
+                    "type": "string"
+                },
+                "session_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "protoobj.LogoutAllResponse": {
+            "type": "object",
+            "properties": {
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "protoobj.LogoutResponse": {
+            "type": "object",
+            "properties": {
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "protoobj.RevokeOtherSessionsResponse": {
+            "type": "object",
+            "properties": {
+                "revoked_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "protoobj.RevokeSessionResponse": {
+            "type": "object",
+            "properties": {
+                "revoked": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "protoobj.RevokeTokenResponse": {
+            "type": "object",
+            "properties": {
+                "revoked": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "securecore.JWK": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "string"
+                },
+                "crv": {
+                    "type": "string"
+                },
+                "e": {
+                    "type": "string"
+                },
+                "kid": {
+                    "type": "string"
+                },
+                "kty": {
+                    "type": "string"
+                },
+                "n": {
+                    "type": "string"
+                },
+                "use": {
+                    "type": "string"
+                },
+                "x": {
+                    "type": "string"
+                },
+                "y": {
+                    "type": "string"
+                }
+            }
+        },
+        "securecore.JWKSet": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/securecore.JWK"
+                    }
+                }
+            }
+        },
+        "typescore.Impersonation": {
+            "type": "object",
+            "properties": {
+                "actor_id": {
+                    "description": "Сотрудник, получивший токен",
+                    "type": "string"
+                },
+                "client_ip": {
+                    "description": "IP-адрес сотрудника",
+                    "type": "string"
+                },
+                "created_at": {
+                    "description": "Дата и время выдачи",
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "Срок действия токена",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Идентификатор (jti токена)",
+                    "type": "string"
+                },
+                "reason": {
+                    "description": "Причина (номер обращения и т.п.)",
+                    "type": "string"
+                },
+                "scopes": {
+                    "description": "Области доступа токена",
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "Пользователь, от имени которого выдан токен",
+                    "type": "string"
+                }
+            }
+        },
+        "typescore.OAuthClient": {
+            "type": "object",
+            "properties": {
+                "client_id": {
+                    "description": "Публичный идентификатор клиента",
+                    "type": "string"
+                },
+                "created_at": {
+                    "description": "Дата и время регистрации",
+                    "type": "string"
+                },
+                "grant_types": {
+                    "description": "Разрешенные типы grant",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Идентификатор записи",
+                    "type": "string"
+                },
+                "is_disabled": {
+                    "description": "Клиент отключен",
+                    "type": "boolean"
+                },
+                "name": {
+                    "description": "Название, отображаемое на экране согласия",
+                    "type": "string"
+                },
+                "public_key": {
+                    "description": "PEM публичного ключа для client_assertion (private_key_jwt) вместо секрета",
+                    "type": "string"
+                },
+                "redirect_uris": {
+                    "description": "Разрешенные адреса возврата (точное совпадение)",
+                    "type": "string"
+                },
+                "scopes": {
+                    "description": "Области доступа, которые может запрашивать клиент",
+                    "type": "string"
+                }
+            }
+        },
+        "typescore.PersonalAccessToken": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "Дата и время создания",
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "Срок действия; пусто — бессрочный",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Идентификатор токена",
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "description": "Дата и время последнего использования",
+                    "type": "string"
+                },
+                "last_used_ip": {
+                    "description": "IP-адрес последнего использования",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Название токена, заданное пользователем",
+                    "type": "string"
+                },
+                "scopes": {
+                    "description": "Области доступа токена",
+                    "type": "string"
+                }
+            }
+        },
+        "typescore.TokenPair": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "typescore.User": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "Дата и время создания записи",
+                    "type": "string"
                 },
                 "email": {
                     "description": "Адрес электронной почты пользователя",
                     "type": "string"
                 },
+                "email_verified_at": {
+                    "description": "Дата и время подтверждения email",
+                    "type": "string"
+                },
                 "first_name": {
                     "description": "Имя пользователя",
                     "type": "string"
@@ -220,6 +3593,10 @@ const docTemplate = `{
                 "telegram_id": {
                     "description": "Идентификатор пользователя в Telegram",
                     "type": "integer"
+                },
+                "totp_enabled_at": {
+                    "description": "Дата и время включения TOTP; пусто — TOTP не подтвержден",
+                    "type": "string"
                 }
             }
         },
@@ -243,6 +3620,251 @@ const docTemplate = `{
                 "SuperAdminRole",
                 "SupportRole"
             ]
+        },
+        "typescore.WebAuthnCredential": {
+            "type": "object",
+            "properties": {
+                "aaguid": {
+                    "description": "Модель аутентификатора",
+                    "type": "string"
+                },
+                "backup_eligible": {
+                    "description": "Ключ может синхронизироваться между устройствами",
+                    "type": "boolean"
+                },
+                "backup_state": {
+                    "description": "Ключ синхронизирован",
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "description": "Дата и время регистрации",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Идентификатор записи",
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "description": "Дата и время последнего входа",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Название ключа, заданное пользователем",
+                    "type": "string"
+                },
+                "transports": {
+                    "description": "Способы связи с аутентификатором через запятую",
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "Системный идентификатор пользователя",
+                    "type": "string"
+                }
+            }
+        },
+        "userhandler.CreatePersonalTokenReq": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "description": "Срок действия (RFC 3339); не задан — бессрочный",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Название токена",
+                    "type": "string"
+                },
+                "scopes": {
+                    "description": "Области доступа: подмножество областей доступа пользователя",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "userhandler.CreatePersonalTokenResp": {
+            "type": "object",
+            "properties": {
+                "personal_token": {
+                    "$ref": "#/definitions/typescore.PersonalAccessToken"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "userhandler.MFACodeReq": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Код из приложения-аутентификатора",
+                    "type": "string"
+                },
+                "recovery_code": {
+                    "description": "Одноразовый код восстановления (вместо code)",
+                    "type": "string"
+                }
+            }
+        },
+        "userhandler.PersonalTokenDeleteResp": {
+            "type": "object",
+            "properties": {
+                "deleted": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "userhandler.RecoveryCodesResp": {
+            "type": "object",
+            "properties": {
+                "recovery_codes": {
+                    "description": "Показываются один раз; хранятся только хеши",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "userhandler.SessionResp": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "current": {
+                    "description": "Сессия, которой принадлежит токен запроса",
+                    "type": "boolean"
+                },
+                "device_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "last_seen_at": {
+                    "type": "string"
+                },
+                "user_agent": {
+                    "type": "string"
+                }
+            }
+        },
+        "userhandler.TOTPDisableResp": {
+            "type": "object",
+            "properties": {
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "userhandler.TOTPEnrollResp": {
+            "type": "object",
+            "properties": {
+                "otpauth_uri": {
+                    "description": "URI otpauth://totp/ для QR-кода",
+                    "type": "string"
+                },
+                "secret": {
+                    "description": "Секрет в base32 для ручного ввода",
+                    "type": "string"
+                }
+            }
+        },
+        "userhandler.WebAuthnCredentialDeleteResp": {
+            "type": "object",
+            "properties": {
+                "deleted": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "userhandler.WebAuthnRegisterFinishReq": {
+            "type": "object",
+            "properties": {
+                "credential": {
+                    "description": "Ответ navigator.credentials.create()",
+                    "type": "object"
+                },
+                "name": {
+                    "description": "Название ключа, например \"MacBook\"",
+                    "type": "string"
+                }
+            }
+        },
+        "wellknownhandler.OpenIDConfigurationResp": {
+            "type": "object",
+            "properties": {
+                "authorization_endpoint": {
+                    "type": "string"
+                },
+                "claims_supported": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "code_challenge_methods_supported": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "grant_types_supported": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id_token_signing_alg_values_supported": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "introspection_endpoint": {
+                    "type": "string"
+                },
+                "issuer": {
+                    "type": "string"
+                },
+                "jwks_uri": {
+                    "type": "string"
+                },
+                "response_types_supported": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "scopes_supported": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "subject_types_supported": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "token_endpoint": {
+                    "type": "string"
+                },
+                "token_endpoint_auth_methods_supported": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "userinfo_endpoint": {
+                    "type": "string"
+                }
+            }
         }
     }
 }`
@@ -0,0 +1,152 @@
+package adminhandler
+
+import (
+	errm "authentication_service/core/errmodule"
+	protoobj "authentication_service/core/proto"
+	"authentication_service/core/securecore"
+	"authentication_service/core/typescore"
+	"authentication_service/rest_user_service/handler"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"net/http"
+)
+
+// максимальное число записей журнала в ответе
+const impersonationsListLimit uint64 = 100
+
+// ImpersonateReq параметры работы от имени пользователя
+type ImpersonateReq struct {
+	Reason *string  `json:"reason"` // Причина (номер обращения и т.п.): сохраняется в журнале и отправляется пользователю
+	Scopes []string `json:"scopes"` // Области доступа: только profile:read (по умолчанию)
+}
+
+// ImpersonateResp токен для работы от имени пользователя; refresh токен не выдается
+type ImpersonateResp struct {
+	AccessToken     string `json:"access_token"`
+	TokenType       string `json:"token_type"`
+	ExpiresIn       int64  `json:"expires_in"`
+	Scope           string `json:"scope"`
+	ImpersonationID string `json:"impersonation_id"`
+}
+
+// ImpersonateUserHandler Выдача токена для работы от имени пользователя
+// @Summary Работа от имени пользователя
+// @Description Выдает сотруднику поддержки или администратору короткоживущий access токен пользователя с claim act (RFC 8693).
+// @Description Токен дает только чтение профиля (profile:read).
+// @Description Токен не позволяет менять настройки безопасности, выдавать доступ клиентам OAuth 2.0 и не продлевается.
+// @Description Выдача сохраняется в журнале, пользователь получает уведомление; каждый запрос с токеном записывается в лог.
+// @Description Аккаунты сотрудников недоступны. Требуется область доступа users:read и недавняя аутентификация
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <access_token>"
+// @Param id path string true "Идентификатор пользователя"
+// @Param request body ImpersonateReq true "Причина и области доступа"
+// @Success 200 {object} ImpersonateResp "Успех"
+// @Failure 401 {object} handler.ErrorResponse "Невалидный токен или требуется повторная аутентификация (insufficient_user_authentication)"
+// @Failure 403 {object} handler.ErrorResponse "Недостаточно прав или аккаунт сотрудника"
+// @Failure 500 {object} handler.ErrorResponse "Некорректные параметры, пользователь не найден или ошибка сервера"
+// @Router /api/admin/users/{id}/impersonate [post]
+func (s *AdminReg) ImpersonateUserHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
+	logrus.Info("🤍 ImpersonateUserHandler")
+	ctx := r.Context()
+
+	actorID, err := handler.GetGuidFromContext(ctx)
+	if err != nil {
+		return nil, errm.NewError("user_address_not_found", err)
+	}
+
+	userID := chi.URLParam(r, "id")
+	if userID == "" {
+		return nil, errm.NewError("empty_obj", errors.New("empty_obj"))
+	}
+	if !securecore.IsValidUUID(userID) {
+		return nil, errm.NewError("not_found", errors.New("user not found"))
+	}
+
+	impersonateReq := &ImpersonateReq{}
+	if errObj := handler.ParseRequestBodyPost(r, impersonateReq); errObj != nil {
+		return nil, errObj
+	}
+	if impersonateReq.Reason == nil {
+		return nil, errm.NewError("empty_obj", errors.New("reason is required"))
+	}
+
+	resp, err := s.ipc.ClientAuthServiceProto.ImpersonateUser(ctx, &protoobj.ImpersonateUserRequest{
+		ActorId:  actorID,
+		UserId:   userID,
+		ClientIp: handler.GetClientIP(r),
+		Scopes:   impersonateReq.Scopes,
+		Reason:   *impersonateReq.Reason,
+	})
+	if err != nil {
+		if status.Code(err) == codes.PermissionDenied {
+			w.WriteHeader(http.StatusForbidden)
+		}
+		return nil, errm.NewError("impersonation_error", err)
+	}
+
+	return &ImpersonateResp{
+		AccessToken:     resp.GetAccessToken(),
+		TokenType:       "Bearer",
+		ExpiresIn:       resp.GetExpiresIn(),
+		Scope:           resp.GetScope(),
+		ImpersonationID: resp.GetImpersonationId(),
+	}, nil
+}
+
+// GetImpersonationsHandler Журнал работы от имени пользователей
+// @Summary Журнал работы от имени пользователей
+// @Description Возвращает последние выдачи токенов для работы от имени пользователей: сотрудник, пользователь, причина, области доступа и срок действия.
+// @Description Идентификатор записи совпадает с jti токена и с impersonation_id в логе запросов. Доступно с областью доступа admin,
+// @Description в том числе сервисным аккаунтам (токен client_credentials)
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer <access_token>"
+// @Param user_id query string false "Идентификатор пользователя"
+// @Param actor_id query string false "Идентификатор сотрудника"
+// @Success 200 {array} typescore.Impersonation "Успех"
+// @Failure 401 {object} handler.ErrorResponse "Невалидный или отозванный токен"
+// @Failure 403 {object} handler.ErrorResponse "Недостаточно прав"
+// @Failure 500 {object} handler.ErrorResponse "Ошибка сервера"
+// @Router /api/admin/impersonations [get]
+func (s *AdminReg) GetImpersonationsHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
+	logrus.Info("🤍 GetImpersonationsHandler")
+	ctx := r.Context()
+
+	// Выгрузка журнала сервисом записывается в лог вместе с его client_id
+	if clientID, err := handler.GetServiceAccountFromContext(ctx); err == nil {
+		logrus.Infof("🤖 impersonation log requested by service account: client_id=%s", clientID)
+	}
+
+	filter := &typescore.Impersonation{}
+	if userID := r.URL.Query().Get("user_id"); userID != "" {
+		if !securecore.IsValidUUID(userID) {
+			return nil, errm.NewError("invalid_params", errors.New("user_id must be a valid uuid"))
+		}
+		filter.UserID = &userID
+	}
+	if actorID := r.URL.Query().Get("actor_id"); actorID != "" {
+		if !securecore.IsValidUUID(actorID) {
+			return nil, errm.NewError("invalid_params", errors.New("actor_id must be a valid uuid"))
+		}
+		filter.ActorID = &actorID
+	}
+
+	limit := impersonationsListLimit
+	impersonations, _, errObj := s.ipc.DB.Impersonations.GetImpersonationsListDB(ctx, typescore.ListDbOptions{
+		Filtering: filter,
+		Limit:     &limit,
+	})
+	if errObj != nil {
+		return nil, errObj
+	}
+	if impersonations == nil {
+		impersonations = []*typescore.Impersonation{}
+	}
+
+	return impersonations, nil
+}
@@ -0,0 +1,64 @@
+package adminhandler
+
+import (
+	errm "authentication_service/core/errmodule"
+	"authentication_service/core/typescore"
+	"authentication_service/rest_user_service/handler"
+	typesm "authentication_service/rest_user_service/types"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+)
+
+const (
+	unlockUserURI     = "/users/{id}/unlock"
+	impersonateURI    = "/users/{id}/impersonate"
+	impersonationsURI = "/impersonations"
+)
+
+type AdminReg struct {
+	ipc *typesm.InternalProviderControl
+}
+
+// RegisterAdminRoutes регистрирует маршруты администрирования пользователей
+func RegisterAdminRoutes(
+	r chi.Router,
+	ipc *typesm.InternalProviderControl,
+) *errm.Error {
+
+	s := &AdminReg{
+		ipc: ipc,
+	}
+
+	verifier := handler.JWTVerifier(handler.JWTVerifierParams{
+		TokenFormat:   ipc.TokenFormat,
+		TokenPolicy:   ipc.TokenPolicy,
+		TokenDenylist: ipc.TokenDenylist,
+	})
+
+	// Журнал работы от имени пользователей выгружается и сервисными аккаунтами, например в систему аудита
+	auditVerifier := handler.JWTVerifier(handler.JWTVerifierParams{
+		TokenFormat:          ipc.TokenFormat,
+		TokenPolicy:          ipc.TokenPolicy,
+		TokenDenylist:        ipc.TokenDenylist,
+		AllowServiceAccounts: true,
+	})
+
+	r.Route("/api/admin", func(r chi.Router) {
+		rl := r.With(auditVerifier, handler.RequireFirstPartyOrServiceAccount, handler.RequireSessionToken, handler.RequireScope(typescore.AdminScope))
+		handler.RegisterRoute(rl, http.MethodGet, impersonationsURI, s.GetImpersonationsHandler)
+
+		r.Group(func(r chi.Router) {
+			// Токен сотрудника, работающего от имени пользователя, администрирование не открывает
+			r.Use(verifier, handler.RequireFirstPartyToken, handler.RequireSessionToken)
+
+			// Работа от имени пользователя доступна поддержке и требует недавней аутентификации
+			rs := r.With(handler.RequireScope(typescore.UsersReadScope), handler.RequireRecentAuth(ipc.Config.ExposedServiceConfig.UserService.StepUp.MaxAge))
+			handler.RegisterRoute(rs, http.MethodPost, impersonateURI, s.ImpersonateUserHandler)
+
+			ra := r.With(handler.RequireScope(typescore.AdminScope))
+			handler.RegisterRoute(ra, http.MethodPost, unlockUserURI, s.UnlockUserHandler)
+		})
+	})
+
+	return nil
+}
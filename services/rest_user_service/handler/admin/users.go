@@ -0,0 +1,62 @@
+package adminhandler
+
+import (
+	errm "authentication_service/core/errmodule"
+	"authentication_service/core/securecore"
+	"authentication_service/core/typescore"
+	"authentication_service/rest_user_service/handler"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+	"net/http"
+)
+
+type UnlockUserResp struct {
+	Unlocked bool `json:"unlocked"`
+}
+
+// UnlockUserHandler Разблокировка аккаунта после неудачных попыток входа
+// @Summary Разблокировка аккаунта
+// @Description Снимает временную блокировку аккаунта после неудачных попыток входа и ввода второго фактора и сбрасывает счетчики попыток.
+// @Description Доступно с областью доступа admin
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer <access_token>"
+// @Param id path string true "Идентификатор пользователя"
+// @Success 200 {object} UnlockUserResp "Успех"
+// @Failure 401 {object} handler.ErrorResponse "Невалидный или отозванный токен"
+// @Failure 403 {object} handler.ErrorResponse "Недостаточно прав"
+// @Failure 500 {object} handler.ErrorResponse "Пользователь не найден или ошибка сервера"
+// @Router /api/admin/users/{id}/unlock [post]
+func (s *AdminReg) UnlockUserHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
+	logrus.Info("🤍 UnlockUserHandler")
+	ctx := r.Context()
+
+	userID := chi.URLParam(r, "id")
+	if userID == "" {
+		return nil, errm.NewError("empty_obj", errors.New("empty_obj"))
+	}
+	if !securecore.IsValidUUID(userID) {
+		return nil, errm.NewError("not_found", errors.New("user not found"))
+	}
+
+	users, _, errObj := s.ipc.DB.Users.GetUsersListDB(ctx, typescore.ListDbOptions{Filtering: &typescore.User{
+		SystemID: &userID,
+	}})
+	if errObj != nil {
+		return nil, errObj
+	}
+	if len(users) == 0 {
+		return nil, errm.NewError("not_found", errors.New("user not found"))
+	}
+
+	if s.ipc.Lockout != nil {
+		s.ipc.Lockout.Unlock(ctx, userID)
+		s.ipc.Lockout.Unlock(ctx, handler.SecondFactorAccount(userID))
+	}
+
+	adminID, _ := handler.GetGuidFromContext(ctx)
+	logrus.Infof("🔓 account unlocked: user_id=%s admin_id=%s", userID, adminID)
+
+	return &UnlockUserResp{Unlocked: true}, nil
+}
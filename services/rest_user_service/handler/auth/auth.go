@@ -6,14 +6,12 @@ import (
 	"authentication_service/rest_user_service/handler"
 	"errors"
 	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"net/http"
 	"strings"
 )
 
-type GetTokensPairReq struct {
-	UserID *string `json:"user_id"`
-}
-
 // RefreshTokensHandler Обновление токенов
 // @Summary Обновление токенов
 // @Description Обновляет Access и Refresh токены на основе действующего Refresh токена
@@ -23,7 +21,8 @@ type GetTokensPairReq struct {
 // @Param Authorization header string true "Bearer <refresh_token>"
 // @Success 200 {object} typescore.TokenPair "Успех"
 // @Failure 400 {object} handler.ErrorResponse "Некорректный запрос"
-// @Failure 401 {object} handler.ErrorResponse "Невалидный или просроченный токен"
+// @Failure 401 {object} handler.ErrorResponse "Невалидный или просроченный токен; step_up_required — сеть сменилась, нужна повторная аутентификация"
+// @Failure 429 {object} handler.ErrorResponse "Слишком много неудачных попыток: повторить после Retry-After"
 // @Failure 500 {object} handler.ErrorResponse "Ошибка сервера"
 // @Router /api/auth/refresh [post]
 func (s *AuthReg) RefreshTokensHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
@@ -44,53 +43,27 @@ func (s *AuthReg) RefreshTokensHandler(w http.ResponseWriter, r *http.Request) (
 	refreshToken := parts[1]
 
 	// Получение IP-адреса клиента
-	ip := r.RemoteAddr
-	if strings.Contains(ip, ":") {
-		ip = strings.Split(ip, ":")[0]
+	ip := handler.GetClientIP(r)
+
+	if errObj := handler.CheckAuthLockout(w, r, s.ipc.Lockout, ""); errObj != nil {
+		return nil, errObj
 	}
 
 	// Проверка и обновление токенов
 	newTokenPair, err := s.ipc.ClientAuthServiceProto.RefreshTokens(ctx, &protoobj.RefreshTokensRequest{ClientIp: ip, RefreshToken: refreshToken})
 	if err != nil {
+		switch status.Code(err) {
+		case codes.Unauthenticated:
+			// Перебор refresh токенов ограничивается по IP-адресу
+			handler.RecordAuthFailure(r, s.ipc.Lockout, s.ipc.RabbitMQ, "", nil)
+			return nil, handler.RejectCredentials(w, errm.NewError("invalid_token", err))
+		case codes.FailedPrecondition:
+			// Смена сети при политике step_up: требуется повторная аутентификация, а не подбор токена
+			w.WriteHeader(http.StatusUnauthorized)
+			return nil, errm.NewError("step_up_required", err)
+		}
 		return nil, errm.NewError("token_generation_error", err)
 	}
 
 	return newTokenPair, nil
 }
-
-// IssueTokensHandler Выдача пары токенов (Access + Refresh)
-// @Summary Выдача пары токенов
-// @Description Выдает Access и Refresh токены для пользователя с указанным GUID
-// @Tags auth
-// @Accept json
-// @Produce json
-// @Param guid query string true "GUID пользователя"
-// @Success 200 {object} typescore.TokenPair "Успех"
-// @Failure 400 {object} handler.ErrorResponse "Некорректный запрос"
-// @Failure 500 {object} handler.ErrorResponse "Ошибка сервера"
-// @Router /api/auth/issue [post]
-func (s *AuthReg) IssueTokensHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
-	logrus.Info("🤍 IssueTokensHandler")
-	ctx := r.Context()
-
-	tokenReq := &GetTokensPairReq{}
-	if errObj := handler.ParseRequestBodyPost(r, tokenReq); errObj != nil {
-		return nil, errObj
-	}
-	if tokenReq == nil || tokenReq.UserID == nil {
-		return nil, errm.NewError("empty_obj", errors.New("empty_obj"))
-	}
-
-	ip := r.RemoteAddr
-	if strings.Contains(ip, ":") {
-		ip = strings.Split(ip, ":")[0]
-	}
-
-	// Генерация токенов
-	accessToken, err := s.ipc.ClientAuthServiceProto.IssueTokens(ctx, &protoobj.IssueTokensRequest{UserId: *tokenReq.UserID, ClientIp: ip})
-	if err != nil {
-		return nil, errm.NewError("token_generation_error", err)
-	}
-
-	return accessToken, nil
-}
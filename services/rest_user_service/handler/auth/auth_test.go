@@ -0,0 +1,56 @@
+package authhandler
+
+import (
+	protoobj "authentication_service/core/proto"
+	"authentication_service/rest_user_service/handler"
+	typesm "authentication_service/rest_user_service/types"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeRefreshService отвечает на обновление токенов заданной ошибкой
+type fakeRefreshService struct {
+	protoobj.AuthServiceClient
+	err error
+}
+
+func (f *fakeRefreshService) RefreshTokens(_ context.Context, _ *protoobj.RefreshTokensRequest, _ ...grpc.CallOption) (*protoobj.RefreshTokensResponse, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &protoobj.RefreshTokensResponse{AccessToken: "access", RefreshToken: "refresh"}, nil
+}
+
+func TestRefreshTokensHandlerStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"rotated", nil, http.StatusOK},
+		{"invalid or reused token", status.Error(codes.Unauthenticated, "refresh token reused"), http.StatusUnauthorized},
+		{"step up required", status.Error(codes.FailedPrecondition, "client ip changed"), http.StatusUnauthorized},
+		{"service unavailable", status.Error(codes.Unavailable, "connection refused"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &AuthReg{ipc: &typesm.InternalProviderControl{ClientAuthServiceProto: &fakeRefreshService{err: tt.err}}}
+
+			r := httptest.NewRequest(http.MethodPost, "/api/auth/refresh", nil)
+			r.Header.Set("Authorization", "Bearer refresh-token")
+			w := httptest.NewRecorder()
+			handler.WrapHandlerF(handler.WrapHandlerParams{HandlerFunc: s.RefreshTokensHandler}).ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package authhandler
+
+import (
+	"authentication_service/core/configcore"
+	errm "authentication_service/core/errmodule"
+	rabbitmqlib "authentication_service/core/lib/external/rabbitmq-lib"
+	"authentication_service/core/lib/internally/onetimecode"
+	"authentication_service/core/typescore"
+	"authentication_service/core/variables"
+	"authentication_service/rest_user_service/handler"
+	"context"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	emailVerifyPurpose    = "email_verify"
+	emailVerifyCodeDigits = 6
+
+	defaultEmailVerifyCodeTTL        = 15 * time.Minute
+	defaultEmailVerifyMaxAttempts    = 5
+	defaultEmailVerifyResendInterval = time.Minute
+)
+
+var errInvalidEmailCode = errors.New("invalid or expired code")
+
+type ConfirmEmailReq struct {
+	Email *string `json:"email"`
+	Code  *string `json:"code"`
+}
+
+type ConfirmEmailResp struct {
+	Verified bool `json:"verified"`
+}
+
+type ResendEmailCodeResp struct {
+	Sent bool `json:"sent"`
+}
+
+// ConfirmEmailHandler Подтверждение email
+// @Summary Подтверждение email
+// @Description Подтверждает адрес электронной почты кодом из письма. Полные области доступа выдаются после обновления токенов
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body ConfirmEmailReq true "Email и код подтверждения"
+// @Success 200 {object} ConfirmEmailResp "Успех"
+// @Failure 400 {object} handler.ErrorResponse "Неверный или просроченный код либо исчерпаны попытки ввода кода"
+// @Failure 429 {object} handler.ErrorResponse "Слишком много неудачных попыток: повторить после Retry-After"
+// @Failure 500 {object} handler.ErrorResponse "Ошибка сервера"
+// @Router /api/auth/email/confirm [post]
+func (s *AuthReg) ConfirmEmailHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
+	logrus.Info("🤍 ConfirmEmailHandler")
+	ctx := r.Context()
+
+	confirmReq := &ConfirmEmailReq{}
+	if errObj := handler.ParseRequestBodyPost(r, confirmReq); errObj != nil {
+		return nil, errObj
+	}
+	email := normalizeEmail(confirmReq.Email)
+	if email == nil || confirmReq.Code == nil || *confirmReq.Code == "" {
+		return nil, errm.NewError("empty_obj", errors.New("email and code are required"))
+	}
+
+	// Попытки на код ограничены самим кодом; перебор по разным адресам ограничивается по IP-адресу
+	if errObj := handler.CheckAuthLockout(w, r, s.ipc.Lockout, ""); errObj != nil {
+		return nil, errObj
+	}
+
+	user, errObj := s.findUser(ctx, &typescore.User{Email: email})
+	if errObj != nil {
+		return nil, errObj
+	}
+	// Неизвестный адрес не отличается от неверного кода
+	if user == nil {
+		handler.RecordAuthFailure(r, s.ipc.Lockout, s.ipc.RabbitMQ, "", nil)
+		w.WriteHeader(http.StatusBadRequest)
+		return nil, errm.NewError("invalid_code", errInvalidEmailCode)
+	}
+	if user.EmailVerifiedAt != nil {
+		return &ConfirmEmailResp{Verified: true}, nil
+	}
+
+	err := s.ipc.OneTimeCodes.Verify(ctx, emailVerifyPurpose, *user.SystemID, *confirmReq.Code)
+	switch {
+	case errors.Is(err, onetimecode.ErrTooManyAttempts):
+		handler.RecordAuthFailure(r, s.ipc.Lockout, s.ipc.RabbitMQ, "", nil)
+		w.WriteHeader(http.StatusBadRequest)
+		return nil, errm.NewError("too_many_attempts", err)
+	case errors.Is(err, onetimecode.ErrCodeInvalid), errors.Is(err, onetimecode.ErrCodeNotFound):
+		handler.RecordAuthFailure(r, s.ipc.Lockout, s.ipc.RabbitMQ, "", nil)
+		w.WriteHeader(http.StatusBadRequest)
+		return nil, errm.NewError("invalid_code", errInvalidEmailCode)
+	case err != nil:
+		return nil, errm.NewError("code_verify_error", err)
+	}
+
+	now := time.Now().UTC()
+	_, _, errObj = s.ipc.DB.Users.UpdateUserDB(ctx, nil, &typescore.User{
+		SystemID:        user.SystemID,
+		EmailVerifiedAt: &now,
+	})
+	if errObj != nil {
+		return nil, errObj
+	}
+
+	return &ConfirmEmailResp{Verified: true}, nil
+}
+
+// ResendEmailCodeHandler Повторная отправка кода подтверждения email
+// @Summary Повторная отправка кода подтверждения email
+// @Description Отправляет новый код подтверждения на email пользователя. Предыдущий код аннулируется
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <access_token>"
+// @Success 200 {object} ResendEmailCodeResp "Успех"
+// @Failure 400 {object} handler.ErrorResponse "Email уже подтвержден"
+// @Failure 401 {object} handler.ErrorResponse "Невалидный или отозванный токен"
+// @Failure 403 {object} handler.ErrorResponse "Недостаточно прав"
+// @Failure 429 {object} handler.ErrorResponse "Код отправлен недавно"
+// @Failure 500 {object} handler.ErrorResponse "Ошибка сервера"
+// @Router /api/auth/email/resend [post]
+func (s *AuthReg) ResendEmailCodeHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
+	logrus.Info("🤍 ResendEmailCodeHandler")
+	ctx := r.Context()
+
+	guidUser, err := handler.GetGuidFromContext(ctx)
+	if err != nil {
+		return nil, errm.NewError("user_address_not_found", err)
+	}
+
+	user, errObj := s.findUser(ctx, &typescore.User{SystemID: &guidUser})
+	if errObj != nil {
+		return nil, errObj
+	}
+	if user == nil || user.Email == nil {
+		return nil, errm.NewError("email_not_found", errors.New("user has no email"))
+	}
+	if user.EmailVerifiedAt != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return nil, errm.NewError("email_already_verified", errors.New("email is already verified"))
+	}
+
+	if errObj := s.sendEmailVerification(ctx, user); errObj != nil {
+		if errors.Is(errObj.Error, onetimecode.ErrResendTooSoon) {
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+		return nil, errObj
+	}
+
+	return &ResendEmailCodeResp{Sent: true}, nil
+}
+
+// sendEmailVerification выдает новый код подтверждения и отправляет его через сервис уведомлений
+func (s *AuthReg) sendEmailVerification(ctx context.Context, user *typescore.User) *errm.Error {
+	code, err := onetimecode.GenerateNumericCode(emailVerifyCodeDigits)
+	if err != nil {
+		return errm.NewError("code_generation_error", err)
+	}
+
+	cfg := s.ipc.Config.ExposedServiceConfig.UserService.EmailVerification
+	err = s.ipc.OneTimeCodes.Issue(ctx, emailVerifyPurpose, *user.SystemID, code, emailVerificationParams(cfg))
+	if errors.Is(err, onetimecode.ErrResendTooSoon) {
+		return errm.NewError("resend_too_soon", err)
+	}
+	if err != nil {
+		return errm.NewError("code_store_error", err)
+	}
+
+	category := typescore.EmailVerifyNotifyCategory
+	notify := &typescore.NotifyParams{
+		Text:      &code,
+		IsEmail:   true,
+		Emergency: true,
+		UsersIDs:  []*string{user.SystemID},
+		Category:  &category,
+	}
+	if cfg.LinkURL != "" {
+		link := cfg.LinkURL + "?" + url.Values{"email": {*user.Email}, "code": {code}}.Encode()
+		notify.LinkURL = &link
+	}
+
+	return rabbitmqlib.PublishMessage(s.ipc.RabbitMQ,
+		variables.RabbitMQExchangeNotifications,
+		variables.RabbitMQNotificationsServiceRoute,
+		notify)
+}
+
+// emailVerificationParams параметры кода подтверждения с учетом значений по умолчанию
+func emailVerificationParams(cfg configcore.EmailVerificationConfig) onetimecode.Params {
+	p := onetimecode.Params{
+		TTL:            cfg.CodeTTL,
+		MaxAttempts:    cfg.MaxAttempts,
+		ResendInterval: cfg.ResendInterval,
+	}
+	if p.TTL <= 0 {
+		p.TTL = defaultEmailVerifyCodeTTL
+	}
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = defaultEmailVerifyMaxAttempts
+	}
+	if p.ResendInterval <= 0 {
+		p.ResendInterval = defaultEmailVerifyResendInterval
+	}
+	return p
+}
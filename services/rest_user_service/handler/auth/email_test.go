@@ -0,0 +1,70 @@
+package authhandler
+
+import (
+	"authentication_service/core/database"
+	dbcore "authentication_service/core/database/db"
+	errm "authentication_service/core/errmodule"
+	"authentication_service/core/lib/internally/onetimecode"
+	"authentication_service/core/typescore"
+	"authentication_service/rest_user_service/handler"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeUserDB пользователи по email; остальные методы UserDBI не используются
+type fakeUserDB struct {
+	dbcore.UserDBI
+	users []*typescore.User
+}
+
+func (f *fakeUserDB) GetUsersListDB(_ context.Context, options ...typescore.ListDbOptions) ([]*typescore.User, uint64, *errm.Error) {
+	filter := options[0].Filtering.(*typescore.User)
+	for _, user := range f.users {
+		if filter.Email != nil && user.Email != nil && *filter.Email == *user.Email {
+			return []*typescore.User{user}, 1, nil
+		}
+	}
+	return nil, 0, nil
+}
+
+func TestConfirmEmailHandlerStatus(t *testing.T) {
+	const code = "123456"
+	userID, email := testUserID, "user@example.com"
+
+	tests := []struct {
+		name     string
+		body     string
+		attempts int // неудачные попытки до запроса
+	}{
+		{"unknown address", `{"email":"other@example.com","code":"123456"}`, 0},
+		{"wrong code", `{"email":"user@example.com","code":"000000"}`, 0},
+		{"attempts exhausted", `{"email":"user@example.com","code":"123456"}`, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := newTestAuthReg()
+			s.ipc.DB = &database.ModuleDB{Users: &fakeUserDB{users: []*typescore.User{{SystemID: &userID, Email: &email}}}}
+			params := onetimecode.Params{TTL: time.Minute, MaxAttempts: 3}
+			if err := s.ipc.OneTimeCodes.Issue(ctx, emailVerifyPurpose, userID, code, params); err != nil {
+				t.Fatalf("Issue() error = %v", err)
+			}
+			for i := 0; i < tt.attempts; i++ {
+				_ = s.ipc.OneTimeCodes.Verify(ctx, emailVerifyPurpose, userID, "000000")
+			}
+
+			r := httptest.NewRequest(http.MethodPost, "/api/auth/email/confirm", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			handler.WrapHandlerF(handler.WrapHandlerParams{HandlerFunc: s.ConfirmEmailHandler}).ServeHTTP(w, r)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
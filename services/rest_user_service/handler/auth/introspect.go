@@ -0,0 +1,104 @@
+package authhandler
+
+import (
+	errm "authentication_service/core/errmodule"
+	protoobj "authentication_service/core/proto"
+	"authentication_service/rest_user_service/handler"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"net/http"
+)
+
+// IntrospectTokenResp ответ интроспекции в формате RFC 7662
+type IntrospectTokenResp struct {
+	Active    bool     `json:"active"`
+	Sub       string   `json:"sub,omitempty"`
+	Exp       int64    `json:"exp,omitempty"`
+	Iat       int64    `json:"iat,omitempty"`
+	TokenType string   `json:"token_type,omitempty"`
+	Scope     string   `json:"scope,omitempty"`
+	Roles     []string `json:"roles,omitempty"`
+	Sid       string   `json:"sid,omitempty"`
+	Jti       string   `json:"jti,omitempty"`
+	ClientID  string   `json:"client_id,omitempty"`
+	Act       *Actor   `json:"act,omitempty"`
+}
+
+// Actor субъект, действующий от имени пользователя (claim act, RFC 8693)
+type Actor struct {
+	Sub string `json:"sub"`
+}
+
+// IntrospectTokenHandler Интроспекция токена
+// @Summary Интроспекция токена
+// @Description Возвращает состояние токена (RFC 7662). Клиент аутентифицируется через HTTP Basic (client_id:client_secret) или параметры client_id и client_secret
+// @Tags auth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param token formData string true "Токен"
+// @Param token_type_hint formData string false "access_token или refresh_token"
+// @Success 200 {object} IntrospectTokenResp "Успех"
+// @Failure 400 {object} handler.ErrorResponse "Некорректный запрос"
+// @Failure 401 {object} handler.ErrorResponse "Неверные учетные данные клиента"
+// @Failure 429 {object} handler.ErrorResponse "Слишком много неудачных попыток: повторить после Retry-After"
+// @Failure 500 {object} handler.ErrorResponse "Ошибка сервера"
+// @Router /api/auth/introspect [post]
+func (s *AuthReg) IntrospectTokenHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
+	logrus.Info("🤍 IntrospectTokenHandler")
+	ctx := r.Context()
+
+	if err := r.ParseForm(); err != nil {
+		return nil, errm.NewError("parse_form_error", err)
+	}
+
+	token := r.PostForm.Get("token")
+	if token == "" {
+		return nil, errm.NewError("empty_obj", errors.New("token is required"))
+	}
+
+	if errObj := handler.CheckAuthLockout(w, r, s.ipc.Lockout, ""); errObj != nil {
+		return nil, errObj
+	}
+
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID = r.PostForm.Get("client_id")
+		clientSecret = r.PostForm.Get("client_secret")
+	}
+
+	resp, err := s.ipc.ClientAuthServiceProto.IntrospectToken(ctx, &protoobj.IntrospectTokenRequest{
+		Token:         token,
+		TokenTypeHint: r.PostForm.Get("token_type_hint"),
+		ClientId:      clientID,
+		ClientSecret:  clientSecret,
+	})
+	if err != nil {
+		// Перебор секретов клиента ограничивается по IP-адресу
+		if status.Code(err) == codes.Unauthenticated {
+			handler.RecordAuthFailure(r, s.ipc.Lockout, s.ipc.RabbitMQ, "", nil)
+			w.Header().Set("WWW-Authenticate", `Basic realm="introspect"`)
+			return nil, handler.RejectCredentials(w, errm.NewError("invalid_client", err))
+		}
+		return nil, errm.NewError("token_introspect_error", err)
+	}
+
+	introspectResp := &IntrospectTokenResp{
+		Active:    resp.GetActive(),
+		Sub:       resp.GetSub(),
+		Exp:       resp.GetExp(),
+		Iat:       resp.GetIat(),
+		TokenType: resp.GetTokenType(),
+		Scope:     resp.GetScope(),
+		Roles:     resp.GetRoles(),
+		Sid:       resp.GetSid(),
+		Jti:       resp.GetJti(),
+		ClientID:  resp.GetClientId(),
+	}
+	if actSub := resp.GetActSub(); actSub != "" {
+		introspectResp.Act = &Actor{Sub: actSub}
+	}
+
+	return introspectResp, nil
+}
@@ -0,0 +1,65 @@
+package authhandler
+
+import (
+	protoobj "authentication_service/core/proto"
+	"authentication_service/rest_user_service/handler"
+	typesm "authentication_service/rest_user_service/types"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeIntrospectService принимает единственного клиента gateway:gateway-secret
+type fakeIntrospectService struct {
+	protoobj.AuthServiceClient
+}
+
+func (f *fakeIntrospectService) IntrospectToken(_ context.Context, req *protoobj.IntrospectTokenRequest, _ ...grpc.CallOption) (*protoobj.IntrospectTokenResponse, error) {
+	if req.GetClientId() != "gateway" || req.GetClientSecret() != "gateway-secret" {
+		return nil, status.Error(codes.Unauthenticated, "invalid client credentials")
+	}
+	return &protoobj.IntrospectTokenResponse{Active: true}, nil
+}
+
+func TestIntrospectTokenHandlerStatus(t *testing.T) {
+	tests := []struct {
+		name          string
+		basicAuth     []string // client_id и client_secret в заголовке Authorization
+		form          url.Values
+		wantStatus    int
+		wantChallenge bool
+	}{
+		{"basic auth", []string{"gateway", "gateway-secret"}, url.Values{"token": {"token"}}, http.StatusOK, false},
+		{"form credentials", nil, url.Values{"token": {"token"}, "client_id": {"gateway"}, "client_secret": {"gateway-secret"}}, http.StatusOK, false},
+		{"wrong secret", []string{"gateway", "other-secret"}, url.Values{"token": {"token"}}, http.StatusUnauthorized, true},
+		{"no credentials", nil, url.Values{"token": {"token"}}, http.StatusUnauthorized, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &AuthReg{ipc: &typesm.InternalProviderControl{ClientAuthServiceProto: &fakeIntrospectService{}}}
+
+			r := httptest.NewRequest(http.MethodPost, "/api/auth/introspect", strings.NewReader(tt.form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if tt.basicAuth != nil {
+				r.SetBasicAuth(tt.basicAuth[0], tt.basicAuth[1])
+			}
+			w := httptest.NewRecorder()
+			handler.WrapHandlerF(handler.WrapHandlerParams{HandlerFunc: s.IntrospectTokenHandler}).ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("WWW-Authenticate") != ""; got != tt.wantChallenge {
+				t.Fatalf("WWW-Authenticate = %q, want present %v", w.Header().Get("WWW-Authenticate"), tt.wantChallenge)
+			}
+		})
+	}
+}
@@ -0,0 +1,276 @@
+package authhandler
+
+import (
+	"authentication_service/core/configcore"
+	errm "authentication_service/core/errmodule"
+	rabbitmqlib "authentication_service/core/lib/external/rabbitmq-lib"
+	"authentication_service/core/lib/internally/onetimecode"
+	"authentication_service/core/securecore"
+	"authentication_service/core/typescore"
+	"authentication_service/core/variables"
+	"authentication_service/rest_user_service/handler"
+	"context"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	magicLinkPurpose       = "magic_link"
+	magicLinkRateKeyPrefix = "magiclink:rate:"
+	magicLinkNonceCookie   = "magic_link_nonce"
+	magicLinkCookiePath    = "/api/auth" + magicLinkURI
+
+	defaultMagicLinkTokenTTL       = 15 * time.Minute
+	defaultMagicLinkResendInterval = time.Minute
+)
+
+var (
+	errInvalidMagicLink = errors.New("invalid or expired sign-in link")
+	errMagicLinkTooSoon = errors.New("sign-in link was requested too recently")
+)
+
+type MagicLinkReq struct {
+	Email *string `json:"email"`
+}
+
+type MagicLinkResp struct {
+	Sent bool `json:"sent"`
+}
+
+type MagicLinkConsumeReq struct {
+	Token      *string `json:"token"`       // Токен из ссылки в письме
+	DeviceName *string `json:"device_name"` // Название устройства для списка сессий
+}
+
+// MagicLinkHandler Запрос ссылки для входа
+// @Summary Запрос ссылки для входа
+// @Description Отправляет на email одноразовую ссылку входа и устанавливает cookie, без которой ссылка не принимается.
+// @Description Ответ не зависит от существования аккаунта; на один адрес письмо отправляется не чаще resend_interval
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body MagicLinkReq true "Email аккаунта"
+// @Success 200 {object} MagicLinkResp "Успех"
+// @Failure 400 {object} handler.ErrorResponse "Некорректный запрос"
+// @Failure 429 {object} handler.ErrorResponse "Ссылка на этот адрес уже запрошена: повторить после Retry-After"
+// @Failure 500 {object} handler.ErrorResponse "Ошибка сервера"
+// @Router /api/auth/magic-link [post]
+func (s *AuthReg) MagicLinkHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
+	logrus.Info("🤍 MagicLinkHandler")
+	ctx := r.Context()
+
+	linkReq := &MagicLinkReq{}
+	if errObj := handler.ParseRequestBodyPost(r, linkReq); errObj != nil {
+		return nil, errObj
+	}
+	email := normalizeEmail(linkReq.Email)
+	if email == nil {
+		return nil, errm.NewError("empty_obj", errors.New("email is required"))
+	}
+
+	// Ограничение действует и для неизвестных адресов, чтобы не раскрывать существование аккаунта
+	cfg := s.ipc.Config.ExposedServiceConfig.UserService.MagicLink
+	resendInterval := magicLinkParams(cfg).ResendInterval
+	if errObj := s.acquireMagicLinkSlot(ctx, *email, resendInterval); errObj != nil {
+		if errors.Is(errObj.Error, errMagicLinkTooSoon) {
+			w.Header().Set("Retry-After", strconv.Itoa(int(resendInterval.Seconds())))
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+		return nil, errObj
+	}
+
+	// Nonce в cookie привязывает ссылку к браузеру, в котором запрошен вход
+	nonce, err := onetimecode.GenerateLinkToken()
+	if err != nil {
+		return nil, errm.NewError("token_generation_error", err)
+	}
+	ttl := magicLinkParams(cfg).TTL
+	http.SetCookie(w, &http.Cookie{
+		Name:     magicLinkNonceCookie,
+		Value:    nonce,
+		Path:     magicLinkCookiePath,
+		MaxAge:   int(ttl.Seconds()),
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	// Ошибки только логируются: ответ не должен раскрывать, существует ли аккаунт
+	user, errObj := s.findUser(ctx, &typescore.User{Email: email})
+	switch {
+	case errObj != nil:
+		logrus.Errorf("magic link: failed to get user: %v", errObj.Error)
+	case user != nil:
+		if errObj := s.sendMagicLink(ctx, user, nonce); errObj != nil {
+			logrus.Warnf("magic link: link not sent: user_id=%s: %v", *user.SystemID, errObj.Error)
+		}
+	}
+
+	return &MagicLinkResp{Sent: true}, nil
+}
+
+// MagicLinkConsumeHandler Вход по ссылке из письма
+// @Summary Вход по ссылке из письма
+// @Description Принимает токен из ссылки в том же браузере, в котором запрошен вход, и выдает Access и Refresh токены.
+// @Description Если для аккаунта включен второй фактор, вместо токенов возвращается MFAChallengeResp
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body MagicLinkConsumeReq true "Токен из ссылки"
+// @Success 200 {object} protoobj.IssueTokensResponse "Успех"
+// @Failure 400 {object} handler.ErrorResponse "Некорректный запрос"
+// @Failure 401 {object} handler.ErrorResponse "Неверная или просроченная ссылка либо запрос из другого браузера"
+// @Failure 429 {object} handler.ErrorResponse "Слишком много неудачных попыток: повторить после Retry-After"
+// @Failure 500 {object} handler.ErrorResponse "Ошибка сервера"
+// @Router /api/auth/magic-link/consume [post]
+func (s *AuthReg) MagicLinkConsumeHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
+	logrus.Info("🤍 MagicLinkConsumeHandler")
+	ctx := r.Context()
+
+	consumeReq := &MagicLinkConsumeReq{}
+	if errObj := handler.ParseRequestBodyPost(r, consumeReq); errObj != nil {
+		return nil, errObj
+	}
+	if consumeReq.Token == nil || *consumeReq.Token == "" {
+		return nil, errm.NewError("empty_obj", errors.New("token is required"))
+	}
+
+	if errObj := handler.CheckAuthLockout(w, r, s.ipc.Lockout, ""); errObj != nil {
+		return nil, errObj
+	}
+
+	userID, errObj := s.verifyMagicLink(r, *consumeReq.Token)
+	if errObj != nil {
+		if errors.Is(errObj.Error, errInvalidMagicLink) {
+			return nil, handler.RejectCredentials(w, errObj)
+		}
+		return nil, errObj
+	}
+
+	// Ссылка использована, cookie больше не нужна
+	http.SetCookie(w, &http.Cookie{
+		Name:     magicLinkNonceCookie,
+		Path:     magicLinkCookiePath,
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	user, errObj := s.findUser(ctx, &typescore.User{SystemID: &userID})
+	if errObj != nil {
+		return nil, errObj
+	}
+	if user == nil {
+		return nil, handler.RejectCredentials(w, errm.NewError("invalid_link", errInvalidMagicLink))
+	}
+
+	// Письмо со ссылкой получено, значит адрес принадлежит пользователю
+	if user.EmailVerifiedAt == nil {
+		now := time.Now().UTC()
+		if _, _, errObj := s.ipc.DB.Users.UpdateUserDB(ctx, nil, &typescore.User{SystemID: &userID, EmailVerifiedAt: &now}); errObj != nil {
+			return nil, errObj
+		}
+		user.EmailVerifiedAt = &now
+	}
+
+	return s.completeLogin(ctx, r, user, consumeReq.DeviceName, []string{securecore.AMREmail})
+}
+
+// verifyMagicLink проверяет токен из ссылки вместе с nonce из cookie браузера
+// и возвращает идентификатор пользователя. Токен принимается один раз
+func (s *AuthReg) verifyMagicLink(r *http.Request, token string) (string, *errm.Error) {
+	// Без cookie из запроса ссылки (другой браузер или устройство) вход невозможен
+	nonceCookie, err := r.Cookie(magicLinkNonceCookie)
+	if err != nil || nonceCookie.Value == "" {
+		return "", errm.NewError("invalid_link", errInvalidMagicLink)
+	}
+
+	// Токен имеет вид <user_id>.<секрет>; хранится только хеш секрета вместе с nonce
+	userID, secret, ok := strings.Cut(token, ".")
+	if !ok || !securecore.IsValidUUID(userID) || secret == "" {
+		handler.RecordAuthFailure(r, s.ipc.Lockout, s.ipc.RabbitMQ, "", nil)
+		return "", errm.NewError("invalid_link", errInvalidMagicLink)
+	}
+
+	err = s.ipc.OneTimeCodes.Verify(r.Context(), magicLinkPurpose, userID, secret+"."+nonceCookie.Value)
+	switch {
+	case errors.Is(err, onetimecode.ErrCodeInvalid), errors.Is(err, onetimecode.ErrCodeNotFound),
+		errors.Is(err, onetimecode.ErrTooManyAttempts):
+		handler.RecordAuthFailure(r, s.ipc.Lockout, s.ipc.RabbitMQ, "", nil)
+		return "", errm.NewError("invalid_link", errInvalidMagicLink)
+	case err != nil:
+		return "", errm.NewError("token_verify_error", err)
+	}
+	return userID, nil
+}
+
+// acquireMagicLinkSlot ограничивает частоту писем на один адрес.
+// Адрес хранится в виде хеша; ключ занимается атомарно, поэтому из параллельных запросов проходит один
+func (s *AuthReg) acquireMagicLinkSlot(ctx context.Context, email string, interval time.Duration) *errm.Error {
+	key := magicLinkRateKeyPrefix + securecore.HashToken(email)
+	acquired, err := s.ipc.KVStore.SetIfAbsent(ctx, key, "1", interval)
+	if err != nil {
+		return errm.NewError("rate_limit_error", err)
+	}
+	if !acquired {
+		return errm.NewError("resend_too_soon", errMagicLinkTooSoon)
+	}
+	return nil
+}
+
+// sendMagicLink выдает токен входа, привязанный к nonce браузера,
+// и отправляет ссылку через сервис уведомлений
+func (s *AuthReg) sendMagicLink(ctx context.Context, user *typescore.User, nonce string) *errm.Error {
+	secret, err := onetimecode.GenerateLinkToken()
+	if err != nil {
+		return errm.NewError("token_generation_error", err)
+	}
+
+	cfg := s.ipc.Config.ExposedServiceConfig.UserService.MagicLink
+	// Частота отправки уже ограничена по адресу, поэтому интервал для кода не задается
+	params := onetimecode.Params{TTL: magicLinkParams(cfg).TTL}
+	if err := s.ipc.OneTimeCodes.Issue(ctx, magicLinkPurpose, *user.SystemID, secret+"."+nonce, params); err != nil {
+		return errm.NewError("token_store_error", err)
+	}
+
+	token := *user.SystemID + "." + secret
+	category := typescore.MagicLinkNotifyCategory
+	notify := &typescore.NotifyParams{
+		Text:      &token,
+		IsEmail:   true,
+		Emergency: true,
+		UsersIDs:  []*string{user.SystemID},
+		Category:  &category,
+	}
+	if cfg.LinkURL != "" {
+		link := cfg.LinkURL + "?" + url.Values{"token": {token}}.Encode()
+		notify.LinkURL = &link
+	}
+
+	return rabbitmqlib.PublishMessage(s.ipc.RabbitMQ,
+		variables.RabbitMQExchangeNotifications,
+		variables.RabbitMQNotificationsServiceRoute,
+		notify)
+}
+
+// magicLinkParams параметры ссылки входа с учетом значений по умолчанию.
+// Число попыток не ограничивается по той же причине, что и для сброса пароля
+func magicLinkParams(cfg configcore.MagicLinkConfig) onetimecode.Params {
+	p := onetimecode.Params{
+		TTL:            cfg.TokenTTL,
+		ResendInterval: cfg.ResendInterval,
+	}
+	if p.TTL <= 0 {
+		p.TTL = defaultMagicLinkTokenTTL
+	}
+	if p.ResendInterval <= 0 {
+		p.ResendInterval = defaultMagicLinkResendInterval
+	}
+	return p
+}
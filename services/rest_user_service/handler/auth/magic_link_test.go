@@ -0,0 +1,159 @@
+package authhandler
+
+import (
+	"authentication_service/core/configcore"
+	errm "authentication_service/core/errmodule"
+	"authentication_service/core/lib/internally/kvstore"
+	"authentication_service/core/lib/internally/onetimecode"
+	"authentication_service/rest_user_service/handler"
+	typesm "authentication_service/rest_user_service/types"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testUserID = "5b7c1e0a-3f2d-4c8e-9a61-2d4f8b0c7e13"
+
+func newTestAuthReg() *AuthReg {
+	store := kvstore.NewMemoryStore()
+	return &AuthReg{ipc: &typesm.InternalProviderControl{
+		Config:       &configcore.Config{},
+		KVStore:      store,
+		OneTimeCodes: onetimecode.NewStore(store),
+	}}
+}
+
+// consumeRequest запрос входа по ссылке; пустой nonce — без cookie
+func consumeRequest(nonce string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, magicLinkCookiePath+"/consume", nil)
+	if nonce != "" {
+		r.AddCookie(&http.Cookie{Name: magicLinkNonceCookie, Value: nonce})
+	}
+	return r
+}
+
+func TestVerifyMagicLink(t *testing.T) {
+	const secret, nonce = "secret", "browser-nonce"
+	token := testUserID + "." + secret
+
+	tests := []struct {
+		name    string
+		token   string
+		nonces  []string // cookie последовательных попыток
+		wantErr []bool
+	}{
+		{"same browser", token, []string{nonce}, []bool{false}},
+		{"single use", token, []string{nonce, nonce}, []bool{false, true}},
+		{"without cookie", token, []string{""}, []bool{true}},
+		{"cookie of another browser", token, []string{"other-nonce"}, []bool{true}},
+		{"mismatch does not consume link", token, []string{"other-nonce", nonce}, []bool{true, false}},
+		{"wrong secret", testUserID + ".other", []string{nonce}, []bool{true}},
+		{"malformed token", "not-a-token", []string{nonce}, []bool{true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestAuthReg()
+			params := onetimecode.Params{TTL: time.Minute}
+			if err := s.ipc.OneTimeCodes.Issue(context.Background(), magicLinkPurpose, testUserID, secret+"."+nonce, params); err != nil {
+				t.Fatalf("Issue() error = %v", err)
+			}
+
+			for i, cookie := range tt.nonces {
+				userID, errObj := s.verifyMagicLink(consumeRequest(cookie), tt.token)
+				if (errObj != nil) != tt.wantErr[i] {
+					t.Fatalf("verifyMagicLink #%d error = %v, wantErr %v", i+1, errObj, tt.wantErr[i])
+				}
+				if errObj != nil && !errors.Is(errObj.Error, errInvalidMagicLink) {
+					t.Fatalf("verifyMagicLink #%d error = %v, want %v", i+1, errObj.Error, errInvalidMagicLink)
+				}
+				if errObj == nil && userID != testUserID {
+					t.Fatalf("verifyMagicLink #%d user = %q, want %q", i+1, userID, testUserID)
+				}
+			}
+		})
+	}
+}
+
+func TestAcquireMagicLinkSlotSingleWinner(t *testing.T) {
+	ctx := context.Background()
+	s := newTestAuthReg()
+
+	var wg sync.WaitGroup
+	var winners atomic.Int32
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errObj := s.acquireMagicLinkSlot(ctx, "user@example.com", time.Minute)
+			switch {
+			case errObj == nil:
+				winners.Add(1)
+			case !errors.Is(errObj.Error, errMagicLinkTooSoon):
+				t.Errorf("acquireMagicLinkSlot() error = %v", errObj.Error)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := winners.Load(); got != 1 {
+		t.Fatalf("acquired %d slots, want 1", got)
+	}
+	// Ограничение действует для каждого адреса отдельно
+	if errObj := s.acquireMagicLinkSlot(ctx, "other@example.com", time.Minute); errObj != nil {
+		t.Fatalf("acquireMagicLinkSlot(other) error = %v", errObj.Error)
+	}
+}
+
+func TestMagicLinkHandlersStatus(t *testing.T) {
+	const email = "user@example.com"
+
+	tests := []struct {
+		name           string
+		handlerFunc    func(s *AuthReg) func(http.ResponseWriter, *http.Request) (interface{}, *errm.Error)
+		body           string
+		nonce          string
+		wantStatus     int
+		wantRetryAfter bool
+	}{
+		{"link requested too soon", func(s *AuthReg) func(http.ResponseWriter, *http.Request) (interface{}, *errm.Error) {
+			return s.MagicLinkHandler
+		}, `{"email":"` + email + `"}`, "", http.StatusTooManyRequests, true},
+		{"link from another browser", func(s *AuthReg) func(http.ResponseWriter, *http.Request) (interface{}, *errm.Error) {
+			return s.MagicLinkConsumeHandler
+		}, `{"token":"` + testUserID + `.secret"}`, "", http.StatusUnauthorized, false},
+		{"unknown link", func(s *AuthReg) func(http.ResponseWriter, *http.Request) (interface{}, *errm.Error) {
+			return s.MagicLinkConsumeHandler
+		}, `{"token":"` + testUserID + `.secret"}`, "browser-nonce", http.StatusUnauthorized, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestAuthReg()
+			// Письмо на адрес уже отправлено в текущем интервале
+			if errObj := s.acquireMagicLinkSlot(context.Background(), email, time.Minute); errObj != nil {
+				t.Fatalf("acquireMagicLinkSlot() error = %v", errObj.Error)
+			}
+
+			r := httptest.NewRequest(http.MethodPost, magicLinkCookiePath, strings.NewReader(tt.body))
+			if tt.nonce != "" {
+				r.AddCookie(&http.Cookie{Name: magicLinkNonceCookie, Value: tt.nonce})
+			}
+			w := httptest.NewRecorder()
+			handler.WrapHandlerF(handler.WrapHandlerParams{HandlerFunc: tt.handlerFunc(s)}).ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("Retry-After") != ""; got != tt.wantRetryAfter {
+				t.Fatalf("Retry-After = %q, want present %v", w.Header().Get("Retry-After"), tt.wantRetryAfter)
+			}
+		})
+	}
+}
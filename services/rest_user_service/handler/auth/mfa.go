@@ -0,0 +1,164 @@
+package authhandler
+
+import (
+	errm "authentication_service/core/errmodule"
+	protoobj "authentication_service/core/proto"
+	"authentication_service/core/securecore"
+	"authentication_service/core/typescore"
+	"authentication_service/rest_user_service/handler"
+	"context"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"time"
+)
+
+const (
+	mfaAttemptsKeyPrefix = "mfa:attempts:"
+
+	defaultMFAMaxAttempts = 5
+)
+
+var errInvalidMFAToken = errors.New("invalid or expired mfa token")
+
+// MFAChallengeResp ответ входа, если для аккаунта включен второй фактор
+type MFAChallengeResp struct {
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token"`  // Промежуточный токен для /api/auth/mfa/verify
+	ExpiresIn   int64  `json:"expires_in"` // Время жизни токена в секундах
+}
+
+type MFAVerifyReq struct {
+	Code         *string `json:"code"`          // Код из приложения-аутентификатора
+	RecoveryCode *string `json:"recovery_code"` // Одноразовый код восстановления (вместо code)
+	DeviceName   *string `json:"device_name"`   // Название устройства для списка сессий
+}
+
+// MFAVerifyHandler Подтверждение входа вторым фактором
+// @Summary Подтверждение входа вторым фактором
+// @Description Проверяет код TOTP или код восстановления по промежуточному токену входа и выдает Access и Refresh токены
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <mfa_token>"
+// @Param request body MFAVerifyReq true "Код TOTP или код восстановления"
+// @Success 200 {object} protoobj.IssueTokensResponse "Успех"
+// @Failure 400 {object} handler.ErrorResponse "Некорректный запрос"
+// @Failure 401 {object} handler.ErrorResponse "Неверный код или промежуточный токен"
+// @Failure 429 {object} handler.ErrorResponse "Слишком много неудачных попыток: повторить после Retry-After"
+// @Failure 500 {object} handler.ErrorResponse "Ошибка сервера"
+// @Router /api/auth/mfa/verify [post]
+func (s *AuthReg) MFAVerifyHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
+	logrus.Info("🤍 MFAVerifyHandler")
+	ctx := r.Context()
+
+	tokenString, errObj := handler.GetBearerToken(r)
+	if errObj != nil {
+		return nil, errObj
+	}
+
+	verifyReq := &MFAVerifyReq{}
+	if errObj := handler.ParseRequestBodyPost(r, verifyReq); errObj != nil {
+		return nil, errObj
+	}
+
+	// Промежуточный токен привязан к IP-адресу, с которого был проверен пароль
+	claims, ipAction, err := securecore.VerifyToken(tokenString, s.ipc.TokenFormat, s.ipc.TokenPolicy, securecore.TokenUseMFAPending, handler.GetClientIP(r))
+	if err != nil || ipAction == securecore.IPMismatchStepUp {
+		return nil, handler.RejectCredentials(w, errm.NewError("invalid_mfa_token", errInvalidMFAToken))
+	}
+
+	userID, _ := claims["guid"].(string)
+	jti, _ := claims["jti"].(string)
+	expiresAt, _ := securecore.ClaimTime(claims, "exp")
+	if userID == "" || jti == "" {
+		return nil, handler.RejectCredentials(w, errm.NewError("invalid_mfa_token", errInvalidMFAToken))
+	}
+
+	revoked, err := s.ipc.TokenDenylist.IsJTIRevoked(ctx, jti)
+	if err != nil {
+		return nil, errm.NewError("denylist_check_error", err)
+	}
+	if revoked {
+		return nil, handler.RejectCredentials(w, errm.NewError("invalid_mfa_token", errInvalidMFAToken))
+	}
+
+	user, errObj := s.findUser(ctx, &typescore.User{SystemID: &userID})
+	if errObj != nil {
+		return nil, errObj
+	}
+	if user == nil || user.TOTPEnabledAt == nil {
+		return nil, handler.RejectCredentials(w, errm.NewError("invalid_mfa_token", errInvalidMFAToken))
+	}
+
+	account := handler.SecondFactorAccount(userID)
+	if errObj := handler.CheckAuthLockout(w, r, s.ipc.Lockout, account); errObj != nil {
+		return nil, errObj
+	}
+	if errObj := handler.VerifySecondFactor(ctx, s.ipc.DB, s.ipc.SecretCipher, user, verifyReq.Code, verifyReq.RecoveryCode); errObj != nil {
+		if errors.Is(errObj.Error, handler.ErrInvalidMFACode) {
+			s.registerMFAFailure(ctx, jti, expiresAt)
+			handler.RecordAuthFailure(r, s.ipc.Lockout, s.ipc.RabbitMQ, account, user)
+			return nil, handler.RejectCredentials(w, errObj)
+		}
+		return nil, errObj
+	}
+	handler.RecordAuthSuccess(r, s.ipc.Lockout, account)
+
+	// Промежуточный токен одноразовый
+	if err := s.ipc.TokenDenylist.RevokeJTI(ctx, jti, expiresAt); err != nil {
+		return nil, errm.NewError("token_revoke_error", err)
+	}
+
+	// К методам первого фактора из промежуточного токена добавляется второй
+	amr := append(securecore.ClaimAMR(claims), securecore.AMROTP, securecore.AMRMultiFactor)
+	return s.issueTokens(ctx, r, userID, verifyReq.DeviceName, amr)
+}
+
+// completeLogin завершает вход после проверки первого фактора (amr — методы его проверки):
+// выдает токены или, если включен TOTP, промежуточный токен для ввода второго фактора
+func (s *AuthReg) completeLogin(ctx context.Context, r *http.Request, user *typescore.User, deviceName *string, amr []string) (interface{}, *errm.Error) {
+	if user.TOTPEnabledAt == nil {
+		return s.issueTokens(ctx, r, *user.SystemID, deviceName, amr)
+	}
+
+	resp, err := s.ipc.ClientAuthServiceProto.IssueMFAToken(ctx, &protoobj.IssueMFATokenRequest{
+		UserId:   *user.SystemID,
+		ClientIp: handler.GetClientIP(r),
+		Amr:      amr,
+	})
+	if err != nil {
+		return nil, errm.NewError("token_generation_error", err)
+	}
+
+	return &MFAChallengeResp{
+		MFARequired: true,
+		MFAToken:    resp.GetMfaToken(),
+		ExpiresIn:   resp.GetExpiresIn(),
+	}, nil
+}
+
+// registerMFAFailure учитывает неверный код. После исчерпания попыток
+// промежуточный токен отзывается, и вход нужно начинать заново
+func (s *AuthReg) registerMFAFailure(ctx context.Context, jti string, expiresAt time.Time) {
+	maxAttempts := s.ipc.Config.ExposedServiceConfig.UserService.MFA.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMFAMaxAttempts
+	}
+
+	key := mfaAttemptsKeyPrefix + jti
+	attempts, err := s.ipc.KVStore.Incr(ctx, key, time.Until(expiresAt))
+	if err != nil {
+		logrus.Errorf("failed to count mfa attempts: %v", err)
+		return
+	}
+
+	if attempts >= int64(maxAttempts) {
+		if err := s.ipc.TokenDenylist.RevokeJTI(ctx, jti, expiresAt); err != nil {
+			logrus.Errorf("failed to revoke mfa token: %v", err)
+		}
+		if err := s.ipc.KVStore.Delete(ctx, key); err != nil {
+			logrus.Errorf("failed to delete mfa attempts: %v", err)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package authhandler
+
+import (
+	"authentication_service/core/configcore"
+	"authentication_service/core/lib/internally/denylist"
+	"authentication_service/core/lib/internally/kvstore"
+	"authentication_service/core/securecore"
+	"authentication_service/rest_user_service/handler"
+	typesm "authentication_service/rest_user_service/types"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestMFAVerifyHandlerRejectsToken(t *testing.T) {
+	keyring, err := securecore.NewKeyring(configcore.AuthJWTConfig{UserSecret: "secret"})
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+	const clientIP = "192.0.2.10"
+	newToken := func(guid, tokenUse string, extra jwt.MapClaims) string {
+		token, err := securecore.GenerateToken(guid, clientIP, tokenUse, keyring, securecore.TokenPolicy{}, time.Minute, extra)
+		if err != nil {
+			t.Fatalf("GenerateToken() error = %v", err)
+		}
+		return token
+	}
+	const revokedJTI = "6a1d2c3b-4e5f-4a6b-8c7d-9e0f1a2b3c4d"
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"malformed token", "not-a-token"},
+		{"access token instead of mfa token", newToken(testUserID, securecore.TokenUseAccess, nil)},
+		{"mfa token without user", newToken("", securecore.TokenUseMFAPending, nil)},
+		{"used mfa token", newToken(testUserID, securecore.TokenUseMFAPending, jwt.MapClaims{"jti": revokedJTI})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokenDenylist := denylist.NewTokenDenylist(kvstore.NewMemoryStore())
+			if err := tokenDenylist.RevokeJTI(context.Background(), revokedJTI, time.Now().Add(time.Minute)); err != nil {
+				t.Fatalf("RevokeJTI() error = %v", err)
+			}
+			s := &AuthReg{ipc: &typesm.InternalProviderControl{TokenFormat: keyring, TokenDenylist: tokenDenylist}}
+
+			r := httptest.NewRequest(http.MethodPost, "/api/auth/mfa/verify", strings.NewReader(`{"code":"123456"}`))
+			r.RemoteAddr = clientIP + ":1234"
+			r.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+			handler.WrapHandlerF(handler.WrapHandlerParams{HandlerFunc: s.MFAVerifyHandler}).ServeHTTP(w, r)
+
+			if w.Code != http.StatusUnauthorized {
+				t.Fatalf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+			}
+		})
+	}
+}
@@ -0,0 +1,295 @@
+package authhandler
+
+import (
+	errm "authentication_service/core/errmodule"
+	"authentication_service/core/securecore"
+	"authentication_service/core/typescore"
+	dbutils "authentication_service/core/utilscore/db"
+	"authentication_service/rest_user_service/handler"
+	"authentication_service/rest_user_service/upstream"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"time"
+)
+
+const (
+	oidcStateKeyPrefix  = "oidc:state:"
+	oidcStateCookie     = "oidc_state"
+	oidcStateCookiePath = "/api/auth/oidc"
+
+	defaultOIDCStateTTL = 10 * time.Minute
+)
+
+var (
+	errInvalidOIDCState = errors.New("invalid or expired oidc state")
+	errOIDCLoginFailed  = errors.New("identity provider login failed")
+	errOIDCEmailTaken   = errors.New("an account with this email already exists, sign in with it first")
+)
+
+type OIDCBeginResp struct {
+	AuthorizationURL string `json:"authorization_url"` // Страница авторизации провайдера
+}
+
+type OIDCCallbackReq struct {
+	Code       *string `json:"code"`        // Код авторизации из redirect_url
+	State      *string `json:"state"`       // state из redirect_url
+	DeviceName *string `json:"device_name"` // Название устройства для списка сессий
+}
+
+// OIDCBeginHandler Начало входа через провайдера OpenID Connect
+// @Summary Начало входа через провайдера OpenID Connect
+// @Description Возвращает адрес страницы авторизации провайдера (authorization code flow с PKCE).
+// @Description После авторизации провайдер перенаправляет пользователя на redirect_url с параметрами code и state.
+// @Description Устанавливает cookie со state: callback принимается только в том же браузере
+// @Tags auth
+// @Produce json
+// @Param provider path string true "Имя провайдера из конфигурации"
+// @Success 200 {object} OIDCBeginResp "Успех"
+// @Failure 500 {object} handler.ErrorResponse "Неизвестный или недоступный провайдер"
+// @Router /api/auth/oidc/{provider}/begin [post]
+func (s *AuthReg) OIDCBeginHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
+	logrus.Info("🤍 OIDCBeginHandler")
+	ctx := r.Context()
+
+	provider, err := s.ipc.OIDC.Provider(chi.URLParam(r, "provider"))
+	if err != nil {
+		return nil, errm.NewError("unknown_provider", err)
+	}
+
+	authReq, err := provider.NewAuthRequest()
+	if err != nil {
+		return nil, errm.NewError("state_generation_error", err)
+	}
+	authURL, err := provider.AuthCodeURL(ctx, authReq)
+	if err != nil {
+		logrus.Errorf("oidc begin: %v", err)
+		return nil, errm.NewError("provider_unavailable", err)
+	}
+
+	if err := s.saveOIDCAuthRequest(ctx, authReq); err != nil {
+		return nil, errm.NewError("state_store_error", err)
+	}
+
+	// Cookie привязывает state к браузеру, в котором начат вход (защита от подмены входа, login CSRF)
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    authReq.State,
+		Path:     oidcStateCookiePath,
+		MaxAge:   int(s.oidcStateTTL().Seconds()),
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	return &OIDCBeginResp{AuthorizationURL: authURL}, nil
+}
+
+// OIDCCallbackHandler Завершение входа через провайдера OpenID Connect
+// @Summary Завершение входа через провайдера OpenID Connect
+// @Description Обменивает код авторизации на ID токен провайдера, находит или создает пользователя по привязанной учетной записи и выдает Access и Refresh токены.
+// @Description Принимается только в браузере, где вызван /begin: state сверяется с cookie.
+// @Description Если включен TOTP, вместо токенов возвращается промежуточный mfa_token для /api/auth/mfa/verify
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param provider path string true "Имя провайдера из конфигурации"
+// @Param request body OIDCCallbackReq true "Параметры из redirect_url"
+// @Success 200 {object} protoobj.IssueTokensResponse "Успех"
+// @Failure 400 {object} handler.ErrorResponse "Некорректный запрос"
+// @Failure 500 {object} handler.ErrorResponse "Неверный state или ошибка провайдера"
+// @Router /api/auth/oidc/{provider}/callback [post]
+func (s *AuthReg) OIDCCallbackHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
+	logrus.Info("🤍 OIDCCallbackHandler")
+	ctx := r.Context()
+
+	provider, err := s.ipc.OIDC.Provider(chi.URLParam(r, "provider"))
+	if err != nil {
+		return nil, errm.NewError("unknown_provider", err)
+	}
+
+	callbackReq := &OIDCCallbackReq{}
+	if errObj := handler.ParseRequestBodyPost(r, callbackReq); errObj != nil {
+		return nil, errObj
+	}
+	if callbackReq.Code == nil || *callbackReq.Code == "" || callbackReq.State == nil || *callbackReq.State == "" {
+		return nil, errm.NewError("empty_obj", errors.New("code and state are required"))
+	}
+
+	// state должен совпадать с cookie браузера, в котором начат вход
+	stateCookie, err := r.Cookie(oidcStateCookie)
+	if err != nil || subtle.ConstantTimeCompare([]byte(stateCookie.Value), []byte(*callbackReq.State)) != 1 {
+		return nil, errm.NewError("invalid_state", errInvalidOIDCState)
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Path:     oidcStateCookiePath,
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	// state одноразовый и выдан для этого же провайдера
+	authReq, err := s.takeOIDCAuthRequest(ctx, *callbackReq.State)
+	if err != nil {
+		return nil, errm.NewError("state_load_error", err)
+	}
+	if authReq == nil || authReq.Provider != provider.Name() {
+		return nil, errm.NewError("invalid_state", errInvalidOIDCState)
+	}
+
+	identity, err := provider.Exchange(ctx, authReq, *callbackReq.Code)
+	if err != nil {
+		logrus.Warnf("oidc callback: provider=%s: %v", provider.Name(), err)
+		return nil, errm.NewError("oidc_login_failed", errOIDCLoginFailed)
+	}
+
+	user, errObj := s.resolveIdentityUser(ctx, provider, identity)
+	if errObj != nil {
+		return nil, errObj
+	}
+
+	return s.completeLogin(ctx, r, user, callbackReq.DeviceName, []string{securecore.AMRFederated})
+}
+
+// resolveIdentityUser возвращает пользователя, к которому привязана учетная запись провайдера.
+// При первом входе учетная запись привязывается к аккаунту с тем же подтвержденным email
+// (только для провайдеров с trust_email) либо создается новый пользователь.
+func (s *AuthReg) resolveIdentityUser(ctx context.Context, provider *upstream.Provider, identity *upstream.Identity) (*typescore.User, *errm.Error) {
+	identities, _, errObj := s.ipc.DB.Identities.GetUserIdentitiesListDB(ctx, typescore.ListDbOptions{Filtering: &typescore.UserIdentity{
+		Provider: &identity.Provider,
+		Subject:  &identity.Subject,
+	}})
+	if errObj != nil {
+		return nil, errObj
+	}
+
+	now := time.Now().UTC()
+	// Email сохраняется, только если провайдер подтвердил владение адресом
+	verifiedEmail := identity.VerifiedEmail()
+	email := normalizeEmail(&verifiedEmail)
+
+	if len(identities) > 0 {
+		linked := identities[0]
+		if _, _, errObj := s.ipc.DB.Identities.UpdateUserIdentityDB(ctx, nil, &typescore.UserIdentity{
+			ID:          linked.ID,
+			Email:       email,
+			LastLoginAt: &now,
+		}); errObj != nil {
+			return nil, errObj
+		}
+
+		user, errObj := s.findUser(ctx, &typescore.User{SystemID: linked.UserID})
+		if errObj != nil {
+			return nil, errObj
+		}
+		if user == nil {
+			return nil, errm.NewError("oidc_login_failed", errOIDCLoginFailed)
+		}
+		return user, nil
+	}
+
+	identityID, err := securecore.GenerateUUID()
+	if err != nil {
+		return nil, errm.NewError("identity_create_error", err)
+	}
+	link := &typescore.UserIdentity{
+		ID:          &identityID,
+		Provider:    &identity.Provider,
+		Subject:     &identity.Subject,
+		Email:       email,
+		CreatedAt:   &now,
+		LastLoginAt: &now,
+	}
+
+	if email != nil {
+		existing, errObj := s.findUser(ctx, &typescore.User{Email: email})
+		if errObj != nil {
+			return nil, errObj
+		}
+		if existing != nil {
+			if !provider.CanLinkByEmail(identity) {
+				return nil, errm.NewError("email_taken", errOIDCEmailTaken)
+			}
+			link.UserID = existing.SystemID
+			if _, _, errObj := s.ipc.DB.Identities.CreateUserIdentityDB(ctx, nil, link); errObj != nil {
+				return nil, errObj
+			}
+			logrus.Infof("oidc: identity linked by email: user_id=%s provider=%s", *existing.SystemID, identity.Provider)
+			return existing, nil
+		}
+	}
+
+	userID, err := securecore.GenerateUUID()
+	if err != nil {
+		return nil, errm.NewError("user_create_error", err)
+	}
+	user := &typescore.User{
+		SystemID:  &userID,
+		Email:     email,
+		FirstName: profileName(identity.GivenName),
+		LastName:  profileName(identity.FamilyName),
+	}
+	if email != nil {
+		user.EmailVerifiedAt = &now
+	}
+	link.UserID = &userID
+
+	// Пользователь и привязка создаются в одной транзакции
+	errObj = dbutils.ExecuteTx(ctx, s.ipc.DB.Pool, nil, func(tx pgx.Tx) error {
+		if _, _, errW := s.ipc.DB.Users.CreateUserDB(ctx, tx, user); errW != nil {
+			return errW.Error
+		}
+		if _, _, errW := s.ipc.DB.Identities.CreateUserIdentityDB(ctx, tx, link); errW != nil {
+			return errW.Error
+		}
+		return nil
+	})
+	if errObj != nil {
+		return nil, errObj
+	}
+	logrus.Infof("oidc: user created: user_id=%s provider=%s", userID, identity.Provider)
+
+	return user, nil
+}
+
+// saveOIDCAuthRequest сохраняет параметры входа до возврата пользователя от провайдера
+func (s *AuthReg) saveOIDCAuthRequest(ctx context.Context, authReq *upstream.AuthRequest) error {
+	data, err := json.Marshal(authReq)
+	if err != nil {
+		return err
+	}
+
+	return s.ipc.KVStore.Set(ctx, oidcStateKeyPrefix+authReq.State, string(data), s.oidcStateTTL())
+}
+
+// takeOIDCAuthRequest атомарно забирает параметры входа: state используется один раз.
+// nil, если state неизвестен или истек
+func (s *AuthReg) takeOIDCAuthRequest(ctx context.Context, state string) (*upstream.AuthRequest, error) {
+	value, ok, err := s.ipc.KVStore.Take(ctx, oidcStateKeyPrefix+state)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	authReq := &upstream.AuthRequest{}
+	if err := json.Unmarshal([]byte(value), authReq); err != nil {
+		return nil, err
+	}
+	authReq.State = state
+	return authReq, nil
+}
+
+// oidcStateTTL время ожидания возврата пользователя от провайдера
+func (s *AuthReg) oidcStateTTL() time.Duration {
+	ttl := s.ipc.Config.ExposedServiceConfig.UserService.OIDC.StateTTL
+	if ttl <= 0 {
+		ttl = defaultOIDCStateTTL
+	}
+	return ttl
+}
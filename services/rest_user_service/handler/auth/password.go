@@ -0,0 +1,288 @@
+package authhandler
+
+import (
+	errm "authentication_service/core/errmodule"
+	protoobj "authentication_service/core/proto"
+	"authentication_service/core/securecore"
+	"authentication_service/core/typescore"
+	"authentication_service/core/utilscore"
+	"authentication_service/rest_user_service/handler"
+	"context"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+var errInvalidCredentials = errors.New("invalid login or password")
+
+// длина полей first_name и last_name в таблице пользователей
+const maxNameLength = 50
+
+type RegisterReq struct {
+	Email      *string `json:"email"`
+	Nickname   *string `json:"nickname"`
+	Password   *string `json:"password"`
+	FirstName  *string `json:"first_name"`
+	LastName   *string `json:"last_name"`
+	DeviceName *string `json:"device_name"` // Название устройства для списка сессий
+}
+
+type LoginReq struct {
+	Login      *string `json:"login"` // Email или никнейм
+	Password   *string `json:"password"`
+	DeviceName *string `json:"device_name"` // Название устройства для списка сессий
+}
+
+// RegisterHandler Регистрация по паролю
+// @Summary Регистрация по паролю
+// @Description Создает пользователя с email и/или никнеймом и паролем и выдает Access и Refresh токены. На email отправляется код подтверждения
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body RegisterReq true "Данные пользователя"
+// @Success 200 {object} protoobj.IssueTokensResponse "Успех"
+// @Failure 400 {object} handler.ErrorResponse "Некорректный запрос"
+// @Failure 500 {object} handler.ErrorResponse "Ошибка сервера"
+// @Router /api/auth/register [post]
+func (s *AuthReg) RegisterHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
+	logrus.Info("🤍 RegisterHandler")
+	ctx := r.Context()
+
+	registerReq := &RegisterReq{}
+	if errObj := handler.ParseRequestBodyPost(r, registerReq); errObj != nil {
+		return nil, errObj
+	}
+
+	email := normalizeEmail(registerReq.Email)
+	nickname := normalizeNickname(registerReq.Nickname)
+	if email == nil && nickname == nil {
+		return nil, errm.NewError("empty_login", errors.New("email or nickname is required"))
+	}
+	if email != nil {
+		if errObj := utilscore.ValidateEmailFormat(email); errObj != nil {
+			return nil, errObj
+		}
+	}
+	// Символ @ зарезервирован за email: по нему определяется способ входа
+	if nickname != nil && strings.Contains(*nickname, "@") {
+		return nil, errm.NewError("invalid_nickname", errors.New("nickname must not contain @"))
+	}
+	if registerReq.Password == nil {
+		return nil, errm.NewError("empty_password", errors.New("password is required"))
+	}
+	if errObj := validatePassword(*registerReq.Password); errObj != nil {
+		return nil, errObj
+	}
+
+	// Email и никнейм уникальны
+	if email != nil {
+		if user, errObj := s.findUser(ctx, &typescore.User{Email: email}); errObj != nil {
+			return nil, errObj
+		} else if user != nil {
+			return nil, errm.NewError("email_taken", errors.New("email is already registered"))
+		}
+	}
+	if nickname != nil {
+		if user, errObj := s.findUser(ctx, &typescore.User{Nickname: nickname}); errObj != nil {
+			return nil, errObj
+		} else if user != nil {
+			return nil, errm.NewError("nickname_taken", errors.New("nickname is already taken"))
+		}
+	}
+
+	passwordHash, err := securecore.HashPassword(*registerReq.Password)
+	if err != nil {
+		return nil, errm.NewError("password_hash_error", err)
+	}
+
+	userID, err := securecore.GenerateUUID()
+	if err != nil {
+		return nil, errm.NewError("user_create_error", err)
+	}
+
+	_, _, errObj := s.ipc.DB.Users.CreateUserDB(ctx, nil, &typescore.User{
+		SystemID:     &userID,
+		Email:        email,
+		Nickname:     nickname,
+		PasswordHash: &passwordHash,
+		FirstName:    registerReq.FirstName,
+		LastName:     registerReq.LastName,
+	})
+	if errObj != nil {
+		return nil, errObj
+	}
+
+	// Код подтверждения email; до подтверждения токены получают ограниченные области доступа
+	if email != nil {
+		if errObj := s.sendEmailVerification(ctx, &typescore.User{SystemID: &userID, Email: email}); errObj != nil {
+			logrus.Errorf("failed to send email verification: user_id=%s: %v", userID, errObj.Error)
+		}
+	}
+
+	return s.issueTokens(ctx, r, userID, registerReq.DeviceName, []string{securecore.AMRPassword})
+}
+
+// LoginHandler Вход по паролю
+// @Summary Вход по паролю
+// @Description Проверяет email или никнейм и пароль и выдает Access и Refresh токены.
+// @Description Если включен TOTP, вместо токенов возвращается промежуточный mfa_token для /api/auth/mfa/verify
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body LoginReq true "Логин и пароль"
+// @Success 200 {object} protoobj.IssueTokensResponse "Успех"
+// @Failure 400 {object} handler.ErrorResponse "Некорректный запрос"
+// @Failure 401 {object} handler.ErrorResponse "Неверный логин или пароль"
+// @Failure 429 {object} handler.ErrorResponse "Слишком много неудачных попыток: повторить после Retry-After"
+// @Failure 500 {object} handler.ErrorResponse "Ошибка сервера"
+// @Router /api/auth/login [post]
+func (s *AuthReg) LoginHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
+	logrus.Info("🤍 LoginHandler")
+	ctx := r.Context()
+
+	loginReq := &LoginReq{}
+	if errObj := handler.ParseRequestBodyPost(r, loginReq); errObj != nil {
+		return nil, errObj
+	}
+	if loginReq.Login == nil || *loginReq.Login == "" || loginReq.Password == nil || *loginReq.Password == "" {
+		return nil, errm.NewError("empty_obj", errors.New("login and password are required"))
+	}
+	if len(*loginReq.Password) > securecore.PasswordMaxLength*utf8.UTFMax {
+		return nil, handler.RejectCredentials(w, errm.NewError("invalid_credentials", errInvalidCredentials))
+	}
+
+	user, errObj := s.findUserByLogin(ctx, *loginReq.Login)
+	if errObj != nil {
+		return nil, errObj
+	}
+	account := handler.LoginAccount(user, *loginReq.Login)
+	if errObj := handler.CheckAuthLockout(w, r, s.ipc.Lockout, account); errObj != nil {
+		return nil, errObj
+	}
+
+	// Для неизвестного пользователя пароль тоже хешируется, чтобы время ответа не раскрывало существование логина
+	if user == nil || user.PasswordHash == nil {
+		securecore.DummyVerifyPassword(*loginReq.Password)
+		handler.RecordAuthFailure(r, s.ipc.Lockout, s.ipc.RabbitMQ, account, user)
+		return nil, handler.RejectCredentials(w, errm.NewError("invalid_credentials", errInvalidCredentials))
+	}
+
+	ok, err := securecore.VerifyPassword(*loginReq.Password, *user.PasswordHash)
+	if err != nil {
+		logrus.Errorf("failed to verify password hash: user_id=%s: %v", *user.SystemID, err)
+		return nil, handler.RejectCredentials(w, errm.NewError("invalid_credentials", errInvalidCredentials))
+	}
+	if !ok {
+		handler.RecordAuthFailure(r, s.ipc.Lockout, s.ipc.RabbitMQ, account, user)
+		return nil, handler.RejectCredentials(w, errm.NewError("invalid_credentials", errInvalidCredentials))
+	}
+	handler.RecordAuthSuccess(r, s.ipc.Lockout, account)
+
+	// Хеш, созданный со старыми параметрами, пересчитывается при успешном входе
+	if securecore.PasswordNeedsRehash(*user.PasswordHash) {
+		if passwordHash, err := securecore.HashPassword(*loginReq.Password); err == nil {
+			if _, _, errObj := s.ipc.DB.Users.UpdateUserDB(ctx, nil, &typescore.User{
+				SystemID:     user.SystemID,
+				PasswordHash: &passwordHash,
+			}); errObj != nil {
+				logrus.Errorf("failed to rehash password: user_id=%s: %v", *user.SystemID, errObj.Error)
+			}
+		}
+	}
+
+	return s.completeLogin(ctx, r, user, loginReq.DeviceName, []string{securecore.AMRPassword})
+}
+
+// issueTokens выдает пару токенов и открывает сессию для пользователя, прошедшего проверку методами amr
+func (s *AuthReg) issueTokens(ctx context.Context, r *http.Request, userID string, deviceName *string, amr []string) (*protoobj.IssueTokensResponse, *errm.Error) {
+	issueReq := &protoobj.IssueTokensRequest{
+		UserId:    userID,
+		ClientIp:  handler.GetClientIP(r),
+		UserAgent: r.UserAgent(),
+		Amr:       amr,
+	}
+	if deviceName != nil {
+		issueReq.DeviceName = *deviceName
+	}
+
+	resp, err := s.ipc.ClientAuthServiceProto.IssueTokens(ctx, issueReq)
+	if err != nil {
+		return nil, errm.NewError("token_generation_error", err)
+	}
+	return resp, nil
+}
+
+// findUserByLogin ищет пользователя по email, а затем по никнейму
+func (s *AuthReg) findUserByLogin(ctx context.Context, login string) (*typescore.User, *errm.Error) {
+	if email := normalizeEmail(&login); email != nil && strings.Contains(*email, "@") {
+		return s.findUser(ctx, &typescore.User{Email: email})
+	}
+	nickname := normalizeNickname(&login)
+	if nickname == nil {
+		return nil, nil
+	}
+	return s.findUser(ctx, &typescore.User{Nickname: nickname})
+}
+
+// findUser возвращает пользователя по фильтру или nil, если пользователь не найден
+func (s *AuthReg) findUser(ctx context.Context, filter *typescore.User) (*typescore.User, *errm.Error) {
+	users, _, errObj := s.ipc.DB.Users.GetUsersListDB(ctx, typescore.ListDbOptions{Filtering: filter})
+	if errObj != nil {
+		return nil, errObj
+	}
+	if len(users) == 0 {
+		return nil, nil
+	}
+	return users[0], nil
+}
+
+// validatePassword проверяет требования к паролю
+func validatePassword(password string) *errm.Error {
+	length := utf8.RuneCountInString(password)
+	if length < securecore.PasswordMinLength {
+		return errm.NewError("password_too_short", errors.New("password is too short"))
+	}
+	if length > securecore.PasswordMaxLength {
+		return errm.NewError("password_too_long", errors.New("password is too long"))
+	}
+	return nil
+}
+
+// normalizeEmail приводит email к нижнему регистру; пустое значение возвращается как nil
+func normalizeEmail(email *string) *string {
+	if email == nil {
+		return nil
+	}
+	value := strings.ToLower(strings.TrimSpace(*email))
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// normalizeNickname удаляет пробелы по краям никнейма; пустое значение возвращается как nil
+func normalizeNickname(nickname *string) *string {
+	if nickname == nil {
+		return nil
+	}
+	value := strings.TrimSpace(*nickname)
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// profileName приводит имя из внешнего источника (Telegram, провайдер OIDC)
+// к длине поля профиля; пустое имя не сохраняется
+func profileName(value string) *string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	if runes := []rune(value); len(runes) > maxNameLength {
+		value = string(runes[:maxNameLength])
+	}
+	return &value
+}
@@ -0,0 +1,219 @@
+package authhandler
+
+import (
+	"authentication_service/core/configcore"
+	errm "authentication_service/core/errmodule"
+	rabbitmqlib "authentication_service/core/lib/external/rabbitmq-lib"
+	"authentication_service/core/lib/internally/onetimecode"
+	protoobj "authentication_service/core/proto"
+	"authentication_service/core/securecore"
+	"authentication_service/core/typescore"
+	"authentication_service/core/variables"
+	"authentication_service/rest_user_service/handler"
+	"context"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	passwordResetPurpose = "password_reset"
+
+	defaultPasswordResetTokenTTL       = 30 * time.Minute
+	defaultPasswordResetResendInterval = time.Minute
+)
+
+var errInvalidResetToken = errors.New("invalid or expired reset token")
+
+type ForgotPasswordReq struct {
+	Email *string `json:"email"`
+}
+
+type ForgotPasswordResp struct {
+	Sent bool `json:"sent"`
+}
+
+type ResetPasswordReq struct {
+	Token       *string `json:"token"`
+	NewPassword *string `json:"new_password"`
+}
+
+type ResetPasswordResp struct {
+	Success bool `json:"success"`
+}
+
+// ForgotPasswordHandler Запрос сброса пароля
+// @Summary Запрос сброса пароля
+// @Description Отправляет на email одноразовую ссылку сброса пароля. Ответ не зависит от существования аккаунта
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body ForgotPasswordReq true "Email аккаунта"
+// @Success 200 {object} ForgotPasswordResp "Успех"
+// @Failure 400 {object} handler.ErrorResponse "Некорректный запрос"
+// @Router /api/auth/password/forgot [post]
+func (s *AuthReg) ForgotPasswordHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
+	logrus.Info("🤍 ForgotPasswordHandler")
+	ctx := r.Context()
+
+	forgotReq := &ForgotPasswordReq{}
+	if errObj := handler.ParseRequestBodyPost(r, forgotReq); errObj != nil {
+		return nil, errObj
+	}
+	email := normalizeEmail(forgotReq.Email)
+	if email == nil {
+		return nil, errm.NewError("empty_obj", errors.New("email is required"))
+	}
+
+	// Ошибки только логируются: ответ не должен раскрывать, существует ли аккаунт
+	user, errObj := s.findUser(ctx, &typescore.User{Email: email})
+	switch {
+	case errObj != nil:
+		logrus.Errorf("forgot password: failed to get user: %v", errObj.Error)
+	case user != nil:
+		if errObj := s.sendPasswordReset(ctx, user); errObj != nil {
+			logrus.Warnf("forgot password: reset not sent: user_id=%s: %v", *user.SystemID, errObj.Error)
+		}
+	}
+
+	return &ForgotPasswordResp{Sent: true}, nil
+}
+
+// ResetPasswordHandler Сброс пароля
+// @Summary Сброс пароля
+// @Description Задает новый пароль по токену из письма и завершает все сессии пользователя
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body ResetPasswordReq true "Токен сброса и новый пароль"
+// @Success 200 {object} ResetPasswordResp "Успех"
+// @Failure 400 {object} handler.ErrorResponse "Неверный или просроченный токен"
+// @Failure 429 {object} handler.ErrorResponse "Слишком много неудачных попыток: повторить после Retry-After"
+// @Failure 500 {object} handler.ErrorResponse "Ошибка сервера"
+// @Router /api/auth/password/reset [post]
+func (s *AuthReg) ResetPasswordHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
+	logrus.Info("🤍 ResetPasswordHandler")
+	ctx := r.Context()
+
+	resetReq := &ResetPasswordReq{}
+	if errObj := handler.ParseRequestBodyPost(r, resetReq); errObj != nil {
+		return nil, errObj
+	}
+	if resetReq.Token == nil || *resetReq.Token == "" || resetReq.NewPassword == nil {
+		return nil, errm.NewError("empty_obj", errors.New("token and new_password are required"))
+	}
+	if errObj := validatePassword(*resetReq.NewPassword); errObj != nil {
+		return nil, errObj
+	}
+
+	// Перебор токенов ограничивается по IP-адресу: блокировка аккаунта позволила бы помешать сбросу пароля
+	if errObj := handler.CheckAuthLockout(w, r, s.ipc.Lockout, ""); errObj != nil {
+		return nil, errObj
+	}
+
+	// Токен имеет вид <user_id>.<секрет>; хранится только хеш секрета
+	userID, secret, ok := strings.Cut(*resetReq.Token, ".")
+	if !ok || !securecore.IsValidUUID(userID) || secret == "" {
+		handler.RecordAuthFailure(r, s.ipc.Lockout, s.ipc.RabbitMQ, "", nil)
+		w.WriteHeader(http.StatusBadRequest)
+		return nil, errm.NewError("invalid_token", errInvalidResetToken)
+	}
+
+	err := s.ipc.OneTimeCodes.Verify(ctx, passwordResetPurpose, userID, secret)
+	switch {
+	case errors.Is(err, onetimecode.ErrCodeInvalid), errors.Is(err, onetimecode.ErrCodeNotFound),
+		errors.Is(err, onetimecode.ErrTooManyAttempts):
+		handler.RecordAuthFailure(r, s.ipc.Lockout, s.ipc.RabbitMQ, "", nil)
+		w.WriteHeader(http.StatusBadRequest)
+		return nil, errm.NewError("invalid_token", errInvalidResetToken)
+	case err != nil:
+		return nil, errm.NewError("token_verify_error", err)
+	}
+
+	user, errObj := s.findUser(ctx, &typescore.User{SystemID: &userID})
+	if errObj != nil {
+		return nil, errObj
+	}
+	if user == nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return nil, errm.NewError("invalid_token", errInvalidResetToken)
+	}
+
+	passwordHash, err := securecore.HashPassword(*resetReq.NewPassword)
+	if err != nil {
+		return nil, errm.NewError("password_hash_error", err)
+	}
+
+	update := &typescore.User{SystemID: &userID, PasswordHash: &passwordHash}
+	// Письмо со ссылкой получено, значит адрес принадлежит пользователю
+	if user.EmailVerifiedAt == nil {
+		now := time.Now().UTC()
+		update.EmailVerifiedAt = &now
+	}
+	if _, _, errObj := s.ipc.DB.Users.UpdateUserDB(ctx, nil, update); errObj != nil {
+		return nil, errObj
+	}
+
+	// Все существующие сессии завершаются: доступ мог быть у постороннего
+	if _, err := s.ipc.ClientAuthServiceProto.RevokeUserTokens(ctx, &protoobj.RevokeUserTokensRequest{UserId: userID}); err != nil {
+		return nil, errm.NewError("token_revoke_error", err)
+	}
+
+	return &ResetPasswordResp{Success: true}, nil
+}
+
+// sendPasswordReset выдает токен сброса пароля и отправляет ссылку через сервис уведомлений
+func (s *AuthReg) sendPasswordReset(ctx context.Context, user *typescore.User) *errm.Error {
+	secret, err := onetimecode.GenerateLinkToken()
+	if err != nil {
+		return errm.NewError("token_generation_error", err)
+	}
+
+	cfg := s.ipc.Config.ExposedServiceConfig.UserService.PasswordReset
+	err = s.ipc.OneTimeCodes.Issue(ctx, passwordResetPurpose, *user.SystemID, secret, passwordResetParams(cfg))
+	if errors.Is(err, onetimecode.ErrResendTooSoon) {
+		return errm.NewError("resend_too_soon", err)
+	}
+	if err != nil {
+		return errm.NewError("token_store_error", err)
+	}
+
+	token := *user.SystemID + "." + secret
+	category := typescore.PasswordResetNotifyCategory
+	notify := &typescore.NotifyParams{
+		Text:      &token,
+		IsEmail:   true,
+		Emergency: true,
+		UsersIDs:  []*string{user.SystemID},
+		Category:  &category,
+	}
+	if cfg.LinkURL != "" {
+		link := cfg.LinkURL + "?" + url.Values{"token": {token}}.Encode()
+		notify.LinkURL = &link
+	}
+
+	return rabbitmqlib.PublishMessage(s.ipc.RabbitMQ,
+		variables.RabbitMQExchangeNotifications,
+		variables.RabbitMQNotificationsServiceRoute,
+		notify)
+}
+
+// passwordResetParams параметры токена сброса пароля с учетом значений по умолчанию.
+// Секрет токена имеет 256 бит энтропии, поэтому число попыток не ограничивается:
+// иначе посторонний мог бы аннулировать чужой токен перебором.
+func passwordResetParams(cfg configcore.PasswordResetConfig) onetimecode.Params {
+	p := onetimecode.Params{
+		TTL:            cfg.TokenTTL,
+		ResendInterval: cfg.ResendInterval,
+	}
+	if p.TTL <= 0 {
+		p.TTL = defaultPasswordResetTokenTTL
+	}
+	if p.ResendInterval <= 0 {
+		p.ResendInterval = defaultPasswordResetResendInterval
+	}
+	return p
+}
@@ -0,0 +1,46 @@
+package authhandler
+
+import (
+	"authentication_service/core/lib/internally/onetimecode"
+	"authentication_service/rest_user_service/handler"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResetPasswordHandlerInvalidToken(t *testing.T) {
+	const secret = "reset-secret"
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"malformed token", "not-a-token"},
+		{"wrong secret", testUserID + ".other-secret"},
+		{"token of another user", "7f3e2a10-5c4b-4d6e-8f90-1a2b3c4d5e01." + secret},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestAuthReg()
+			if err := s.ipc.OneTimeCodes.Issue(context.Background(), passwordResetPurpose, testUserID, secret, onetimecode.Params{TTL: time.Minute}); err != nil {
+				t.Fatalf("Issue() error = %v", err)
+			}
+
+			body := `{"token":"` + tt.token + `","new_password":"correct horse battery staple"}`
+			r := httptest.NewRequest(http.MethodPost, "/api/auth/password/reset", strings.NewReader(body))
+			w := httptest.NewRecorder()
+			handler.WrapHandlerF(handler.WrapHandlerParams{HandlerFunc: s.ResetPasswordHandler}).ServeHTTP(w, r)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+			if !strings.Contains(w.Body.String(), "invalid_token") {
+				t.Fatalf("response = %s, want invalid_token", w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package authhandler
+
+import (
+	errm "authentication_service/core/errmodule"
+	protoobj "authentication_service/core/proto"
+	"authentication_service/core/securecore"
+	"authentication_service/core/typescore"
+	"authentication_service/rest_user_service/handler"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"unicode/utf8"
+)
+
+var (
+	errReauthSessionRequired = errors.New("reauthentication requires a session access token")
+	errReauthUnavailable     = errors.New("no password or second factor is configured: sign in again")
+)
+
+type ReauthenticateReq struct {
+	Password     *string `json:"password"`      // Обязателен, если у аккаунта есть пароль
+	Code         *string `json:"code"`          // Код TOTP; обязателен (или recovery_code), если включен второй фактор
+	RecoveryCode *string `json:"recovery_code"` // Одноразовый код восстановления (вместо code)
+}
+
+// ReauthenticateResp access токен после повторной аутентификации
+type ReauthenticateResp struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"` // Bearer
+	ExpiresIn   int64  `json:"expires_in"` // Время жизни токена в секундах
+	AuthTime    int64  `json:"auth_time"`  // Время повторной аутентификации (Unix)
+}
+
+// ReauthenticateHandler Повторная аутентификация для чувствительных операций
+// @Summary Повторная аутентификация
+// @Description Повторно проверяет факторы пользователя в текущей сессии: пароль, если он задан, и код TOTP или код восстановления, если включен второй фактор.
+// @Description Возвращает короткоживущий access токен с обновленными auth_time и amr для операций, требующих недавней аутентификации (ответ 401 insufficient_user_authentication).
+// @Description Refresh токен сессии не меняется. Аккаунту без пароля и второго фактора нужно войти заново
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <access_token>"
+// @Param request body ReauthenticateReq true "Пароль и/или код второго фактора"
+// @Success 200 {object} ReauthenticateResp "Успех"
+// @Failure 401 {object} handler.ErrorResponse "Невалидный или отозванный токен, неверный пароль или код"
+// @Failure 403 {object} handler.ErrorResponse "Токен выдан клиенту OAuth 2.0"
+// @Failure 429 {object} handler.ErrorResponse "Слишком много неудачных попыток: повторить после Retry-After"
+// @Failure 500 {object} handler.ErrorResponse "Ошибка сервера"
+// @Router /api/auth/reauthenticate [post]
+func (s *AuthReg) ReauthenticateHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
+	logrus.Info("🤍 ReauthenticateHandler")
+	ctx := r.Context()
+
+	guidUser, err := handler.GetGuidFromContext(ctx)
+	if err != nil {
+		return nil, errm.NewError("user_address_not_found", err)
+	}
+	claims, err := handler.GetClaimsFromContext(ctx)
+	if err != nil {
+		return nil, errm.NewError("claims_not_found", err)
+	}
+	sessionID, _ := claims["sid"].(string)
+	if sessionID == "" {
+		return nil, errm.NewError("session_required", errReauthSessionRequired)
+	}
+
+	reauthReq := &ReauthenticateReq{}
+	if errObj := handler.ParseRequestBodyPost(r, reauthReq); errObj != nil {
+		return nil, errObj
+	}
+
+	user, errObj := s.findUser(ctx, &typescore.User{SystemID: &guidUser})
+	if errObj != nil {
+		return nil, errObj
+	}
+	if user == nil {
+		return nil, errm.NewError("user_not_found", errors.New("user not found"))
+	}
+	if user.PasswordHash == nil && user.TOTPEnabledAt == nil {
+		return nil, errm.NewError("reauthentication_unavailable", errReauthUnavailable)
+	}
+
+	// Проверяются все факторы, которые пользователь проходит при входе
+	amr := make([]string, 0, 3)
+	if user.PasswordHash != nil {
+		if errObj := s.reauthPassword(w, r, user, reauthReq.Password); errObj != nil {
+			return nil, errObj
+		}
+		amr = append(amr, securecore.AMRPassword)
+	}
+	if user.TOTPEnabledAt != nil {
+		account := handler.SecondFactorAccount(guidUser)
+		if errObj := handler.CheckAuthLockout(w, r, s.ipc.Lockout, account); errObj != nil {
+			return nil, errObj
+		}
+		if errObj := handler.VerifySecondFactor(ctx, s.ipc.DB, s.ipc.SecretCipher, user, reauthReq.Code, reauthReq.RecoveryCode); errObj != nil {
+			if errors.Is(errObj.Error, handler.ErrInvalidMFACode) {
+				handler.RecordAuthFailure(r, s.ipc.Lockout, s.ipc.RabbitMQ, account, user)
+				return nil, handler.RejectCredentials(w, errObj)
+			}
+			return nil, errObj
+		}
+		handler.RecordAuthSuccess(r, s.ipc.Lockout, account)
+		amr = append(amr, securecore.AMROTP)
+	}
+	if len(amr) > 1 {
+		amr = append(amr, securecore.AMRMultiFactor)
+	}
+
+	resp, err := s.ipc.ClientAuthServiceProto.IssueStepUpToken(ctx, &protoobj.IssueStepUpTokenRequest{
+		UserId:    guidUser,
+		SessionId: sessionID,
+		ClientIp:  handler.GetClientIP(r),
+		Amr:       amr,
+	})
+	if err != nil {
+		return nil, errm.NewError("token_generation_error", err)
+	}
+	logrus.Infof("🔐 reauthenticated: user_id=%s session_id=%s", guidUser, sessionID)
+
+	return &ReauthenticateResp{
+		AccessToken: resp.GetAccessToken(),
+		TokenType:   "Bearer",
+		ExpiresIn:   resp.GetExpiresIn(),
+		AuthTime:    resp.GetAuthTime(),
+	}, nil
+}
+
+// reauthPassword проверяет пароль пользователя с учетом блокировки после неудачных попыток
+func (s *AuthReg) reauthPassword(w http.ResponseWriter, r *http.Request, user *typescore.User, password *string) *errm.Error {
+	if password == nil || *password == "" {
+		return errm.NewError("empty_obj", errors.New("password is required"))
+	}
+	if len(*password) > securecore.PasswordMaxLength*utf8.UTFMax {
+		return handler.RejectCredentials(w, errm.NewError("invalid_credentials", errInvalidCredentials))
+	}
+
+	account := handler.LoginAccount(user, "")
+	if errObj := handler.CheckAuthLockout(w, r, s.ipc.Lockout, account); errObj != nil {
+		return errObj
+	}
+	ok, err := securecore.VerifyPassword(*password, *user.PasswordHash)
+	if err != nil {
+		logrus.Errorf("failed to verify password hash: user_id=%s: %v", *user.SystemID, err)
+		return handler.RejectCredentials(w, errm.NewError("invalid_credentials", errInvalidCredentials))
+	}
+	if !ok {
+		handler.RecordAuthFailure(r, s.ipc.Lockout, s.ipc.RabbitMQ, account, user)
+		return handler.RejectCredentials(w, errm.NewError("invalid_credentials", errInvalidCredentials))
+	}
+	handler.RecordAuthSuccess(r, s.ipc.Lockout, account)
+	return nil
+}
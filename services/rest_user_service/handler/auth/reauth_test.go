@@ -0,0 +1,49 @@
+package authhandler
+
+import (
+	"authentication_service/core/securecore"
+	"authentication_service/core/typescore"
+	typesm "authentication_service/rest_user_service/types"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestReauthPasswordStatus(t *testing.T) {
+	passwordHash, err := securecore.HashPassword("correct horse battery staple")
+	if err != nil {
+		t.Fatalf("HashPassword() error = %v", err)
+	}
+	userID := testUserID
+	user := &typescore.User{SystemID: &userID, PasswordHash: &passwordHash}
+
+	tests := []struct {
+		name       string
+		password   string
+		wantErr    bool
+		wantStatus int
+	}{
+		{"correct password", "correct horse battery staple", false, http.StatusOK},
+		{"wrong password", "wrong password", true, http.StatusUnauthorized},
+		{"too long password", strings.Repeat("a", securecore.PasswordMaxLength*5), true, http.StatusUnauthorized},
+		// Пустой пароль — некорректный запрос, а не неудачная попытка
+		{"empty password", "", true, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &AuthReg{ipc: &typesm.InternalProviderControl{}}
+			w := httptest.NewRecorder()
+			password := tt.password
+
+			errObj := s.reauthPassword(w, httptest.NewRequest(http.MethodPost, "/api/auth/reauthenticate", nil), user, &password)
+			if (errObj != nil) != tt.wantErr {
+				t.Fatalf("reauthPassword() error = %v, wantErr %v", errObj, tt.wantErr)
+			}
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package authhandler
+
+import (
+	errm "authentication_service/core/errmodule"
+	protoobj "authentication_service/core/proto"
+	"authentication_service/rest_user_service/handler"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"net/http"
+)
+
+type RevokeTokenReq struct {
+	Token *string `json:"token"`
+}
+
+type LogoutReq struct {
+	RefreshToken *string `json:"refresh_token"`
+}
+
+// RevokeTokenHandler Отзыв токена
+// @Summary Отзыв токена
+// @Description Отзывает Access или Refresh токен (RFC 7009). При отзыве Refresh токена отзывается вся цепочка ротации
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body RevokeTokenReq true "Токен для отзыва"
+// @Success 200 {object} protoobj.RevokeTokenResponse "Успех"
+// @Failure 400 {object} handler.ErrorResponse "Некорректный запрос"
+// @Failure 500 {object} handler.ErrorResponse "Ошибка сервера"
+// @Router /api/auth/revoke [post]
+func (s *AuthReg) RevokeTokenHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
+	logrus.Info("🤍 RevokeTokenHandler")
+	ctx := r.Context()
+
+	revokeReq := &RevokeTokenReq{}
+	if errObj := handler.ParseRequestBodyPost(r, revokeReq); errObj != nil {
+		return nil, errObj
+	}
+	if revokeReq.Token == nil || *revokeReq.Token == "" {
+		return nil, errm.NewError("empty_obj", errors.New("empty_obj"))
+	}
+
+	resp, err := s.ipc.ClientAuthServiceProto.RevokeToken(ctx, &protoobj.RevokeTokenRequest{Token: *revokeReq.Token})
+	if err != nil {
+		return nil, errm.NewError("token_revoke_error", err)
+	}
+
+	return resp, nil
+}
+
+// LogoutHandler Выход из текущей сессии
+// @Summary Выход из текущей сессии
+// @Description Отзывает Access токен и, если передан, Refresh токен текущей сессии
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <access_token>"
+// @Param request body LogoutReq false "Refresh токен текущей сессии"
+// @Success 200 {object} protoobj.LogoutResponse "Успех"
+// @Failure 400 {object} handler.ErrorResponse "Некорректный запрос"
+// @Failure 401 {object} handler.ErrorResponse "Невалидный или отозванный токен"
+// @Failure 500 {object} handler.ErrorResponse "Ошибка сервера"
+// @Router /api/auth/logout [post]
+func (s *AuthReg) LogoutHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
+	logrus.Info("🤍 LogoutHandler")
+	ctx := r.Context()
+
+	accessToken, errObj := handler.GetBearerToken(r)
+	if errObj != nil {
+		return nil, errObj
+	}
+
+	// Тело запроса необязательно
+	logoutReq := &LogoutReq{}
+	if r.ContentLength > 0 {
+		if errObj := handler.ParseRequestBodyPost(r, logoutReq); errObj != nil {
+			return nil, errObj
+		}
+	}
+
+	req := &protoobj.LogoutRequest{AccessToken: accessToken}
+	if logoutReq.RefreshToken != nil {
+		req.RefreshToken = *logoutReq.RefreshToken
+	}
+
+	resp, err := s.ipc.ClientAuthServiceProto.Logout(ctx, req)
+	if err != nil {
+		return nil, errm.NewError("logout_error", err)
+	}
+
+	return resp, nil
+}
+
+// LogoutAllHandler Выход из всех сессий
+// @Summary Выход из всех сессий
+// @Description Отзывает все Access и Refresh токены пользователя, выданные до текущего момента, и удаляет его персональные токены доступа
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <access_token>"
+// @Success 200 {object} protoobj.LogoutAllResponse "Успех"
+// @Failure 401 {object} handler.ErrorResponse "Невалидный или отозванный токен"
+// @Failure 500 {object} handler.ErrorResponse "Ошибка сервера"
+// @Router /api/auth/logout-all [post]
+func (s *AuthReg) LogoutAllHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
+	logrus.Info("🤍 LogoutAllHandler")
+	ctx := r.Context()
+
+	accessToken, errObj := handler.GetBearerToken(r)
+	if errObj != nil {
+		return nil, errObj
+	}
+
+	resp, err := s.ipc.ClientAuthServiceProto.LogoutAll(ctx, &protoobj.LogoutAllRequest{AccessToken: accessToken})
+	if err != nil {
+		return nil, errm.NewError("logout_all_error", err)
+	}
+
+	return resp, nil
+}
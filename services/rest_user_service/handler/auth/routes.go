@@ -2,6 +2,7 @@ package authhandler
 
 import (
 	errm "authentication_service/core/errmodule"
+	"authentication_service/core/typescore"
 	"authentication_service/rest_user_service/handler"
 	typesm "authentication_service/rest_user_service/types"
 	"github.com/go-chi/chi/v5"
@@ -9,8 +10,27 @@ import (
 )
 
 const (
-	authURI    = "/issue"
-	refreshURI = "/refresh"
+	registerURI       = "/register"
+	loginURI          = "/login"
+	mfaVerifyURI      = "/mfa/verify"
+	webAuthnBeginURI  = "/webauthn/login/begin"
+	webAuthnFinishURI = "/webauthn/login/finish"
+	emailConfirmURI   = "/email/confirm"
+	emailResendURI    = "/email/resend"
+	passwordForgotURI = "/password/forgot"
+	passwordResetURI  = "/password/reset"
+	magicLinkURI      = "/magic-link"
+	magicConsumeURI   = "/magic-link/consume"
+	telegramWidgetURI = "/telegram/widget"
+	telegramWebAppURI = "/telegram/webapp"
+	oidcBeginURI      = "/oidc/{provider}/begin"
+	oidcCallbackURI   = "/oidc/{provider}/callback"
+	refreshURI        = "/refresh"
+	revokeURI         = "/revoke"
+	logoutURI         = "/logout"
+	logoutAllURI      = "/logout-all"
+	introspectURI     = "/introspect"
+	reauthURI         = "/reauthenticate"
 )
 
 type AuthReg struct {
@@ -28,8 +48,46 @@ func RegisterAuthRoutes(
 	}
 
 	r.Route("/api/auth", func(r chi.Router) {
-		handler.RegisterRoute(r, http.MethodPost, authURI, s.IssueTokensHandler)
+		handler.RegisterRoute(r, http.MethodPost, registerURI, s.RegisterHandler)
+		handler.RegisterRoute(r, http.MethodPost, loginURI, s.LoginHandler)
+		handler.RegisterRoute(r, http.MethodPost, mfaVerifyURI, s.MFAVerifyHandler)
+		handler.RegisterRoute(r, http.MethodPost, webAuthnBeginURI, s.WebAuthnLoginBeginHandler)
+		handler.RegisterRoute(r, http.MethodPost, webAuthnFinishURI, s.WebAuthnLoginFinishHandler)
 		handler.RegisterRoute(r, http.MethodPost, refreshURI, s.RefreshTokensHandler)
+		handler.RegisterRoute(r, http.MethodPost, revokeURI, s.RevokeTokenHandler)
+		handler.RegisterRoute(r, http.MethodPost, logoutURI, s.LogoutHandler)
+		handler.RegisterRoute(r, http.MethodPost, logoutAllURI, s.LogoutAllHandler)
+		handler.RegisterRoute(r, http.MethodPost, introspectURI, s.IntrospectTokenHandler)
+		handler.RegisterRoute(r, http.MethodPost, emailConfirmURI, s.ConfirmEmailHandler)
+		handler.RegisterRoute(r, http.MethodPost, passwordForgotURI, s.ForgotPasswordHandler)
+		handler.RegisterRoute(r, http.MethodPost, passwordResetURI, s.ResetPasswordHandler)
+		handler.RegisterRoute(r, http.MethodPost, magicLinkURI, s.MagicLinkHandler)
+		handler.RegisterRoute(r, http.MethodPost, magicConsumeURI, s.MagicLinkConsumeHandler)
+		handler.RegisterRoute(r, http.MethodPost, telegramWidgetURI, s.TelegramWidgetLoginHandler)
+		handler.RegisterRoute(r, http.MethodPost, telegramWebAppURI, s.TelegramWebAppLoginHandler)
+		handler.RegisterRoute(r, http.MethodPost, oidcBeginURI, s.OIDCBeginHandler)
+		handler.RegisterRoute(r, http.MethodPost, oidcCallbackURI, s.OIDCCallbackHandler)
+
+		verifier := handler.JWTVerifier(handler.JWTVerifierParams{
+			TokenFormat:   ipc.TokenFormat,
+			TokenPolicy:   ipc.TokenPolicy,
+			TokenDenylist: ipc.TokenDenylist,
+		})
+
+		// Повторная отправка кода доступна только неподтвержденному аккаунту
+		ra := r.With(verifier, handler.RequireScope(typescore.EmailVerifyScope))
+		handler.RegisterRoute(ra, http.MethodPost, emailResendURI, s.ResendEmailCodeHandler)
+
+		// Повторная аутентификация в сессии собственного приложения. Принимает и токен, сменивший сеть
+		// при политике привязки step_up: после проверки факторов сессия привязывается к новому адресу
+		stepUpVerifier := handler.JWTVerifier(handler.JWTVerifierParams{
+			TokenFormat:   ipc.TokenFormat,
+			TokenPolicy:   ipc.TokenPolicy,
+			TokenDenylist: ipc.TokenDenylist,
+			AllowIPStepUp: true,
+		})
+		rs := r.With(stepUpVerifier, handler.RequireFirstPartyToken)
+		handler.RegisterRoute(rs, http.MethodPost, reauthURI, s.ReauthenticateHandler)
 	})
 
 	return nil
@@ -0,0 +1,178 @@
+package authhandler
+
+import (
+	errm "authentication_service/core/errmodule"
+	"authentication_service/core/securecore"
+	"authentication_service/core/typescore"
+	"authentication_service/rest_user_service/handler"
+	"context"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const defaultTelegramAuthMaxAge = time.Hour
+
+var (
+	errTelegramUnavailable = errors.New("telegram login is not configured")
+	errInvalidTelegramAuth = errors.New("invalid or expired telegram auth data")
+)
+
+// TelegramWidgetReq данные, переданные Telegram Login Widget, без изменений
+type TelegramWidgetReq struct {
+	ID         *int64  `json:"id"`
+	FirstName  *string `json:"first_name"`
+	LastName   *string `json:"last_name"`
+	Username   *string `json:"username"`
+	PhotoURL   *string `json:"photo_url"`
+	AuthDate   *int64  `json:"auth_date"`
+	Hash       *string `json:"hash"`
+	DeviceName *string `json:"device_name"` // Название устройства для списка сессий (не входит в подпись)
+}
+
+type TelegramWebAppReq struct {
+	InitData   *string `json:"init_data"`   // Telegram.WebApp.initData без изменений
+	DeviceName *string `json:"device_name"` // Название устройства для списка сессий
+}
+
+// TelegramWidgetLoginHandler Вход через Telegram Login Widget
+// @Summary Вход через Telegram Login Widget
+// @Description Проверяет подпись данных виджета токеном бота и срок auth_date, находит или создает пользователя по telegram_id и выдает Access и Refresh токены.
+// @Description Если включен TOTP, вместо токенов возвращается промежуточный mfa_token для /api/auth/mfa/verify
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body TelegramWidgetReq true "Данные виджета"
+// @Success 200 {object} protoobj.IssueTokensResponse "Успех"
+// @Failure 400 {object} handler.ErrorResponse "Некорректный запрос"
+// @Failure 429 {object} handler.ErrorResponse "Слишком много неудачных попыток: повторить после Retry-After"
+// @Failure 500 {object} handler.ErrorResponse "Неверная подпись или ошибка сервера"
+// @Router /api/auth/telegram/widget [post]
+func (s *AuthReg) TelegramWidgetLoginHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
+	logrus.Info("🤍 TelegramWidgetLoginHandler")
+	ctx := r.Context()
+
+	cfg := s.ipc.Config.Telegram
+	if cfg.BotToken == "" {
+		return nil, errm.NewError("telegram_unavailable", errTelegramUnavailable)
+	}
+
+	widgetReq := &TelegramWidgetReq{}
+	if errObj := handler.ParseRequestBodyPost(r, widgetReq); errObj != nil {
+		return nil, errObj
+	}
+	if widgetReq.ID == nil || widgetReq.AuthDate == nil || widgetReq.Hash == nil {
+		return nil, errm.NewError("empty_obj", errors.New("id, auth_date and hash are required"))
+	}
+
+	if errObj := handler.CheckAuthLockout(w, r, s.ipc.Lockout, ""); errObj != nil {
+		return nil, errObj
+	}
+
+	// В подпись входят только поля, переданные виджетом
+	fields := map[string]string{
+		"id":        strconv.FormatInt(*widgetReq.ID, 10),
+		"auth_date": strconv.FormatInt(*widgetReq.AuthDate, 10),
+		"hash":      *widgetReq.Hash,
+	}
+	for key, value := range map[string]*string{
+		"first_name": widgetReq.FirstName,
+		"last_name":  widgetReq.LastName,
+		"username":   widgetReq.Username,
+		"photo_url":  widgetReq.PhotoURL,
+	} {
+		if value != nil {
+			fields[key] = *value
+		}
+	}
+
+	identity, err := securecore.VerifyTelegramLogin(cfg.BotToken, fields, telegramAuthMaxAge(cfg.AuthMaxAge), time.Now())
+	if err != nil {
+		logrus.Warnf("telegram widget: %v", err)
+		handler.RecordAuthFailure(r, s.ipc.Lockout, s.ipc.RabbitMQ, "", nil)
+		return nil, errm.NewError("invalid_telegram_auth", errInvalidTelegramAuth)
+	}
+
+	return s.telegramLogin(ctx, r, identity, widgetReq.DeviceName)
+}
+
+// TelegramWebAppLoginHandler Вход из Telegram Mini App
+// @Summary Вход из Telegram Mini App
+// @Description Проверяет подпись initData токеном бота и срок auth_date, находит или создает пользователя по telegram_id и выдает Access и Refresh токены.
+// @Description Если включен TOTP, вместо токенов возвращается промежуточный mfa_token для /api/auth/mfa/verify
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body TelegramWebAppReq true "initData Mini App"
+// @Success 200 {object} protoobj.IssueTokensResponse "Успех"
+// @Failure 400 {object} handler.ErrorResponse "Некорректный запрос"
+// @Failure 429 {object} handler.ErrorResponse "Слишком много неудачных попыток: повторить после Retry-After"
+// @Failure 500 {object} handler.ErrorResponse "Неверная подпись или ошибка сервера"
+// @Router /api/auth/telegram/webapp [post]
+func (s *AuthReg) TelegramWebAppLoginHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
+	logrus.Info("🤍 TelegramWebAppLoginHandler")
+	ctx := r.Context()
+
+	cfg := s.ipc.Config.Telegram
+	if cfg.BotToken == "" {
+		return nil, errm.NewError("telegram_unavailable", errTelegramUnavailable)
+	}
+
+	webAppReq := &TelegramWebAppReq{}
+	if errObj := handler.ParseRequestBodyPost(r, webAppReq); errObj != nil {
+		return nil, errObj
+	}
+	if webAppReq.InitData == nil || *webAppReq.InitData == "" {
+		return nil, errm.NewError("empty_obj", errors.New("init_data is required"))
+	}
+
+	if errObj := handler.CheckAuthLockout(w, r, s.ipc.Lockout, ""); errObj != nil {
+		return nil, errObj
+	}
+
+	identity, err := securecore.VerifyTelegramInitData(cfg.BotToken, *webAppReq.InitData, telegramAuthMaxAge(cfg.AuthMaxAge), time.Now())
+	if err != nil {
+		logrus.Warnf("telegram webapp: %v", err)
+		handler.RecordAuthFailure(r, s.ipc.Lockout, s.ipc.RabbitMQ, "", nil)
+		return nil, errm.NewError("invalid_telegram_auth", errInvalidTelegramAuth)
+	}
+
+	return s.telegramLogin(ctx, r, identity, webAppReq.DeviceName)
+}
+
+// telegramLogin находит пользователя по telegram_id, при первом входе создает его, и завершает вход
+func (s *AuthReg) telegramLogin(ctx context.Context, r *http.Request, identity *securecore.TelegramIdentity, deviceName *string) (interface{}, *errm.Error) {
+	user, errObj := s.findUser(ctx, &typescore.User{TelegramID: &identity.ID})
+	if errObj != nil {
+		return nil, errObj
+	}
+
+	if user == nil {
+		userID, err := securecore.GenerateUUID()
+		if err != nil {
+			return nil, errm.NewError("user_create_error", err)
+		}
+		user = &typescore.User{
+			SystemID:   &userID,
+			TelegramID: &identity.ID,
+			FirstName:  profileName(identity.FirstName),
+			LastName:   profileName(identity.LastName),
+		}
+		if _, _, errObj := s.ipc.DB.Users.CreateUserDB(ctx, nil, user); errObj != nil {
+			return nil, errObj
+		}
+		logrus.Infof("telegram: user created: user_id=%s telegram_id=%d", userID, identity.ID)
+	}
+
+	return s.completeLogin(ctx, r, user, deviceName, []string{securecore.AMRFederated})
+}
+
+// telegramAuthMaxAge срок действия данных входа Telegram с учетом значения по умолчанию
+func telegramAuthMaxAge(maxAge time.Duration) time.Duration {
+	if maxAge <= 0 {
+		return defaultTelegramAuthMaxAge
+	}
+	return maxAge
+}
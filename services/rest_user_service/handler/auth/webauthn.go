@@ -0,0 +1,223 @@
+package authhandler
+
+import (
+	errm "authentication_service/core/errmodule"
+	"authentication_service/core/securecore"
+	"authentication_service/core/typescore"
+	"authentication_service/rest_user_service/handler"
+	"encoding/json"
+	"errors"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"time"
+)
+
+// префикс церемонии входа; церемония определяется ceremony_id из ответа begin
+const webAuthnLoginKeyPrefix = "login:"
+
+var errInvalidPasskey = errors.New("invalid passkey assertion")
+
+type WebAuthnLoginBeginReq struct {
+	Login *string `json:"login"` // Email или никнейм; пусто — вход выбранным на устройстве ключом (discoverable)
+}
+
+type WebAuthnLoginBeginResp struct {
+	CeremonyID string                        `json:"ceremony_id"`                  // Передается в /api/auth/webauthn/login/finish
+	Options    *protocol.CredentialAssertion `json:"options" swaggertype:"object"` // Параметры для navigator.credentials.get()
+}
+
+type WebAuthnLoginFinishReq struct {
+	CeremonyID *string         `json:"ceremony_id"`
+	Credential json.RawMessage `json:"credential" swaggertype:"object"` // Ответ navigator.credentials.get()
+	DeviceName *string         `json:"device_name"`                     // Название устройства для списка сессий
+}
+
+// WebAuthnLoginBeginHandler Начало входа по ключу доступа
+// @Summary Начало входа по ключу доступа
+// @Description Возвращает параметры для navigator.credentials.get(). Без логина выполняется вход выбранным на устройстве ключом (passkey)
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body WebAuthnLoginBeginReq false "Логин пользователя"
+// @Success 200 {object} WebAuthnLoginBeginResp "Успех"
+// @Failure 500 {object} handler.ErrorResponse "Ошибка сервера"
+// @Router /api/auth/webauthn/login/begin [post]
+func (s *AuthReg) WebAuthnLoginBeginHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
+	logrus.Info("🤍 WebAuthnLoginBeginHandler")
+	ctx := r.Context()
+
+	if s.ipc.WebAuthn == nil {
+		return nil, errm.NewError("webauthn_unavailable", handler.ErrWebAuthnUnavailable)
+	}
+
+	beginReq := &WebAuthnLoginBeginReq{}
+	if r.ContentLength != 0 {
+		if errObj := handler.ParseRequestBodyPost(r, beginReq); errObj != nil {
+			return nil, errObj
+		}
+	}
+
+	// Для известного логина с ключами доступа список ключей ограничивается ключами пользователя.
+	// Иначе выполняется вход без логина, чтобы ответ не раскрывал существование аккаунта
+	var webAuthnUser *handler.WebAuthnUser
+	if beginReq.Login != nil && *beginReq.Login != "" {
+		user, errObj := s.findUserByLogin(ctx, *beginReq.Login)
+		if errObj != nil {
+			return nil, errObj
+		}
+		if user != nil {
+			if webAuthnUser, errObj = handler.LoadWebAuthnUser(ctx, s.ipc.DB, user); errObj != nil {
+				return nil, errObj
+			}
+			if len(webAuthnUser.WebAuthnCredentials()) == 0 {
+				webAuthnUser = nil
+			}
+		}
+	}
+
+	var (
+		assertion *protocol.CredentialAssertion
+		session   *webauthn.SessionData
+		err       error
+	)
+	if webAuthnUser != nil {
+		assertion, session, err = s.ipc.WebAuthn.BeginLogin(webAuthnUser)
+	} else {
+		assertion, session, err = s.ipc.WebAuthn.BeginDiscoverableLogin()
+	}
+	if err != nil {
+		return nil, errm.NewError("webauthn_begin_error", err)
+	}
+
+	ceremonyID, err := securecore.GenerateUUID()
+	if err != nil {
+		return nil, errm.NewError("webauthn_session_error", err)
+	}
+	if err := handler.SaveWebAuthnSession(ctx, s.ipc.KVStore, webAuthnLoginKeyPrefix+ceremonyID, session); err != nil {
+		return nil, errm.NewError("webauthn_session_error", err)
+	}
+
+	return &WebAuthnLoginBeginResp{
+		CeremonyID: ceremonyID,
+		Options:    assertion,
+	}, nil
+}
+
+// WebAuthnLoginFinishHandler Завершение входа по ключу доступа
+// @Summary Завершение входа по ключу доступа
+// @Description Проверяет подпись аутентификатора и счетчик подписей и выдает Access и Refresh токены
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body WebAuthnLoginFinishReq true "Ответ аутентификатора"
+// @Success 200 {object} protoobj.IssueTokensResponse "Успех"
+// @Failure 400 {object} handler.ErrorResponse "Некорректный запрос"
+// @Failure 401 {object} handler.ErrorResponse "Неверный ключ доступа"
+// @Failure 429 {object} handler.ErrorResponse "Слишком много неудачных попыток: повторить после Retry-After"
+// @Failure 500 {object} handler.ErrorResponse "Ошибка сервера"
+// @Router /api/auth/webauthn/login/finish [post]
+func (s *AuthReg) WebAuthnLoginFinishHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
+	logrus.Info("🤍 WebAuthnLoginFinishHandler")
+	ctx := r.Context()
+
+	if s.ipc.WebAuthn == nil {
+		return nil, errm.NewError("webauthn_unavailable", handler.ErrWebAuthnUnavailable)
+	}
+
+	finishReq := &WebAuthnLoginFinishReq{}
+	if errObj := handler.ParseRequestBodyPost(r, finishReq); errObj != nil {
+		return nil, errObj
+	}
+	if finishReq.CeremonyID == nil || *finishReq.CeremonyID == "" || len(finishReq.Credential) == 0 {
+		return nil, errm.NewError("empty_obj", errors.New("ceremony_id and credential are required"))
+	}
+
+	if errObj := handler.CheckAuthLockout(w, r, s.ipc.Lockout, ""); errObj != nil {
+		return nil, errObj
+	}
+
+	session, err := handler.TakeWebAuthnSession(ctx, s.ipc.KVStore, webAuthnLoginKeyPrefix+*finishReq.CeremonyID)
+	if err != nil {
+		return nil, errm.NewError("webauthn_session_error", err)
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(finishReq.Credential)
+	if err != nil {
+		handler.RecordAuthFailure(r, s.ipc.Lockout, s.ipc.RabbitMQ, "", nil)
+		return nil, handler.RejectCredentials(w, errm.NewError("invalid_passkey", errInvalidPasskey))
+	}
+
+	var (
+		webAuthnUser *handler.WebAuthnUser
+		credential   *webauthn.Credential
+	)
+	if len(session.UserID) > 0 {
+		userID := string(session.UserID)
+		user, errObj := s.findUser(ctx, &typescore.User{SystemID: &userID})
+		if errObj != nil {
+			return nil, errObj
+		}
+		if user == nil {
+			handler.RecordAuthFailure(r, s.ipc.Lockout, s.ipc.RabbitMQ, "", nil)
+			return nil, handler.RejectCredentials(w, errm.NewError("invalid_passkey", errInvalidPasskey))
+		}
+		if webAuthnUser, errObj = handler.LoadWebAuthnUser(ctx, s.ipc.DB, user); errObj != nil {
+			return nil, errObj
+		}
+		credential, err = s.ipc.WebAuthn.ValidateLogin(webAuthnUser, *session, parsed)
+	} else {
+		// Пользователь определяется по user handle, сохраненному аутентификатором при регистрации
+		_, credential, err = s.ipc.WebAuthn.ValidatePasskeyLogin(func(rawID, userHandle []byte) (webauthn.User, error) {
+			userID := string(userHandle)
+			if !securecore.IsValidUUID(userID) {
+				return nil, errInvalidPasskey
+			}
+			user, errObj := s.findUser(ctx, &typescore.User{SystemID: &userID})
+			if errObj != nil {
+				return nil, errObj.Error
+			}
+			if user == nil {
+				return nil, errInvalidPasskey
+			}
+			if webAuthnUser, errObj = handler.LoadWebAuthnUser(ctx, s.ipc.DB, user); errObj != nil {
+				return nil, errObj.Error
+			}
+			return webAuthnUser, nil
+		}, *session, parsed)
+	}
+	if err != nil {
+		logrus.Warnf("passkey assertion rejected: %v", err)
+		handler.RecordAuthFailure(r, s.ipc.Lockout, s.ipc.RabbitMQ, "", nil)
+		return nil, handler.RejectCredentials(w, errm.NewError("invalid_passkey", errInvalidPasskey))
+	}
+
+	record := webAuthnUser.Record(credential.ID)
+	if record == nil {
+		handler.RecordAuthFailure(r, s.ipc.Lockout, s.ipc.RabbitMQ, "", nil)
+		return nil, handler.RejectCredentials(w, errm.NewError("invalid_passkey", errInvalidPasskey))
+	}
+	userID := *webAuthnUser.User.SystemID
+
+	// Счетчик подписей не увеличился — возможно, ключ скопирован с аутентификатора
+	if credential.Authenticator.CloneWarning {
+		logrus.Warnf("passkey sign count did not increase, possible cloned authenticator: user_id=%s credential=%s", userID, *record.ID)
+		handler.RecordAuthFailure(r, s.ipc.Lockout, s.ipc.RabbitMQ, "", nil)
+		return nil, handler.RejectCredentials(w, errm.NewError("invalid_passkey", errInvalidPasskey))
+	}
+
+	signCount := int64(credential.Authenticator.SignCount)
+	backupState := credential.Flags.BackupState
+	now := time.Now().UTC()
+	if _, _, errObj := s.ipc.DB.WebAuthn.UpdateWebAuthnCredentialDB(ctx, nil, &typescore.WebAuthnCredential{
+		ID:          record.ID,
+		SignCount:   &signCount,
+		BackupState: &backupState,
+		LastUsedAt:  &now,
+	}); errObj != nil {
+		return nil, errObj
+	}
+
+	return s.issueTokens(ctx, r, userID, finishReq.DeviceName, []string{securecore.AMRHardwareKey})
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	errm "authentication_service/core/errmodule"
+	rabbitmqlib "authentication_service/core/lib/external/rabbitmq-lib"
+	"authentication_service/core/lib/internally/lockout"
+	"authentication_service/core/securecore"
+	"authentication_service/core/typescore"
+	"authentication_service/core/variables"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// CheckAuthLockout отклоняет проверку учетных данных, пока аккаунт или IP-адрес клиента заблокирован
+// или не истекла задержка после неудачной попытки: ответ 429 с заголовком Retry-After.
+// Пустой account — проверяется только IP-адрес
+func CheckAuthLockout(w http.ResponseWriter, r *http.Request, tracker *lockout.Tracker, account string) *errm.Error {
+	retryAfter, locked := AuthLockoutRetryAfter(r, tracker, account)
+	if !locked {
+		return nil
+	}
+
+	w.Header().Set("Retry-After", retryAfter)
+	w.WriteHeader(http.StatusTooManyRequests)
+	return errm.NewError("too_many_attempts", lockout.ErrLocked)
+}
+
+// RejectCredentials отклоняет неверные учетные данные ответом 401 и возвращает ошибку для тела ответа
+func RejectCredentials(w http.ResponseWriter, errObj *errm.Error) *errm.Error {
+	w.WriteHeader(http.StatusUnauthorized)
+	return errObj
+}
+
+// AuthLockoutRetryAfter возвращает значение заголовка Retry-After (в секундах), если попытки
+// аккаунта или IP-адреса клиента временно отклоняются. Для эндпоинтов со своим форматом ошибок
+func AuthLockoutRetryAfter(r *http.Request, tracker *lockout.Tracker, account string) (string, bool) {
+	if tracker == nil {
+		return "", false
+	}
+	var lockedErr *lockout.LockedError
+	if !errors.As(tracker.Check(r.Context(), account, GetClientIP(r)), &lockedErr) {
+		return "", false
+	}
+	return strconv.Itoa(int(math.Ceil(lockedErr.RetryAfter.Seconds()))), true
+}
+
+// RecordAuthFailure учитывает неудачную проверку учетных данных по аккаунту и IP-адресу клиента.
+// Если попытка заблокировала аккаунт существующего пользователя (user не nil), он получает уведомление
+func RecordAuthFailure(r *http.Request, tracker *lockout.Tracker, rabbit *rabbitmqlib.ConnectionRabitMq, account string, user *typescore.User) {
+	if tracker == nil {
+		return
+	}
+	clientIP := GetClientIP(r)
+	result := tracker.Failure(r.Context(), account, clientIP)
+	if !result.AccountLocked || user == nil || user.SystemID == nil {
+		return
+	}
+
+	category := typescore.AccountLockedNotifyCategory
+	err := rabbitmqlib.PublishMessage(rabbit,
+		variables.RabbitMQExchangeNotifications,
+		variables.RabbitMQNotificationsServiceRoute,
+		&typescore.NotifyParams{
+			Text:      &clientIP,
+			IsEmail:   true,
+			Emergency: true,
+			UsersIDs:  []*string{user.SystemID},
+			Category:  &category,
+		})
+	if err != nil {
+		logrus.Errorf("failed to send lockout notification: user_id=%s: %v", *user.SystemID, err.Error)
+	}
+}
+
+// RecordAuthSuccess сбрасывает неудачные попытки аккаунта после успешной проверки учетных данных
+func RecordAuthSuccess(r *http.Request, tracker *lockout.Tracker, account string) {
+	if tracker == nil {
+		return
+	}
+	tracker.Success(r.Context(), account)
+}
+
+// LoginAccount ключ учета попыток для входа по логину: системный идентификатор известного пользователя,
+// иначе хеш логина — неизвестный логин блокируется так же, и ответ не раскрывает существование аккаунта
+func LoginAccount(user *typescore.User, login string) string {
+	if user != nil && user.SystemID != nil {
+		return *user.SystemID
+	}
+	return "login:" + securecore.HashToken(strings.ToLower(strings.TrimSpace(login)))
+}
+
+// SecondFactorAccount ключ учета попыток ввода второго фактора. Отделен от ключа входа по паролю:
+// иначе знающий пароль сбрасывал бы счетчик перебора кода TOTP успешным вводом пароля
+func SecondFactorAccount(userID string) string {
+	return "mfa:" + userID
+}
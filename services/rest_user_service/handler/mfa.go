@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"authentication_service/core/database"
+	errm "authentication_service/core/errmodule"
+	"authentication_service/core/securecore"
+	"authentication_service/core/typescore"
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrInvalidMFACode неверный, просроченный или уже использованный код второго фактора
+var ErrInvalidMFACode = errors.New("invalid mfa code")
+
+// ErrMFAUnavailable ключ шифрования секретов TOTP не настроен
+var ErrMFAUnavailable = errors.New("totp is not available: aes_bucket_key is not configured")
+
+// DecryptTOTPSecret расшифровывает секрет TOTP пользователя
+func DecryptTOTPSecret(cipher *securecore.SecretCipher, user *typescore.User) (string, *errm.Error) {
+	if cipher == nil {
+		return "", errm.NewError("mfa_unavailable", ErrMFAUnavailable)
+	}
+	if user.TOTPSecret == nil {
+		return "", errm.NewError("totp_not_enrolled", errors.New("totp is not enrolled"))
+	}
+	secret, err := cipher.Decrypt(*user.TOTPSecret)
+	if err != nil {
+		return "", errm.NewError("totp_secret_error", err)
+	}
+	return secret, nil
+}
+
+// VerifySecondFactor проверяет код TOTP или одноразовый код восстановления пользователя.
+// Принятый временной шаг TOTP и использованный код восстановления повторно не принимаются.
+func VerifySecondFactor(ctx context.Context, db *database.ModuleDB, cipher *securecore.SecretCipher, user *typescore.User, code, recoveryCode *string) *errm.Error {
+	switch {
+	case code != nil && *code != "":
+		secret, errObj := DecryptTOTPSecret(cipher, user)
+		if errObj != nil {
+			return errObj
+		}
+		step, ok, err := securecore.ValidateTOTP(secret, *code, time.Now())
+		if err != nil {
+			return errm.NewError("totp_secret_error", err)
+		}
+		if !ok {
+			return errm.NewError("invalid_mfa_code", ErrInvalidMFACode)
+		}
+		accepted, errObj := db.Users.AcceptUserTOTPStepDB(ctx, *user.SystemID, step)
+		if errObj != nil {
+			return errObj
+		}
+		if !accepted {
+			return errm.NewError("invalid_mfa_code", ErrInvalidMFACode)
+		}
+		return nil
+	case recoveryCode != nil && *recoveryCode != "":
+		used, errObj := db.RecoveryCodes.UseRecoveryCodeDB(ctx, *user.SystemID, securecore.HashRecoveryCode(*recoveryCode))
+		if errObj != nil {
+			return errObj
+		}
+		if !used {
+			return errm.NewError("invalid_mfa_code", ErrInvalidMFACode)
+		}
+		return nil
+	default:
+		return errm.NewError("empty_mfa_code", errors.New("code or recovery_code is required"))
+	}
+}
@@ -1,14 +1,18 @@
 package handler
 
 import (
-	"authentication_service/core/configcore"
+	"authentication_service/core/database"
 	errm "authentication_service/core/errmodule"
+	"authentication_service/core/lib/internally/denylist"
 	"authentication_service/core/securecore"
 	"context"
 	"errors"
+	"fmt"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/sirupsen/logrus"
 	"net"
 	"net/http"
+	"net/netip"
 	"strings"
 )
 
@@ -33,57 +37,157 @@ func validateCredentials(username, password, validUsername, validPassword string
 // Определить пользовательский тип для контекстных ключей
 type contextKey string
 
-const guidContextKey contextKey = "guid"
+const (
+	guidContextKey           contextKey = "guid"
+	claimsContextKey         contextKey = "claims"
+	serviceAccountContextKey contextKey = "service_account"
+)
+
+// JWTVerifierParams зависимости middleware проверки токена
+type JWTVerifierParams struct {
+	TokenFormat   securecore.TokenFormat
+	TokenPolicy   securecore.TokenPolicy
+	TokenDenylist *denylist.TokenDenylist
+
+	// AllowServiceAccounts принимать токены сервисных аккаунтов (client_credentials) без пользователя.
+	// client_id такого токена доступен через GetServiceAccountFromContext, GUID в контексте не выставляется
+	AllowServiceAccounts bool
 
-// JWTVerifier middleware для проверки JWT токена
-func JWTVerifier(cfg *configcore.Config) func(http.Handler) http.Handler {
+	// AllowPersonalTokens принимать персональные токены доступа (с префиксом securecore.PersonalAccessTokenPrefix)
+	// наравне с access токенами. Для проверки требуется DB
+	AllowPersonalTokens bool
+	DB                  *database.ModuleDB
+
+	// AllowIPStepUp принимать токен, сменивший сеть, при политике привязки step_up.
+	// Включается только для повторной аутентификации: она и есть требуемый step-up
+	AllowIPStepUp bool
+}
+
+// JWTVerifier middleware для проверки access токена.
+// Refresh токены отклоняются; токены сервисных аккаунтов — если не включен AllowServiceAccounts,
+// персональные токены доступа — если не включен AllowPersonalTokens.
+func JWTVerifier(p JWTVerifierParams) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if cfg.Secrets.AuthJWT.UserSecret == "" {
-				errm.NewError("jwt_secret_not_found", errors.New("jwt secret not found"))
+			if p.TokenFormat == nil {
+				errm.NewError("token_format_not_found", errors.New("token format not found"))
 				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 				return
 			}
 
-			authHeader := r.Header.Get("Authorization")
-			if authHeader == "" {
-				errm.NewError("jwt_token_not_found", errors.New("jwt token not found"))
+			tokenString, errObj := GetBearerToken(r)
+			if errObj != nil {
 				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
 				return
 			}
 
-			parts := strings.Split(authHeader, " ")
-			if len(parts) != 2 || parts[0] != "Bearer" {
-				errm.NewError("jwt_bearer_not_found", errors.New("invalid authorization header"))
+			// Получение IP-адреса клиента
+			clientIP := GetClientIP(r)
+			if clientIP == "" {
+				errm.NewError("client_ip_not_found", errors.New("unable to determine client IP"))
 				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
 				return
 			}
 
-			tokenString := parts[1]
+			// Персональный токен доступа проверяется по базе, а не подписью
+			if strings.HasPrefix(tokenString, securecore.PersonalAccessTokenPrefix) {
+				if !p.AllowPersonalTokens {
+					errm.NewError("personal_token_not_allowed", errors.New("personal access tokens are not accepted"))
+					http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+					return
+				}
+				claims, errObj := VerifyPersonalAccessToken(r.Context(), p.DB, tokenString, clientIP)
+				if errObj != nil {
+					if errors.Is(errObj.Error, ErrInvalidPersonalToken) {
+						http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+						return
+					}
+					http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+					return
+				}
 
-			// Получение IP-адреса клиента
-			clientIP := getClientIP(r)
-			if clientIP == "" {
-				errm.NewError("client_ip_not_found", errors.New("unable to determine client IP"))
-				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				guid, _ := claims["guid"].(string)
+				ctx := context.WithValue(r.Context(), guidContextKey, guid)
+				ctx = context.WithValue(ctx, claimsContextKey, claims)
+				next.ServeHTTP(w, r.WithContext(ctx))
 				return
 			}
 
 			// Проверка токена
-			_, claims, err := securecore.VerifyToken(tokenString, cfg.Secrets.AuthJWT.UserSecret, clientIP, jwt.SigningMethodHS512)
+			claims, ipAction, err := securecore.VerifyToken(tokenString, p.TokenFormat, p.TokenPolicy, securecore.TokenUseAccess, clientIP)
 			if err != nil {
 				errm.NewError("jwt_token_verification_error", err)
 				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
 				return
 			}
 
-			// Сохранение GUID в контексте
-			ctx := context.WithValue(r.Context(), guidContextKey, claims["guid"].(string))
+			// Смена сети клиента требует повторной аутентификации
+			if ipAction == securecore.IPMismatchStepUp && !p.AllowIPStepUp {
+				errm.NewError("jwt_step_up_required", securecore.ErrIPMismatch)
+				respondWithStatus(w, http.StatusUnauthorized, "step_up_required")
+				return
+			}
+
+			guid, _ := claims["guid"].(string)
+			serviceAccount := ""
+			if guid == "" && p.AllowServiceAccounts {
+				serviceAccount, _ = claims["client_id"].(string)
+			}
+			if guid == "" && serviceAccount == "" {
+				errm.NewError("jwt_guid_not_found", errors.New("guid not found in token claims"))
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+
+			// Проверка отзыва токена. При недоступности хранилища запрос отклоняется
+			jti, _ := claims["jti"].(string)
+			sessionID, _ := claims["sid"].(string)
+			issuedAt, _ := securecore.ClaimTime(claims, "iat")
+			revoked, err := p.TokenDenylist.IsTokenRevoked(r.Context(), jti, guid, sessionID, issuedAt)
+			if err != nil {
+				errm.NewError("jwt_denylist_check_error", err)
+				http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+				return
+			}
+			if revoked {
+				errm.NewError("jwt_token_revoked", errors.New("token revoked"))
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+
+			// Каждый запрос сотрудника от имени пользователя попадает в журнал: jti связывает его с записью о выдаче токена
+			if actorID := securecore.ClaimActor(claims); actorID != "" {
+				logrus.Infof("🎭 impersonated request: impersonation_id=%s actor_id=%s user_id=%s %s %s ip=%s",
+					jti, actorID, guid, r.Method, r.URL.Path, clientIP)
+			}
+
+			// Сохранение GUID (или client_id сервисного аккаунта) и claims токена в контексте
+			ctx := context.WithValue(r.Context(), claimsContextKey, claims)
+			if serviceAccount != "" {
+				ctx = context.WithValue(ctx, serviceAccountContextKey, serviceAccount)
+			} else {
+				ctx = context.WithValue(ctx, guidContextKey, guid)
+			}
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
+// GetBearerToken извлекает токен из заголовка Authorization
+func GetBearerToken(r *http.Request) (string, *errm.Error) {
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		return "", errm.NewError("jwt_token_not_found", errors.New("jwt token not found"))
+	}
+
+	parts := strings.Split(authHeader, " ")
+	if len(parts) != 2 || parts[0] != "Bearer" || parts[1] == "" {
+		return "", errm.NewError("jwt_bearer_not_found", errors.New("invalid authorization header"))
+	}
+
+	return parts[1], nil
+}
+
 // GetGuidFromContext извлекает GUID из контекста
 func GetGuidFromContext(ctx context.Context) (string, error) {
 	guid, ok := ctx.Value(guidContextKey).(string)
@@ -93,30 +197,114 @@ func GetGuidFromContext(ctx context.Context) (string, error) {
 	return guid, nil
 }
 
-// getClientIP получает IP-адрес клиента из заголовков или RemoteAddr
-func getClientIP(r *http.Request) string {
-	// Проверка заголовка X-Real-IP
-	if ip := r.Header.Get("X-Real-IP"); ip != "" {
-		return ip
+// GetServiceAccountFromContext извлекает client_id сервисного аккаунта из контекста.
+// Для токенов пользователей возвращает ошибку
+func GetServiceAccountFromContext(ctx context.Context) (string, error) {
+	clientID, ok := ctx.Value(serviceAccountContextKey).(string)
+	if !ok {
+		return "", errors.New("no service account found in context")
+	}
+	return clientID, nil
+}
+
+// GetClaimsFromContext извлекает claims проверенного токена из контекста
+func GetClaimsFromContext(ctx context.Context) (jwt.MapClaims, error) {
+	claims, ok := ctx.Value(claimsContextKey).(jwt.MapClaims)
+	if !ok {
+		return nil, errors.New("no token claims found in context")
+	}
+	return claims, nil
+}
+
+// GetClientIP возвращает IP-адрес клиента из RemoteAddr.
+// Адрес из заголовков прокси подставляется в RemoteAddr только middleware RealIP
+func GetClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// TrustedProxies сети доверенных обратных прокси: только их заголовки X-Forwarded-For и X-Real-IP
+// принимаются как адрес клиента
+type TrustedProxies []netip.Prefix
+
+// ParseTrustedProxies разбирает список адресов и сетей в нотации CIDR
+func ParseTrustedProxies(values []string) (TrustedProxies, error) {
+	proxies := make(TrustedProxies, 0, len(values))
+	for _, value := range values {
+		if prefix, err := netip.ParsePrefix(value); err == nil {
+			proxies = append(proxies, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", value, err)
+		}
+		addr = addr.Unmap()
+		proxies = append(proxies, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return proxies, nil
+}
+
+// trusted проверяет, что адрес принадлежит доверенному прокси
+func (p TrustedProxies) trusted(value string) bool {
+	addr, err := netip.ParseAddr(strings.TrimSpace(value))
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range p {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP определяет адрес клиента. Если соединение пришло не от доверенного прокси,
+// заголовки игнорируются. Иначе X-Forwarded-For просматривается справа налево до первого
+// адреса, не принадлежащего доверенным прокси; без X-Forwarded-For используется X-Real-IP
+func (p TrustedProxies) ClientIP(r *http.Request) string {
+	peer := GetClientIP(r)
+	if !p.trusted(peer) {
+		return peer
 	}
 
-	// Проверка заголовка X-Forwarded-For
-	if ip := r.Header.Get("X-Forwarded-For"); ip != "" {
-		parts := strings.Split(ip, ",")
-		if len(parts) > 0 {
-			return strings.TrimSpace(parts[0])
+	if forwarded := r.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+		hops := strings.Split(strings.Join(forwarded, ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if _, err := netip.ParseAddr(hop); err != nil {
+				// Цепочка повреждена: левее нее адресам доверять нельзя
+				return peer
+			}
+			if !p.trusted(hop) {
+				return hop
+			}
+			peer = hop
 		}
+		return peer
 	}
 
-	// Используем RemoteAddr как fallback
-	if ip := r.RemoteAddr; ip != "" {
-		// Удаляем порт из адреса
-		host, _, err := net.SplitHostPort(ip)
-		if err == nil {
-			return host
+	if realIP := strings.TrimSpace(r.Header.Get("X-Real-IP")); realIP != "" {
+		if _, err := netip.ParseAddr(realIP); err == nil {
+			return realIP
 		}
-		return ip
 	}
+	return peer
+}
 
-	return ""
+// RealIP подставляет в RemoteAddr адрес клиента, определенный по доверенным прокси.
+// Без доверенных прокси заголовки X-Forwarded-For и X-Real-IP не учитываются
+func RealIP(proxies TrustedProxies) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if len(proxies) > 0 {
+				r.RemoteAddr = proxies.ClientIP(r)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
 }
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"authentication_service/core/configcore"
+	"authentication_service/core/lib/internally/denylist"
+	"authentication_service/core/lib/internally/kvstore"
+	"authentication_service/core/securecore"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestParseTrustedProxies(t *testing.T) {
+	if _, err := ParseTrustedProxies([]string{"10.0.0.0/8", "192.168.1.10", "::1"}); err != nil {
+		t.Fatalf("ParseTrustedProxies() error = %v", err)
+	}
+	if _, err := ParseTrustedProxies([]string{"proxy.local"}); err == nil {
+		t.Fatal("ParseTrustedProxies() error = nil, want error")
+	}
+}
+
+func TestTrustedProxiesClientIP(t *testing.T) {
+	proxies, err := ParseTrustedProxies([]string{"10.0.0.0/8", "192.168.1.10"})
+	if err != nil {
+		t.Fatalf("ParseTrustedProxies() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		proxies    TrustedProxies
+		remoteAddr string
+		forwarded  string
+		realIP     string
+		want       string
+	}{
+		{"direct connection", proxies, "203.0.113.7:5000", "", "", "203.0.113.7"},
+		{"headers from untrusted peer", proxies, "203.0.113.7:5000", "198.51.100.1", "198.51.100.2", "203.0.113.7"},
+		{"no trusted proxies", nil, "10.0.0.1:5000", "198.51.100.1", "", "10.0.0.1"},
+		{"trusted proxy", proxies, "10.0.0.1:5000", "198.51.100.1", "", "198.51.100.1"},
+		{"spoofed leftmost hop", proxies, "10.0.0.1:5000", "1.2.3.4, 198.51.100.1", "", "198.51.100.1"},
+		{"proxy chain", proxies, "10.0.0.1:5000", "198.51.100.1, 192.168.1.10, 10.0.0.2", "", "198.51.100.1"},
+		{"only proxies in chain", proxies, "10.0.0.1:5000", "10.0.0.2", "", "10.0.0.2"},
+		{"malformed hop", proxies, "10.0.0.1:5000", "198.51.100.1, garbage", "", "10.0.0.1"},
+		{"real ip from trusted proxy", proxies, "192.168.1.10:5000", "", "198.51.100.1", "198.51.100.1"},
+		{"malformed real ip", proxies, "192.168.1.10:5000", "", "garbage", "192.168.1.10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.forwarded != "" {
+				r.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-IP", tt.realIP)
+			}
+
+			var got string
+			RealIP(tt.proxies)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				got = GetClientIP(r)
+			})).ServeHTTP(httptest.NewRecorder(), r)
+			if got != tt.want {
+				t.Fatalf("GetClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJWTVerifierServiceAccounts(t *testing.T) {
+	keyring, err := securecore.NewKeyring(configcore.AuthJWTConfig{UserSecret: "secret"})
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+	const clientIP = "203.0.113.7"
+	token := func(guid string, claims jwt.MapClaims) string {
+		t.Helper()
+		signed, err := securecore.GenerateToken(guid, clientIP, securecore.TokenUseAccess, keyring, securecore.TokenPolicy{}, time.Minute, claims)
+		if err != nil {
+			t.Fatalf("GenerateToken() error = %v", err)
+		}
+		return signed
+	}
+	serviceToken := token("", jwt.MapClaims{"client_id": "batch-job", "scope": "admin"})
+	userToken := token("5b7c1e0a-3f2d-4c8e-9a61-2d4f8b0c7e13", jwt.MapClaims{"scope": "admin"})
+	clientToken := token("5b7c1e0a-3f2d-4c8e-9a61-2d4f8b0c7e13", jwt.MapClaims{"client_id": "partner", "scope": "admin"})
+
+	tests := []struct {
+		name         string
+		allowService bool
+		token        string
+		wantStatus   int
+		wantService  string
+	}{
+		{"service account accepted", true, serviceToken, http.StatusOK, "batch-job"},
+		{"service account rejected", false, serviceToken, http.StatusUnauthorized, ""},
+		{"user token", true, userToken, http.StatusOK, ""},
+		{"oauth client token on behalf of user", true, clientToken, http.StatusForbidden, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier := JWTVerifier(JWTVerifierParams{
+				TokenFormat:          keyring,
+				TokenDenylist:        denylist.NewTokenDenylist(kvstore.NewMemoryStore()),
+				AllowServiceAccounts: tt.allowService,
+			})
+
+			// В контексте либо GUID пользователя, либо client_id сервисного аккаунта
+			var gotService string
+			next := RequireFirstPartyOrServiceAccount(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotService, _ = GetServiceAccountFromContext(r.Context())
+				if _, err := GetGuidFromContext(r.Context()); (err == nil) == (gotService != "") {
+					t.Errorf("context must hold either guid or service account, service = %q", gotService)
+				}
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/api/admin/impersonations", nil)
+			r.RemoteAddr = clientIP + ":5000"
+			r.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+			verifier(next).ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if gotService != tt.wantService {
+				t.Fatalf("service account = %q, want %q", gotService, tt.wantService)
+			}
+		})
+	}
+}
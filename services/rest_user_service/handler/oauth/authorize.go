@@ -0,0 +1,435 @@
+package oauthhandler
+
+import (
+	errm "authentication_service/core/errmodule"
+	"authentication_service/core/lib/internally/onetimecode"
+	"authentication_service/core/securecore"
+	"authentication_service/core/typescore"
+	"authentication_service/rest_user_service/handler"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	authCodeKeyPrefix = "oauth:code:"
+
+	defaultAuthCodeTTL = time.Minute
+
+	// единственный поддерживаемый метод PKCE (RFC 7636)
+	codeChallengeMethodS256 = "S256"
+)
+
+var (
+	errUnknownClient      = errors.New("unknown or disabled client")
+	errInvalidRedirectURI = errors.New("redirect_uri is not registered for client")
+	errAuthCodeNotAllowed = errors.New("authorization_code grant is not allowed for client")
+	errApproveRequired    = errors.New("approve is required")
+)
+
+// AuthorizeReq параметры запроса авторизации (RFC 6749, раздел 4.1.1)
+type AuthorizeReq struct {
+	ResponseType        string `json:"response_type"`         // Только code
+	ClientID            string `json:"client_id"`             // Идентификатор клиента
+	RedirectURI         string `json:"redirect_uri"`          // Адрес возврата, зарегистрированный для клиента
+	Scope               string `json:"scope"`                 // Области доступа через пробел; пусто — все разрешенные клиенту
+	State               string `json:"state"`                 // Значение клиента, возвращается без изменений
+	CodeChallenge       string `json:"code_challenge"`        // PKCE (RFC 7636)
+	CodeChallengeMethod string `json:"code_challenge_method"` // Только S256
+	Nonce               string `json:"nonce"`                 // OpenID Connect: передается в ID токен без изменений
+	MaxAge              *int64 `json:"max_age,omitempty"`     // OpenID Connect: допустимое время с аутентификации пользователя в секундах
+	Approve             *bool  `json:"approve,omitempty"`     // Решение пользователя на экране согласия
+}
+
+// AuthorizeResp результат запроса авторизации.
+// Если согласие пользователя уже получено, возвращается адрес возврата с кодом авторизации,
+// иначе — данные для экрана согласия
+type AuthorizeResp struct {
+	RedirectTo      string   `json:"redirect_to,omitempty"`      // Адрес возврата клиенту с code и state (или error)
+	ReauthRequired  bool     `json:"reauth_required,omitempty"`  // Аутентификация старше max_age: нужна /api/auth/reauthenticate и повтор запроса
+	ConsentRequired bool     `json:"consent_required,omitempty"` // Нужно показать экран согласия
+	ClientName      string   `json:"client_name,omitempty"`      // Название клиента для экрана согласия
+	Scopes          []string `json:"scopes,omitempty"`           // Запрошенные области доступа
+}
+
+// authCode данные кода авторизации, хранятся до обмена на токены
+type authCode struct {
+	ClientID      string   `json:"client_id"`
+	UserID        string   `json:"user_id"`
+	RedirectURI   string   `json:"redirect_uri"`
+	Scopes        []string `json:"scopes"`
+	CodeChallenge string   `json:"code_challenge"`
+	Nonce         string   `json:"nonce,omitempty"`
+	AuthTime      int64    `json:"auth_time,omitempty"` // время аутентификации пользователя, давшего согласие
+	AMR           []string `json:"amr,omitempty"`
+}
+
+// AuthorizeHandler Запрос авторизации клиента OAuth 2.0
+// @Summary Запрос авторизации клиента OAuth 2.0
+// @Description Проверяет запрос авторизации (authorization code с PKCE S256). Если пользователь уже разрешил клиенту запрошенные области доступа, возвращает redirect_to с кодом авторизации,
+// @Description иначе — consent_required и данные для экрана согласия. Решение пользователя передается в POST /oauth/authorize.
+// @Description Если пользователь аутентифицировался раньше max_age секунд назад, возвращается reauth_required: после /api/auth/reauthenticate запрос повторяется с новым токеном
+// @Tags oauth
+// @Produce json
+// @Param Authorization header string true "Bearer <access_token>"
+// @Param response_type query string true "code"
+// @Param client_id query string true "Идентификатор клиента"
+// @Param redirect_uri query string true "Адрес возврата"
+// @Param scope query string false "Области доступа через пробел"
+// @Param state query string false "Значение клиента"
+// @Param code_challenge query string true "PKCE code_challenge"
+// @Param code_challenge_method query string true "S256"
+// @Param nonce query string false "nonce OpenID Connect для ID токена"
+// @Param max_age query int false "Допустимое время с аутентификации пользователя в секундах"
+// @Success 200 {object} AuthorizeResp "Успех"
+// @Failure 401 {object} handler.ErrorResponse "Невалидный или отозванный токен"
+// @Failure 403 {object} handler.ErrorResponse "Токен выдан клиенту OAuth 2.0"
+// @Failure 500 {object} handler.ErrorResponse "Неизвестный клиент или неверный redirect_uri"
+// @Router /oauth/authorize [get]
+func (s *OAuthReg) AuthorizeHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
+	logrus.Info("🤍 AuthorizeHandler")
+	ctx := r.Context()
+
+	guidUser, err := handler.GetGuidFromContext(ctx)
+	if err != nil {
+		return nil, errm.NewError("user_address_not_found", err)
+	}
+
+	query := r.URL.Query()
+	authReq := &AuthorizeReq{
+		ResponseType:        query.Get("response_type"),
+		ClientID:            query.Get("client_id"),
+		RedirectURI:         query.Get("redirect_uri"),
+		Scope:               query.Get("scope"),
+		State:               query.Get("state"),
+		CodeChallenge:       query.Get("code_challenge"),
+		CodeChallengeMethod: query.Get("code_challenge_method"),
+		Nonce:               query.Get("nonce"),
+	}
+	if value := query.Get("max_age"); value != "" {
+		maxAge, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			maxAge = -1 // отклоняется как invalid_request
+		}
+		authReq.MaxAge = &maxAge
+	}
+
+	client, errObj := s.authorizeClient(ctx, authReq)
+	if errObj != nil {
+		return nil, errObj
+	}
+	scopes, oauthErr := authorizeScopes(client, authReq)
+	if oauthErr != "" {
+		return &AuthorizeResp{RedirectTo: errorRedirect(authReq, oauthErr)}, nil
+	}
+	if reauthRequired(ctx, authReq) {
+		return &AuthorizeResp{ReauthRequired: true}, nil
+	}
+
+	consent, errObj := s.findConsent(ctx, guidUser, authReq.ClientID)
+	if errObj != nil {
+		return nil, errObj
+	}
+	if consent == nil || !containsAll(consent.Scopes, scopes) {
+		return &AuthorizeResp{ConsentRequired: true, ClientName: *client.Name, Scopes: scopes}, nil
+	}
+
+	return s.authorizeRedirect(ctx, authReq, guidUser, scopes)
+}
+
+// ConsentHandler Решение пользователя на экране согласия
+// @Summary Решение пользователя на экране согласия
+// @Description Принимает параметры запроса авторизации и решение пользователя. При согласии области доступа сохраняются для клиента
+// @Description и возвращается redirect_to с кодом авторизации, при отказе — redirect_to с error=access_denied
+// @Tags oauth
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <access_token>"
+// @Param request body AuthorizeReq true "Параметры запроса авторизации и approve"
+// @Success 200 {object} AuthorizeResp "Успех"
+// @Failure 401 {object} handler.ErrorResponse "Невалидный или отозванный токен"
+// @Failure 403 {object} handler.ErrorResponse "Недостаточно прав"
+// @Failure 500 {object} handler.ErrorResponse "Неизвестный клиент или неверный redirect_uri"
+// @Router /oauth/authorize [post]
+func (s *OAuthReg) ConsentHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
+	logrus.Info("🤍 ConsentHandler")
+	ctx := r.Context()
+
+	guidUser, err := handler.GetGuidFromContext(ctx)
+	if err != nil {
+		return nil, errm.NewError("user_address_not_found", err)
+	}
+
+	authReq := &AuthorizeReq{}
+	if errObj := handler.ParseRequestBodyPost(r, authReq); errObj != nil {
+		return nil, errObj
+	}
+	if authReq.Approve == nil {
+		return nil, errm.NewError("empty_obj", errApproveRequired)
+	}
+
+	client, errObj := s.authorizeClient(ctx, authReq)
+	if errObj != nil {
+		return nil, errObj
+	}
+	scopes, oauthErr := authorizeScopes(client, authReq)
+	if oauthErr != "" {
+		return &AuthorizeResp{RedirectTo: errorRedirect(authReq, oauthErr)}, nil
+	}
+	if reauthRequired(ctx, authReq) {
+		return &AuthorizeResp{ReauthRequired: true}, nil
+	}
+
+	if !*authReq.Approve {
+		logrus.Infof("oauth: consent denied: user_id=%s client_id=%s", guidUser, authReq.ClientID)
+		return &AuthorizeResp{RedirectTo: errorRedirect(authReq, "access_denied")}, nil
+	}
+
+	if errObj := s.saveConsent(ctx, guidUser, authReq.ClientID, scopes); errObj != nil {
+		return nil, errObj
+	}
+	logrus.Infof("oauth: consent granted: user_id=%s client_id=%s scope=%s", guidUser, authReq.ClientID, strings.Join(scopes, " "))
+
+	return s.authorizeRedirect(ctx, authReq, guidUser, scopes)
+}
+
+// authorizeClient проверяет клиента и адрес возврата.
+// До проверки redirect_uri ошибки не передаются клиенту через перенаправление (RFC 6749, раздел 4.1.2.1)
+func (s *OAuthReg) authorizeClient(ctx context.Context, authReq *AuthorizeReq) (*typescore.OAuthClient, *errm.Error) {
+	if authReq.ClientID == "" || authReq.RedirectURI == "" {
+		return nil, errm.NewError("empty_obj", errors.New("client_id and redirect_uri are required"))
+	}
+
+	client, errObj := s.findClient(ctx, authReq.ClientID)
+	if errObj != nil {
+		return nil, errObj
+	}
+	if client == nil {
+		return nil, errm.NewError("invalid_client", errUnknownClient)
+	}
+	if !hasField(client.RedirectURIs, authReq.RedirectURI) {
+		return nil, errm.NewError("invalid_redirect_uri", errInvalidRedirectURI)
+	}
+	if !hasField(client.GrantTypes, typescore.GrantTypeAuthorizationCode) {
+		return nil, errm.NewError("unauthorized_client", errAuthCodeNotAllowed)
+	}
+	return client, nil
+}
+
+// authorizeScopes проверяет параметры запроса и возвращает запрошенные области доступа
+// либо код ошибки OAuth 2.0 для передачи клиенту
+func authorizeScopes(client *typescore.OAuthClient, authReq *AuthorizeReq) ([]string, string) {
+	if authReq.ResponseType != "code" {
+		return nil, "unsupported_response_type"
+	}
+	// PKCE обязателен для всех клиентов, включая конфиденциальные
+	if authReq.CodeChallenge == "" || authReq.CodeChallengeMethod != codeChallengeMethodS256 {
+		return nil, "invalid_request"
+	}
+	if authReq.MaxAge != nil && *authReq.MaxAge < 0 {
+		return nil, "invalid_request"
+	}
+
+	scopes := strings.Fields(authReq.Scope)
+	if len(scopes) == 0 {
+		scopes = fields(client.Scopes)
+	}
+	if len(scopes) == 0 || !containsAll(client.Scopes, scopes) {
+		return nil, "invalid_scope"
+	}
+	return scopes, ""
+}
+
+// reauthRequired проверяет max_age запроса авторизации (OpenID Connect Core, раздел 3.1.2.1):
+// с аутентификации пользователя, выполняющего запрос, должно пройти не больше max_age секунд
+func reauthRequired(ctx context.Context, authReq *AuthorizeReq) bool {
+	if authReq.MaxAge == nil {
+		return false
+	}
+	claims, err := handler.GetClaimsFromContext(ctx)
+	if err != nil {
+		return true
+	}
+	return !handler.IsRecentAuth(claims, time.Duration(*authReq.MaxAge)*time.Second)
+}
+
+// authorizeRedirect выдает код авторизации и возвращает адрес возврата клиенту.
+// Время и методы аутентификации пользователя сохраняются в коде и попадают в токены клиента
+func (s *OAuthReg) authorizeRedirect(ctx context.Context, authReq *AuthorizeReq, userID string, scopes []string) (interface{}, *errm.Error) {
+	code, err := onetimecode.GenerateLinkToken()
+	if err != nil {
+		return nil, errm.NewError("code_generation_error", err)
+	}
+
+	data := &authCode{
+		ClientID:      authReq.ClientID,
+		UserID:        userID,
+		RedirectURI:   authReq.RedirectURI,
+		Scopes:        scopes,
+		CodeChallenge: authReq.CodeChallenge,
+		Nonce:         authReq.Nonce,
+	}
+	if claims, err := handler.GetClaimsFromContext(ctx); err == nil {
+		if authTime, ok := securecore.ClaimTime(claims, "auth_time"); ok {
+			data.AuthTime = authTime.Unix()
+		}
+		data.AMR = securecore.ClaimAMR(claims)
+	}
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, errm.NewError("code_generation_error", err)
+	}
+
+	ttl := s.ipc.Config.ExposedServiceConfig.UserService.OAuthServer.CodeTTL
+	if ttl <= 0 {
+		ttl = defaultAuthCodeTTL
+	}
+	if err := s.ipc.KVStore.Set(ctx, authCodeKeyPrefix+securecore.HashToken(code), string(encoded), ttl); err != nil {
+		return nil, errm.NewError("code_store_error", err)
+	}
+
+	return &AuthorizeResp{RedirectTo: redirectWith(authReq.RedirectURI, url.Values{
+		"code":  {code},
+		"state": {authReq.State},
+	})}, nil
+}
+
+// takeAuthCode возвращает и удаляет код авторизации; nil, если код неизвестен, истек или уже использован
+func (s *OAuthReg) takeAuthCode(ctx context.Context, code string) (*authCode, error) {
+	value, ok, err := s.ipc.KVStore.Take(ctx, authCodeKeyPrefix+securecore.HashToken(code))
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	data := &authCode{}
+	if err := json.Unmarshal([]byte(value), data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// findConsent возвращает согласие пользователя для клиента или nil
+func (s *OAuthReg) findConsent(ctx context.Context, userID, clientID string) (*typescore.OAuthConsent, *errm.Error) {
+	consents, _, errObj := s.ipc.DB.OAuthConsents.GetOAuthConsentsListDB(ctx, typescore.ListDbOptions{Filtering: &typescore.OAuthConsent{
+		UserID:   &userID,
+		ClientID: &clientID,
+	}})
+	if errObj != nil {
+		return nil, errObj
+	}
+	if len(consents) == 0 {
+		return nil, nil
+	}
+	return consents[0], nil
+}
+
+// saveConsent сохраняет согласие; области доступа добавляются к ранее разрешенным
+func (s *OAuthReg) saveConsent(ctx context.Context, userID, clientID string, scopes []string) *errm.Error {
+	consent, errObj := s.findConsent(ctx, userID, clientID)
+	if errObj != nil {
+		return errObj
+	}
+
+	now := time.Now().UTC()
+	if consent != nil {
+		merged := fields(consent.Scopes)
+		for _, scope := range scopes {
+			if !hasField(consent.Scopes, scope) {
+				merged = append(merged, scope)
+			}
+		}
+		scope := strings.Join(merged, " ")
+		_, _, errObj = s.ipc.DB.OAuthConsents.UpdateOAuthConsentDB(ctx, nil, &typescore.OAuthConsent{
+			ID:        consent.ID,
+			Scopes:    &scope,
+			UpdatedAt: &now,
+		})
+		return errObj
+	}
+
+	consentID, err := securecore.GenerateUUID()
+	if err != nil {
+		return errm.NewError("consent_create_error", err)
+	}
+	scope := strings.Join(scopes, " ")
+	_, _, errObj = s.ipc.DB.OAuthConsents.CreateOAuthConsentDB(ctx, nil, &typescore.OAuthConsent{
+		ID:        &consentID,
+		UserID:    &userID,
+		ClientID:  &clientID,
+		Scopes:    &scope,
+		CreatedAt: &now,
+	})
+	return errObj
+}
+
+// findClient возвращает активного клиента или nil, если клиент неизвестен или отключен
+func (s *OAuthReg) findClient(ctx context.Context, clientID string) (*typescore.OAuthClient, *errm.Error) {
+	clients, _, errObj := s.ipc.DB.OAuthClients.GetOAuthClientsListDB(ctx, typescore.ListDbOptions{Filtering: &typescore.OAuthClient{
+		ClientID: &clientID,
+	}})
+	if errObj != nil {
+		return nil, errObj
+	}
+	if len(clients) == 0 || (clients[0].IsDisabled != nil && *clients[0].IsDisabled) {
+		return nil, nil
+	}
+	return clients[0], nil
+}
+
+// errorRedirect возвращает адрес возврата с ошибкой авторизации (RFC 6749, раздел 4.1.2.1)
+func errorRedirect(authReq *AuthorizeReq, code string) string {
+	return redirectWith(authReq.RedirectURI, url.Values{
+		"error": {code},
+		"state": {authReq.State},
+	})
+}
+
+// redirectWith добавляет параметры к адресу возврата; пустые значения пропускаются
+func redirectWith(redirectURI string, params url.Values) string {
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		return redirectURI
+	}
+	query := u.Query()
+	for key, values := range params {
+		if len(values) > 0 && values[0] != "" {
+			query.Set(key, values[0])
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// fields разбирает список, разделенный пробелами
+func fields(list *string) []string {
+	if list == nil {
+		return nil
+	}
+	return strings.Fields(*list)
+}
+
+// hasField проверяет, что значение входит в список, разделенный пробелами
+func hasField(list *string, value string) bool {
+	for _, item := range fields(list) {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// containsAll проверяет, что все значения входят в список, разделенный пробелами
+func containsAll(list *string, values []string) bool {
+	for _, value := range values {
+		if !hasField(list, value) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,244 @@
+package oauthhandler
+
+import (
+	errm "authentication_service/core/errmodule"
+	"authentication_service/core/lib/internally/onetimecode"
+	"authentication_service/core/securecore"
+	"authentication_service/core/typescore"
+	"authentication_service/rest_user_service/handler"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// максимальная длина названия клиента (колонка name)
+const maxClientNameLength = 100
+
+var errInvalidClientParams = errors.New("invalid client parameters")
+
+// CreateClientReq параметры регистрации клиента OAuth 2.0
+type CreateClientReq struct {
+	Name         *string  `json:"name"`          // Название, отображаемое на экране согласия
+	RedirectURIs []string `json:"redirect_uris"` // Адреса возврата (обязательны для authorization_code)
+	Scopes       []string `json:"scopes"`        // Области доступа, которые может запрашивать клиент
+	GrantTypes   []string `json:"grant_types"`   // authorization_code, refresh_token, client_credentials
+	Public       bool     `json:"public"`        // Публичный клиент (мобильное или браузерное приложение) без секрета
+	PublicKey    *string  `json:"public_key"`    // Публичный ключ PEM для client_assertion; секрет при этом не выдается
+}
+
+// CreateServiceAccountReq параметры регистрации сервисного аккаунта
+type CreateServiceAccountReq struct {
+	Name      *string  `json:"name"`       // Название сервиса
+	Scopes    []string `json:"scopes"`     // Области доступа, которые может получить сервис
+	PublicKey *string  `json:"public_key"` // Публичный ключ PEM (Ed25519, ECDSA P-256 или RSA); без ключа выдается секрет
+}
+
+// CreateClientResp зарегистрированный клиент; секрет возвращается только один раз
+type CreateClientResp struct {
+	Client       *typescore.OAuthClient `json:"client"`
+	ClientSecret string                 `json:"client_secret,omitempty"`
+}
+
+// GetClientsHandler Получение списка клиентов OAuth 2.0
+// @Summary Получение списка клиентов OAuth 2.0
+// @Description Возвращает зарегистрированных клиентов OAuth 2.0. Доступно с областью доступа admin
+// @Tags oauth
+// @Produce json
+// @Param Authorization header string true "Bearer <access_token>"
+// @Success 200 {array} typescore.OAuthClient "Успех"
+// @Failure 401 {object} handler.ErrorResponse "Невалидный или отозванный токен"
+// @Failure 403 {object} handler.ErrorResponse "Недостаточно прав"
+// @Failure 500 {object} handler.ErrorResponse "Ошибка сервера"
+// @Router /api/oauth/clients [get]
+func (s *OAuthReg) GetClientsHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
+	logrus.Info("🤍 GetClientsHandler")
+	ctx := r.Context()
+
+	clients, _, errObj := s.ipc.DB.OAuthClients.GetOAuthClientsListDB(ctx)
+	if errObj != nil {
+		return nil, errObj
+	}
+	return clients, nil
+}
+
+// CreateClientHandler Регистрация клиента OAuth 2.0
+// @Summary Регистрация клиента OAuth 2.0
+// @Description Регистрирует клиента OAuth 2.0 и возвращает client_id и client_secret. Секрет хранится в виде хэша и возвращается только в этом ответе.
+// @Description Публичному клиенту и клиенту с публичным ключом секрет не выдается; grant client_credentials публичному клиенту недоступен. Доступно с областью доступа admin
+// @Tags oauth
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <access_token>"
+// @Param request body CreateClientReq true "Параметры клиента"
+// @Success 200 {object} CreateClientResp "Успех"
+// @Failure 401 {object} handler.ErrorResponse "Невалидный или отозванный токен"
+// @Failure 403 {object} handler.ErrorResponse "Недостаточно прав"
+// @Failure 500 {object} handler.ErrorResponse "Некорректные параметры или ошибка сервера"
+// @Router /api/oauth/clients [post]
+func (s *OAuthReg) CreateClientHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
+	logrus.Info("🤍 CreateClientHandler")
+	ctx := r.Context()
+
+	createReq := &CreateClientReq{}
+	if errObj := handler.ParseRequestBodyPost(r, createReq); errObj != nil {
+		return nil, errObj
+	}
+	return s.createClient(ctx, createReq)
+}
+
+// CreateServiceAccountHandler Регистрация сервисного аккаунта
+// @Summary Регистрация сервисного аккаунта
+// @Description Регистрирует сервисный аккаунт для межсервисной аутентификации: клиента OAuth 2.0 только с grant client_credentials.
+// @Description Сервис аутентифицируется client_assertion, подписанным зарегистрированным ключом, либо секретом, если ключ не передан.
+// @Description Секрет хранится в виде хэша и возвращается только в этом ответе. Доступно с областью доступа admin
+// @Tags oauth
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <access_token>"
+// @Param request body CreateServiceAccountReq true "Параметры сервисного аккаунта"
+// @Success 200 {object} CreateClientResp "Успех"
+// @Failure 401 {object} handler.ErrorResponse "Невалидный или отозванный токен"
+// @Failure 403 {object} handler.ErrorResponse "Недостаточно прав"
+// @Failure 500 {object} handler.ErrorResponse "Некорректные параметры или ошибка сервера"
+// @Router /api/oauth/service-accounts [post]
+func (s *OAuthReg) CreateServiceAccountHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
+	logrus.Info("🤍 CreateServiceAccountHandler")
+	ctx := r.Context()
+
+	accountReq := &CreateServiceAccountReq{}
+	if errObj := handler.ParseRequestBodyPost(r, accountReq); errObj != nil {
+		return nil, errObj
+	}
+	return s.createClient(ctx, &CreateClientReq{
+		Name:       accountReq.Name,
+		Scopes:     accountReq.Scopes,
+		GrantTypes: []string{typescore.GrantTypeClientCredentials},
+		PublicKey:  accountReq.PublicKey,
+	})
+}
+
+// createClient проверяет параметры и сохраняет клиента; конфиденциальному клиенту без ключа генерирует секрет
+func (s *OAuthReg) createClient(ctx context.Context, createReq *CreateClientReq) (*CreateClientResp, *errm.Error) {
+	if err := validateClientParams(createReq); err != nil {
+		return nil, errm.NewError("invalid_client_params", err)
+	}
+
+	id, err := securecore.GenerateUUID()
+	if err != nil {
+		return nil, errm.NewError("client_create_error", err)
+	}
+	clientID, err := securecore.GenerateUUID()
+	if err != nil {
+		return nil, errm.NewError("client_create_error", err)
+	}
+
+	name := strings.TrimSpace(*createReq.Name)
+	redirectURIs := strings.Join(createReq.RedirectURIs, " ")
+	scopes := strings.Join(createReq.Scopes, " ")
+	grantTypes := strings.Join(createReq.GrantTypes, " ")
+	now := time.Now().UTC()
+	client := &typescore.OAuthClient{
+		ID:           &id,
+		ClientID:     &clientID,
+		Name:         &name,
+		RedirectURIs: &redirectURIs,
+		Scopes:       &scopes,
+		GrantTypes:   &grantTypes,
+		CreatedAt:    &now,
+	}
+
+	resp := &CreateClientResp{Client: client}
+	if createReq.PublicKey != nil {
+		publicKey := strings.TrimSpace(*createReq.PublicKey)
+		client.PublicKey = &publicKey
+	} else if !createReq.Public {
+		secret, err := onetimecode.GenerateLinkToken()
+		if err != nil {
+			return nil, errm.NewError("client_create_error", err)
+		}
+		secretHash := securecore.HashToken(secret)
+		client.ClientSecretHash = &secretHash
+		resp.ClientSecret = secret
+	}
+
+	if _, _, errObj := s.ipc.DB.OAuthClients.CreateOAuthClientDB(ctx, nil, client); errObj != nil {
+		return nil, errObj
+	}
+	logrus.Infof("oauth: client registered: client_id=%s name=%s", clientID, name)
+
+	return resp, nil
+}
+
+// validateClientParams проверяет параметры регистрации клиента
+func validateClientParams(req *CreateClientReq) error {
+	if req.Name == nil || strings.TrimSpace(*req.Name) == "" || len([]rune(strings.TrimSpace(*req.Name))) > maxClientNameLength {
+		return fmt.Errorf("%w: name is required (up to %d characters)", errInvalidClientParams, maxClientNameLength)
+	}
+	if len(req.GrantTypes) == 0 {
+		return fmt.Errorf("%w: grant_types are required", errInvalidClientParams)
+	}
+	if req.PublicKey != nil {
+		if req.Public {
+			return fmt.Errorf("%w: public client cannot have a public key", errInvalidClientParams)
+		}
+		if _, err := securecore.ParseClientPublicKey(*req.PublicKey); err != nil {
+			return fmt.Errorf("%w: public_key: %v", errInvalidClientParams, err)
+		}
+		// client_assertion принимается только эндпоинтом токенов для client_credentials
+		for _, grantType := range req.GrantTypes {
+			if grantType != typescore.GrantTypeClientCredentials {
+				return fmt.Errorf("%w: public_key is supported only for client_credentials", errInvalidClientParams)
+			}
+		}
+	}
+
+	needsRedirect := false
+	for _, grantType := range req.GrantTypes {
+		switch grantType {
+		case typescore.GrantTypeAuthorizationCode:
+			needsRedirect = true
+		case typescore.GrantTypeRefreshToken:
+		case typescore.GrantTypeClientCredentials:
+			if req.Public {
+				return fmt.Errorf("%w: client_credentials requires a confidential client", errInvalidClientParams)
+			}
+		default:
+			return fmt.Errorf("%w: unsupported grant type %q", errInvalidClientParams, grantType)
+		}
+	}
+
+	if needsRedirect && len(req.RedirectURIs) == 0 {
+		return fmt.Errorf("%w: redirect_uris are required for authorization_code", errInvalidClientParams)
+	}
+	for _, redirectURI := range req.RedirectURIs {
+		// Адреса сравниваются точно и разделяются пробелом; фрагмент запрещен (RFC 6749, раздел 3.1.2)
+		u, err := url.Parse(redirectURI)
+		if err != nil || u.Scheme == "" || u.Host == "" || u.Fragment != "" || strings.ContainsAny(redirectURI, " \t\n") {
+			return fmt.Errorf("%w: invalid redirect uri %q", errInvalidClientParams, redirectURI)
+		}
+	}
+
+	known := make(map[string]struct{})
+	for _, scopes := range typescore.RoleScopes {
+		for _, scope := range scopes {
+			known[string(scope)] = struct{}{}
+		}
+	}
+	for _, scope := range typescore.OIDCScopes {
+		known[string(scope)] = struct{}{}
+	}
+	if len(req.Scopes) == 0 {
+		return fmt.Errorf("%w: scopes are required", errInvalidClientParams)
+	}
+	for _, scope := range req.Scopes {
+		if _, ok := known[scope]; !ok {
+			return fmt.Errorf("%w: unknown scope %q", errInvalidClientParams, scope)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package oauthhandler
+
+import (
+	errm "authentication_service/core/errmodule"
+	"authentication_service/core/typescore"
+	"authentication_service/rest_user_service/handler"
+	typesm "authentication_service/rest_user_service/types"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+)
+
+const (
+	authorizeURI = "/authorize"
+	tokenURI     = "/token"
+	userInfoURI  = "/userinfo"
+	clientsURI   = "/clients"
+
+	serviceAccountsURI = "/service-accounts"
+)
+
+type OAuthReg struct {
+	ipc *typesm.InternalProviderControl
+}
+
+// RegisterOAuthRoutes регистрирует маршруты сервера авторизации OAuth 2.0
+func RegisterOAuthRoutes(
+	r chi.Router,
+	ipc *typesm.InternalProviderControl,
+) *errm.Error {
+
+	s := &OAuthReg{
+		ipc: ipc,
+	}
+
+	verifier := handler.JWTVerifier(handler.JWTVerifierParams{
+		TokenFormat:   ipc.TokenFormat,
+		TokenPolicy:   ipc.TokenPolicy,
+		TokenDenylist: ipc.TokenDenylist,
+	})
+
+	r.Route("/oauth", func(r chi.Router) {
+		// Ответы эндпоинта токенов формируются по RFC 6749, а не через ErrorResponse
+		r.Post(tokenURI, s.TokenHandler)
+
+		// Согласие дает пользователь, вошедший в собственное приложение сервиса, а не сотрудник от его имени
+		ra := r.With(verifier, handler.RequireFirstPartyToken, handler.RequireSessionToken)
+		handler.RegisterRoute(ra, http.MethodGet, authorizeURI, s.AuthorizeHandler)
+		handler.RegisterRoute(ra.With(handler.RequireScope(typescore.ProfileWriteScope)), http.MethodPost, authorizeURI, s.ConsentHandler)
+
+		// Claims пользователя для клиентов OpenID Connect (GET и POST по спецификации)
+		ru := r.With(verifier, handler.RequireScope(typescore.OpenIDScope))
+		handler.RegisterRoute(ru, http.MethodGet, userInfoURI, s.UserInfoHandler)
+		handler.RegisterRoute(ru, http.MethodPost, userInfoURI, s.UserInfoHandler)
+	})
+
+	r.Route("/api/oauth", func(r chi.Router) {
+		r.Use(verifier, handler.RequireFirstPartyToken, handler.RequireScope(typescore.AdminScope))
+
+		handler.RegisterRoute(r, http.MethodGet, clientsURI, s.GetClientsHandler)
+		handler.RegisterRoute(r, http.MethodPost, clientsURI, s.CreateClientHandler)
+		handler.RegisterRoute(r, http.MethodPost, serviceAccountsURI, s.CreateServiceAccountHandler)
+	})
+
+	return nil
+}
@@ -0,0 +1,323 @@
+package oauthhandler
+
+import (
+	protoobj "authentication_service/core/proto"
+	"authentication_service/core/securecore"
+	"authentication_service/core/typescore"
+	"authentication_service/rest_user_service/handler"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"strings"
+)
+
+// TokenResp ответ эндпоинта токенов (RFC 6749, раздел 5.1)
+type TokenResp struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"` // Bearer
+	ExpiresIn    int64  `json:"expires_in"` // Время жизни access токена в секундах
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+	IDToken      string `json:"id_token,omitempty"` // ID токен OpenID Connect (при области доступа openid)
+}
+
+// TokenErrorResp ошибка эндпоинта токенов (RFC 6749, раздел 5.2)
+type TokenErrorResp struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// tokenError ошибка эндпоинта токенов с HTTP статусом ответа
+type tokenError struct {
+	status      int
+	code        string
+	description string
+}
+
+func newTokenError(status int, code, description string) *tokenError {
+	return &tokenError{status: status, code: code, description: description}
+}
+
+// TokenHandler Эндпоинт токенов OAuth 2.0
+// @Summary Эндпоинт токенов OAuth 2.0
+// @Description Выдает токены клиенту OAuth 2.0 по grant authorization_code (с code_verifier PKCE), refresh_token или client_credentials.
+// @Description Клиент аутентифицируется через HTTP Basic (client_id:client_secret) или параметры client_id и client_secret; публичный клиент передает только client_id.
+// @Description Для client_credentials сервисный аккаунт с зарегистрированным ключом может вместо секрета передать client_assertion (RFC 7523)
+// @Tags oauth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param grant_type formData string true "authorization_code, refresh_token или client_credentials"
+// @Param code formData string false "Код авторизации"
+// @Param redirect_uri formData string false "Адрес возврата из запроса авторизации"
+// @Param code_verifier formData string false "PKCE code_verifier"
+// @Param refresh_token formData string false "Refresh токен"
+// @Param scope formData string false "Области доступа для client_credentials"
+// @Param client_assertion_type formData string false "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+// @Param client_assertion formData string false "JWT, подписанный ключом клиента (iss и sub — client_id, aud — issuer или адрес эндпоинта токенов)"
+// @Success 200 {object} TokenResp "Успех"
+// @Failure 400 {object} TokenErrorResp "Некорректный запрос или неверный grant"
+// @Failure 401 {object} TokenErrorResp "Неверные учетные данные клиента"
+// @Failure 429 {object} TokenErrorResp "Слишком много неудачных попыток: повторить после Retry-After"
+// @Failure 500 {object} TokenErrorResp "Ошибка сервера"
+// @Router /oauth/token [post]
+func (s *OAuthReg) TokenHandler(w http.ResponseWriter, r *http.Request) {
+	logrus.Info("🤍 TokenHandler")
+	ctx := r.Context()
+
+	// Перебор учетных данных клиента и grant ограничивается по IP-адресу
+	if retryAfter, locked := handler.AuthLockoutRetryAfter(r, s.ipc.Lockout, ""); locked {
+		w.Header().Set("Retry-After", retryAfter)
+		writeTokenResponse(w, http.StatusTooManyRequests, &TokenErrorResp{Error: "slow_down", ErrorDescription: "too many failed attempts"})
+		return
+	}
+
+	resp, tokenErr := s.token(ctx, r)
+	if tokenErr != nil {
+		if tokenErr.code == "invalid_client" || tokenErr.code == "invalid_grant" {
+			handler.RecordAuthFailure(r, s.ipc.Lockout, s.ipc.RabbitMQ, "", nil)
+		}
+		if tokenErr.status == http.StatusUnauthorized {
+			w.Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+		}
+		writeTokenResponse(w, tokenErr.status, &TokenErrorResp{Error: tokenErr.code, ErrorDescription: tokenErr.description})
+		return
+	}
+	writeTokenResponse(w, http.StatusOK, resp)
+}
+
+// token аутентифицирует клиента и выполняет запрошенный grant
+func (s *OAuthReg) token(ctx context.Context, r *http.Request) (*TokenResp, *tokenError) {
+	if err := r.ParseForm(); err != nil {
+		return nil, newTokenError(http.StatusBadRequest, "invalid_request", "invalid form body")
+	}
+
+	grantType := r.PostForm.Get("grant_type")
+	if grantType == "" {
+		return nil, newTokenError(http.StatusBadRequest, "invalid_request", "grant_type is required")
+	}
+	// Учетные данные сервисного аккаунта (секрет или client_assertion) проверяет сервис авторизации
+	if grantType == typescore.GrantTypeClientCredentials {
+		return s.clientCredentialsGrant(ctx, r)
+	}
+
+	client, tokenErr := s.authenticateClient(ctx, r)
+	if tokenErr != nil {
+		return nil, tokenErr
+	}
+	if !hasField(client.GrantTypes, grantType) {
+		return nil, newTokenError(http.StatusBadRequest, "unauthorized_client", "grant_type is not allowed for client")
+	}
+
+	switch grantType {
+	case typescore.GrantTypeAuthorizationCode:
+		return s.authorizationCodeGrant(ctx, r, client)
+	case typescore.GrantTypeRefreshToken:
+		return s.refreshTokenGrant(ctx, r, client)
+	default:
+		return nil, newTokenError(http.StatusBadRequest, "unsupported_grant_type", "")
+	}
+}
+
+// authorizationCodeGrant обменивает код авторизации на токены пользователя (RFC 6749, раздел 4.1.3; RFC 7636)
+func (s *OAuthReg) authorizationCodeGrant(ctx context.Context, r *http.Request, client *typescore.OAuthClient) (*TokenResp, *tokenError) {
+	code := r.PostForm.Get("code")
+	codeVerifier := r.PostForm.Get("code_verifier")
+	if code == "" || codeVerifier == "" {
+		return nil, newTokenError(http.StatusBadRequest, "invalid_request", "code and code_verifier are required")
+	}
+
+	// Код одноразовый: повторный обмен отклоняется
+	data, err := s.takeAuthCode(ctx, code)
+	if err != nil {
+		logrus.Errorf("oauth token: failed to load authorization code: %v", err)
+		return nil, newTokenError(http.StatusInternalServerError, "server_error", "")
+	}
+	if data == nil || data.ClientID != *client.ClientID || data.RedirectURI != r.PostForm.Get("redirect_uri") {
+		return nil, newTokenError(http.StatusBadRequest, "invalid_grant", "invalid or expired authorization code")
+	}
+	challenge := oauth2.S256ChallengeFromVerifier(codeVerifier)
+	if subtle.ConstantTimeCompare([]byte(challenge), []byte(data.CodeChallenge)) != 1 {
+		logrus.Warnf("oauth token: code_verifier mismatch: client_id=%s", *client.ClientID)
+		return nil, newTokenError(http.StatusBadRequest, "invalid_grant", "invalid code_verifier")
+	}
+
+	resp, err := s.ipc.ClientAuthServiceProto.IssueTokens(ctx, &protoobj.IssueTokensRequest{
+		UserId:     data.UserID,
+		ClientIp:   handler.GetClientIP(r),
+		UserAgent:  r.UserAgent(),
+		DeviceName: *client.Name,
+		ClientId:   *client.ClientID,
+		Scopes:     data.Scopes,
+		Nonce:      data.Nonce,
+		AuthTime:   data.AuthTime,
+		Amr:        data.AMR,
+	})
+	if err != nil {
+		return nil, grantError(err)
+	}
+
+	tokenResp := &TokenResp{
+		AccessToken: resp.GetAccessToken(),
+		TokenType:   "Bearer",
+		ExpiresIn:   resp.GetExpiresIn(),
+		Scope:       resp.GetScope(),
+		IDToken:     resp.GetIdToken(),
+	}
+	// Refresh токен передается только клиенту, которому разрешено его использовать
+	if hasField(client.GrantTypes, typescore.GrantTypeRefreshToken) {
+		tokenResp.RefreshToken = resp.GetRefreshToken()
+	}
+	return tokenResp, nil
+}
+
+// refreshTokenGrant обновляет токены клиента (RFC 6749, раздел 6)
+func (s *OAuthReg) refreshTokenGrant(ctx context.Context, r *http.Request, client *typescore.OAuthClient) (*TokenResp, *tokenError) {
+	refreshToken := r.PostForm.Get("refresh_token")
+	if refreshToken == "" {
+		return nil, newTokenError(http.StatusBadRequest, "invalid_request", "refresh_token is required")
+	}
+
+	resp, err := s.ipc.ClientAuthServiceProto.RefreshTokens(ctx, &protoobj.RefreshTokensRequest{
+		RefreshToken: refreshToken,
+		ClientIp:     handler.GetClientIP(r),
+		ClientId:     *client.ClientID,
+	})
+	if err != nil {
+		return nil, grantError(err)
+	}
+
+	return &TokenResp{
+		AccessToken:  resp.GetAccessToken(),
+		TokenType:    "Bearer",
+		ExpiresIn:    resp.GetExpiresIn(),
+		RefreshToken: resp.GetRefreshToken(),
+		Scope:        resp.GetScope(),
+		IDToken:      resp.GetIdToken(),
+	}, nil
+}
+
+// clientCredentialsGrant выдает токен клиенту или сервисному аккаунту от его собственного имени (RFC 6749, раздел 4.4).
+// Клиент аутентифицируется секретом или client_assertion (RFC 7523, раздел 2.2)
+func (s *OAuthReg) clientCredentialsGrant(ctx context.Context, r *http.Request) (*TokenResp, *tokenError) {
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID = r.PostForm.Get("client_id")
+		clientSecret = r.PostForm.Get("client_secret")
+	}
+	assertion := r.PostForm.Get("client_assertion")
+	if assertion != "" {
+		if r.PostForm.Get("client_assertion_type") != securecore.ClientAssertionType {
+			return nil, newTokenError(http.StatusBadRequest, "invalid_request", "unsupported client_assertion_type")
+		}
+		if clientSecret != "" {
+			return nil, newTokenError(http.StatusBadRequest, "invalid_request", "only one client authentication method is allowed")
+		}
+		// client_id можно не передавать: он совпадает с iss client_assertion
+		if clientID == "" {
+			clientID = assertionIssuer(assertion)
+		}
+	}
+	if clientID == "" {
+		return nil, newTokenError(http.StatusUnauthorized, "invalid_client", "client authentication is required")
+	}
+
+	resp, err := s.ipc.ClientAuthServiceProto.IssueClientToken(ctx, &protoobj.IssueClientTokenRequest{
+		ClientId:        clientID,
+		Scopes:          strings.Fields(r.PostForm.Get("scope")),
+		ClientIp:        handler.GetClientIP(r),
+		ClientSecret:    clientSecret,
+		ClientAssertion: assertion,
+	})
+	if err != nil {
+		switch status.Code(err) {
+		case codes.Unauthenticated, codes.NotFound:
+			return nil, newTokenError(http.StatusUnauthorized, "invalid_client", "")
+		case codes.PermissionDenied:
+			return nil, newTokenError(http.StatusBadRequest, "unauthorized_client", "grant_type is not allowed for client")
+		case codes.InvalidArgument:
+			return nil, newTokenError(http.StatusBadRequest, "invalid_scope", "")
+		}
+		return nil, grantError(err)
+	}
+
+	return &TokenResp{
+		AccessToken: resp.GetAccessToken(),
+		TokenType:   "Bearer",
+		ExpiresIn:   resp.GetExpiresIn(),
+		Scope:       resp.GetScope(),
+	}, nil
+}
+
+// assertionIssuer возвращает iss client_assertion без проверки подписи: подпись проверяет сервис авторизации
+func assertionIssuer(assertion string) string {
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(assertion, claims); err != nil {
+		return ""
+	}
+	iss, _ := claims["iss"].(string)
+	return iss
+}
+
+// authenticateClient проверяет учетные данные клиента (RFC 6749, раздел 2.3.1).
+// Публичный клиент передает только client_id; конфиденциальный — обязательно client_secret.
+// client_assertion принимается только для grant client_credentials
+func (s *OAuthReg) authenticateClient(ctx context.Context, r *http.Request) (*typescore.OAuthClient, *tokenError) {
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID = r.PostForm.Get("client_id")
+		clientSecret = r.PostForm.Get("client_secret")
+	}
+	if clientID == "" || r.PostForm.Get("client_assertion") != "" {
+		return nil, newTokenError(http.StatusUnauthorized, "invalid_client", "client authentication is required")
+	}
+
+	client, errObj := s.findClient(ctx, clientID)
+	if errObj != nil {
+		return nil, newTokenError(http.StatusInternalServerError, "server_error", "")
+	}
+	if client == nil {
+		return nil, newTokenError(http.StatusUnauthorized, "invalid_client", "")
+	}
+
+	if client.ClientSecretHash == nil {
+		// Клиент только с ключом (сервисный аккаунт) не может аутентифицироваться как публичный
+		if clientSecret != "" || client.PublicKey != nil {
+			return nil, newTokenError(http.StatusUnauthorized, "invalid_client", "")
+		}
+		return client, nil
+	}
+	secretHash := securecore.HashToken(clientSecret)
+	if clientSecret == "" || subtle.ConstantTimeCompare([]byte(secretHash), []byte(*client.ClientSecretHash)) != 1 {
+		logrus.Warnf("oauth token: invalid client credentials: %s", clientID)
+		return nil, newTokenError(http.StatusUnauthorized, "invalid_client", "")
+	}
+	return client, nil
+}
+
+// grantError преобразует ошибку сервиса авторизации в ответ эндпоинта токенов
+func grantError(err error) *tokenError {
+	switch status.Code(err) {
+	case codes.Unauthenticated, codes.NotFound, codes.PermissionDenied, codes.InvalidArgument:
+		return newTokenError(http.StatusBadRequest, "invalid_grant", "")
+	default:
+		logrus.Errorf("oauth token: %v", err)
+		return newTokenError(http.StatusInternalServerError, "server_error", "")
+	}
+}
+
+// writeTokenResponse отправляет ответ эндпоинта токенов; ответы не кэшируются (RFC 6749, раздел 5.1)
+func writeTokenResponse(w http.ResponseWriter, statusCode int, payload interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+	w.WriteHeader(statusCode)
+
+	response, _ := json.Marshal(payload)
+	_, _ = w.Write(response)
+}
@@ -0,0 +1,121 @@
+package oauthhandler
+
+import (
+	"authentication_service/core/lib/internally/kvstore"
+	protoobj "authentication_service/core/proto"
+	"authentication_service/core/securecore"
+	"authentication_service/core/typescore"
+	typesm "authentication_service/rest_user_service/types"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/grpc"
+)
+
+// fakeAuthService выдает токены без обращения к сервису авторизации и учитывает вызовы
+type fakeAuthService struct {
+	protoobj.AuthServiceClient
+	issued []*protoobj.IssueTokensRequest
+}
+
+func (f *fakeAuthService) IssueTokens(_ context.Context, req *protoobj.IssueTokensRequest, _ ...grpc.CallOption) (*protoobj.IssueTokensResponse, error) {
+	f.issued = append(f.issued, req)
+	return &protoobj.IssueTokensResponse{AccessToken: "access", RefreshToken: "refresh", ExpiresIn: 900}, nil
+}
+
+func TestAuthorizationCodeGrant(t *testing.T) {
+	const (
+		code        = "auth-code"
+		redirectURI = "https://app.example.com/callback"
+		userID      = "5b7c1e0a-3f2d-4c8e-9a61-2d4f8b0c7e13"
+	)
+	verifier := oauth2.GenerateVerifier()
+	clientID, otherClientID, name := "client", "other-client", "App"
+	grantTypes := typescore.GrantTypeAuthorizationCode + " " + typescore.GrantTypeRefreshToken
+	client := &typescore.OAuthClient{ClientID: &clientID, Name: &name, GrantTypes: &grantTypes}
+	otherClient := &typescore.OAuthClient{ClientID: &otherClientID, Name: &name, GrantTypes: &grantTypes}
+
+	type exchange struct {
+		client       *typescore.OAuthClient
+		redirectURI  string
+		codeVerifier string
+		wantCode     string // код ошибки RFC 6749; пусто — токены выданы
+	}
+	valid := exchange{client, redirectURI, verifier, ""}
+
+	tests := []struct {
+		name      string
+		exchanges []exchange
+	}{
+		{"valid", []exchange{valid}},
+		{"single use", []exchange{valid, {client, redirectURI, verifier, "invalid_grant"}}},
+		{"redirect_uri mismatch", []exchange{{client, "https://evil.example.com/callback", verifier, "invalid_grant"}}},
+		{"redirect_uri missing", []exchange{{client, "", verifier, "invalid_grant"}}},
+		{"code of another client", []exchange{{otherClient, redirectURI, verifier, "invalid_grant"}}},
+		{"pkce verifier mismatch", []exchange{{client, redirectURI, oauth2.GenerateVerifier(), "invalid_grant"}}},
+		{"pkce verifier missing", []exchange{{client, redirectURI, "", "invalid_request"}}},
+		// Неудачный обмен расходует код: его нельзя подобрать повторными попытками
+		{"mismatch burns code", []exchange{{client, redirectURI, oauth2.GenerateVerifier(), "invalid_grant"}, {client, redirectURI, verifier, "invalid_grant"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			authService := &fakeAuthService{}
+			s := &OAuthReg{ipc: &typesm.InternalProviderControl{
+				KVStore:                kvstore.NewMemoryStore(),
+				ClientAuthServiceProto: authService,
+			}}
+			encoded, _ := json.Marshal(&authCode{
+				ClientID:      clientID,
+				UserID:        userID,
+				RedirectURI:   redirectURI,
+				CodeChallenge: oauth2.S256ChallengeFromVerifier(verifier),
+			})
+			if err := s.ipc.KVStore.Set(ctx, authCodeKeyPrefix+securecore.HashToken(code), string(encoded), time.Minute); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+
+			wantIssued := 0
+			for i, ex := range tt.exchanges {
+				form := url.Values{"grant_type": {typescore.GrantTypeAuthorizationCode}, "code": {code}, "redirect_uri": {ex.redirectURI}}
+				if ex.codeVerifier != "" {
+					form.Set("code_verifier", ex.codeVerifier)
+				}
+				r := httptest.NewRequest(http.MethodPost, "/oauth/token", strings.NewReader(form.Encode()))
+				r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				if err := r.ParseForm(); err != nil {
+					t.Fatalf("ParseForm() error = %v", err)
+				}
+
+				resp, tokenErr := s.authorizationCodeGrant(ctx, r, ex.client)
+				gotCode := ""
+				if tokenErr != nil {
+					gotCode = tokenErr.code
+				}
+				if gotCode != ex.wantCode {
+					t.Fatalf("exchange #%d error = %q, want %q", i+1, gotCode, ex.wantCode)
+				}
+				if tokenErr == nil {
+					wantIssued++
+					if resp.AccessToken != "access" || resp.RefreshToken != "refresh" {
+						t.Fatalf("exchange #%d response = %+v", i+1, resp)
+					}
+				}
+			}
+			if len(authService.issued) != wantIssued {
+				t.Fatalf("IssueTokens called %d times, want %d", len(authService.issued), wantIssued)
+			}
+			if wantIssued > 0 && authService.issued[0].GetUserId() != userID {
+				t.Fatalf("tokens issued for %q, want %q", authService.issued[0].GetUserId(), userID)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package oauthhandler
+
+import (
+	errm "authentication_service/core/errmodule"
+	"authentication_service/core/securecore"
+	"authentication_service/core/typescore"
+	"authentication_service/rest_user_service/handler"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strings"
+)
+
+var errUserUnavailable = errors.New("user not found or blocked")
+
+// UserInfoHandler Claims пользователя OpenID Connect
+// @Summary Claims пользователя OpenID Connect
+// @Description Возвращает стандартные claims пользователя (OpenID Connect Core, раздел 5.3): sub всегда,
+// @Description name, given_name, family_name и preferred_username — при области доступа profile, email и email_verified — при области доступа email.
+// @Description Требует access токен клиента с областью доступа openid
+// @Tags oauth
+// @Produce json
+// @Param Authorization header string true "Bearer <access_token>"
+// @Success 200 {object} map[string]interface{} "Успех"
+// @Failure 401 {object} handler.ErrorResponse "Невалидный или отозванный токен"
+// @Failure 403 {object} handler.ErrorResponse "Нет области доступа openid"
+// @Failure 500 {object} handler.ErrorResponse "Пользователь не найден или заблокирован"
+// @Router /oauth/userinfo [get]
+// @Router /oauth/userinfo [post]
+func (s *OAuthReg) UserInfoHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
+	logrus.Info("🤍 UserInfoHandler")
+	ctx := r.Context()
+
+	claims, err := handler.GetClaimsFromContext(ctx)
+	if err != nil {
+		return nil, errm.NewError("claims_not_found", err)
+	}
+	guidUser, err := handler.GetGuidFromContext(ctx)
+	if err != nil {
+		return nil, errm.NewError("user_address_not_found", err)
+	}
+
+	users, _, errObj := s.ipc.DB.Users.GetUsersListDB(ctx, typescore.ListDbOptions{Filtering: &typescore.User{
+		SystemID: &guidUser,
+	}})
+	if errObj != nil {
+		return nil, errObj
+	}
+	if len(users) == 0 || (users[0].IsBlocked != nil && *users[0].IsBlocked) {
+		return nil, errm.NewError("user_not_found", errUserUnavailable)
+	}
+
+	scope, _ := claims["scope"].(string)
+	return securecore.OIDCUserClaims(users[0], strings.Fields(scope)), nil
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"authentication_service/core/database"
+	errm "authentication_service/core/errmodule"
+	"authentication_service/core/securecore"
+	"authentication_service/core/typescore"
+	"context"
+	"errors"
+	"github.com/golang-jwt/jwt/v5"
+	"strings"
+	"time"
+)
+
+// минимальный интервал обновления времени последнего использования токена: не пишем в базу на каждый запрос
+const personalTokenTouchInterval = time.Minute
+
+// ErrInvalidPersonalToken неизвестный, просроченный или отозванный персональный токен доступа
+var ErrInvalidPersonalToken = errors.New("invalid personal access token")
+
+// VerifyPersonalAccessToken проверяет персональный токен доступа и возвращает claims в формате access токена:
+// guid, role, scope и pat_id. Области доступа токена ограничиваются текущими областями пользователя,
+// поэтому понижение роли или блокировка аккаунта действуют на уже выпущенные токены.
+func VerifyPersonalAccessToken(ctx context.Context, db *database.ModuleDB, token, clientIP string) (jwt.MapClaims, *errm.Error) {
+	if db == nil {
+		return nil, errm.NewError("personal_token_unavailable", errors.New("database is not configured"))
+	}
+	// Контрольная сумма отсекает опечатки и случайные строки без обращения к базе
+	if !securecore.IsPersonalAccessToken(token) {
+		return nil, errm.NewError("invalid_personal_token", ErrInvalidPersonalToken)
+	}
+
+	tokenHash := securecore.HashToken(token)
+	tokens, _, errObj := db.PersonalTokens.GetPersonalAccessTokensListDB(ctx, typescore.ListDbOptions{
+		Filtering: &typescore.PersonalAccessToken{TokenHash: &tokenHash},
+	})
+	if errObj != nil {
+		return nil, errObj
+	}
+	now := time.Now().UTC()
+	if len(tokens) == 0 || tokens[0].UserID == nil || (tokens[0].ExpiresAt != nil && !now.Before(*tokens[0].ExpiresAt)) {
+		return nil, errm.NewError("invalid_personal_token", ErrInvalidPersonalToken)
+	}
+	personalToken := tokens[0]
+
+	users, _, errObj := db.Users.GetUsersListDB(ctx, typescore.ListDbOptions{Filtering: &typescore.User{
+		SystemID: personalToken.UserID,
+	}})
+	if errObj != nil {
+		return nil, errObj
+	}
+	if len(users) == 0 || (users[0].IsBlocked != nil && *users[0].IsBlocked) {
+		return nil, errm.NewError("invalid_personal_token", ErrInvalidPersonalToken)
+	}
+
+	role, roleScopes := typescore.UserRoleAndScopes(users[0])
+	allowed := make(map[string]struct{}, len(roleScopes))
+	for _, scope := range roleScopes {
+		allowed[string(scope)] = struct{}{}
+	}
+	scopes := make([]string, 0)
+	if personalToken.Scopes != nil {
+		for _, scope := range strings.Fields(*personalToken.Scopes) {
+			if _, ok := allowed[scope]; ok {
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+
+	if personalToken.LastUsedAt == nil || now.Sub(*personalToken.LastUsedAt) >= personalTokenTouchInterval {
+		// Ошибка обновления не мешает запросу: время использования носит справочный характер
+		_, _, _ = db.PersonalTokens.UpdatePersonalAccessTokenDB(ctx, nil, &typescore.PersonalAccessToken{
+			ID:         personalToken.ID,
+			LastUsedAt: &now,
+			LastUsedIP: &clientIP,
+		})
+	}
+
+	return jwt.MapClaims{
+		"guid":   *personalToken.UserID,
+		"role":   string(role),
+		"scope":  strings.Join(scopes, " "),
+		"pat_id": *personalToken.ID,
+	}, nil
+}
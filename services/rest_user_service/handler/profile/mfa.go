@@ -0,0 +1,299 @@
+package userhandler
+
+import (
+	errm "authentication_service/core/errmodule"
+	"authentication_service/core/securecore"
+	"authentication_service/core/typescore"
+	dbutils "authentication_service/core/utilscore/db"
+	"authentication_service/rest_user_service/handler"
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"time"
+)
+
+// издатель в URI otpauth, если не задан в конфигурации
+const defaultTOTPIssuer = "Authentication Service"
+
+type TOTPEnrollResp struct {
+	Secret     string `json:"secret"`      // Секрет в base32 для ручного ввода
+	OtpauthURI string `json:"otpauth_uri"` // URI otpauth://totp/ для QR-кода
+}
+
+type MFACodeReq struct {
+	Code         *string `json:"code"`          // Код из приложения-аутентификатора
+	RecoveryCode *string `json:"recovery_code"` // Одноразовый код восстановления (вместо code)
+}
+
+type RecoveryCodesResp struct {
+	RecoveryCodes []string `json:"recovery_codes"` // Показываются один раз; хранятся только хеши
+}
+
+type TOTPDisableResp struct {
+	Success bool `json:"success"`
+}
+
+// TOTPEnrollHandler Начало подключения TOTP
+// @Summary Начало подключения TOTP
+// @Description Генерирует секрет TOTP и возвращает URI otpauth для QR-кода. TOTP включается после подтверждения первым кодом
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <access_token>"
+// @Success 200 {object} TOTPEnrollResp "Успех"
+// @Failure 401 {object} handler.ErrorResponse "Невалидный токен или требуется повторная аутентификация (insufficient_user_authentication)"
+// @Failure 403 {object} handler.ErrorResponse "Недостаточно прав"
+// @Failure 500 {object} handler.ErrorResponse "Ошибка сервера"
+// @Router /api/users/mfa/totp/enroll [post]
+func (s *UsersReg) TOTPEnrollHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
+	logrus.Info("🤍 TOTPEnrollHandler")
+	ctx := r.Context()
+
+	if s.ipc.SecretCipher == nil {
+		return nil, errm.NewError("mfa_unavailable", handler.ErrMFAUnavailable)
+	}
+
+	user, errObj := s.getCurrentUser(ctx)
+	if errObj != nil {
+		return nil, errObj
+	}
+	if user.TOTPEnabledAt != nil {
+		return nil, errm.NewError("totp_already_enabled", errors.New("totp is already enabled"))
+	}
+
+	secret, err := securecore.GenerateTOTPSecret()
+	if err != nil {
+		return nil, errm.NewError("totp_secret_error", err)
+	}
+	encryptedSecret, err := s.ipc.SecretCipher.Encrypt(secret)
+	if err != nil {
+		return nil, errm.NewError("totp_secret_error", err)
+	}
+
+	// Неподтвержденный секрет перезаписывается при повторном начале подключения
+	if _, _, errObj := s.ipc.DB.Users.UpdateUserDB(ctx, nil, &typescore.User{
+		SystemID:   user.SystemID,
+		TOTPSecret: &encryptedSecret,
+	}); errObj != nil {
+		return nil, errObj
+	}
+
+	issuer := s.ipc.Config.ExposedServiceConfig.UserService.MFA.TOTPIssuer
+	if issuer == "" {
+		issuer = defaultTOTPIssuer
+	}
+
+	return &TOTPEnrollResp{
+		Secret:     secret,
+		OtpauthURI: securecore.TOTPURI(issuer, handler.AccountName(user), secret),
+	}, nil
+}
+
+// TOTPConfirmHandler Подтверждение подключения TOTP
+// @Summary Подтверждение подключения TOTP
+// @Description Включает TOTP после проверки первого кода и возвращает одноразовые коды восстановления
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <access_token>"
+// @Param request body MFACodeReq true "Код из приложения-аутентификатора"
+// @Success 200 {object} RecoveryCodesResp "Успех"
+// @Failure 400 {object} handler.ErrorResponse "Неверный код"
+// @Failure 401 {object} handler.ErrorResponse "Невалидный или отозванный токен"
+// @Failure 403 {object} handler.ErrorResponse "Недостаточно прав"
+// @Failure 500 {object} handler.ErrorResponse "Ошибка сервера"
+// @Router /api/users/mfa/totp/confirm [post]
+func (s *UsersReg) TOTPConfirmHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
+	logrus.Info("🤍 TOTPConfirmHandler")
+	ctx := r.Context()
+
+	req := &MFACodeReq{}
+	if errObj := handler.ParseRequestBodyPost(r, req); errObj != nil {
+		return nil, errObj
+	}
+	if req.Code == nil || *req.Code == "" {
+		return nil, errm.NewError("empty_mfa_code", errors.New("code is required"))
+	}
+
+	user, errObj := s.getCurrentUser(ctx)
+	if errObj != nil {
+		return nil, errObj
+	}
+	if user.TOTPEnabledAt != nil {
+		return nil, errm.NewError("totp_already_enabled", errors.New("totp is already enabled"))
+	}
+
+	// Подключение подтверждается только кодом TOTP: кодов восстановления еще нет
+	if errObj := s.verifySecondFactor(w, r, user, req.Code, nil); errObj != nil {
+		return nil, errObj
+	}
+
+	codes, hashes, errObj := newRecoveryCodes()
+	if errObj != nil {
+		return nil, errObj
+	}
+
+	now := time.Now().UTC()
+	errObj = dbutils.ExecuteTx(ctx, s.ipc.DB.Pool, nil, func(tx pgx.Tx) error {
+		if _, _, errObj := s.ipc.DB.Users.UpdateUserDB(ctx, tx, &typescore.User{
+			SystemID:      user.SystemID,
+			TOTPEnabledAt: &now,
+		}); errObj != nil {
+			return errObj.Error
+		}
+		if errObj := s.ipc.DB.RecoveryCodes.ReplaceUserRecoveryCodesDB(ctx, tx, *user.SystemID, hashes); errObj != nil {
+			return errObj.Error
+		}
+		return nil
+	})
+	if errObj != nil {
+		return nil, errObj
+	}
+
+	logrus.Infof("🔐 TOTP enabled: user_id=%s", *user.SystemID)
+	return &RecoveryCodesResp{RecoveryCodes: codes}, nil
+}
+
+// TOTPDisableHandler Отключение TOTP
+// @Summary Отключение TOTP
+// @Description Отключает TOTP после проверки кода TOTP или кода восстановления. Коды восстановления удаляются
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <access_token>"
+// @Param request body MFACodeReq true "Код TOTP или код восстановления"
+// @Success 200 {object} TOTPDisableResp "Успех"
+// @Failure 400 {object} handler.ErrorResponse "Неверный код"
+// @Failure 401 {object} handler.ErrorResponse "Невалидный или отозванный токен"
+// @Failure 403 {object} handler.ErrorResponse "Недостаточно прав"
+// @Failure 500 {object} handler.ErrorResponse "Ошибка сервера"
+// @Router /api/users/mfa/totp/disable [post]
+func (s *UsersReg) TOTPDisableHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
+	logrus.Info("🤍 TOTPDisableHandler")
+	ctx := r.Context()
+
+	req := &MFACodeReq{}
+	if errObj := handler.ParseRequestBodyPost(r, req); errObj != nil {
+		return nil, errObj
+	}
+
+	user, errObj := s.getEnabledTOTPUser(ctx)
+	if errObj != nil {
+		return nil, errObj
+	}
+	if errObj := s.verifySecondFactor(w, r, user, req.Code, req.RecoveryCode); errObj != nil {
+		return nil, errObj
+	}
+
+	if errObj := s.ipc.DB.Users.ResetUserTOTPDB(ctx, nil, *user.SystemID); errObj != nil {
+		return nil, errObj
+	}
+
+	logrus.Infof("🔓 TOTP disabled: user_id=%s", *user.SystemID)
+	return &TOTPDisableResp{Success: true}, nil
+}
+
+// RegenerateRecoveryCodesHandler Перевыпуск кодов восстановления
+// @Summary Перевыпуск кодов восстановления
+// @Description Заменяет коды восстановления новым набором после проверки кода TOTP или кода восстановления
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <access_token>"
+// @Param request body MFACodeReq true "Код TOTP или код восстановления"
+// @Success 200 {object} RecoveryCodesResp "Успех"
+// @Failure 400 {object} handler.ErrorResponse "Неверный код"
+// @Failure 401 {object} handler.ErrorResponse "Невалидный или отозванный токен"
+// @Failure 403 {object} handler.ErrorResponse "Недостаточно прав"
+// @Failure 500 {object} handler.ErrorResponse "Ошибка сервера"
+// @Router /api/users/mfa/recovery-codes/regenerate [post]
+func (s *UsersReg) RegenerateRecoveryCodesHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
+	logrus.Info("🤍 RegenerateRecoveryCodesHandler")
+	ctx := r.Context()
+
+	req := &MFACodeReq{}
+	if errObj := handler.ParseRequestBodyPost(r, req); errObj != nil {
+		return nil, errObj
+	}
+
+	user, errObj := s.getEnabledTOTPUser(ctx)
+	if errObj != nil {
+		return nil, errObj
+	}
+	if errObj := s.verifySecondFactor(w, r, user, req.Code, req.RecoveryCode); errObj != nil {
+		return nil, errObj
+	}
+
+	codes, hashes, errObj := newRecoveryCodes()
+	if errObj != nil {
+		return nil, errObj
+	}
+	if errObj := s.ipc.DB.RecoveryCodes.ReplaceUserRecoveryCodesDB(ctx, nil, *user.SystemID, hashes); errObj != nil {
+		return nil, errObj
+	}
+
+	return &RecoveryCodesResp{RecoveryCodes: codes}, nil
+}
+
+// verifySecondFactor проверяет второй фактор с учетом неудачных попыток:
+// перебор кода с похищенной сессией блокируется так же, как при входе
+func (s *UsersReg) verifySecondFactor(w http.ResponseWriter, r *http.Request, user *typescore.User, code, recoveryCode *string) *errm.Error {
+	account := handler.SecondFactorAccount(*user.SystemID)
+	if errObj := handler.CheckAuthLockout(w, r, s.ipc.Lockout, account); errObj != nil {
+		return errObj
+	}
+	if errObj := handler.VerifySecondFactor(r.Context(), s.ipc.DB, s.ipc.SecretCipher, user, code, recoveryCode); errObj != nil {
+		if errors.Is(errObj.Error, handler.ErrInvalidMFACode) {
+			handler.RecordAuthFailure(r, s.ipc.Lockout, s.ipc.RabbitMQ, account, user)
+		}
+		return errObj
+	}
+	handler.RecordAuthSuccess(r, s.ipc.Lockout, account)
+	return nil
+}
+
+// getCurrentUser возвращает пользователя из токена запроса
+func (s *UsersReg) getCurrentUser(ctx context.Context) (*typescore.User, *errm.Error) {
+	guidUser, err := handler.GetGuidFromContext(ctx)
+	if err != nil {
+		return nil, errm.NewError("user_address_not_found", err)
+	}
+
+	users, _, errObj := s.ipc.DB.Users.GetUsersListDB(ctx, typescore.ListDbOptions{Filtering: &typescore.User{
+		SystemID: &guidUser,
+	}})
+	if errObj != nil {
+		return nil, errObj
+	}
+	if len(users) == 0 {
+		return nil, errm.NewError("not_found", errors.New("not_found"))
+	}
+	return users[0], nil
+}
+
+// getEnabledTOTPUser возвращает пользователя из токена запроса с подтвержденным TOTP
+func (s *UsersReg) getEnabledTOTPUser(ctx context.Context) (*typescore.User, *errm.Error) {
+	user, errObj := s.getCurrentUser(ctx)
+	if errObj != nil {
+		return nil, errObj
+	}
+	if user.TOTPEnabledAt == nil {
+		return nil, errm.NewError("totp_not_enabled", errors.New("totp is not enabled"))
+	}
+	return user, nil
+}
+
+// newRecoveryCodes генерирует коды восстановления и их хеши для хранения
+func newRecoveryCodes() ([]string, []string, *errm.Error) {
+	codes, err := securecore.GenerateRecoveryCodes(securecore.RecoveryCodesCount)
+	if err != nil {
+		return nil, nil, errm.NewError("recovery_codes_error", err)
+	}
+	hashes := make([]string, 0, len(codes))
+	for _, code := range codes {
+		hashes = append(hashes, securecore.HashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
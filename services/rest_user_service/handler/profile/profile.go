@@ -17,6 +17,7 @@ import (
 // @Produce json
 // @Success 200 {object} typescore.User "Успех"
 // @Failure 400 {object} handler.ErrorResponse "Некорректный запрос"
+// @Failure 403 {object} handler.ErrorResponse "Недостаточно прав"
 // @Failure 500 {object} handler.ErrorResponse "Ошибка сервера"
 // @Router /api/users/profile [get]
 func (s *UsersReg) GetProfileHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
@@ -2,6 +2,7 @@ package userhandler
 
 import (
 	errm "authentication_service/core/errmodule"
+	"authentication_service/core/typescore"
 	"authentication_service/rest_user_service/handler"
 	typesm "authentication_service/rest_user_service/types"
 	"github.com/go-chi/chi/v5"
@@ -9,7 +10,20 @@ import (
 )
 
 const (
-	profileURI = "/profile"
+	profileURI                = "/profile"
+	sessionsURI               = "/sessions"
+	sessionURI                = "/sessions/{id}"
+	revokeOtherSessionsURI    = "/sessions/revoke-others"
+	totpEnrollURI             = "/mfa/totp/enroll"
+	totpConfirmURI            = "/mfa/totp/confirm"
+	totpDisableURI            = "/mfa/totp/disable"
+	recoveryCodesURI          = "/mfa/recovery-codes/regenerate"
+	webAuthnRegisterBeginURI  = "/webauthn/register/begin"
+	webAuthnRegisterFinishURI = "/webauthn/register/finish"
+	webAuthnCredentialsURI    = "/webauthn/credentials"
+	webAuthnCredentialURI     = "/webauthn/credentials/{id}"
+	personalTokensURI         = "/tokens"
+	personalTokenURI          = "/tokens/{id}"
 )
 
 type UsersReg struct {
@@ -27,9 +41,41 @@ func RegisterUsersRoutes(
 	}
 
 	r.Route("/api/users", func(r chi.Router) {
-		r.Use(handler.JWTVerifier(ipc.Config))
+		// Чтение профиля доступно и с персональным токеном доступа
+		r.Use(handler.JWTVerifier(handler.JWTVerifierParams{
+			TokenFormat:         ipc.TokenFormat,
+			TokenPolicy:         ipc.TokenPolicy,
+			TokenDenylist:       ipc.TokenDenylist,
+			AllowPersonalTokens: true,
+			DB:                  ipc.DB,
+		}))
+		r.Use(handler.RequireScope(typescore.ProfileReadScope))
 
 		handler.RegisterRoute(r, http.MethodGet, profileURI, s.GetProfileHandler)
+		handler.RegisterRoute(r, http.MethodGet, sessionsURI, s.GetSessionsHandler)
+		handler.RegisterRoute(r, http.MethodGet, webAuthnCredentialsURI, s.GetWebAuthnCredentialsHandler)
+
+		// Настройки безопасности аккаунта и сами персональные токены меняются только из сессии пользователя
+		rs := r.With(handler.RequireSessionToken)
+		handler.RegisterRoute(rs, http.MethodGet, personalTokensURI, s.GetPersonalTokensHandler)
+
+		// Завершение сессий, настройка второго фактора, ключей доступа и токенов изменяют состояние аккаунта
+		rw := rs.With(handler.RequireScope(typescore.ProfileWriteScope))
+		handler.RegisterRoute(rw, http.MethodDelete, sessionURI, s.RevokeSessionHandler)
+		handler.RegisterRoute(rw, http.MethodPost, revokeOtherSessionsURI, s.RevokeOtherSessionsHandler)
+		handler.RegisterRoute(rw, http.MethodPost, totpConfirmURI, s.TOTPConfirmHandler)
+		handler.RegisterRoute(rw, http.MethodPost, totpDisableURI, s.TOTPDisableHandler)
+		handler.RegisterRoute(rw, http.MethodPost, recoveryCodesURI, s.RegenerateRecoveryCodesHandler)
+		handler.RegisterRoute(rw, http.MethodPost, webAuthnRegisterFinishURI, s.WebAuthnRegisterFinishHandler)
+
+		// Новые способы входа и API-ключи требуют недавней аутентификации: действующего access токена недостаточно.
+		// Отключение TOTP и новые коды восстановления и так требуют кода второго фактора
+		rr := rw.With(handler.RequireRecentAuth(ipc.Config.ExposedServiceConfig.UserService.StepUp.MaxAge))
+		handler.RegisterRoute(rr, http.MethodPost, totpEnrollURI, s.TOTPEnrollHandler)
+		handler.RegisterRoute(rr, http.MethodPost, webAuthnRegisterBeginURI, s.WebAuthnRegisterBeginHandler)
+		handler.RegisterRoute(rr, http.MethodDelete, webAuthnCredentialURI, s.DeleteWebAuthnCredentialHandler)
+		handler.RegisterRoute(rr, http.MethodPost, personalTokensURI, s.CreatePersonalTokenHandler)
+		handler.RegisterRoute(rr, http.MethodDelete, personalTokenURI, s.DeletePersonalTokenHandler)
 	})
 
 	return nil
@@ -0,0 +1,158 @@
+package userhandler
+
+import (
+	errm "authentication_service/core/errmodule"
+	protoobj "authentication_service/core/proto"
+	"authentication_service/core/typescore"
+	"authentication_service/rest_user_service/handler"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"time"
+)
+
+// SessionResp сессия пользователя на устройстве
+type SessionResp struct {
+	ID         string     `json:"id"`
+	DeviceName *string    `json:"device_name,omitempty"`
+	UserAgent  *string    `json:"user_agent,omitempty"`
+	IP         *string    `json:"ip,omitempty"`
+	CreatedAt  *time.Time `json:"created_at,omitempty"`
+	LastSeenAt *time.Time `json:"last_seen_at,omitempty"`
+	Current    bool       `json:"current"` // Сессия, которой принадлежит токен запроса
+}
+
+// GetSessionsHandler Получение активных сессий пользователя
+// @Summary Получение активных сессий пользователя
+// @Description Возвращает активные сессии пользователя на устройствах. Текущая сессия отмечена флагом current
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <access_token>"
+// @Success 200 {array} SessionResp "Успех"
+// @Failure 401 {object} handler.ErrorResponse "Невалидный или отозванный токен"
+// @Failure 403 {object} handler.ErrorResponse "Недостаточно прав"
+// @Failure 500 {object} handler.ErrorResponse "Ошибка сервера"
+// @Router /api/users/sessions [get]
+func (s *UsersReg) GetSessionsHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
+	logrus.Info("🤍 GetSessionsHandler")
+	ctx := r.Context()
+
+	guidUser, err := handler.GetGuidFromContext(ctx)
+	if err != nil {
+		return nil, errm.NewError("user_address_not_found", err)
+	}
+	currentSessionID := getSessionIDFromContext(r)
+
+	sessions, _, errW := s.ipc.DB.Sessions.GetSessionsListDB(ctx, typescore.ListDbOptions{Filtering: &typescore.Session{
+		UserID: &guidUser,
+	}})
+	if errW != nil {
+		return nil, errW
+	}
+
+	result := make([]*SessionResp, 0, len(sessions))
+	for _, session := range sessions {
+		if session.ID == nil || session.RevokedAt != nil {
+			continue
+		}
+		result = append(result, &SessionResp{
+			ID:         *session.ID,
+			DeviceName: session.DeviceName,
+			UserAgent:  session.UserAgent,
+			IP:         session.IP,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			Current:    *session.ID == currentSessionID,
+		})
+	}
+
+	return result, nil
+}
+
+// RevokeSessionHandler Завершение сессии
+// @Summary Завершение сессии
+// @Description Завершает сессию пользователя: отзывает её Refresh токены, Access токены сессии перестают приниматься
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <access_token>"
+// @Param id path string true "Идентификатор сессии"
+// @Success 200 {object} protoobj.RevokeSessionResponse "Успех"
+// @Failure 401 {object} handler.ErrorResponse "Невалидный или отозванный токен"
+// @Failure 403 {object} handler.ErrorResponse "Недостаточно прав"
+// @Failure 500 {object} handler.ErrorResponse "Ошибка сервера"
+// @Router /api/users/sessions/{id} [delete]
+func (s *UsersReg) RevokeSessionHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
+	logrus.Info("🤍 RevokeSessionHandler")
+	ctx := r.Context()
+
+	guidUser, err := handler.GetGuidFromContext(ctx)
+	if err != nil {
+		return nil, errm.NewError("user_address_not_found", err)
+	}
+
+	sessionID := chi.URLParam(r, "id")
+	if sessionID == "" {
+		return nil, errm.NewError("empty_obj", errors.New("empty_obj"))
+	}
+
+	resp, err := s.ipc.ClientAuthServiceProto.RevokeSession(ctx, &protoobj.RevokeSessionRequest{
+		UserId:    guidUser,
+		SessionId: sessionID,
+	})
+	if err != nil {
+		return nil, errm.NewError("session_revoke_error", err)
+	}
+
+	return resp, nil
+}
+
+// RevokeOtherSessionsHandler Выход на всех остальных устройствах
+// @Summary Выход на всех остальных устройствах
+// @Description Завершает все сессии пользователя, кроме текущей
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <access_token>"
+// @Success 200 {object} protoobj.RevokeOtherSessionsResponse "Успех"
+// @Failure 401 {object} handler.ErrorResponse "Невалидный или отозванный токен"
+// @Failure 403 {object} handler.ErrorResponse "Недостаточно прав"
+// @Failure 500 {object} handler.ErrorResponse "Ошибка сервера"
+// @Router /api/users/sessions/revoke-others [post]
+func (s *UsersReg) RevokeOtherSessionsHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
+	logrus.Info("🤍 RevokeOtherSessionsHandler")
+	ctx := r.Context()
+
+	guidUser, err := handler.GetGuidFromContext(ctx)
+	if err != nil {
+		return nil, errm.NewError("user_address_not_found", err)
+	}
+
+	// Токены, выданные до появления сессий, не позволяют определить текущую сессию
+	currentSessionID := getSessionIDFromContext(r)
+	if currentSessionID == "" {
+		return nil, errm.NewError("session_not_found", errors.New("token has no session"))
+	}
+
+	resp, err := s.ipc.ClientAuthServiceProto.RevokeOtherSessions(ctx, &protoobj.RevokeOtherSessionsRequest{
+		UserId:           guidUser,
+		CurrentSessionId: currentSessionID,
+	})
+	if err != nil {
+		return nil, errm.NewError("session_revoke_error", err)
+	}
+
+	return resp, nil
+}
+
+// getSessionIDFromContext возвращает идентификатор сессии (claim sid) токена запроса
+func getSessionIDFromContext(r *http.Request) string {
+	claims, err := handler.GetClaimsFromContext(r.Context())
+	if err != nil {
+		return ""
+	}
+	sessionID, _ := claims["sid"].(string)
+	return sessionID
+}
@@ -0,0 +1,224 @@
+package userhandler
+
+import (
+	errm "authentication_service/core/errmodule"
+	"authentication_service/core/securecore"
+	"authentication_service/core/typescore"
+	"authentication_service/rest_user_service/handler"
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	personalTokenNameMaxLength = 100 // максимальная длина названия токена
+	personalTokensMaxCount     = 50  // максимальное число токенов пользователя
+)
+
+var errInvalidPersonalTokenParams = errors.New("invalid personal access token parameters")
+
+// CreatePersonalTokenReq параметры персонального токена доступа
+type CreatePersonalTokenReq struct {
+	Name      *string    `json:"name"`       // Название токена
+	Scopes    []string   `json:"scopes"`     // Области доступа: подмножество областей доступа пользователя
+	ExpiresAt *time.Time `json:"expires_at"` // Срок действия (RFC 3339); не задан — бессрочный
+}
+
+// CreatePersonalTokenResp созданный токен; значение токена возвращается только один раз
+type CreatePersonalTokenResp struct {
+	PersonalToken *typescore.PersonalAccessToken `json:"personal_token"`
+	Token         string                         `json:"token"`
+}
+
+type PersonalTokenDeleteResp struct {
+	Deleted bool `json:"deleted"`
+}
+
+// GetPersonalTokensHandler Получение персональных токенов доступа
+// @Summary Получение персональных токенов доступа
+// @Description Возвращает персональные токены доступа пользователя: название, области доступа, срок действия и время последнего использования.
+// @Description Значения токенов не возвращаются. Доступно только с access токеном сессии
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <access_token>"
+// @Success 200 {array} typescore.PersonalAccessToken "Успех"
+// @Failure 401 {object} handler.ErrorResponse "Невалидный или отозванный токен"
+// @Failure 403 {object} handler.ErrorResponse "Недостаточно прав"
+// @Failure 500 {object} handler.ErrorResponse "Ошибка сервера"
+// @Router /api/users/tokens [get]
+func (s *UsersReg) GetPersonalTokensHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
+	logrus.Info("🤍 GetPersonalTokensHandler")
+	ctx := r.Context()
+
+	guidUser, err := handler.GetGuidFromContext(ctx)
+	if err != nil {
+		return nil, errm.NewError("user_address_not_found", err)
+	}
+
+	tokens, _, errObj := s.ipc.DB.PersonalTokens.GetPersonalAccessTokensListDB(ctx, typescore.ListDbOptions{
+		Filtering: &typescore.PersonalAccessToken{UserID: &guidUser},
+	})
+	if errObj != nil {
+		return nil, errObj
+	}
+	if tokens == nil {
+		tokens = []*typescore.PersonalAccessToken{}
+	}
+
+	return tokens, nil
+}
+
+// CreatePersonalTokenHandler Создание персонального токена доступа
+// @Summary Создание персонального токена доступа
+// @Description Создает именованный персональный токен доступа (API-ключ) для скриптов. Токен передается в заголовке Authorization: Bearer
+// @Description и имеет префикс aspat_. Хранится только хэш токена: значение возвращается один раз в этом ответе.
+// @Description Области доступа токена ограничиваются текущими областями пользователя. Доступно только с access токеном сессии
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <access_token>"
+// @Param request body CreatePersonalTokenReq true "Параметры токена"
+// @Success 200 {object} CreatePersonalTokenResp "Успех"
+// @Failure 401 {object} handler.ErrorResponse "Невалидный токен или требуется повторная аутентификация (insufficient_user_authentication)"
+// @Failure 403 {object} handler.ErrorResponse "Недостаточно прав"
+// @Failure 500 {object} handler.ErrorResponse "Некорректные параметры или ошибка сервера"
+// @Router /api/users/tokens [post]
+func (s *UsersReg) CreatePersonalTokenHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
+	logrus.Info("🤍 CreatePersonalTokenHandler")
+	ctx := r.Context()
+
+	guidUser, err := handler.GetGuidFromContext(ctx)
+	if err != nil {
+		return nil, errm.NewError("user_address_not_found", err)
+	}
+	claims, err := handler.GetClaimsFromContext(ctx)
+	if err != nil {
+		return nil, errm.NewError("claims_not_found", err)
+	}
+
+	createReq := &CreatePersonalTokenReq{}
+	if errObj := handler.ParseRequestBodyPost(r, createReq); errObj != nil {
+		return nil, errObj
+	}
+	// Токен не может получить больше прав, чем есть у сессии пользователя
+	grantedScope, _ := claims["scope"].(string)
+	if err := validatePersonalTokenParams(createReq, strings.Fields(grantedScope), time.Now()); err != nil {
+		return nil, errm.NewError("invalid_personal_token_params", err)
+	}
+
+	_, count, errObj := s.ipc.DB.PersonalTokens.GetPersonalAccessTokensListDB(ctx, typescore.ListDbOptions{
+		Filtering: &typescore.PersonalAccessToken{UserID: &guidUser},
+	})
+	if errObj != nil {
+		return nil, errObj
+	}
+	if count >= personalTokensMaxCount {
+		return nil, errm.NewError("personal_tokens_limit", fmt.Errorf("at most %d personal access tokens are allowed", personalTokensMaxCount))
+	}
+
+	id, err := securecore.GenerateUUID()
+	if err != nil {
+		return nil, errm.NewError("personal_token_create_error", err)
+	}
+	token, err := securecore.GeneratePersonalAccessToken()
+	if err != nil {
+		return nil, errm.NewError("personal_token_create_error", err)
+	}
+
+	name := strings.TrimSpace(*createReq.Name)
+	tokenHash := securecore.HashToken(token)
+	scopes := strings.Join(createReq.Scopes, " ")
+	now := time.Now().UTC()
+	personalToken := &typescore.PersonalAccessToken{
+		ID:        &id,
+		UserID:    &guidUser,
+		Name:      &name,
+		TokenHash: &tokenHash,
+		Scopes:    &scopes,
+		CreatedAt: &now,
+	}
+	if createReq.ExpiresAt != nil {
+		expiresAt := createReq.ExpiresAt.UTC()
+		personalToken.ExpiresAt = &expiresAt
+	}
+
+	if _, _, errObj := s.ipc.DB.PersonalTokens.CreatePersonalAccessTokenDB(ctx, nil, personalToken); errObj != nil {
+		return nil, errObj
+	}
+	logrus.Infof("🔑 personal access token created: user_id=%s id=%s", guidUser, id)
+
+	return &CreatePersonalTokenResp{PersonalToken: personalToken, Token: token}, nil
+}
+
+// DeletePersonalTokenHandler Отзыв персонального токена доступа
+// @Summary Отзыв персонального токена доступа
+// @Description Отзывает персональный токен доступа: запросы с ним сразу перестают приниматься. Доступно только с access токеном сессии
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <access_token>"
+// @Param id path string true "Идентификатор токена"
+// @Success 200 {object} PersonalTokenDeleteResp "Успех"
+// @Failure 401 {object} handler.ErrorResponse "Невалидный токен или требуется повторная аутентификация (insufficient_user_authentication)"
+// @Failure 403 {object} handler.ErrorResponse "Недостаточно прав"
+// @Failure 500 {object} handler.ErrorResponse "Ошибка сервера"
+// @Router /api/users/tokens/{id} [delete]
+func (s *UsersReg) DeletePersonalTokenHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
+	logrus.Info("🤍 DeletePersonalTokenHandler")
+	ctx := r.Context()
+
+	guidUser, err := handler.GetGuidFromContext(ctx)
+	if err != nil {
+		return nil, errm.NewError("user_address_not_found", err)
+	}
+
+	tokenID := chi.URLParam(r, "id")
+	if tokenID == "" {
+		return nil, errm.NewError("empty_obj", errors.New("empty_obj"))
+	}
+	if !securecore.IsValidUUID(tokenID) {
+		return nil, errm.NewError("not_found", errors.New("personal access token not found"))
+	}
+
+	deleted, errObj := s.ipc.DB.PersonalTokens.DeletePersonalAccessTokenDB(ctx, guidUser, tokenID)
+	if errObj != nil {
+		return nil, errObj
+	}
+	if !deleted {
+		return nil, errm.NewError("not_found", errors.New("personal access token not found"))
+	}
+	logrus.Infof("🔑 personal access token revoked: user_id=%s id=%s", guidUser, tokenID)
+
+	return &PersonalTokenDeleteResp{Deleted: true}, nil
+}
+
+// validatePersonalTokenParams проверяет название, области доступа и срок действия токена
+func validatePersonalTokenParams(req *CreatePersonalTokenReq, grantedScopes []string, now time.Time) error {
+	if req.Name == nil || strings.TrimSpace(*req.Name) == "" || utf8.RuneCountInString(strings.TrimSpace(*req.Name)) > personalTokenNameMaxLength {
+		return fmt.Errorf("%w: name is required (up to %d characters)", errInvalidPersonalTokenParams, personalTokenNameMaxLength)
+	}
+	if len(req.Scopes) == 0 {
+		return fmt.Errorf("%w: scopes are required", errInvalidPersonalTokenParams)
+	}
+
+	granted := make(map[string]struct{}, len(grantedScopes))
+	for _, scope := range grantedScopes {
+		granted[scope] = struct{}{}
+	}
+	for _, scope := range req.Scopes {
+		if _, ok := granted[scope]; !ok {
+			return fmt.Errorf("%w: scope %q is not granted to user", errInvalidPersonalTokenParams, scope)
+		}
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(now) {
+		return fmt.Errorf("%w: expires_at must be in the future", errInvalidPersonalTokenParams)
+	}
+	return nil
+}
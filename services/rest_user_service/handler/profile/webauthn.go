@@ -0,0 +1,223 @@
+package userhandler
+
+import (
+	errm "authentication_service/core/errmodule"
+	"authentication_service/core/securecore"
+	"authentication_service/core/typescore"
+	"authentication_service/rest_user_service/handler"
+	"encoding/json"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// максимальная длина названия ключа доступа
+const webAuthnNameMaxLength = 100
+
+// префикс церемонии регистрации: одновременно у пользователя может идти одна регистрация
+const webAuthnRegisterKeyPrefix = "register:"
+
+type WebAuthnRegisterFinishReq struct {
+	Name       *string         `json:"name"`                            // Название ключа, например "MacBook"
+	Credential json.RawMessage `json:"credential" swaggertype:"object"` // Ответ navigator.credentials.create()
+}
+
+type WebAuthnCredentialDeleteResp struct {
+	Deleted bool `json:"deleted"`
+}
+
+// WebAuthnRegisterBeginHandler Начало регистрации ключа доступа
+// @Summary Начало регистрации ключа доступа
+// @Description Возвращает параметры для navigator.credentials.create(). Уже зарегистрированные ключи исключаются
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <access_token>"
+// @Success 200 {object} object "Параметры PublicKeyCredentialCreationOptions"
+// @Failure 401 {object} handler.ErrorResponse "Невалидный токен или требуется повторная аутентификация (insufficient_user_authentication)"
+// @Failure 403 {object} handler.ErrorResponse "Недостаточно прав"
+// @Failure 500 {object} handler.ErrorResponse "Ошибка сервера"
+// @Router /api/users/webauthn/register/begin [post]
+func (s *UsersReg) WebAuthnRegisterBeginHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
+	logrus.Info("🤍 WebAuthnRegisterBeginHandler")
+	ctx := r.Context()
+
+	if s.ipc.WebAuthn == nil {
+		return nil, errm.NewError("webauthn_unavailable", handler.ErrWebAuthnUnavailable)
+	}
+
+	user, errObj := s.getCurrentUser(ctx)
+	if errObj != nil {
+		return nil, errObj
+	}
+	webAuthnUser, errObj := handler.LoadWebAuthnUser(ctx, s.ipc.DB, user)
+	if errObj != nil {
+		return nil, errObj
+	}
+
+	creation, session, err := s.ipc.WebAuthn.BeginRegistration(webAuthnUser,
+		webauthn.WithExclusions(webauthn.Credentials(webAuthnUser.WebAuthnCredentials()).CredentialDescriptors()),
+	)
+	if err != nil {
+		return nil, errm.NewError("webauthn_begin_error", err)
+	}
+
+	if err := handler.SaveWebAuthnSession(ctx, s.ipc.KVStore, webAuthnRegisterKeyPrefix+*user.SystemID, session); err != nil {
+		return nil, errm.NewError("webauthn_session_error", err)
+	}
+
+	return creation, nil
+}
+
+// WebAuthnRegisterFinishHandler Завершение регистрации ключа доступа
+// @Summary Завершение регистрации ключа доступа
+// @Description Проверяет ответ аутентификатора и сохраняет ключ доступа пользователя
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <access_token>"
+// @Param request body WebAuthnRegisterFinishReq true "Ответ аутентификатора"
+// @Success 200 {object} typescore.WebAuthnCredential "Успех"
+// @Failure 400 {object} handler.ErrorResponse "Некорректный ответ аутентификатора"
+// @Failure 401 {object} handler.ErrorResponse "Невалидный или отозванный токен"
+// @Failure 403 {object} handler.ErrorResponse "Недостаточно прав"
+// @Failure 500 {object} handler.ErrorResponse "Ошибка сервера"
+// @Router /api/users/webauthn/register/finish [post]
+func (s *UsersReg) WebAuthnRegisterFinishHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
+	logrus.Info("🤍 WebAuthnRegisterFinishHandler")
+	ctx := r.Context()
+
+	if s.ipc.WebAuthn == nil {
+		return nil, errm.NewError("webauthn_unavailable", handler.ErrWebAuthnUnavailable)
+	}
+
+	req := &WebAuthnRegisterFinishReq{}
+	if errObj := handler.ParseRequestBodyPost(r, req); errObj != nil {
+		return nil, errObj
+	}
+	if len(req.Credential) == 0 {
+		return nil, errm.NewError("empty_obj", errors.New("credential is required"))
+	}
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if utf8.RuneCountInString(name) > webAuthnNameMaxLength {
+			return nil, errm.NewError("invalid_name", errors.New("name is too long"))
+		}
+		req.Name = &name
+	}
+
+	user, errObj := s.getCurrentUser(ctx)
+	if errObj != nil {
+		return nil, errObj
+	}
+	webAuthnUser, errObj := handler.LoadWebAuthnUser(ctx, s.ipc.DB, user)
+	if errObj != nil {
+		return nil, errObj
+	}
+
+	session, err := handler.TakeWebAuthnSession(ctx, s.ipc.KVStore, webAuthnRegisterKeyPrefix+*user.SystemID)
+	if err != nil {
+		return nil, errm.NewError("webauthn_session_error", err)
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(req.Credential)
+	if err != nil {
+		return nil, errm.NewError("webauthn_invalid_response", err)
+	}
+	credential, err := s.ipc.WebAuthn.CreateCredential(webAuthnUser, *session, parsed)
+	if err != nil {
+		return nil, errm.NewError("webauthn_invalid_response", err)
+	}
+
+	record, err := handler.NewWebAuthnCredentialRecord(*user.SystemID, req.Name, credential)
+	if err != nil {
+		return nil, errm.NewError("webauthn_credential_error", err)
+	}
+	created, _, errObj := s.ipc.DB.WebAuthn.CreateWebAuthnCredentialDB(ctx, nil, record, true)
+	if errObj != nil {
+		return nil, errObj
+	}
+
+	logrus.Infof("🔑 passkey registered: user_id=%s", *user.SystemID)
+	return created, nil
+}
+
+// GetWebAuthnCredentialsHandler Получение ключей доступа пользователя
+// @Summary Получение ключей доступа пользователя
+// @Description Возвращает зарегистрированные ключи доступа (passkeys) пользователя
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <access_token>"
+// @Success 200 {array} typescore.WebAuthnCredential "Успех"
+// @Failure 401 {object} handler.ErrorResponse "Невалидный или отозванный токен"
+// @Failure 403 {object} handler.ErrorResponse "Недостаточно прав"
+// @Failure 500 {object} handler.ErrorResponse "Ошибка сервера"
+// @Router /api/users/webauthn/credentials [get]
+func (s *UsersReg) GetWebAuthnCredentialsHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
+	logrus.Info("🤍 GetWebAuthnCredentialsHandler")
+	ctx := r.Context()
+
+	guidUser, err := handler.GetGuidFromContext(ctx)
+	if err != nil {
+		return nil, errm.NewError("user_address_not_found", err)
+	}
+
+	credentials, _, errObj := s.ipc.DB.WebAuthn.GetWebAuthnCredentialsListDB(ctx, typescore.ListDbOptions{
+		Filtering: &typescore.WebAuthnCredential{UserID: &guidUser},
+	})
+	if errObj != nil {
+		return nil, errObj
+	}
+	if credentials == nil {
+		credentials = []*typescore.WebAuthnCredential{}
+	}
+
+	return credentials, nil
+}
+
+// DeleteWebAuthnCredentialHandler Удаление ключа доступа
+// @Summary Удаление ключа доступа
+// @Description Удаляет ключ доступа пользователя: вход с ним становится невозможен
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer <access_token>"
+// @Param id path string true "Идентификатор ключа"
+// @Success 200 {object} WebAuthnCredentialDeleteResp "Успех"
+// @Failure 401 {object} handler.ErrorResponse "Невалидный токен или требуется повторная аутентификация (insufficient_user_authentication)"
+// @Failure 403 {object} handler.ErrorResponse "Недостаточно прав"
+// @Failure 500 {object} handler.ErrorResponse "Ошибка сервера"
+// @Router /api/users/webauthn/credentials/{id} [delete]
+func (s *UsersReg) DeleteWebAuthnCredentialHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
+	logrus.Info("🤍 DeleteWebAuthnCredentialHandler")
+	ctx := r.Context()
+
+	guidUser, err := handler.GetGuidFromContext(ctx)
+	if err != nil {
+		return nil, errm.NewError("user_address_not_found", err)
+	}
+
+	credentialID := chi.URLParam(r, "id")
+	if credentialID == "" {
+		return nil, errm.NewError("empty_obj", errors.New("empty_obj"))
+	}
+	if !securecore.IsValidUUID(credentialID) {
+		return nil, errm.NewError("not_found", errors.New("credential not found"))
+	}
+
+	deleted, errObj := s.ipc.DB.WebAuthn.DeleteWebAuthnCredentialDB(ctx, guidUser, credentialID)
+	if errObj != nil {
+		return nil, errObj
+	}
+	if !deleted {
+		return nil, errm.NewError("not_found", errors.New("credential not found"))
+	}
+
+	return &WebAuthnCredentialDeleteResp{Deleted: true}, nil
+}
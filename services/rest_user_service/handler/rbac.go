@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"authentication_service/core/securecore"
+	"authentication_service/core/typescore"
+	"encoding/json"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// RequireRole middleware пропускает запрос, если роль пользователя входит в roles.
+// Используется после JWTVerifier.
+func RequireRole(roles ...typescore.UserRoleTypes) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, err := GetClaimsFromContext(r.Context())
+			if err != nil {
+				respondWithStatus(w, http.StatusUnauthorized, "unauthorized")
+				return
+			}
+
+			role, _ := claims["role"].(string)
+			for _, allowed := range roles {
+				if role == string(allowed) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			respondWithStatus(w, http.StatusForbidden, "insufficient_role")
+		})
+	}
+}
+
+// RequireScope middleware пропускает запрос, если в токене есть все перечисленные области доступа.
+// Используется после JWTVerifier.
+func RequireScope(scopes ...typescore.ScopeTypes) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, err := GetClaimsFromContext(r.Context())
+			if err != nil {
+				respondWithStatus(w, http.StatusUnauthorized, "unauthorized")
+				return
+			}
+
+			scopeClaim, _ := claims["scope"].(string)
+			granted := make(map[string]struct{})
+			for _, scope := range strings.Fields(scopeClaim) {
+				granted[scope] = struct{}{}
+			}
+
+			for _, required := range scopes {
+				if _, ok := granted[string(required)]; !ok {
+					respondWithStatus(w, http.StatusForbidden, "insufficient_scope: "+string(required))
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireFirstPartyToken middleware отклоняет токены, выданные клиентам OAuth 2.0:
+// действия с аккаунтом доступны только собственным приложениям сервиса.
+// Используется после JWTVerifier.
+func RequireFirstPartyToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, err := GetClaimsFromContext(r.Context())
+		if err != nil {
+			respondWithStatus(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+
+		if clientID, _ := claims["client_id"].(string); clientID != "" {
+			respondWithStatus(w, http.StatusForbidden, "first_party_token_required")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireFirstPartyOrServiceAccount middleware как RequireFirstPartyToken, но пропускает и токены
+// сервисных аккаунтов (JWTVerifier с AllowServiceAccounts). Токены клиентов OAuth 2.0,
+// выданные от имени пользователя, по-прежнему отклоняются.
+// Используется после JWTVerifier.
+func RequireFirstPartyOrServiceAccount(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := GetServiceAccountFromContext(r.Context()); err == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		RequireFirstPartyToken(next).ServeHTTP(w, r)
+	})
+}
+
+// RequireSessionToken middleware отклоняет персональные токены доступа и токены имперсонации (claim act):
+// управление ими доступно только из сессии пользователя, чтобы утекший токен нельзя было продлить или размножить,
+// а сотрудник поддержки не мог изменить настройки безопасности или выдать доступ от имени пользователя.
+// Используется после JWTVerifier.
+func RequireSessionToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, err := GetClaimsFromContext(r.Context())
+		if err != nil {
+			respondWithStatus(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+
+		if personalTokenID, _ := claims["pat_id"].(string); personalTokenID != "" || securecore.ClaimActor(claims) != "" {
+			respondWithStatus(w, http.StatusForbidden, "session_token_required")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// DefaultRecentAuthMaxAge допустимое время с последней аутентификации для чувствительных операций
+const DefaultRecentAuthMaxAge = 5 * time.Minute
+
+// RequireRecentAuth middleware пропускает запрос, если пользователь аутентифицировался не раньше maxAge назад
+// (claim auth_time, семантика max_age OpenID Connect). Иначе — 401 с вызовом повторной аутентификации (RFC 9470):
+// клиент получает новый токен через /api/auth/reauthenticate и повторяет запрос.
+// Нулевой maxAge — DefaultRecentAuthMaxAge. Используется после JWTVerifier.
+func RequireRecentAuth(maxAge time.Duration) func(http.Handler) http.Handler {
+	if maxAge <= 0 {
+		maxAge = DefaultRecentAuthMaxAge
+	}
+	challenge := fmt.Sprintf(`Bearer error="insufficient_user_authentication", error_description="recent authentication is required", max_age=%d`, int64(maxAge.Seconds()))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, err := GetClaimsFromContext(r.Context())
+			if err != nil {
+				respondWithStatus(w, http.StatusUnauthorized, "unauthorized")
+				return
+			}
+
+			if !IsRecentAuth(claims, maxAge) {
+				w.Header().Set("WWW-Authenticate", challenge)
+				respondWithStatus(w, http.StatusUnauthorized, "insufficient_user_authentication")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// IsRecentAuth проверяет, что с момента аутентификации (auth_time) прошло не больше maxAge.
+// Токен без auth_time (выданный до его появления или персональный) недостаточно свежий
+func IsRecentAuth(claims jwt.MapClaims, maxAge time.Duration) bool {
+	authTime, ok := securecore.ClaimTime(claims, "auth_time")
+	return ok && time.Since(authTime) <= maxAge
+}
+
+// respondWithStatus отправляет ErrorResponse с указанным HTTP статусом
+func respondWithStatus(w http.ResponseWriter, status int, description string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	response, _ := json.Marshal(ErrorResponse{
+		ErrorCode:        status,
+		ErrorDescription: description,
+	})
+	_, _ = w.Write(response)
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"authentication_service/core/securecore"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestIsRecentAuth(t *testing.T) {
+	const maxAge = 5 * time.Minute
+	authClaims := func(ago time.Duration) jwt.MapClaims {
+		return jwt.MapClaims{"auth_time": securecore.NumericDate(time.Now().Add(-ago))}
+	}
+
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+		want   bool
+	}{
+		{"just authenticated", authClaims(0), true},
+		{"within max age", authClaims(maxAge - time.Second), true},
+		{"older than max age", authClaims(maxAge + time.Second), false},
+		{"without auth_time", jwt.MapClaims{}, false},
+		{"malformed auth_time", jwt.MapClaims{"auth_time": "yesterday"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRecentAuth(tt.claims, maxAge); got != tt.want {
+				t.Fatalf("IsRecentAuth() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequireRecentAuth(t *testing.T) {
+	tests := []struct {
+		name          string
+		maxAge        time.Duration
+		claims        jwt.MapClaims // nil — запрос без проверенного токена
+		wantStatus    int
+		wantChallenge string
+	}{
+		{"recent", time.Minute, jwt.MapClaims{"auth_time": securecore.NumericDate(time.Now())}, http.StatusOK, ""},
+		{"stale", time.Minute, jwt.MapClaims{"auth_time": securecore.NumericDate(time.Now().Add(-2 * time.Minute))}, http.StatusUnauthorized, "max_age=60"},
+		{"default max age", 0, jwt.MapClaims{"auth_time": securecore.NumericDate(time.Now().Add(-4 * time.Minute))}, http.StatusOK, ""},
+		{"stale for default max age", 0, jwt.MapClaims{"auth_time": securecore.NumericDate(time.Now().Add(-6 * time.Minute))}, http.StatusUnauthorized, "max_age=300"},
+		{"personal token without auth_time", time.Minute, jwt.MapClaims{"pat_id": "token"}, http.StatusUnauthorized, "insufficient_user_authentication"},
+		{"no token", time.Minute, nil, http.StatusUnauthorized, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/api/admin/users/id/impersonate", nil)
+			if tt.claims != nil {
+				r = r.WithContext(context.WithValue(r.Context(), claimsContextKey, tt.claims))
+			}
+			w := httptest.NewRecorder()
+			RequireRecentAuth(tt.maxAge)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})).ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			challenge := w.Header().Get("WWW-Authenticate")
+			if tt.wantChallenge == "" {
+				if tt.wantStatus == http.StatusOK && challenge != "" {
+					t.Fatalf("WWW-Authenticate = %q, want none", challenge)
+				}
+				return
+			}
+			if !strings.Contains(challenge, `error="insufficient_user_authentication"`) || !strings.Contains(challenge, tt.wantChallenge) {
+				t.Fatalf("WWW-Authenticate = %q, want challenge with %q", challenge, tt.wantChallenge)
+			}
+		})
+	}
+}
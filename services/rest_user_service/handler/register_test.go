@@ -0,0 +1,58 @@
+package handler
+
+import (
+	errm "authentication_service/core/errmodule"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWrapHandlerFStatus(t *testing.T) {
+	errTest := errors.New("test error")
+
+	tests := []struct {
+		name       string
+		handler    func(http.ResponseWriter, *http.Request) (interface{}, *errm.Error)
+		wantStatus int
+		wantCode   string
+	}{
+		{"success", func(http.ResponseWriter, *http.Request) (interface{}, *errm.Error) {
+			return map[string]string{"status": "ok"}, nil
+		}, http.StatusOK, ""},
+		{"error without status", func(http.ResponseWriter, *http.Request) (interface{}, *errm.Error) {
+			return nil, errm.NewError("internal_error", errTest)
+		}, http.StatusInternalServerError, "internal_error"},
+		{"rejected credentials", func(w http.ResponseWriter, _ *http.Request) (interface{}, *errm.Error) {
+			return nil, RejectCredentials(w, errm.NewError("invalid_credentials", errTest))
+		}, http.StatusUnauthorized, "invalid_credentials"},
+		// Статус, записанный обработчиком, не перезаписывается при формировании ответа
+		{"status written by handler", func(w http.ResponseWriter, _ *http.Request) (interface{}, *errm.Error) {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return nil, errm.NewError("resend_too_soon", errTest)
+		}, http.StatusTooManyRequests, "resend_too_soon"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			WrapHandlerF(WrapHandlerParams{HandlerFunc: tt.handler}).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantCode == "" {
+				return
+			}
+			var resp ErrorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if !strings.HasPrefix(resp.ErrorDescription, tt.wantCode+":") {
+				t.Fatalf("error_description = %q, want error %q", resp.ErrorDescription, tt.wantCode)
+			}
+		})
+	}
+}
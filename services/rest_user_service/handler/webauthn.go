@@ -0,0 +1,270 @@
+package handler
+
+import (
+	"authentication_service/core/configcore"
+	"authentication_service/core/database"
+	errm "authentication_service/core/errmodule"
+	"authentication_service/core/lib/internally/kvstore"
+	"authentication_service/core/securecore"
+	"authentication_service/core/typescore"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+const (
+	webAuthnSessionKeyPrefix = "webauthn:session:"
+
+	defaultWebAuthnTimeout = 5 * time.Minute
+)
+
+// ErrWebAuthnUnavailable вход по ключам доступа не настроен
+var ErrWebAuthnUnavailable = errors.New("webauthn is not configured")
+
+// ErrWebAuthnSessionNotFound церемония WebAuthn не начата или истекла
+var ErrWebAuthnSessionNotFound = errors.New("webauthn ceremony not found or expired")
+
+var webAuthnEncoding = base64.RawURLEncoding
+
+// NewWebAuthn создает проверяющую сторону WebAuthn из конфигурации REST сервиса.
+// Если rp_id не задан, возвращает nil: вход по ключам доступа отключен.
+func NewWebAuthn(cfg configcore.RestServiceConfig) (*webauthn.WebAuthn, error) {
+	if cfg.WebAuthn.RPID == "" {
+		return nil, nil
+	}
+
+	origins := cfg.WebAuthn.RPOrigins
+	if len(origins) == 0 {
+		for _, origin := range cfg.Cors.AllowedOrigins {
+			if origin != "*" {
+				origins = append(origins, origin)
+			}
+		}
+	}
+
+	displayName := cfg.WebAuthn.RPDisplayName
+	if displayName == "" {
+		displayName = cfg.WebAuthn.RPID
+	}
+
+	timeout := cfg.WebAuthn.Timeout
+	if timeout <= 0 {
+		timeout = defaultWebAuthnTimeout
+	}
+	timeouts := webauthn.TimeoutConfig{Enforce: true, Timeout: timeout, TimeoutUVD: timeout}
+
+	return webauthn.New(&webauthn.Config{
+		RPID:          cfg.WebAuthn.RPID,
+		RPDisplayName: displayName,
+		RPOrigins:     origins,
+		// Ключ доступа заменяет пароль, поэтому проверка пользователя на аутентификаторе обязательна
+		AuthenticatorSelection: protocol.AuthenticatorSelection{
+			ResidentKey:      protocol.ResidentKeyRequirementPreferred,
+			UserVerification: protocol.VerificationRequired,
+		},
+		Timeouts: webauthn.TimeoutsConfig{Login: timeouts, Registration: timeouts},
+	})
+}
+
+// WebAuthnUser пользователь и его ключи доступа для церемоний WebAuthn.
+// Идентификатор пользователя (user handle) — system_id.
+type WebAuthnUser struct {
+	User        *typescore.User
+	Records     []*typescore.WebAuthnCredential
+	credentials []webauthn.Credential
+}
+
+// LoadWebAuthnUser загружает ключи доступа пользователя
+func LoadWebAuthnUser(ctx context.Context, db *database.ModuleDB, user *typescore.User) (*WebAuthnUser, *errm.Error) {
+	records, _, errObj := db.WebAuthn.GetWebAuthnCredentialsListDB(ctx, typescore.ListDbOptions{
+		Filtering: &typescore.WebAuthnCredential{UserID: user.SystemID},
+	})
+	if errObj != nil {
+		return nil, errObj
+	}
+
+	webAuthnUser := &WebAuthnUser{User: user, Records: records}
+	for _, record := range records {
+		credential, err := webAuthnCredentialFromRecord(record)
+		if err != nil {
+			return nil, errm.NewError("webauthn_credential_error", err)
+		}
+		webAuthnUser.credentials = append(webAuthnUser.credentials, credential)
+	}
+	return webAuthnUser, nil
+}
+
+func (u *WebAuthnUser) WebAuthnID() []byte {
+	return []byte(*u.User.SystemID)
+}
+
+func (u *WebAuthnUser) WebAuthnName() string {
+	return AccountName(u.User)
+}
+
+func (u *WebAuthnUser) WebAuthnDisplayName() string {
+	var parts []string
+	if u.User.FirstName != nil && *u.User.FirstName != "" {
+		parts = append(parts, *u.User.FirstName)
+	}
+	if u.User.LastName != nil && *u.User.LastName != "" {
+		parts = append(parts, *u.User.LastName)
+	}
+	if len(parts) == 0 {
+		return AccountName(u.User)
+	}
+	return strings.Join(parts, " ")
+}
+
+func (u *WebAuthnUser) WebAuthnCredentials() []webauthn.Credential {
+	return u.credentials
+}
+
+// Record возвращает сохраненную запись ключа по идентификатору учетных данных
+func (u *WebAuthnUser) Record(credentialID []byte) *typescore.WebAuthnCredential {
+	for i, credential := range u.credentials {
+		if bytes.Equal(credential.ID, credentialID) {
+			return u.Records[i]
+		}
+	}
+	return nil
+}
+
+// NewWebAuthnCredentialRecord формирует запись для хранения ключа, созданного при регистрации
+func NewWebAuthnCredentialRecord(userID string, name *string, credential *webauthn.Credential) (*typescore.WebAuthnCredential, error) {
+	id, err := securecore.GenerateUUID()
+	if err != nil {
+		return nil, err
+	}
+
+	credentialID := webAuthnEncoding.EncodeToString(credential.ID)
+	publicKey := webAuthnEncoding.EncodeToString(credential.PublicKey)
+	signCount := int64(credential.Authenticator.SignCount)
+	backupEligible := credential.Flags.BackupEligible
+	backupState := credential.Flags.BackupState
+	now := time.Now().UTC()
+
+	record := &typescore.WebAuthnCredential{
+		ID:             &id,
+		UserID:         &userID,
+		CredentialID:   &credentialID,
+		PublicKey:      &publicKey,
+		SignCount:      &signCount,
+		BackupEligible: &backupEligible,
+		BackupState:    &backupState,
+		Name:           name,
+		CreatedAt:      &now,
+	}
+	if credential.AttestationType != "" {
+		record.AttestationType = &credential.AttestationType
+	}
+	if len(credential.Transport) > 0 {
+		transports := make([]string, 0, len(credential.Transport))
+		for _, transport := range credential.Transport {
+			transports = append(transports, string(transport))
+		}
+		value := strings.Join(transports, ",")
+		record.Transports = &value
+	}
+	if len(credential.Authenticator.AAGUID) > 0 {
+		aaguid := hex.EncodeToString(credential.Authenticator.AAGUID)
+		record.AAGUID = &aaguid
+	}
+	return record, nil
+}
+
+// webAuthnCredentialFromRecord восстанавливает ключ из сохраненной записи
+func webAuthnCredentialFromRecord(record *typescore.WebAuthnCredential) (webauthn.Credential, error) {
+	if record.CredentialID == nil || record.PublicKey == nil {
+		return webauthn.Credential{}, errors.New("credential record is incomplete")
+	}
+
+	credentialID, err := webAuthnEncoding.DecodeString(*record.CredentialID)
+	if err != nil {
+		return webauthn.Credential{}, err
+	}
+	publicKey, err := webAuthnEncoding.DecodeString(*record.PublicKey)
+	if err != nil {
+		return webauthn.Credential{}, err
+	}
+
+	credential := webauthn.Credential{
+		ID:        credentialID,
+		PublicKey: publicKey,
+	}
+	if record.AttestationType != nil {
+		credential.AttestationType = *record.AttestationType
+	}
+	if record.Transports != nil && *record.Transports != "" {
+		for _, transport := range strings.Split(*record.Transports, ",") {
+			credential.Transport = append(credential.Transport, protocol.AuthenticatorTransport(transport))
+		}
+	}
+	if record.AAGUID != nil {
+		if credential.Authenticator.AAGUID, err = hex.DecodeString(*record.AAGUID); err != nil {
+			return webauthn.Credential{}, err
+		}
+	}
+	if record.SignCount != nil {
+		credential.Authenticator.SignCount = uint32(*record.SignCount)
+	}
+	if record.BackupEligible != nil {
+		credential.Flags.BackupEligible = *record.BackupEligible
+	}
+	if record.BackupState != nil {
+		credential.Flags.BackupState = *record.BackupState
+	}
+	return credential, nil
+}
+
+// SaveWebAuthnSession сохраняет состояние церемонии до её завершения
+func SaveWebAuthnSession(ctx context.Context, store kvstore.Store, key string, session *webauthn.SessionData) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	ttl := defaultWebAuthnTimeout
+	if !session.Expires.IsZero() {
+		ttl = time.Until(session.Expires)
+	}
+	return store.Set(ctx, webAuthnSessionKeyPrefix+key, string(data), ttl)
+}
+
+// TakeWebAuthnSession атомарно забирает состояние церемонии: challenge используется один раз,
+// даже если завершение церемонии прислали параллельно
+func TakeWebAuthnSession(ctx context.Context, store kvstore.Store, key string) (*webauthn.SessionData, error) {
+	value, ok, err := store.Take(ctx, webAuthnSessionKeyPrefix+key)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrWebAuthnSessionNotFound
+	}
+
+	session := &webauthn.SessionData{}
+	if err := json.Unmarshal([]byte(value), session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// AccountName возвращает имя аккаунта для аутентификаторов: email, никнейм или system_id
+func AccountName(user *typescore.User) string {
+	switch {
+	case user.Email != nil && *user.Email != "":
+		return *user.Email
+	case user.Nickname != nil && *user.Nickname != "":
+		return *user.Nickname
+	default:
+		return *user.SystemID
+	}
+}
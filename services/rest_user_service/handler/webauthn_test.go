@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"authentication_service/core/lib/internally/kvstore"
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+func TestTakeWebAuthnSessionSingleUse(t *testing.T) {
+	ctx := context.Background()
+	store := kvstore.NewMemoryStore()
+	session := &webauthn.SessionData{Challenge: "challenge", Expires: time.Now().Add(time.Minute)}
+	if err := SaveWebAuthnSession(ctx, store, "ceremony", session); err != nil {
+		t.Fatalf("SaveWebAuthnSession() error = %v", err)
+	}
+
+	var wg sync.WaitGroup
+	var winners atomic.Int32
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got, err := TakeWebAuthnSession(ctx, store, "ceremony")
+			switch {
+			case err == nil:
+				if got.Challenge != session.Challenge {
+					t.Errorf("challenge = %q, want %q", got.Challenge, session.Challenge)
+				}
+				winners.Add(1)
+			case !errors.Is(err, ErrWebAuthnSessionNotFound):
+				t.Errorf("TakeWebAuthnSession() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := winners.Load(); got != 1 {
+		t.Fatalf("session taken %d times, want once", got)
+	}
+}
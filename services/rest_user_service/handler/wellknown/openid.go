@@ -0,0 +1,121 @@
+package wellknownhandler
+
+import (
+	errm "authentication_service/core/errmodule"
+	"authentication_service/core/typescore"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// время кэширования метаданных провайдера клиентами
+const openIDConfigurationCacheControl = "public, max-age=300"
+
+// Пути сервера авторизации относительно issuer
+const (
+	authorizationPath = "/oauth/authorize"
+	tokenPath         = "/oauth/token"
+	userInfoPath      = "/oauth/userinfo"
+	jwksPath          = "/.well-known/jwks.json"
+	introspectionPath = "/api/auth/introspect"
+)
+
+// OpenIDConfigurationResp метаданные провайдера OpenID Connect (OpenID Connect Discovery 1.0, раздел 3)
+type OpenIDConfigurationResp struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserInfoEndpoint                  string   `json:"userinfo_endpoint"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	IntrospectionEndpoint             string   `json:"introspection_endpoint"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+}
+
+// GetOpenIDConfigurationHandler Метаданные провайдера OpenID Connect
+// @Summary Метаданные провайдера OpenID Connect
+// @Description Возвращает адреса эндпоинтов и поддерживаемые параметры сервера авторизации для стандартных библиотек OpenID Connect.
+// @Description Адреса строятся от issuer сервиса авторизации, поэтому сервис должен быть доступен по этому адресу
+// @Tags well-known
+// @Produce json
+// @Success 200 {object} OpenIDConfigurationResp "Успех"
+// @Failure 500 {object} handler.ErrorResponse "Не настроен issuer или асимметричный ключ подписи"
+// @Router /.well-known/openid-configuration [get]
+func (s *WellKnownReg) GetOpenIDConfigurationHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
+	logrus.Info("🤍 GetOpenIDConfigurationHandler")
+
+	issuer := strings.TrimSuffix(s.ipc.TokenPolicy.Issuer, "/")
+	if issuer == "" {
+		return nil, errm.NewError("issuer_not_configured", errors.New("auth_service.issuer is not configured"))
+	}
+	if s.ipc.Keyring == nil {
+		return nil, errm.NewError("jwks_not_found", errors.New("signing keys not configured"))
+	}
+	// ID токены проверяются клиентами по JWKS, поэтому нужен асимметричный ключ
+	alg, err := s.ipc.Keyring.PublicAlgorithm()
+	if err != nil {
+		return nil, errm.NewError("signing_key_not_supported", err)
+	}
+
+	authorizationEndpoint := s.ipc.Config.ExposedServiceConfig.UserService.OAuthServer.AuthorizationPageURL
+	if authorizationEndpoint == "" {
+		authorizationEndpoint = issuer + authorizationPath
+	}
+
+	w.Header().Set("Cache-Control", openIDConfigurationCacheControl)
+	return &OpenIDConfigurationResp{
+		Issuer:                 issuer,
+		AuthorizationEndpoint:  authorizationEndpoint,
+		TokenEndpoint:          issuer + tokenPath,
+		UserInfoEndpoint:       issuer + userInfoPath,
+		JWKSURI:                issuer + jwksPath,
+		IntrospectionEndpoint:  issuer + introspectionPath,
+		ScopesSupported:        supportedScopes(),
+		ResponseTypesSupported: []string{"code"},
+		GrantTypesSupported: []string{
+			typescore.GrantTypeAuthorizationCode,
+			typescore.GrantTypeRefreshToken,
+			typescore.GrantTypeClientCredentials,
+		},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{alg},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{"S256"},
+		ClaimsSupported: []string{
+			"sub", "iss", "aud", "exp", "iat", "nonce", "azp", "auth_time", "amr",
+			"name", "given_name", "family_name", "preferred_username", "email", "email_verified",
+		},
+	}, nil
+}
+
+// supportedScopes возвращает области доступа OpenID Connect и области доступа ролей
+func supportedScopes() []string {
+	seen := make(map[string]struct{})
+	scopes := make([]string, 0)
+	for _, scope := range typescore.OIDCScopes {
+		seen[string(scope)] = struct{}{}
+		scopes = append(scopes, string(scope))
+	}
+
+	roleScopes := make([]string, 0)
+	for _, list := range typescore.RoleScopes {
+		for _, scope := range list {
+			if _, ok := seen[string(scope)]; !ok {
+				seen[string(scope)] = struct{}{}
+				roleScopes = append(roleScopes, string(scope))
+			}
+		}
+	}
+	// Стабильный порядок областей доступа в ответе
+	sort.Strings(roleScopes)
+
+	return append(scopes, roleScopes...)
+}
@@ -0,0 +1,36 @@
+package wellknownhandler
+
+import (
+	errm "authentication_service/core/errmodule"
+	"authentication_service/rest_user_service/handler"
+	typesm "authentication_service/rest_user_service/types"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+)
+
+const (
+	jwksURI                = "/jwks.json"
+	openIDConfigurationURI = "/openid-configuration"
+)
+
+type WellKnownReg struct {
+	ipc *typesm.InternalProviderControl
+}
+
+// RegisterWellKnownRoutes регистрирует маршруты для группы .well-known
+func RegisterWellKnownRoutes(
+	r chi.Router,
+	ipc *typesm.InternalProviderControl,
+) *errm.Error {
+
+	s := &WellKnownReg{
+		ipc: ipc,
+	}
+
+	r.Route("/.well-known", func(r chi.Router) {
+		handler.RegisterRoute(r, http.MethodGet, jwksURI, s.GetJWKSHandler)
+		handler.RegisterRoute(r, http.MethodGet, openIDConfigurationURI, s.GetOpenIDConfigurationHandler)
+	})
+
+	return nil
+}
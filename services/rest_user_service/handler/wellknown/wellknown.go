@@ -0,0 +1,30 @@
+package wellknownhandler
+
+import (
+	errm "authentication_service/core/errmodule"
+	"errors"
+	"github.com/sirupsen/logrus"
+	"net/http"
+)
+
+// время кэширования набора ключей клиентами
+const jwksCacheControl = "public, max-age=300"
+
+// GetJWKSHandler Публичные ключи проверки токенов
+// @Summary Публичные ключи проверки токенов
+// @Description Возвращает набор публичных ключей (JWKS) для проверки подписи токенов по заголовку kid
+// @Tags well-known
+// @Produce json
+// @Success 200 {object} securecore.JWKSet "Успех"
+// @Failure 500 {object} handler.ErrorResponse "Ошибка сервера"
+// @Router /.well-known/jwks.json [get]
+func (s *WellKnownReg) GetJWKSHandler(w http.ResponseWriter, r *http.Request) (interface{}, *errm.Error) {
+	logrus.Info("🤍 GetJWKSHandler")
+
+	if s.ipc.Keyring == nil {
+		return nil, errm.NewError("jwks_not_found", errors.New("signing keys not configured"))
+	}
+
+	w.Header().Set("Cache-Control", jwksCacheControl)
+	return s.ipc.Keyring.JWKS(), nil
+}
@@ -4,7 +4,15 @@ import (
 	"authentication_service/core/configcore"
 	"authentication_service/core/database"
 	rabbitmqlib "authentication_service/core/lib/external/rabbitmq-lib"
+	"authentication_service/core/lib/internally/denylist"
+	"authentication_service/core/lib/internally/kvstore"
+	"authentication_service/core/lib/internally/lockout"
+	"authentication_service/core/lib/internally/onetimecode"
 	protoobj "authentication_service/core/proto"
+	"authentication_service/core/securecore"
+	"authentication_service/rest_user_service/upstream"
+
+	"github.com/go-webauthn/webauthn/webauthn"
 )
 
 type InternalProviderControl struct {
@@ -12,4 +20,14 @@ type InternalProviderControl struct {
 	Config                 *configcore.Config
 	DB                     *database.ModuleDB
 	ClientAuthServiceProto protoobj.AuthServiceClient
+	TokenDenylist          *denylist.TokenDenylist
+	OneTimeCodes           *onetimecode.Store
+	Lockout                *lockout.Tracker // учет неудачных попыток аутентификации
+	KVStore                kvstore.Store
+	SecretCipher           *securecore.SecretCipher // nil, если ключ шифрования секретов не задан
+	WebAuthn               *webauthn.WebAuthn       // nil, если вход по ключам доступа не настроен
+	OIDC                   *upstream.Registry       // внешние провайдеры OpenID Connect
+	Keyring                *securecore.Keyring
+	TokenFormat            securecore.TokenFormat
+	TokenPolicy            securecore.TokenPolicy
 }
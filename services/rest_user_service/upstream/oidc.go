@@ -0,0 +1,224 @@
+package upstream
+
+import (
+	"authentication_service/core/configcore"
+	"authentication_service/core/lib/internally/onetimecode"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// время ожидания ответа провайдера, если HTTP клиент не передан
+const defaultHTTPTimeout = 10 * time.Second
+
+var defaultScopes = []string{oidc.ScopeOpenID, "email", "profile"}
+
+var (
+	// ErrUnknownProvider провайдер не настроен
+	ErrUnknownProvider = errors.New("unknown identity provider")
+	// ErrInvalidIDToken ID токен провайдера не прошел проверку
+	ErrInvalidIDToken = errors.New("invalid id token")
+)
+
+// Identity учетная запись пользователя у провайдера, подтвержденная ID токеном
+type Identity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	GivenName     string
+	FamilyName    string
+}
+
+// VerifiedEmail email, владение которым подтвердил провайдер, или пустая строка
+func (i *Identity) VerifiedEmail() string {
+	if !i.EmailVerified {
+		return ""
+	}
+	return i.Email
+}
+
+// AuthRequest параметры одного входа через провайдера.
+// Хранятся на стороне сервиса до возврата пользователя с кодом авторизации.
+type AuthRequest struct {
+	Provider     string `json:"provider"`
+	State        string `json:"-"` // ключ, по которому хранится запрос
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"` // PKCE (RFC 7636)
+}
+
+// idTokenClaims claims профиля в ID токене
+type idTokenClaims struct {
+	Email         string `json:"email"`
+	EmailVerified *bool  `json:"email_verified"`
+	GivenName     string `json:"given_name"`
+	FamilyName    string `json:"family_name"`
+}
+
+// Registry набор провайдеров OpenID Connect из конфигурации.
+// Все обращения к провайдерам выполняются через переданный HTTP клиент.
+type Registry struct {
+	providers map[string]*Provider
+}
+
+// NewRegistry создает набор провайдеров. Конфигурация провайдера (discovery)
+// загружается при первом обращении, поэтому недоступность провайдера не мешает запуску сервиса.
+// Если client равен nil, используется клиент с таймаутом по умолчанию.
+func NewRegistry(cfg configcore.OIDCConfig, client *http.Client) (*Registry, error) {
+	if client == nil {
+		client = &http.Client{Timeout: defaultHTTPTimeout}
+	}
+
+	r := &Registry{providers: make(map[string]*Provider, len(cfg.Providers))}
+	for _, providerCfg := range cfg.Providers {
+		if providerCfg.Name == "" || providerCfg.Issuer == "" || providerCfg.ClientID == "" || providerCfg.RedirectURL == "" {
+			return nil, fmt.Errorf("oidc provider %q: name, issuer, client_id and redirect_url are required", providerCfg.Name)
+		}
+		if _, exists := r.providers[providerCfg.Name]; exists {
+			return nil, fmt.Errorf("oidc provider %q: duplicate name", providerCfg.Name)
+		}
+		r.providers[providerCfg.Name] = &Provider{cfg: providerCfg, client: client}
+	}
+	return r, nil
+}
+
+// Provider возвращает провайдера по имени
+func (r *Registry) Provider(name string) (*Provider, error) {
+	if r == nil {
+		return nil, ErrUnknownProvider
+	}
+	p, ok := r.providers[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	return p, nil
+}
+
+// Provider провайдер OpenID Connect
+type Provider struct {
+	cfg    configcore.OIDCProviderConfig
+	client *http.Client
+
+	mu       sync.Mutex
+	oauth2   *oauth2.Config
+	verifier *oidc.IDTokenVerifier
+}
+
+// Name имя провайдера из конфигурации
+func (p *Provider) Name() string {
+	return p.cfg.Name
+}
+
+// TrustEmail провайдер подтверждает владение email, и по нему можно привязать существующий аккаунт
+func (p *Provider) TrustEmail() bool {
+	return p.cfg.TrustEmail
+}
+
+// CanLinkByEmail сообщает, можно ли привязать учетную запись провайдера к существующему аккаунту
+// с тем же email: провайдер должен быть доверенным, а адрес — подтвержденным провайдером.
+// Иначе владелец адреса у провайдера получил бы доступ к чужому аккаунту.
+func (p *Provider) CanLinkByEmail(identity *Identity) bool {
+	return p.cfg.TrustEmail && identity.VerifiedEmail() != ""
+}
+
+// NewAuthRequest создает state, nonce и верификатор PKCE для нового входа через провайдера
+func (p *Provider) NewAuthRequest() (*AuthRequest, error) {
+	state, err := onetimecode.GenerateLinkToken()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := onetimecode.GenerateLinkToken()
+	if err != nil {
+		return nil, err
+	}
+	return &AuthRequest{Provider: p.cfg.Name, State: state, Nonce: nonce, CodeVerifier: oauth2.GenerateVerifier()}, nil
+}
+
+// AuthCodeURL возвращает адрес страницы авторизации провайдера
+func (p *Provider) AuthCodeURL(ctx context.Context, req *AuthRequest) (string, error) {
+	oauth2Cfg, _, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	return oauth2Cfg.AuthCodeURL(req.State,
+		oidc.Nonce(req.Nonce),
+		oauth2.S256ChallengeOption(req.CodeVerifier),
+	), nil
+}
+
+// Exchange обменивает код авторизации на токены и проверяет ID токен:
+// подпись по JWKS провайдера, issuer, audience, срок действия и nonce
+func (p *Provider) Exchange(ctx context.Context, req *AuthRequest, code string) (*Identity, error) {
+	oauth2Cfg, verifier, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = oidc.ClientContext(ctx, p.client)
+	token, err := oauth2Cfg.Exchange(ctx, code, oauth2.VerifierOption(req.CodeVerifier))
+	if err != nil {
+		return nil, fmt.Errorf("exchange code: %w", err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, fmt.Errorf("%w: id_token is missing", ErrInvalidIDToken)
+	}
+	idToken, err := verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+	if idToken.Nonce != req.Nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	}
+
+	var claims idTokenClaims
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	return &Identity{
+		Provider:      p.cfg.Name,
+		Subject:       idToken.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified != nil && *claims.EmailVerified,
+		GivenName:     claims.GivenName,
+		FamilyName:    claims.FamilyName,
+	}, nil
+}
+
+// discover загружает конфигурацию провайдера при первом обращении.
+// При ошибке загрузка повторяется при следующем обращении.
+func (p *Provider) discover(ctx context.Context) (*oauth2.Config, *oidc.IDTokenVerifier, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.oauth2 != nil {
+		return p.oauth2, p.verifier, nil
+	}
+
+	provider, err := oidc.NewProvider(oidc.ClientContext(ctx, p.client), p.cfg.Issuer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("oidc provider %q discovery: %w", p.cfg.Name, err)
+	}
+
+	scopes := p.cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = defaultScopes
+	}
+	p.oauth2 = &oauth2.Config{
+		ClientID:     p.cfg.ClientID,
+		ClientSecret: p.cfg.ClientSecret,
+		RedirectURL:  p.cfg.RedirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       scopes,
+	}
+	p.verifier = provider.Verifier(&oidc.Config{ClientID: p.cfg.ClientID})
+	return p.oauth2, p.verifier, nil
+}
@@ -0,0 +1,451 @@
+package upstream
+
+import (
+	"authentication_service/core/configcore"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testClientID    = "test-client"
+	testRedirectURL = "https://app.example.com/oidc/callback"
+	testKeyID       = "test-key"
+	testSubject     = "provider-user-1"
+)
+
+// fakeOIDCProvider провайдер OpenID Connect в процессе теста:
+// discovery, JWKS, страница авторизации (без браузера) и token endpoint с проверкой PKCE
+type fakeOIDCProvider struct {
+	server *httptest.Server
+
+	publishedKey *rsa.PrivateKey // ключ, опубликованный в JWKS
+	signingKey   *rsa.PrivateKey // ключ подписи ID токенов
+
+	discoveryIssuer string                     // issuer в документе discovery; по умолчанию адрес сервера
+	claims          func(claims jwt.MapClaims) // изменения claims ID токена
+	omitIDToken     bool
+	failDiscovery   atomic.Bool
+	discoveryHits   atomic.Int32
+
+	mu     sync.Mutex
+	grants map[string]fakeGrant // code -> параметры авторизации
+}
+
+type fakeGrant struct {
+	challenge string
+	nonce     string
+}
+
+func newFakeOIDCProvider(t *testing.T) *fakeOIDCProvider {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey: %v", err)
+	}
+
+	f := &fakeOIDCProvider{publishedKey: key, signingKey: key, grants: map[string]fakeGrant{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", f.handleDiscovery)
+	mux.HandleFunc("/jwks", f.handleJWKS)
+	mux.HandleFunc("/token", f.handleToken)
+	f.server = httptest.NewServer(mux)
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeOIDCProvider) issuer() string {
+	return f.server.URL
+}
+
+func (f *fakeOIDCProvider) registry(t *testing.T, trustEmail bool) *Provider {
+	t.Helper()
+	registry, err := NewRegistry(configcore.OIDCConfig{Providers: []configcore.OIDCProviderConfig{{
+		Name:         "fake",
+		Issuer:       f.issuer(),
+		ClientID:     testClientID,
+		ClientSecret: "secret",
+		RedirectURL:  testRedirectURL,
+		TrustEmail:   trustEmail,
+	}}}, f.server.Client())
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+	provider, err := registry.Provider("fake")
+	if err != nil {
+		t.Fatalf("Provider: %v", err)
+	}
+	return provider
+}
+
+func (f *fakeOIDCProvider) handleDiscovery(w http.ResponseWriter, r *http.Request) {
+	f.discoveryHits.Add(1)
+	if f.failDiscovery.Load() {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	issuer := f.discoveryIssuer
+	if issuer == "" {
+		issuer = f.issuer()
+	}
+	writeJSON(w, map[string]interface{}{
+		"issuer":                                issuer,
+		"authorization_endpoint":                f.issuer() + "/authorize",
+		"token_endpoint":                        f.issuer() + "/token",
+		"jwks_uri":                              f.issuer() + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+func (f *fakeOIDCProvider) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	pub := f.publishedKey.PublicKey
+	writeJSON(w, map[string]interface{}{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": testKeyID,
+		"use": "sig",
+		"alg": "RS256",
+		"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+	}}})
+}
+
+// authorize имитирует вход пользователя у провайдера: запоминает code_challenge и nonce и возвращает код
+func (f *fakeOIDCProvider) authorize(t *testing.T, authURL string) string {
+	t.Helper()
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("parse authorization url: %v", err)
+	}
+	query := u.Query()
+	if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		t.Fatalf("authorization url has no S256 PKCE challenge: %s", authURL)
+	}
+
+	code := "code-" + query.Get("state")
+	f.mu.Lock()
+	f.grants[code] = fakeGrant{challenge: query.Get("code_challenge"), nonce: query.Get("nonce")}
+	f.mu.Unlock()
+	return code
+}
+
+func (f *fakeOIDCProvider) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		tokenError(w, "invalid_request")
+		return
+	}
+
+	f.mu.Lock()
+	grant, ok := f.grants[r.PostForm.Get("code")]
+	delete(f.grants, r.PostForm.Get("code"))
+	f.mu.Unlock()
+	if !ok {
+		tokenError(w, "invalid_grant")
+		return
+	}
+	// PKCE (RFC 7636): BASE64URL(SHA256(code_verifier)) должен совпасть с code_challenge
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != grant.challenge {
+		tokenError(w, "invalid_grant")
+		return
+	}
+
+	resp := map[string]interface{}{"access_token": "access", "token_type": "Bearer", "expires_in": 3600}
+	if !f.omitIDToken {
+		now := time.Now()
+		claims := jwt.MapClaims{
+			"iss":            f.issuer(),
+			"aud":            testClientID,
+			"sub":            testSubject,
+			"iat":            now.Unix(),
+			"exp":            now.Add(time.Hour).Unix(),
+			"nonce":          grant.nonce,
+			"email":          "user@example.com",
+			"email_verified": true,
+			"given_name":     "Ivan",
+			"family_name":    "Petrov",
+		}
+		if f.claims != nil {
+			f.claims(claims)
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = testKeyID
+		idToken, err := token.SignedString(f.signingKey)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		resp["id_token"] = idToken
+	}
+	writeJSON(w, resp)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func tokenError(w http.ResponseWriter, code string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": code})
+}
+
+// login проходит вход у провайдера и обменивает код
+func login(t *testing.T, f *fakeOIDCProvider, provider *Provider) (*Identity, error) {
+	t.Helper()
+	ctx := context.Background()
+	authReq, err := provider.NewAuthRequest()
+	if err != nil {
+		t.Fatalf("NewAuthRequest: %v", err)
+	}
+	authURL, err := provider.AuthCodeURL(ctx, authReq)
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	return provider.Exchange(ctx, authReq, f.authorize(t, authURL))
+}
+
+func TestProviderDiscovery(t *testing.T) {
+	f := newFakeOIDCProvider(t)
+	provider := f.registry(t, false)
+	ctx := context.Background()
+
+	authReq, err := provider.NewAuthRequest()
+	if err != nil {
+		t.Fatalf("NewAuthRequest: %v", err)
+	}
+	authURL, err := provider.AuthCodeURL(ctx, authReq)
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	if !strings.HasPrefix(authURL, f.issuer()+"/authorize?") {
+		t.Fatalf("authorization url = %s, want provider authorization_endpoint", authURL)
+	}
+
+	u, _ := url.Parse(authURL)
+	query := u.Query()
+	want := map[string]string{
+		"client_id":     testClientID,
+		"redirect_uri":  testRedirectURL,
+		"response_type": "code",
+		"state":         authReq.State,
+		"nonce":         authReq.Nonce,
+		"scope":         "openid email profile",
+	}
+	for key, value := range want {
+		if got := query.Get(key); got != value {
+			t.Errorf("authorization url %s = %q, want %q", key, got, value)
+		}
+	}
+
+	// Конфигурация загружается один раз
+	if _, err := provider.AuthCodeURL(ctx, authReq); err != nil {
+		t.Fatalf("second AuthCodeURL: %v", err)
+	}
+	if got := f.discoveryHits.Load(); got != 1 {
+		t.Fatalf("discovery requested %d times, want 1", got)
+	}
+}
+
+func TestProviderDiscoveryErrors(t *testing.T) {
+	t.Run("issuer mismatch", func(t *testing.T) {
+		f := newFakeOIDCProvider(t)
+		f.discoveryIssuer = "https://evil.example.com"
+		provider := f.registry(t, false)
+		authReq, _ := provider.NewAuthRequest()
+		if _, err := provider.AuthCodeURL(context.Background(), authReq); err == nil {
+			t.Fatal("AuthCodeURL() error = nil for foreign issuer in discovery")
+		}
+	})
+
+	t.Run("retry after provider outage", func(t *testing.T) {
+		f := newFakeOIDCProvider(t)
+		f.failDiscovery.Store(true)
+		provider := f.registry(t, false)
+		authReq, _ := provider.NewAuthRequest()
+		if _, err := provider.AuthCodeURL(context.Background(), authReq); err == nil {
+			t.Fatal("AuthCodeURL() error = nil while provider is unavailable")
+		}
+		f.failDiscovery.Store(false)
+		if _, err := provider.AuthCodeURL(context.Background(), authReq); err != nil {
+			t.Fatalf("AuthCodeURL() after recovery error = %v", err)
+		}
+	})
+}
+
+func TestProviderExchange(t *testing.T) {
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		setup   func(f *fakeOIDCProvider)
+		wantErr error
+	}{
+		{"valid", func(f *fakeOIDCProvider) {}, nil},
+		{"signed with unknown key", func(f *fakeOIDCProvider) { f.signingKey = otherKey }, ErrInvalidIDToken},
+		{"foreign issuer", func(f *fakeOIDCProvider) {
+			f.claims = func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }
+		}, ErrInvalidIDToken},
+		{"foreign audience", func(f *fakeOIDCProvider) {
+			f.claims = func(c jwt.MapClaims) { c["aud"] = "other-client" }
+		}, ErrInvalidIDToken},
+		{"nonce mismatch", func(f *fakeOIDCProvider) {
+			f.claims = func(c jwt.MapClaims) { c["nonce"] = "replayed-nonce" }
+		}, ErrInvalidIDToken},
+		{"expired", func(f *fakeOIDCProvider) {
+			f.claims = func(c jwt.MapClaims) {
+				c["iat"] = time.Now().Add(-2 * time.Hour).Unix()
+				c["exp"] = time.Now().Add(-time.Hour).Unix()
+			}
+		}, ErrInvalidIDToken},
+		{"missing id_token", func(f *fakeOIDCProvider) { f.omitIDToken = true }, ErrInvalidIDToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeOIDCProvider(t)
+			tt.setup(f)
+			identity, err := login(t, f, f.registry(t, false))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Exchange() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			want := Identity{
+				Provider:      "fake",
+				Subject:       testSubject,
+				Email:         "user@example.com",
+				EmailVerified: true,
+				GivenName:     "Ivan",
+				FamilyName:    "Petrov",
+			}
+			if *identity != want {
+				t.Fatalf("Exchange() identity = %+v, want %+v", *identity, want)
+			}
+		})
+	}
+}
+
+func TestProviderExchangePKCE(t *testing.T) {
+	f := newFakeOIDCProvider(t)
+	provider := f.registry(t, false)
+	ctx := context.Background()
+
+	authReq, err := provider.NewAuthRequest()
+	if err != nil {
+		t.Fatalf("NewAuthRequest: %v", err)
+	}
+	authURL, err := provider.AuthCodeURL(ctx, authReq)
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	u, _ := url.Parse(authURL)
+	sum := sha256.Sum256([]byte(authReq.CodeVerifier))
+	if got := u.Query().Get("code_challenge"); got != base64.RawURLEncoding.EncodeToString(sum[:]) {
+		t.Fatalf("code_challenge = %q, want S256 of code_verifier", got)
+	}
+
+	// Перехваченный код без верификатора из хранилища сервиса не обменивается
+	stolen := *authReq
+	stolen.CodeVerifier = "attacker-verifier-attacker-verifier-attacker"
+	if _, err := provider.Exchange(ctx, &stolen, f.authorize(t, authURL)); err == nil {
+		t.Fatal("Exchange() with foreign code_verifier error = nil")
+	}
+
+	if _, err := provider.Exchange(ctx, authReq, f.authorize(t, authURL)); err != nil {
+		t.Fatalf("Exchange() with original code_verifier error = %v", err)
+	}
+}
+
+func TestIdentityEmailLinking(t *testing.T) {
+	tests := []struct {
+		name          string
+		trustEmail    bool
+		emailVerified interface{} // значение claim email_verified; nil — claim отсутствует
+		wantVerified  string
+		wantLink      bool
+	}{
+		{"trusted provider, verified email", true, true, "user@example.com", true},
+		{"untrusted provider, verified email", false, true, "user@example.com", false},
+		{"trusted provider, unverified email", true, false, "", false},
+		{"trusted provider, no email_verified claim", true, nil, "", false},
+		{"untrusted provider, unverified email", false, false, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeOIDCProvider(t)
+			f.claims = func(c jwt.MapClaims) {
+				if tt.emailVerified == nil {
+					delete(c, "email_verified")
+				} else {
+					c["email_verified"] = tt.emailVerified
+				}
+			}
+			provider := f.registry(t, tt.trustEmail)
+
+			identity, err := login(t, f, provider)
+			if err != nil {
+				t.Fatalf("Exchange() error = %v", err)
+			}
+			if got := identity.VerifiedEmail(); got != tt.wantVerified {
+				t.Fatalf("VerifiedEmail() = %q, want %q", got, tt.wantVerified)
+			}
+			if got := provider.CanLinkByEmail(identity); got != tt.wantLink {
+				t.Fatalf("CanLinkByEmail() = %v, want %v", got, tt.wantLink)
+			}
+		})
+	}
+}
+
+func TestNewRegistry(t *testing.T) {
+	valid := configcore.OIDCProviderConfig{Name: "google", Issuer: "https://accounts.google.com", ClientID: "id", RedirectURL: testRedirectURL}
+	missingIssuer := valid
+	missingIssuer.Issuer = ""
+
+	tests := []struct {
+		name      string
+		providers []configcore.OIDCProviderConfig
+		wantErr   bool
+	}{
+		{"no providers", nil, false},
+		{"valid", []configcore.OIDCProviderConfig{valid}, false},
+		{"missing issuer", []configcore.OIDCProviderConfig{missingIssuer}, true},
+		{"duplicate name", []configcore.OIDCProviderConfig{valid, valid}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry, err := NewRegistry(configcore.OIDCConfig{Providers: tt.providers}, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewRegistry() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				if _, err := registry.Provider("unknown"); !errors.Is(err, ErrUnknownProvider) {
+					t.Fatalf("Provider(unknown) error = %v, want %v", err, ErrUnknownProvider)
+				}
+			}
+		})
+	}
+}